@@ -19,7 +19,9 @@ import (
 	"context"
 	"net"
 	"net/http"
+	"strings"
 
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 )
 
@@ -28,6 +30,9 @@ type ctxKey struct{}
 // Client represents a generic client that sends data to any receiver supported by the OT receiver
 type Client struct {
 	IP string
+	// Metadata is the metadata sent by the client with the data, e.g. the gRPC metadata or the HTTP headers.
+	// The keys are lower case.
+	Metadata map[string][]string
 }
 
 // NewContext takes an existing context and derives a new context with the client value stored on it
@@ -46,19 +51,49 @@ func FromGRPC(ctx context.Context) (*Client, bool) {
 	if p, ok := peer.FromContext(ctx); ok {
 		ip := parseIP(p.Addr.String())
 		if ip != "" {
-			return &Client{ip}, true
+			return &Client{IP: ip, Metadata: grpcMetadata(ctx)}, true
 		}
 	}
 	return nil, false
 }
 
+func grpcMetadata(ctx context.Context) map[string][]string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+	// The keys of the gRPC metadata are already lower case.
+	return md.Copy()
+}
+
 // FromHTTP takes a net/http Request object and tries to extract client information from it
 func FromHTTP(r *http.Request) (*Client, bool) {
 	ip := parseIP(r.RemoteAddr)
 	if ip == "" {
 		return nil, false
 	}
-	return &Client{ip}, true
+	return &Client{IP: ip, Metadata: httpMetadata(r.Header)}, true
+}
+
+func httpMetadata(header http.Header) map[string][]string {
+	if len(header) == 0 {
+		return nil
+	}
+	md := make(map[string][]string, len(header))
+	for key, values := range header {
+		// Copy the values, so later changes to the header do not change the metadata.
+		md[strings.ToLower(key)] = append([]string(nil), values...)
+	}
+	return md
+}
+
+// MetadataValue returns the first value of the metadata key, looked up case-insensitively.
+func (c *Client) MetadataValue(key string) (string, bool) {
+	values := c.Metadata[strings.ToLower(key)]
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
 }
 
 func parseIP(source string) string {
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 )
 
@@ -30,7 +31,7 @@ func TestClientContext(t *testing.T) {
 		"1.1.1.1", "127.0.0.1", "1111", "ip",
 	}
 	for _, ip := range ips {
-		ctx := NewContext(context.Background(), &Client{ip})
+		ctx := NewContext(context.Background(), &Client{IP: ip})
 		c, ok := FromContext(ctx)
 		assert.True(t, ok)
 		assert.NotNil(t, c)
@@ -58,3 +59,37 @@ func TestParsingHTTP(t *testing.T) {
 	assert.NotNil(t, client)
 	assert.Equal(t, client.IP, "192.168.1.2")
 }
+
+func TestMetadataGRPC(t *testing.T) {
+	grpcCtx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{
+			IP:   net.ParseIP("192.168.1.1"),
+			Port: 80,
+		},
+	})
+	grpcCtx = metadata.NewIncomingContext(grpcCtx, metadata.Pairs("X-Tenant", "tenant-a"))
+
+	client, ok := FromGRPC(grpcCtx)
+	assert.True(t, ok)
+	value, ok := client.MetadataValue("x-tenant")
+	assert.True(t, ok)
+	assert.Equal(t, "tenant-a", value)
+}
+
+func TestMetadataHTTP(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Tenant", "tenant-a")
+	client, ok := FromHTTP(&http.Request{RemoteAddr: "192.168.1.2", Header: header})
+	assert.True(t, ok)
+	value, ok := client.MetadataValue("X-TENANT")
+	assert.True(t, ok)
+	assert.Equal(t, "tenant-a", value)
+
+	_, ok = client.MetadataValue("x-missing")
+	assert.False(t, ok)
+
+	// The metadata is not changed by later changes to the header.
+	header["X-Tenant"][0] = "tenant-b"
+	header.Add("X-Tenant", "tenant-c")
+	assert.Equal(t, []string{"tenant-a"}, client.Metadata["x-tenant"])
+}
@@ -148,6 +148,12 @@ func (cfg *Config) validateService() error {
 				return fmt.Errorf("pipeline %q references exporter %q which does not exist", pipelineID, ref)
 			}
 		}
+
+		if pipeline.Routing != nil {
+			if err := pipeline.Routing.validate(pipeline.Exporters); err != nil {
+				return fmt.Errorf("pipeline %q has invalid routing: %w", pipelineID, err)
+			}
+		}
 	}
 	return nil
 }
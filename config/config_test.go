@@ -160,6 +160,98 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expected: errors.New(`pipeline "traces" must have at least one exporter`),
 		},
+		{
+			name: "invalid-routing-source",
+			cfgFn: func() *Config {
+				cfg := generateConfig()
+				pipe := cfg.Service.Pipelines[NewComponentID("traces")]
+				pipe.Routing = &PipelineRouting{
+					Table: []PipelineRoute{{Value: "a", Exporters: []ComponentID{NewComponentID("nop")}}},
+				}
+				return cfg
+			},
+			expected: fmt.Errorf(`pipeline "traces" has invalid routing: %w`, errors.New(`routing must have exactly one of from_attribute and from_context`)),
+		},
+		{
+			name: "missing-routing-table",
+			cfgFn: func() *Config {
+				cfg := generateConfig()
+				pipe := cfg.Service.Pipelines[NewComponentID("traces")]
+				pipe.Routing = &PipelineRouting{FromAttribute: "tenant"}
+				return cfg
+			},
+			expected: fmt.Errorf(`pipeline "traces" has invalid routing: %w`, errors.New(`routing table must have at least one route`)),
+		},
+		{
+			name: "duplicate-routing-value",
+			cfgFn: func() *Config {
+				cfg := generateConfig()
+				pipe := cfg.Service.Pipelines[NewComponentID("traces")]
+				pipe.Routing = &PipelineRouting{
+					FromContext: "x-tenant",
+					Table: []PipelineRoute{
+						{Value: "a", Exporters: []ComponentID{NewComponentID("nop")}},
+						{Value: "a", Exporters: []ComponentID{NewComponentID("nop")}},
+					},
+				}
+				return cfg
+			},
+			expected: fmt.Errorf(`pipeline "traces" has invalid routing: %w`, errors.New(`routing table has duplicate value "a"`)),
+		},
+		{
+			name: "missing-route-exporters",
+			cfgFn: func() *Config {
+				cfg := generateConfig()
+				pipe := cfg.Service.Pipelines[NewComponentID("traces")]
+				pipe.Routing = &PipelineRouting{
+					FromAttribute: "tenant",
+					Table:         []PipelineRoute{{Value: "a"}},
+				}
+				return cfg
+			},
+			expected: fmt.Errorf(`pipeline "traces" has invalid routing: %w`, errors.New(`routing route "a" must have at least one exporter`)),
+		},
+		{
+			name: "invalid-route-exporter-reference",
+			cfgFn: func() *Config {
+				cfg := generateConfig()
+				pipe := cfg.Service.Pipelines[NewComponentID("traces")]
+				pipe.Routing = &PipelineRouting{
+					FromAttribute: "tenant",
+					Table:         []PipelineRoute{{Value: "a", Exporters: []ComponentID{NewComponentIDWithName("nop", "2")}}},
+				}
+				return cfg
+			},
+			expected: fmt.Errorf(`pipeline "traces" has invalid routing: %w`, errors.New(`routing route "a" references exporter "nop/2" which is not in the pipeline`)),
+		},
+		{
+			name: "invalid-default-exporter-reference",
+			cfgFn: func() *Config {
+				cfg := generateConfig()
+				pipe := cfg.Service.Pipelines[NewComponentID("traces")]
+				pipe.Routing = &PipelineRouting{
+					FromAttribute:    "tenant",
+					Table:            []PipelineRoute{{Value: "a", Exporters: []ComponentID{NewComponentID("nop")}}},
+					DefaultExporters: []ComponentID{NewComponentIDWithName("nop", "2")},
+				}
+				return cfg
+			},
+			expected: fmt.Errorf(`pipeline "traces" has invalid routing: %w`, errors.New(`routing default_exporters references exporter "nop/2" which is not in the pipeline`)),
+		},
+		{
+			name: "valid-routing",
+			cfgFn: func() *Config {
+				cfg := generateConfig()
+				pipe := cfg.Service.Pipelines[NewComponentID("traces")]
+				pipe.Routing = &PipelineRouting{
+					FromAttribute:    "tenant",
+					Table:            []PipelineRoute{{Value: "a", Exporters: []ComponentID{NewComponentID("nop")}}},
+					DefaultExporters: []ComponentID{NewComponentID("nop")},
+				}
+				return cfg
+			},
+			expected: nil,
+		},
 		{
 			name: "missing-pipelines",
 			cfgFn: func() *Config {
@@ -0,0 +1,37 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configgrpc // import "go.opentelemetry.io/collector/config/configgrpc"
+
+import (
+	"io"
+
+	"go.opentelemetry.io/collector/internal/compression"
+)
+
+// levelCompressor compresses the messages of a connection with the compression level of its settings. The
+// compressors registered in the grpc encoding package have a single level shared by all the connections.
+type levelCompressor struct {
+	compressor *compression.Compressor
+}
+
+// Do compresses p into w.
+func (c *levelCompressor) Do(w io.Writer, p []byte) error {
+	return c.compressor.Compress(w, p)
+}
+
+// Type returns the name of the registered encoding, used by the server to decompress the messages.
+func (c *levelCompressor) Type() string {
+	return c.compressor.Encoding()
+}
@@ -16,6 +16,7 @@ package configgrpc // import "go.opentelemetry.io/collector/config/configgrpc"
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"strings"
@@ -35,6 +36,7 @@ import (
 	"go.opentelemetry.io/collector/config/configauth"
 	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/internal/compression"
 )
 
 // Compression gRPC keys for supported compression types within collector.
@@ -76,6 +78,10 @@ type GRPCClientSettings struct {
 	// The compression key for supported compression types within collector.
 	Compression string `mapstructure:"compression"`
 
+	// CompressionLevel is the level of the gzip (1 to 9) or zstd (1 to 22) compression. If 0, the default
+	// level of the compression type is used.
+	CompressionLevel int `mapstructure:"compression_level"`
+
 	// TLSSetting struct exposes TLS client configuration.
 	TLSSetting configtls.TLSClientSetting `mapstructure:"tls,omitempty"`
 
@@ -174,6 +180,21 @@ func (gcs *GRPCClientSettings) SanitizedEndpoint() string {
 	}
 }
 
+// Validate checks that the compression type is supported and that the compression level is valid for it.
+func (gcs *GRPCClientSettings) Validate() error {
+	if gcs.Compression == "" {
+		if gcs.CompressionLevel != 0 {
+			return errors.New("compression_level requires compression to be set")
+		}
+		return nil
+	}
+	compressionKey := GetGRPCCompressionKey(gcs.Compression)
+	if compressionKey == CompressionUnsupported {
+		return fmt.Errorf("unsupported compression type %q", gcs.Compression)
+	}
+	return compression.Validate(compressionKey, gcs.CompressionLevel)
+}
+
 func (gcs *GRPCClientSettings) isSchemeHTTP() bool {
 	return strings.HasPrefix(gcs.Endpoint, "http://")
 }
@@ -186,10 +207,19 @@ func (gcs *GRPCClientSettings) isSchemeHTTPS() bool {
 func (gcs *GRPCClientSettings) ToDialOptions(host component.Host) ([]grpc.DialOption, error) {
 	var opts []grpc.DialOption
 	if gcs.Compression != "" {
-		if compressionKey := GetGRPCCompressionKey(gcs.Compression); compressionKey != CompressionUnsupported {
-			opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(compressionKey)))
-		} else {
+		compressionKey := GetGRPCCompressionKey(gcs.Compression)
+		switch {
+		case compressionKey == CompressionUnsupported:
 			return nil, fmt.Errorf("unsupported compression type %q", gcs.Compression)
+		case gcs.CompressionLevel != 0:
+			compressor, err := compression.NewCompressor(compressionKey, gcs.CompressionLevel)
+			if err != nil {
+				return nil, err
+			}
+			// The registered compressors cannot have a level per connection.
+			opts = append(opts, grpc.WithCompressor(&levelCompressor{compressor: compressor})) //nolint:staticcheck // SA1019 grpc.WithCompressor is the only per connection compressor.
+		default:
+			opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(compressionKey)))
 		}
 	}
 
@@ -16,16 +16,19 @@ package configgrpc
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/stats"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
@@ -34,6 +37,7 @@ import (
 	"go.opentelemetry.io/collector/config/confignet"
 	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
 )
 
 func TestDefaultGrpcClientSettings(t *testing.T) {
@@ -208,6 +212,22 @@ func TestGRPCClientSettingsError(t *testing.T) {
 				BalancerName:    "test",
 			},
 		},
+		{
+			err: "gzip compression level must be between 1 and 9, got 10",
+			settings: GRPCClientSettings{
+				Endpoint:         "localhost:1234",
+				Compression:      "gzip",
+				CompressionLevel: 10,
+			},
+		},
+		{
+			err: `compression level is not supported by "snappy"`,
+			settings: GRPCClientSettings{
+				Endpoint:         "localhost:1234",
+				Compression:      "snappy",
+				CompressionLevel: 1,
+			},
+		},
 		{
 			err: "failed to resolve authenticator \"doesntexist\": authenticator not found",
 			settings: GRPCClientSettings{
@@ -235,6 +255,41 @@ func TestGRPCClientSettingsError(t *testing.T) {
 	}
 }
 
+func TestGRPCClientSettingsValidate(t *testing.T) {
+	tests := []struct {
+		settings GRPCClientSettings
+		err      string
+	}{
+		{settings: GRPCClientSettings{}},
+		{settings: GRPCClientSettings{Compression: "gzip"}},
+		{settings: GRPCClientSettings{Compression: "zstd", CompressionLevel: 22}},
+		{
+			settings: GRPCClientSettings{Compression: "lz4"},
+			err:      `unsupported compression type "lz4"`,
+		},
+		{
+			settings: GRPCClientSettings{Compression: "gzip", CompressionLevel: 10},
+			err:      "gzip compression level must be between 1 and 9, got 10",
+		},
+		{
+			settings: GRPCClientSettings{Compression: "snappy", CompressionLevel: 1},
+			err:      `compression level is not supported by "snappy"`,
+		},
+		{
+			settings: GRPCClientSettings{CompressionLevel: 1},
+			err:      "compression_level requires compression to be set",
+		},
+	}
+	for _, test := range tests {
+		err := test.settings.Validate()
+		if test.err == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, test.err)
+		}
+	}
+}
+
 func TestUseSecure(t *testing.T) {
 	gcs := &GRPCClientSettings{
 		Headers:     nil,
@@ -540,6 +595,107 @@ func TestReceiveOnUnixDomainSocket(t *testing.T) {
 	s.Stop()
 }
 
+func TestCompressionReception(t *testing.T) {
+	tests := []struct {
+		compression string
+		level       int
+	}{
+		{compression: "gzip"},
+		{compression: "gzip", level: 1},
+		{compression: "zstd"},
+		{compression: "zstd", level: 19},
+		{compression: "snappy"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s_%d", tt.compression, tt.level), func(t *testing.T) {
+			gss := &GRPCServerSettings{
+				NetAddr: confignet.NetAddr{
+					Endpoint:  "localhost:0",
+					Transport: "tcp",
+				},
+			}
+			ln, err := gss.ToListener()
+			require.NoError(t, err)
+			opts, err := gss.ToServerOption(componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
+			require.NoError(t, err)
+			encoding := &encodingStatsHandler{}
+			s := grpc.NewServer(append(opts, grpc.StatsHandler(encoding))...)
+			srv := &spanCountTraceServer{}
+			otlpgrpc.RegisterTracesServer(s, srv)
+			go func() {
+				_ = s.Serve(ln)
+			}()
+			defer s.Stop()
+
+			gcs := &GRPCClientSettings{
+				Endpoint:         ln.Addr().String(),
+				Compression:      tt.compression,
+				CompressionLevel: tt.level,
+				TLSSetting: configtls.TLSClientSetting{
+					Insecure: true,
+				},
+			}
+			clientOpts, err := gcs.ToDialOptions(componenttest.NewNopHost())
+			require.NoError(t, err)
+			grpcClientConn, err := grpc.Dial(gcs.Endpoint, clientOpts...)
+			require.NoError(t, err)
+			defer grpcClientConn.Close()
+
+			client := otlpgrpc.NewTracesClient(grpcClientConn)
+			ctx, cancelFunc := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancelFunc()
+			td := pdata.NewTraces()
+			td.ResourceSpans().AppendEmpty().InstrumentationLibrarySpans().AppendEmpty().Spans().AppendEmpty().SetName("span")
+			req := otlpgrpc.NewTracesRequest()
+			req.SetTraces(td)
+			_, err = client.Export(ctx, req, grpc.WaitForReady(true))
+			require.NoError(t, err)
+			assert.Equal(t, tt.compression, encoding.get())
+			assert.Equal(t, 1, srv.spanCount)
+		})
+	}
+}
+
+// spanCountTraceServer records the span count of the last request.
+type spanCountTraceServer struct {
+	spanCount int
+}
+
+func (sts *spanCountTraceServer) Export(_ context.Context, req otlpgrpc.TracesRequest) (otlpgrpc.TracesResponse, error) {
+	sts.spanCount = req.Traces().SpanCount()
+	return otlpgrpc.NewTracesResponse(), nil
+}
+
+// encodingStatsHandler records the compression of the last request received by the server.
+type encodingStatsHandler struct {
+	mu       sync.Mutex
+	encoding string
+}
+
+func (h *encodingStatsHandler) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (h *encodingStatsHandler) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.encoding = header.Compression
+	}
+}
+
+func (h *encodingStatsHandler) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (h *encodingStatsHandler) HandleConn(context.Context, stats.ConnStats) {}
+
+func (h *encodingStatsHandler) get() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.encoding
+}
+
 type grpcTraceServer struct{}
 
 func (gts *grpcTraceServer) Export(context.Context, otlpgrpc.TracesRequest) (otlpgrpc.TracesResponse, error) {
@@ -15,6 +15,7 @@
 package config // import "go.opentelemetry.io/collector/config"
 
 import (
+	"errors"
 	"fmt"
 
 	"go.uber.org/zap/zapcore"
@@ -85,6 +86,66 @@ type Pipeline struct {
 	Receivers  []ComponentID `mapstructure:"receivers"`
 	Processors []ComponentID `mapstructure:"processors"`
 	Exporters  []ComponentID `mapstructure:"exporters"`
+
+	// Routing sends the data to a subset of the exporters depending on a resource attribute or on
+	// the client metadata. If not set, the data is sent to all the exporters.
+	Routing *PipelineRouting `mapstructure:"routing"`
+}
+
+// PipelineRouting defines how the data of a pipeline is routed to its exporters.
+type PipelineRouting struct {
+	// FromAttribute is the resource attribute whose value selects the route of every resource.
+	FromAttribute string `mapstructure:"from_attribute"`
+
+	// FromContext is the client metadata key, e.g. a gRPC metadata key or an HTTP header, whose value
+	// selects the route of all the data of a request.
+	FromContext string `mapstructure:"from_context"`
+
+	// Table is the list of routes.
+	Table []PipelineRoute `mapstructure:"table"`
+
+	// DefaultExporters are the exporters of the data matching no route. If empty, this data is dropped.
+	DefaultExporters []ComponentID `mapstructure:"default_exporters"`
+}
+
+// PipelineRoute sends the data whose routing value is Value to the Exporters.
+type PipelineRoute struct {
+	Value     string        `mapstructure:"value"`
+	Exporters []ComponentID `mapstructure:"exporters"`
+}
+
+func (pr *PipelineRouting) validate(pipelineExporters []ComponentID) error {
+	if (pr.FromAttribute == "") == (pr.FromContext == "") {
+		return errors.New("routing must have exactly one of from_attribute and from_context")
+	}
+	if len(pr.Table) == 0 {
+		return errors.New("routing table must have at least one route")
+	}
+	exporters := make(map[ComponentID]bool, len(pipelineExporters))
+	for _, id := range pipelineExporters {
+		exporters[id] = true
+	}
+	values := make(map[string]bool, len(pr.Table))
+	for _, route := range pr.Table {
+		if values[route.Value] {
+			return fmt.Errorf("routing table has duplicate value %q", route.Value)
+		}
+		values[route.Value] = true
+		if len(route.Exporters) == 0 {
+			return fmt.Errorf("routing route %q must have at least one exporter", route.Value)
+		}
+		for _, id := range route.Exporters {
+			if !exporters[id] {
+				return fmt.Errorf("routing route %q references exporter %q which is not in the pipeline", route.Value, id)
+			}
+		}
+	}
+	for _, id := range pr.DefaultExporters {
+		if !exporters[id] {
+			return fmt.Errorf("routing default_exporters references exporter %q which is not in the pipeline", id)
+		}
+	}
+	return nil
 }
 
 // Pipelines is a map of names to Pipelines.
@@ -0,0 +1,118 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consumererror // import "go.opentelemetry.io/collector/consumer/consumererror"
+
+import (
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// PartialTraces is an error that reports that only a subset of the received Trace data failed to be
+// processed or sent. The failed data is split between the data that can be retried and the data that
+// was permanently rejected and must not be retried.
+type PartialTraces struct {
+	error
+	retryable pdata.Traces
+	permanent pdata.Traces
+}
+
+// NewPartialTraces creates a PartialTraces that separates the retryable failed traces from the permanently failed ones.
+func NewPartialTraces(err error, retryable, permanent pdata.Traces) error {
+	return PartialTraces{
+		error:     err,
+		retryable: retryable,
+		permanent: permanent,
+	}
+}
+
+// GetRetryable returns the failed traces that can be retried.
+func (err PartialTraces) GetRetryable() pdata.Traces {
+	return err.retryable
+}
+
+// GetPermanent returns the failed traces that were permanently rejected.
+func (err PartialTraces) GetPermanent() pdata.Traces {
+	return err.permanent
+}
+
+// Unwrap returns the wrapped error for functions Is and As in standard package errors.
+func (err PartialTraces) Unwrap() error {
+	return err.error
+}
+
+// PartialLogs is an error that reports that only a subset of the received Log data failed to be
+// processed or sent. The failed data is split between the data that can be retried and the data that
+// was permanently rejected and must not be retried.
+type PartialLogs struct {
+	error
+	retryable pdata.Logs
+	permanent pdata.Logs
+}
+
+// NewPartialLogs creates a PartialLogs that separates the retryable failed logs from the permanently failed ones.
+func NewPartialLogs(err error, retryable, permanent pdata.Logs) error {
+	return PartialLogs{
+		error:     err,
+		retryable: retryable,
+		permanent: permanent,
+	}
+}
+
+// GetRetryable returns the failed logs that can be retried.
+func (err PartialLogs) GetRetryable() pdata.Logs {
+	return err.retryable
+}
+
+// GetPermanent returns the failed logs that were permanently rejected.
+func (err PartialLogs) GetPermanent() pdata.Logs {
+	return err.permanent
+}
+
+// Unwrap returns the wrapped error for functions Is and As in standard package errors.
+func (err PartialLogs) Unwrap() error {
+	return err.error
+}
+
+// PartialMetrics is an error that reports that only a subset of the received Metrics data failed to be
+// processed or sent. The failed data is split between the data that can be retried and the data that
+// was permanently rejected and must not be retried.
+type PartialMetrics struct {
+	error
+	retryable pdata.Metrics
+	permanent pdata.Metrics
+}
+
+// NewPartialMetrics creates a PartialMetrics that separates the retryable failed metrics from the permanently failed ones.
+func NewPartialMetrics(err error, retryable, permanent pdata.Metrics) error {
+	return PartialMetrics{
+		error:     err,
+		retryable: retryable,
+		permanent: permanent,
+	}
+}
+
+// GetRetryable returns the failed metrics that can be retried.
+func (err PartialMetrics) GetRetryable() pdata.Metrics {
+	return err.retryable
+}
+
+// GetPermanent returns the failed metrics that were permanently rejected.
+func (err PartialMetrics) GetPermanent() pdata.Metrics {
+	return err.permanent
+}
+
+// Unwrap returns the wrapped error for functions Is and As in standard package errors.
+func (err PartialMetrics) Unwrap() error {
+	return err.error
+}
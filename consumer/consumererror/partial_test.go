@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consumererror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestPartialTraces(t *testing.T) {
+	retryable := testdata.GenerateTracesOneSpan()
+	permanent := testdata.GenerateTracesTwoSpansSameResource()
+	err := fmt.Errorf("some error")
+	partialErr := NewPartialTraces(err, retryable, permanent)
+	assert.Equal(t, err.Error(), partialErr.Error())
+	var target PartialTraces
+	assert.False(t, errors.As(err, &target))
+	assert.True(t, errors.As(NewPermanent(partialErr), &target))
+	assert.Equal(t, retryable, target.GetRetryable())
+	assert.Equal(t, permanent, target.GetPermanent())
+	assert.True(t, errors.Is(partialErr, err))
+}
+
+func TestPartialLogs(t *testing.T) {
+	retryable := testdata.GenerateLogsOneLogRecord()
+	permanent := testdata.GenerateLogsTwoLogRecordsSameResource()
+	err := fmt.Errorf("some error")
+	partialErr := NewPartialLogs(err, retryable, permanent)
+	assert.Equal(t, err.Error(), partialErr.Error())
+	var target PartialLogs
+	assert.False(t, errors.As(err, &target))
+	assert.True(t, errors.As(NewPermanent(partialErr), &target))
+	assert.Equal(t, retryable, target.GetRetryable())
+	assert.Equal(t, permanent, target.GetPermanent())
+	assert.True(t, errors.Is(partialErr, err))
+}
+
+func TestPartialMetrics(t *testing.T) {
+	retryable := testdata.GenerateMetricsOneMetric()
+	permanent := pdata.NewMetrics()
+	err := fmt.Errorf("some error")
+	partialErr := NewPartialMetrics(err, retryable, permanent)
+	assert.Equal(t, err.Error(), partialErr.Error())
+	var target PartialMetrics
+	assert.False(t, errors.As(err, &target))
+	assert.True(t, errors.As(NewPermanent(partialErr), &target))
+	assert.Equal(t, retryable, target.GetRetryable())
+	assert.Equal(t, permanent, target.GetPermanent())
+	assert.True(t, errors.Is(partialErr, err))
+}
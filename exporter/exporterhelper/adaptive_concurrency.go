@@ -0,0 +1,176 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+var (
+	errMinConsumers = errors.New("sending_queue.adaptive_concurrency.min_consumers must be at least 1")
+	errMaxConsumers = errors.New("sending_queue.adaptive_concurrency.max_consumers must be greater or equal to min_consumers")
+)
+
+// AdaptiveConcurrencySettings defines configuration for adapting the number of concurrent queue consumers
+// to the health of the backend.
+type AdaptiveConcurrencySettings struct {
+	// Enabled indicates whether to adapt the number of concurrent consumers instead of using a fixed number.
+	Enabled bool `mapstructure:"enabled"`
+	// MinConsumers is the minimum number of concurrent consumers.
+	MinConsumers int `mapstructure:"min_consumers"`
+	// MaxConsumers is the maximum number of concurrent consumers.
+	MaxConsumers int `mapstructure:"max_consumers"`
+	// LatencyThreshold is the export latency above which the backend is considered overloaded
+	// and the number of concurrent consumers is decreased.
+	LatencyThreshold time.Duration `mapstructure:"latency_threshold"`
+}
+
+// DefaultAdaptiveConcurrencySettings returns the default settings for AdaptiveConcurrencySettings.
+func DefaultAdaptiveConcurrencySettings() AdaptiveConcurrencySettings {
+	return AdaptiveConcurrencySettings{
+		Enabled:          false,
+		MinConsumers:     1,
+		MaxConsumers:     100,
+		LatencyThreshold: time.Second,
+	}
+}
+
+// Validate checks if the adaptive concurrency configuration is valid.
+func (cfg *AdaptiveConcurrencySettings) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.MinConsumers < 1 {
+		return errMinConsumers
+	}
+	if cfg.MaxConsumers < cfg.MinConsumers {
+		return errMaxConsumers
+	}
+	return nil
+}
+
+// concurrencyDecreaseFactor is the factor applied to the concurrency limit when the backend is overloaded.
+const concurrencyDecreaseFactor = 0.5
+
+// concurrencyLimiter limits the number of requests concurrently processed by the queue consumers
+// using an AIMD (additive increase, multiplicative decrease) algorithm:
+//   - every successful attempt faster than cfg.LatencyThreshold increases the limit by 1/limit,
+//     so the limit grows by one after a full window of successful requests.
+//   - a failed or slow attempt halves the limit, at most once per observed latency to not react
+//     multiple times to the same overload.
+//
+// The limiter is also a requestSender, placed in front of the timeoutSender to observe every attempt.
+type concurrencyLimiter struct {
+	cfg        AdaptiveConcurrencySettings
+	nextSender requestSender
+
+	mu           sync.Mutex
+	cond         *sync.Cond
+	limit        float64
+	inFlight     int
+	lastDecrease time.Time
+	stopped      bool
+}
+
+func newConcurrencyLimiter(cfg AdaptiveConcurrencySettings, initialLimit int, nextSender requestSender) *concurrencyLimiter {
+	cl := &concurrencyLimiter{
+		cfg:        cfg,
+		nextSender: nextSender,
+	}
+	cl.cond = sync.NewCond(&cl.mu)
+	cl.limit = cl.clamp(float64(initialLimit))
+	return cl
+}
+
+func (cl *concurrencyLimiter) clamp(limit float64) float64 {
+	if limit < float64(cl.cfg.MinConsumers) {
+		limit = float64(cl.cfg.MinConsumers)
+	}
+	if limit > float64(cl.cfg.MaxConsumers) {
+		limit = float64(cl.cfg.MaxConsumers)
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	return limit
+}
+
+// send implements the requestSender interface
+func (cl *concurrencyLimiter) send(req request) error {
+	start := time.Now()
+	err := cl.nextSender.send(req)
+	cl.onAttempt(time.Since(start), err)
+	return err
+}
+
+// acquire blocks until the number of in-flight requests is below the current limit.
+func (cl *concurrencyLimiter) acquire() {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	for !cl.stopped && cl.inFlight >= int(cl.limit) {
+		cl.cond.Wait()
+	}
+	cl.inFlight++
+}
+
+// release marks an in-flight request as finished.
+func (cl *concurrencyLimiter) release() {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.inFlight--
+	cl.cond.Signal()
+}
+
+// stop removes the limit, so the queue can be drained during shutdown.
+func (cl *concurrencyLimiter) stop() {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	cl.stopped = true
+	cl.cond.Broadcast()
+}
+
+// currentLimit returns the current number of concurrent requests allowed.
+func (cl *concurrencyLimiter) currentLimit() int {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return int(cl.limit)
+}
+
+func (cl *concurrencyLimiter) onAttempt(latency time.Duration, err error) {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+
+	// Permanent errors are caused by the data, they say nothing about the backend health.
+	failed := err != nil && !consumererror.IsPermanent(err)
+	if failed || latency > cl.cfg.LatencyThreshold {
+		now := time.Now()
+		if now.Sub(cl.lastDecrease) < latency {
+			return
+		}
+		cl.lastDecrease = now
+		cl.limit = cl.clamp(cl.limit * concurrencyDecreaseFactor)
+		return
+	}
+
+	prev := int(cl.limit)
+	cl.limit = cl.clamp(cl.limit + 1/cl.limit)
+	if int(cl.limit) > prev {
+		cl.cond.Broadcast()
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/tag"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+func newTestConcurrencyLimiter(initialLimit int) *concurrencyLimiter {
+	cfg := AdaptiveConcurrencySettings{Enabled: true, MinConsumers: 2, MaxConsumers: 8, LatencyThreshold: 100 * time.Millisecond}
+	return newConcurrencyLimiter(cfg, initialLimit, newFailingSender(errors.New("transient error")))
+}
+
+func TestConcurrencyLimiter_AdditiveIncrease(t *testing.T) {
+	cl := newTestConcurrencyLimiter(4)
+	assert.Equal(t, 4, cl.currentLimit())
+
+	// About a full window of successful attempts increases the limit by one.
+	for i := 0; i < 5; i++ {
+		cl.onAttempt(time.Millisecond, nil)
+	}
+	assert.Equal(t, 5, cl.currentLimit())
+
+	// The limit never goes above the maximum.
+	for i := 0; i < 100; i++ {
+		cl.onAttempt(time.Millisecond, nil)
+	}
+	assert.Equal(t, 8, cl.currentLimit())
+}
+
+func TestConcurrencyLimiter_MultiplicativeDecrease(t *testing.T) {
+	cl := newTestConcurrencyLimiter(8)
+
+	cl.onAttempt(time.Millisecond, errors.New("transient error"))
+	assert.Equal(t, 4, cl.currentLimit())
+
+	// Slow attempts finishing within the latency of the previous decrease are caused by the same overload.
+	cl.onAttempt(time.Second, nil)
+	assert.Equal(t, 4, cl.currentLimit())
+
+	cl.lastDecrease = time.Time{}
+	cl.onAttempt(time.Second, nil)
+	assert.Equal(t, 2, cl.currentLimit())
+
+	// The limit never goes below the minimum.
+	cl.lastDecrease = time.Time{}
+	cl.onAttempt(time.Millisecond, errors.New("transient error"))
+	assert.Equal(t, 2, cl.currentLimit())
+}
+
+func TestConcurrencyLimiter_PermanentErrorsIgnored(t *testing.T) {
+	cl := newTestConcurrencyLimiter(4)
+	cl.onAttempt(time.Millisecond, consumererror.NewPermanent(errors.New("bad data")))
+	assert.Equal(t, 4, cl.currentLimit())
+}
+
+func TestConcurrencyLimiter_AcquireBlocks(t *testing.T) {
+	cl := newTestConcurrencyLimiter(2)
+	cl.acquire()
+	cl.acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		cl.acquire()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("acquired more than the limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cl.release()
+	<-acquired
+
+	// After stop the limit does not apply anymore.
+	cl.stop()
+	cl.acquire()
+}
+
+func TestQueuedRetry_AdaptiveConcurrency(t *testing.T) {
+	exporterCfg := config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "adaptive"))
+	qCfg := DefaultQueueSettings()
+	qCfg.NumConsumers = 2
+	qCfg.AdaptiveConcurrency.Enabled = true
+	qCfg.AdaptiveConcurrency.MaxConsumers = 4
+	rCfg := DefaultRetrySettings()
+	be := newBaseExporter(&exporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	ocs := newObservabilityConsumerSender(be.qrSender.consumerSender)
+	be.qrSender.consumerSender = ocs
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+
+	exporterTags := []tag.Tag{{Key: exporterTag, Value: exporterCfg.ID().String()}}
+	checkValueForGlobalManager(t, exporterTags, int64(2), "exporter/concurrency_limit")
+
+	for i := 0; i < 20; i++ {
+		ocs.run(func() {
+			require.NoError(t, be.sender.send(newMockRequest(context.Background(), 2, nil)))
+		})
+	}
+	ocs.awaitAsyncProcessing()
+	ocs.checkSendItemsCount(t, 40)
+	ocs.checkDroppedItemsCount(t, 0)
+
+	// The backend is healthy, so the limit increased up to the maximum.
+	checkValueForGlobalManager(t, exporterTags, int64(4), "exporter/concurrency_limit")
+
+	assert.NoError(t, be.Shutdown(context.Background()))
+	checkValueForGlobalManager(t, exporterTags, int64(0), "exporter/concurrency_limit")
+}
+
+func TestAdaptiveConcurrencySettings_Validate(t *testing.T) {
+	cfg := DefaultAdaptiveConcurrencySettings()
+	cfg.MinConsumers = 0
+	assert.NoError(t, cfg.Validate(), "disabled adaptive concurrency is not validated")
+
+	cfg = DefaultAdaptiveConcurrencySettings()
+	cfg.Enabled = true
+	assert.NoError(t, cfg.Validate())
+	cfg.MinConsumers = 0
+	assert.ErrorIs(t, cfg.Validate(), errMinConsumers)
+	cfg.MinConsumers = 10
+	cfg.MaxConsumers = 5
+	assert.ErrorIs(t, cfg.Validate(), errMaxConsumers)
+	cfg.MaxConsumers = 10
+	assert.NoError(t, cfg.Validate())
+
+	qCfg := DefaultQueueSettings()
+	qCfg.AdaptiveConcurrency = cfg
+	qCfg.AdaptiveConcurrency.MaxConsumers = 1
+	assert.ErrorIs(t, qCfg.Validate(), errMaxConsumers)
+}
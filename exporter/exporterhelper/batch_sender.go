@@ -0,0 +1,252 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+var (
+	errBatchTimeout      = errors.New("sending_batch.timeout must be positive")
+	errBatchSize         = errors.New("sending_batch.send_batch_size must be positive")
+	errBatchMaxSize      = errors.New("sending_batch.send_batch_max_size must be greater or equal to send_batch_size")
+	errBatchMaxSizeBytes = errors.New("sending_batch.send_batch_max_size_bytes must not be negative")
+)
+
+// BatchSettings defines configuration for batching requests before sending them to the sending queue.
+type BatchSettings struct {
+	// Enabled indicates whether to batch requests before sending them to the sending queue.
+	Enabled bool `mapstructure:"enabled"`
+	// Timeout sets the time after which a batch will be sent regardless of size.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// SendBatchSize is the number of spans, metric data points or log records after which a batch will be sent
+	// regardless of the timeout.
+	SendBatchSize int `mapstructure:"send_batch_size"`
+	// SendBatchMaxSize is the maximum number of items in a batch. Larger batches are split into smaller units.
+	// Default value is 0, that means no maximum number of items.
+	SendBatchMaxSize int `mapstructure:"send_batch_max_size"`
+	// SendBatchMaxSizeBytes is the maximum size of a batch in bytes, measured as OTLP protobuf encoded size.
+	// Larger batches are split into smaller units. Default value is 0, that means no maximum size.
+	SendBatchMaxSizeBytes int `mapstructure:"send_batch_max_size_bytes"`
+}
+
+// DefaultBatchSettings returns the default settings for BatchSettings.
+// Batching is disabled by default since most pipelines already batch using the batch processor.
+func DefaultBatchSettings() BatchSettings {
+	return BatchSettings{
+		Enabled:       false,
+		Timeout:       200 * time.Millisecond,
+		SendBatchSize: 8192,
+	}
+}
+
+// Validate checks if the batch configuration is valid.
+func (cfg *BatchSettings) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Timeout <= 0 {
+		return errBatchTimeout
+	}
+	if cfg.SendBatchSize <= 0 {
+		return errBatchSize
+	}
+	if cfg.SendBatchMaxSize < 0 || (cfg.SendBatchMaxSize > 0 && cfg.SendBatchMaxSize < cfg.SendBatchSize) {
+		return errBatchMaxSize
+	}
+	if cfg.SendBatchMaxSizeBytes < 0 {
+		return errBatchMaxSizeBytes
+	}
+	return nil
+}
+
+// batchSender is a request sender that merges incoming requests into batches and splits
+// them according to the configured limits before forwarding them to the next sender.
+//
+// Batches are sent out with any of the following conditions:
+// - batch size reaches cfg.SendBatchSize or cfg.SendBatchMaxSizeBytes
+// - cfg.Timeout is elapsed since the timestamp when the previous batch was sent out.
+type batchSender struct {
+	cfg        BatchSettings
+	signal     config.DataType
+	obsrep     *obsExporter
+	nextSender requestSender
+	logger     *zap.Logger
+	// partitionOf returns the partition of a request, requests of different partitions are never merged.
+	// It is nil if the sending queue is not partitioned.
+	partitionOf func(request) string
+
+	newItem chan request
+	batch   request
+	timer   *time.Timer
+
+	// stoppedMu guards stopped, send holds it for reading while handing a request off to the processing cycle,
+	// so no request is handed off after shutdown drained the pending ones.
+	stoppedMu  sync.RWMutex
+	stopped    bool
+	shutdownC  chan struct{}
+	goroutines sync.WaitGroup
+}
+
+func newBatchSender(cfg BatchSettings, signal config.DataType, obsrep *obsExporter, nextSender requestSender, logger *zap.Logger) *batchSender {
+	return &batchSender{
+		cfg:        cfg,
+		signal:     signal,
+		obsrep:     obsrep,
+		nextSender: nextSender,
+		logger:     logger,
+		newItem:    make(chan request, 1),
+		shutdownC:  make(chan struct{}),
+	}
+}
+
+// send implements the requestSender interface. It returns errSendingQueueIsFull if the request cannot be handed off
+// because the batch sender is stopped. Batches are sent asynchronously, so the failures to send a batch are not
+// returned to the caller: they are logged, and recorded by the next senders and as enqueue failures.
+func (bs *batchSender) send(req request) error {
+	if isSynchronousSend(req.context()) {
+		return bs.nextSender.send(req)
+	}
+	bs.stoppedMu.RLock()
+	defer bs.stoppedMu.RUnlock()
+	if bs.stopped {
+		bs.logger.Error("Dropping data because the exporter is shutting down.", zap.Int("dropped_items", req.count()))
+		return errSendingQueueIsFull
+	}
+	bs.newItem <- req
+	return nil
+}
+
+// start is invoked during service startup.
+func (bs *batchSender) start() error {
+	if err := bs.cfg.Validate(); err != nil {
+		return err
+	}
+	bs.timer = time.NewTimer(bs.cfg.Timeout)
+	bs.goroutines.Add(1)
+	go bs.startProcessingCycle()
+	return nil
+}
+
+// shutdown is invoked during service shutdown. It sends out all pending items before returning.
+func (bs *batchSender) shutdown() {
+	// Wait for the requests being handed off, the processing cycle keeps consuming them until shutdownC is closed.
+	bs.stoppedMu.Lock()
+	bs.stopped = true
+	bs.stoppedMu.Unlock()
+	close(bs.shutdownC)
+	bs.goroutines.Wait()
+}
+
+func (bs *batchSender) startProcessingCycle() {
+	defer bs.goroutines.Done()
+	for {
+		select {
+		case <-bs.shutdownC:
+		DONE:
+			for {
+				select {
+				case req := <-bs.newItem:
+					bs.processItem(req)
+				default:
+					break DONE
+				}
+			}
+			for bs.batch != nil {
+				bs.sendBatch()
+			}
+			return
+		case req := <-bs.newItem:
+			bs.processItem(req)
+		case <-bs.timer.C:
+			for bs.batch != nil {
+				bs.sendBatch()
+			}
+			bs.timer.Reset(bs.cfg.Timeout)
+		}
+	}
+}
+
+func (bs *batchSender) processItem(req request) {
+	if req.count() == 0 {
+		return
+	}
+	if bs.batch != nil && bs.partitionOf != nil && bs.partitionOf(bs.batch) != bs.partitionOf(req) {
+		for bs.batch != nil {
+			bs.sendBatch()
+		}
+	}
+	if bs.batch == nil {
+		// The incoming context is cancelled by the receivers once the call returns, so prevent cancellation and
+		// deadline to propagate to the batch while keeping the values, e.g. the client metadata.
+		req.setContext(noCancellationContext{Context: req.context()})
+		bs.batch = req
+	} else {
+		bs.batch.merge(req)
+	}
+
+	sent := false
+	for bs.batch != nil && bs.isBatchReady() {
+		sent = true
+		bs.sendBatch()
+	}
+
+	if sent {
+		if !bs.timer.Stop() {
+			<-bs.timer.C
+		}
+		bs.timer.Reset(bs.cfg.Timeout)
+	}
+}
+
+// isBatchReady returns true if the current batch reached any of the configured size triggers.
+func (bs *batchSender) isBatchReady() bool {
+	if bs.batch.count() >= bs.cfg.SendBatchSize {
+		return true
+	}
+	return bs.cfg.SendBatchMaxSizeBytes > 0 && bs.batch.bytesSize() >= bs.cfg.SendBatchMaxSizeBytes
+}
+
+// sendBatch sends the current batch, or the part of it that fits into the configured maximum sizes,
+// to the next sender.
+func (bs *batchSender) sendBatch() {
+	req := bs.batch
+	if bs.fits(req) {
+		bs.batch = nil
+	} else {
+		req = bs.batch.split(bs.cfg.SendBatchMaxSize, bs.cfg.SendBatchMaxSizeBytes)
+	}
+
+	if err := bs.nextSender.send(req); err != nil {
+		if errors.Is(err, errSendingQueueIsFull) {
+			bs.obsrep.recordEnqueueFailure(req.context(), bs.signal, int64(req.count()))
+		}
+		bs.logger.Warn("Failed to send batch", zap.Error(err), zap.Int("dropped_items", req.count()))
+	}
+}
+
+// fits returns true if the request is within the configured maximum sizes.
+func (bs *batchSender) fits(req request) bool {
+	if bs.cfg.SendBatchMaxSize > 0 && req.count() > bs.cfg.SendBatchMaxSize {
+		return false
+	}
+	return bs.cfg.SendBatchMaxSizeBytes <= 0 || req.bytesSize() <= bs.cfg.SendBatchMaxSizeBytes
+}
@@ -0,0 +1,237 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestBatchSender_MergeAndSplit(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	bCfg := BatchSettings{Enabled: true, Timeout: time.Hour, SendBatchSize: 20, SendBatchMaxSize: 20}
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeTraces, WithBatch(bCfg))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.NoError(t, te.ConsumeTraces(context.Background(), testdata.GenerateTracesManySpansSameResource(10)))
+	require.NoError(t, te.ConsumeTraces(context.Background(), testdata.GenerateTracesManySpansSameResource(25)))
+
+	// The batch reaches send_batch_size and is split at send_batch_max_size, the rest waits for the timeout.
+	assert.Eventually(t, func() bool {
+		return sink.SpanCount() == 20
+	}, time.Second, time.Millisecond)
+	require.NoError(t, te.Shutdown(context.Background()))
+	assert.Equal(t, 35, sink.SpanCount())
+	require.Len(t, sink.AllTraces(), 2)
+	assert.Equal(t, 20, sink.AllTraces()[0].SpanCount())
+	assert.Equal(t, 15, sink.AllTraces()[1].SpanCount())
+}
+
+func TestBatchSender_Timeout(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	bCfg := BatchSettings{Enabled: true, Timeout: 10 * time.Millisecond, SendBatchSize: 1000}
+	me, err := NewMetricsExporter(&fakeMetricsExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeMetrics, WithBatch(bCfg))
+	require.NoError(t, err)
+	require.NoError(t, me.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, me.Shutdown(context.Background()))
+	})
+
+	require.NoError(t, me.ConsumeMetrics(context.Background(), testdata.GenerateMetricsTwoMetrics()))
+	require.NoError(t, me.ConsumeMetrics(context.Background(), testdata.GenerateMetricsTwoMetrics()))
+
+	assert.Eventually(t, func() bool {
+		return sink.DataPointCount() == 8
+	}, time.Second, time.Millisecond)
+	assert.Len(t, sink.AllMetrics(), 1)
+}
+
+func TestBatchSender_FlushOnShutdown(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	bCfg := BatchSettings{Enabled: true, Timeout: time.Hour, SendBatchSize: 1000}
+	le, err := NewLogsExporter(&fakeLogsExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeLogs, WithBatch(bCfg))
+	require.NoError(t, err)
+	require.NoError(t, le.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.NoError(t, le.ConsumeLogs(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(5)))
+	require.NoError(t, le.Shutdown(context.Background()))
+
+	assert.Equal(t, 5, sink.LogRecordCount())
+	assert.Len(t, sink.AllLogs(), 1)
+}
+
+func TestBatchSender_SendAfterShutdown(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	exporterCfg := config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "batch_stopped"))
+	bCfg := BatchSettings{Enabled: true, Timeout: time.Hour, SendBatchSize: 1000}
+	le, err := NewLogsExporter(&exporterCfg, componenttest.NewNopExporterCreateSettings(), sink.ConsumeLogs, WithBatch(bCfg))
+	require.NoError(t, err)
+	require.NoError(t, le.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, le.Shutdown(context.Background()))
+
+	// More requests than the handoff buffer must not block once the batch sender is stopped.
+	for i := 0; i < 3; i++ {
+		assert.ErrorIs(t, le.ConsumeLogs(context.Background(), testdata.GenerateLogsManyLogRecordsSameResource(5)), errSendingQueueIsFull)
+	}
+	assert.Equal(t, 0, sink.LogRecordCount())
+}
+
+func TestBatchSender_MaxSizeBytes(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	td := testdata.GenerateTracesManySpansSameResource(20)
+	maxSizeBytes := tracesSizer.TracesSize(td) / 4
+	bCfg := BatchSettings{Enabled: true, Timeout: time.Hour, SendBatchSize: 1000, SendBatchMaxSizeBytes: maxSizeBytes}
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeTraces, WithBatch(bCfg))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.NoError(t, te.ConsumeTraces(context.Background(), td))
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	assert.Equal(t, 20, sink.SpanCount())
+	assert.GreaterOrEqual(t, len(sink.AllTraces()), 4)
+	for _, batch := range sink.AllTraces() {
+		assert.LessOrEqual(t, tracesSizer.TracesSize(batch), maxSizeBytes)
+	}
+}
+
+func TestBatchSender_MutatesData(t *testing.T) {
+	bCfg := DefaultBatchSettings()
+	bCfg.Enabled = true
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), newTraceDataPusher(nil), WithBatch(bCfg))
+	require.NoError(t, err)
+	assert.True(t, te.Capabilities().MutatesData)
+}
+
+func TestBatchSender_KeepsContextValues(t *testing.T) {
+	var mu sync.Mutex
+	var tenants []string
+	var ctxErrs []error
+	pusher := func(ctx context.Context, td pdata.Traces) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if c, ok := client.FromContext(ctx); ok {
+			tenants = append(tenants, c.Metadata["x-tenant"]...)
+		}
+		ctxErrs = append(ctxErrs, ctx.Err())
+		return nil
+	}
+	bCfg := BatchSettings{Enabled: true, Timeout: time.Hour, SendBatchSize: 1000}
+	qCfg := DefaultQueueSettings()
+	qCfg.Partition = PartitionSettings{MetadataKey: "x-tenant", QueueSize: 10}
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), pusher, WithBatch(bCfg), WithQueue(qCfg))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+
+	for _, tenant := range []string{"acme", "acme", "initech"} {
+		ctx, cancel := context.WithCancel(client.NewContext(context.Background(), &client.Client{Metadata: map[string][]string{"x-tenant": {tenant}}}))
+		require.NoError(t, te.ConsumeTraces(ctx, testdata.GenerateTracesOneSpan()))
+		// The receivers cancel the request context once the call returns.
+		cancel()
+	}
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	// Requests of different partitions are not merged into the same batch.
+	assert.Equal(t, []string{"acme", "initech"}, tenants)
+	assert.Equal(t, []error{nil, nil}, ctxErrs)
+}
+
+func TestBatchSender_ResourceAttributePartition(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	bCfg := BatchSettings{Enabled: true, Timeout: time.Hour, SendBatchSize: 1000}
+	qCfg := DefaultQueueSettings()
+	qCfg.Partition = PartitionSettings{ResourceAttribute: "tenant", QueueSize: 10}
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeTraces, WithBatch(bCfg), WithQueue(qCfg))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+
+	for _, tenant := range []string{"acme", "acme", "initech"} {
+		require.NoError(t, te.ConsumeTraces(context.Background(), newTenantTraces(tenant)))
+	}
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	// Requests of different partitions are not merged into the same batch.
+	require.Len(t, sink.AllTraces(), 2)
+	assert.Equal(t, 2, sink.AllTraces()[0].SpanCount())
+	assert.Equal(t, 1, sink.AllTraces()[1].SpanCount())
+}
+
+func TestBatchSender_SendFailureLogged(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	set := componenttest.NewNopExporterCreateSettings()
+	set.Logger = zap.New(core)
+	bCfg := BatchSettings{Enabled: true, Timeout: time.Hour, SendBatchSize: 1000}
+	qCfg := DefaultQueueSettings()
+	qCfg.Enabled = false
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, set, newTraceDataPusher(errors.New("my error")), WithBatch(bCfg), WithQueue(qCfg), WithRetry(RetrySettings{Enabled: false}))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+
+	require.NoError(t, te.ConsumeTraces(context.Background(), testdata.GenerateTracesTwoSpansSameResource()))
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	failures := logs.FilterMessage("Failed to send batch").All()
+	require.Len(t, failures, 1)
+	assert.Equal(t, zapcore.WarnLevel, failures[0].Level)
+	assert.Equal(t, int64(2), failures[0].ContextMap()["dropped_items"])
+}
+
+func TestBatchSettings_Validate(t *testing.T) {
+	cfg := DefaultBatchSettings()
+	cfg.SendBatchSize = 0
+	assert.NoError(t, cfg.Validate(), "disabled batching is not validated")
+
+	cfg = DefaultBatchSettings()
+	cfg.Enabled = true
+	assert.NoError(t, cfg.Validate())
+	cfg.Timeout = 0
+	assert.ErrorIs(t, cfg.Validate(), errBatchTimeout)
+	cfg.Timeout = time.Second
+	cfg.SendBatchSize = 0
+	assert.ErrorIs(t, cfg.Validate(), errBatchSize)
+	cfg.SendBatchSize = 100
+	cfg.SendBatchMaxSize = 50
+	assert.ErrorIs(t, cfg.Validate(), errBatchMaxSize)
+	cfg.SendBatchMaxSize = 100
+	cfg.SendBatchMaxSizeBytes = -1
+	assert.ErrorIs(t, cfg.Validate(), errBatchMaxSizeBytes)
+	cfg.SendBatchMaxSizeBytes = 0
+	assert.NoError(t, cfg.Validate())
+
+	exporterCfg := config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "batch_invalid"))
+	cfg.Timeout = 0
+	be := newBaseExporter(&exporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithBatch(cfg)), "", nopRequestUnmarshaler())
+	assert.ErrorIs(t, be.Start(context.Background(), componenttest.NewNopHost()), errBatchTimeout)
+	assert.NoError(t, be.Shutdown(context.Background()))
+}
@@ -0,0 +1,254 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opencensus.io/metric/metricdata"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+var (
+	errCircuitBreakerOpen             = errors.New("circuit breaker is open")
+	errCircuitBreakerFailureThreshold = errors.New("circuit_breaker.failure_threshold must be positive")
+	errCircuitBreakerProbeInterval    = errors.New("circuit_breaker.probe_interval must be positive")
+)
+
+// CircuitBreakerSettings defines configuration for the circuit breaker that stops sending requests
+// to a backend that keeps failing.
+type CircuitBreakerSettings struct {
+	// Enabled indicates whether to use a circuit breaker when sending requests.
+	Enabled bool `mapstructure:"enabled"`
+	// FailureThreshold is the number of consecutive failed attempts after which the circuit breaker opens.
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// ProbeInterval is the time the circuit breaker stays open before a single probe request is let through
+	// to check if the backend recovered.
+	ProbeInterval time.Duration `mapstructure:"probe_interval"`
+}
+
+// DefaultCircuitBreakerSettings returns the default settings for CircuitBreakerSettings.
+func DefaultCircuitBreakerSettings() CircuitBreakerSettings {
+	return CircuitBreakerSettings{
+		Enabled:          false,
+		FailureThreshold: 5,
+		ProbeInterval:    10 * time.Second,
+	}
+}
+
+// Validate checks if the circuit breaker configuration is valid.
+func (cfg *CircuitBreakerSettings) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.FailureThreshold <= 0 {
+		return errCircuitBreakerFailureThreshold
+	}
+	if cfg.ProbeInterval <= 0 {
+		return errCircuitBreakerProbeInterval
+	}
+	return nil
+}
+
+type circuitBreakerState int32
+
+const (
+	circuitBreakerClosed circuitBreakerState = iota
+	circuitBreakerOpen
+	circuitBreakerHalfOpen
+)
+
+func (s circuitBreakerState) String() string {
+	switch s {
+	case circuitBreakerClosed:
+		return "closed"
+	case circuitBreakerOpen:
+		return "open"
+	case circuitBreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// circuitBreakerSender is a request sender that stops sending requests after cfg.FailureThreshold
+// consecutive failed attempts. While open, senders are blocked, which pauses the queue consumers,
+// until cfg.ProbeInterval elapses. Then a single probe request is sent (half-open state): if it succeeds
+// the circuit breaker closes and all the blocked senders resume, otherwise it opens again.
+//
+// Permanent errors are caused by the data and not by the backend, so they do not count as failures.
+// The results of the requests let through before the last state change are stale and ignored, so that
+// a late result cannot close or reopen the circuit breaker.
+type circuitBreakerSender struct {
+	fullName   string
+	cfg        CircuitBreakerSettings
+	nextSender requestSender
+	logger     *zap.Logger
+	stopCh     chan struct{}
+
+	mu                  sync.Mutex
+	state               circuitBreakerState
+	consecutiveFailures int
+	probeAt             time.Time
+	probeInFlight       bool
+	// generation is incremented on every state change. Requests are tagged with the generation they were let through in.
+	generation uint64
+	// stateChanged is closed and replaced every time the state changes to wake up the blocked senders.
+	stateChanged chan struct{}
+}
+
+func newCircuitBreakerSender(id config.ComponentID, signal config.DataType, cfg CircuitBreakerSettings, nextSender requestSender, logger *zap.Logger) *circuitBreakerSender {
+	fullName := id.String()
+	if signal != "" {
+		fullName = fmt.Sprintf("%s-%s", fullName, signal)
+	}
+	return &circuitBreakerSender{
+		fullName:     fullName,
+		cfg:          cfg,
+		nextSender:   nextSender,
+		logger:       logger,
+		stopCh:       make(chan struct{}),
+		stateChanged: make(chan struct{}),
+	}
+}
+
+// send implements the requestSender interface
+func (cb *circuitBreakerSender) send(req request) error {
+	generation, err := cb.acquire(req)
+	if err != nil {
+		return err
+	}
+	err = cb.nextSender.send(req)
+	cb.onResult(generation, err)
+	return err
+}
+
+// start is invoked during service startup.
+func (cb *circuitBreakerSender) start() error {
+	if err := cb.cfg.Validate(); err != nil {
+		return err
+	}
+	// Start reporting circuit breaker state metric
+	err := globalInstruments.circuitBreakerState.UpsertEntry(func() int64 {
+		return int64(cb.getState())
+	}, metricdata.NewLabelValue(cb.fullName))
+	if err != nil {
+		return fmt.Errorf("failed to create circuit breaker state metric: %v", err)
+	}
+	return nil
+}
+
+// shutdown is invoked during service shutdown. It unblocks all the senders waiting for the circuit breaker to close.
+func (cb *circuitBreakerSender) shutdown() {
+	close(cb.stopCh)
+	// Cleanup circuit breaker metric reporting
+	_ = globalInstruments.circuitBreakerState.UpsertEntry(func() int64 {
+		return int64(circuitBreakerClosed)
+	}, metricdata.NewLabelValue(cb.fullName))
+}
+
+// getState returns the current state of the circuit breaker.
+func (cb *circuitBreakerSender) getState() circuitBreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return cb.state
+}
+
+// acquire blocks until the request is allowed to be sent, and returns the generation it is let through in.
+func (cb *circuitBreakerSender) acquire(req request) (uint64, error) {
+	for {
+		var timer <-chan time.Time
+		cb.mu.Lock()
+		switch cb.state {
+		case circuitBreakerClosed:
+			generation := cb.generation
+			cb.mu.Unlock()
+			return generation, nil
+		case circuitBreakerOpen:
+			wait := time.Until(cb.probeAt)
+			if wait <= 0 {
+				cb.setState(circuitBreakerHalfOpen)
+				cb.probeInFlight = true
+				generation := cb.generation
+				cb.mu.Unlock()
+				return generation, nil
+			}
+			timer = time.After(wait)
+		case circuitBreakerHalfOpen:
+			if !cb.probeInFlight {
+				cb.probeInFlight = true
+				generation := cb.generation
+				cb.mu.Unlock()
+				return generation, nil
+			}
+		}
+		stateChanged := cb.stateChanged
+		cb.mu.Unlock()
+
+		select {
+		case <-stateChanged:
+		case <-timer:
+		case <-req.context().Done():
+			return 0, req.context().Err()
+		case <-cb.stopCh:
+			return 0, errCircuitBreakerOpen
+		}
+	}
+}
+
+// onResult updates the state of the circuit breaker with the result of a request let through in the given generation.
+func (cb *circuitBreakerSender) onResult(generation uint64, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if generation != cb.generation {
+		// The state changed since the request was let through, the result is stale.
+		return
+	}
+	cb.probeInFlight = false
+
+	if err == nil || consumererror.IsPermanent(err) {
+		cb.consecutiveFailures = 0
+		if cb.state != circuitBreakerClosed {
+			cb.logger.Info("Circuit breaker closed, backend recovered.")
+			cb.setState(circuitBreakerClosed)
+		}
+		return
+	}
+
+	cb.consecutiveFailures++
+	if cb.state == circuitBreakerHalfOpen || (cb.state == circuitBreakerClosed && cb.consecutiveFailures >= cb.cfg.FailureThreshold) {
+		cb.logger.Warn(
+			"Circuit breaker opened, pausing sending requests.",
+			zap.Error(err),
+			zap.Int("consecutive_failures", cb.consecutiveFailures),
+			zap.Duration("probe_interval", cb.cfg.ProbeInterval),
+		)
+		cb.probeAt = time.Now().Add(cb.cfg.ProbeInterval)
+		cb.setState(circuitBreakerOpen)
+	}
+}
+
+// setState must be called while holding the lock.
+func (cb *circuitBreakerSender) setState(state circuitBreakerState) {
+	cb.state = state
+	cb.generation++
+	close(cb.stateChanged)
+	cb.stateChanged = make(chan struct{})
+}
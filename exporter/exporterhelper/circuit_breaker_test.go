@@ -0,0 +1,233 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/tag"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+// failingSender is a request sender that fails all the requests while failing is set.
+type failingSender struct {
+	failing *atomic.Bool
+	err     error
+	calls   *atomic.Int32
+}
+
+func newFailingSender(err error) *failingSender {
+	return &failingSender{failing: atomic.NewBool(true), err: err, calls: atomic.NewInt32(0)}
+}
+
+func (fs *failingSender) send(request) error {
+	fs.calls.Inc()
+	if fs.failing.Load() {
+		return fs.err
+	}
+	return nil
+}
+
+func newTestCircuitBreakerSender(nextSender requestSender) *circuitBreakerSender {
+	cfg := CircuitBreakerSettings{Enabled: true, FailureThreshold: 3, ProbeInterval: 50 * time.Millisecond}
+	return newCircuitBreakerSender(config.NewComponentID(typeStr), "", cfg, nextSender, zap.NewNop())
+}
+
+func TestCircuitBreaker_OpenAndRecover(t *testing.T) {
+	fs := newFailingSender(errors.New("transient error"))
+	cb := newTestCircuitBreakerSender(fs)
+
+	for i := 0; i < 3; i++ {
+		assert.Error(t, cb.send(newMockRequest(context.Background(), 1, nil)))
+	}
+	assert.Equal(t, circuitBreakerOpen, cb.getState())
+
+	// Requests are blocked until the probe interval elapses and the probe succeeds.
+	fs.failing.Store(false)
+	start := time.Now()
+	assert.NoError(t, cb.send(newMockRequest(context.Background(), 1, nil)))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	assert.EqualValues(t, 4, fs.calls.Load())
+	assert.Equal(t, circuitBreakerClosed, cb.getState())
+}
+
+func TestCircuitBreaker_ProbeFailureReopens(t *testing.T) {
+	fs := newFailingSender(errors.New("transient error"))
+	cb := newTestCircuitBreakerSender(fs)
+
+	for i := 0; i < 3; i++ {
+		assert.Error(t, cb.send(newMockRequest(context.Background(), 1, nil)))
+	}
+	require.Equal(t, circuitBreakerOpen, cb.getState())
+
+	// The probe fails, so the circuit breaker opens again.
+	assert.Error(t, cb.send(newMockRequest(context.Background(), 1, nil)))
+	assert.Equal(t, circuitBreakerOpen, cb.getState())
+	assert.EqualValues(t, 4, fs.calls.Load())
+}
+
+func TestCircuitBreaker_SingleProbe(t *testing.T) {
+	fs := newFailingSender(errors.New("transient error"))
+	cb := newTestCircuitBreakerSender(fs)
+	for i := 0; i < 3; i++ {
+		assert.Error(t, cb.send(newMockRequest(context.Background(), 1, nil)))
+	}
+	fs.failing.Store(false)
+
+	done := make(chan struct{})
+	for i := 0; i < 5; i++ {
+		go func() {
+			assert.NoError(t, cb.send(newMockRequest(context.Background(), 1, nil)))
+			done <- struct{}{}
+		}()
+	}
+	// Nothing is sent while the circuit breaker is open.
+	time.Sleep(20 * time.Millisecond)
+	assert.EqualValues(t, 3, fs.calls.Load())
+
+	for i := 0; i < 5; i++ {
+		<-done
+	}
+	assert.EqualValues(t, 8, fs.calls.Load())
+	assert.Equal(t, circuitBreakerClosed, cb.getState())
+}
+
+func TestCircuitBreaker_StaleResultsIgnored(t *testing.T) {
+	fs := newFailingSender(errors.New("transient error"))
+	cb := newTestCircuitBreakerSender(fs)
+
+	// A request let through while closed fails only after the circuit breaker opened and a probe is in flight.
+	staleGeneration, err := cb.acquire(newMockRequest(context.Background(), 1, nil))
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		assert.Error(t, cb.send(newMockRequest(context.Background(), 1, nil)))
+	}
+	require.Equal(t, circuitBreakerOpen, cb.getState())
+	cb.cfg.ProbeInterval = 0
+	cb.probeAt = time.Now()
+	probeGeneration, err := cb.acquire(newMockRequest(context.Background(), 1, nil))
+	require.NoError(t, err)
+	require.Equal(t, circuitBreakerHalfOpen, cb.getState())
+
+	cb.onResult(staleGeneration, errors.New("transient error"))
+	assert.Equal(t, circuitBreakerHalfOpen, cb.getState())
+	assert.True(t, cb.probeInFlight)
+
+	cb.onResult(probeGeneration, nil)
+	assert.Equal(t, circuitBreakerClosed, cb.getState())
+	cb.onResult(probeGeneration, errors.New("transient error"))
+	assert.Equal(t, 0, cb.consecutiveFailures)
+}
+
+func TestCircuitBreaker_PermanentErrorsIgnored(t *testing.T) {
+	fs := newFailingSender(consumererror.NewPermanent(errors.New("bad data")))
+	cb := newTestCircuitBreakerSender(fs)
+
+	for i := 0; i < 10; i++ {
+		assert.Error(t, cb.send(newMockRequest(context.Background(), 1, nil)))
+	}
+	assert.Equal(t, circuitBreakerClosed, cb.getState())
+}
+
+func TestCircuitBreaker_ShutdownUnblocks(t *testing.T) {
+	fs := newFailingSender(errors.New("transient error"))
+	cb := newTestCircuitBreakerSender(fs)
+	cb.cfg.ProbeInterval = time.Hour
+	for i := 0; i < 3; i++ {
+		assert.Error(t, cb.send(newMockRequest(context.Background(), 1, nil)))
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- cb.send(newMockRequest(context.Background(), 1, nil))
+	}()
+	cb.shutdown()
+	assert.ErrorIs(t, <-errCh, errCircuitBreakerOpen)
+	assert.EqualValues(t, 3, fs.calls.Load())
+}
+
+func TestCircuitBreaker_StateReported(t *testing.T) {
+	exporterCfg := config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "circuit_breaker"))
+	cbCfg := DefaultCircuitBreakerSettings()
+	cbCfg.Enabled = true
+	cbCfg.FailureThreshold = 1
+	cbCfg.ProbeInterval = time.Hour
+	be := newBaseExporter(&exporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithCircuitBreaker(cbCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	exporterTags := []tag.Tag{{Key: exporterTag, Value: exporterCfg.ID().String()}}
+	assert.Equal(t, "closed", be.CircuitBreakerState())
+	checkValueForGlobalManager(t, exporterTags, int64(0), "exporter/circuit_breaker_state")
+
+	assert.Error(t, be.sender.send(newErrorRequest(context.Background())))
+	assert.Equal(t, "open", be.CircuitBreakerState())
+	checkValueForGlobalManager(t, exporterTags, int64(1), "exporter/circuit_breaker_state")
+}
+
+func TestCircuitBreaker_StateReportedPerSignal(t *testing.T) {
+	exporterCfg := config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "circuit_breaker_signal"))
+	cbCfg := DefaultCircuitBreakerSettings()
+	cbCfg.Enabled = true
+	cbCfg.FailureThreshold = 1
+	cbCfg.ProbeInterval = time.Hour
+	be := newBaseExporter(&exporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithCircuitBreaker(cbCfg)), config.TracesDataType, nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	assert.Error(t, be.sender.send(newErrorRequest(context.Background())))
+	exporterTags := []tag.Tag{{Key: exporterTag, Value: exporterCfg.ID().String() + "-" + string(config.TracesDataType)}}
+	checkValueForGlobalManager(t, exporterTags, int64(1), "exporter/circuit_breaker_state")
+}
+
+func TestCircuitBreakerSettings_Validate(t *testing.T) {
+	cfg := DefaultCircuitBreakerSettings()
+	assert.NoError(t, cfg.Validate())
+	cfg.FailureThreshold = 0
+	assert.NoError(t, cfg.Validate(), "a disabled circuit breaker is not validated")
+
+	cfg.Enabled = true
+	assert.ErrorIs(t, cfg.Validate(), errCircuitBreakerFailureThreshold)
+	cfg.FailureThreshold = 1
+	cfg.ProbeInterval = -time.Second
+	assert.ErrorIs(t, cfg.Validate(), errCircuitBreakerProbeInterval)
+	cfg.ProbeInterval = time.Second
+	assert.NoError(t, cfg.Validate())
+
+	cfg.ProbeInterval = 0
+	exporterCfg := config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "circuit_breaker_invalid"))
+	be := newBaseExporter(&exporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithCircuitBreaker(cfg)), "", nopRequestUnmarshaler())
+	assert.ErrorIs(t, be.Start(context.Background(), componenttest.NewNopHost()), errCircuitBreakerProbeInterval)
+}
+
+func TestCircuitBreaker_Disabled(t *testing.T) {
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(), "", nopRequestUnmarshaler())
+	assert.Nil(t, be.cbSender)
+	assert.Equal(t, "", be.CircuitBreakerState())
+}
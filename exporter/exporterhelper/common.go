@@ -18,6 +18,8 @@ import (
 	"context"
 	"time"
 
+	"go.uber.org/multierr"
+
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenthelper"
 	"go.opentelemetry.io/collector/config"
@@ -53,11 +55,28 @@ type request interface {
 	onError(error) request
 	// Returns the count of spans/metric points or log records.
 	count() int
+	// Returns the size in bytes of the data when encoded as OTLP protobuf.
+	bytesSize() int
+	// merge moves the data of the other request, which must be of the same type, into this request.
+	merge(other request)
+	// split removes at most maxItems items, which are at most maxBytes bytes once encoded, from the request and
+	// returns them as a new request. A limit of 0 means no limit. At least one item is always removed.
+	split(maxItems, maxBytes int) request
 
 	// PersistentRequest provides interface with additional capabilities required by persistent queue
 	internal.PersistentRequest
 }
 
+// synchronousSendKey is the context key marking the requests sent synchronously, bypassing the batching
+// and the sending queue, so the call returns only once the request is exported.
+type synchronousSendKey struct{}
+
+// isSynchronousSend returns true if the requests of the context must be sent synchronously.
+func isSynchronousSend(ctx context.Context) bool {
+	synchronous, _ := ctx.Value(synchronousSendKey{}).(bool)
+	return synchronous
+}
+
 // requestSender is an abstraction of a sender for a request independent of the type of the data (traces, metrics, logs).
 type requestSender interface {
 	send(req request) error
@@ -94,6 +113,10 @@ type baseSettings struct {
 	TimeoutSettings
 	QueueSettings
 	RetrySettings
+	BatchSettings
+	CircuitBreakerSettings
+	DeadLetterSettings
+	TransformSettings
 }
 
 // fromOptions returns the internal options starting from the default and applying all configured options.
@@ -104,13 +127,21 @@ func fromOptions(options ...Option) *baseSettings {
 		// TODO: Enable queuing by default (call DefaultQueueSettings)
 		QueueSettings: QueueSettings{Enabled: false},
 		// TODO: Enable retry by default (call DefaultRetrySettings)
-		RetrySettings: RetrySettings{Enabled: false},
+		RetrySettings:          RetrySettings{Enabled: false},
+		BatchSettings:          BatchSettings{Enabled: false},
+		CircuitBreakerSettings: CircuitBreakerSettings{Enabled: false},
 	}
 
 	for _, op := range options {
 		op(opts)
 	}
 
+	if opts.BatchSettings.Enabled || len(opts.TransformSettings.Actions) > 0 {
+		// The batch sender moves the incoming data into the pending batch, and the transforms modify it,
+		// so the exporter must get its own copy of the data if other consumers share it.
+		opts.consumerOptions = append(opts.consumerOptions, consumerhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+	}
+
 	return opts
 }
 
@@ -157,6 +188,38 @@ func WithQueue(queueSettings QueueSettings) Option {
 	}
 }
 
+// WithBatch overrides the default BatchSettings for an exporter.
+// The default BatchSettings is to disable batching.
+func WithBatch(batchSettings BatchSettings) Option {
+	return func(o *baseSettings) {
+		o.BatchSettings = batchSettings
+	}
+}
+
+// WithCircuitBreaker overrides the default CircuitBreakerSettings for an exporter.
+// The default CircuitBreakerSettings is to disable the circuit breaker.
+func WithCircuitBreaker(circuitBreakerSettings CircuitBreakerSettings) Option {
+	return func(o *baseSettings) {
+		o.CircuitBreakerSettings = circuitBreakerSettings
+	}
+}
+
+// WithDeadLetter overrides the default DeadLetterSettings for an exporter.
+// The default DeadLetterSettings is to drop the data.
+func WithDeadLetter(deadLetterSettings DeadLetterSettings) Option {
+	return func(o *baseSettings) {
+		o.DeadLetterSettings = deadLetterSettings
+	}
+}
+
+// WithTransform overrides the default TransformSettings for an exporter.
+// The default TransformSettings is to send the data unchanged.
+func WithTransform(transformSettings TransformSettings) Option {
+	return func(o *baseSettings) {
+		o.TransformSettings = transformSettings
+	}
+}
+
 // WithCapabilities overrides the default Capabilities() function for a Consumer.
 // The default is non-mutable data.
 // TODO: Verify if we can change the default to be mutable as we do for processors.
@@ -169,9 +232,12 @@ func WithCapabilities(capabilities consumer.Capabilities) Option {
 // baseExporter contains common fields between different exporter types.
 type baseExporter struct {
 	component.Component
-	obsrep   *obsExporter
-	sender   requestSender
-	qrSender *queuedRetrySender
+	obsrep      *obsExporter
+	sender      requestSender
+	qrSender    *queuedRetrySender
+	batchSender *batchSender
+	cbSender    *circuitBreakerSender
+	deadLetter  *deadLetterSender
 }
 
 func newBaseExporter(cfg config.Exporter, set component.ExporterCreateSettings, bs *baseSettings, signal config.DataType, reqUnmarshaler internal.RequestUnmarshaler) *baseExporter {
@@ -184,8 +250,23 @@ func newBaseExporter(cfg config.Exporter, set component.ExporterCreateSettings,
 		ExporterID:             cfg.ID(),
 		ExporterCreateSettings: set,
 	}, globalInstruments)
-	be.qrSender = newQueuedRetrySender(cfg.ID(), signal, bs.QueueSettings, bs.RetrySettings, reqUnmarshaler, &timeoutSender{cfg: bs.TimeoutSettings}, set.Logger)
+	var nextSender requestSender = &timeoutSender{cfg: bs.TimeoutSettings}
+	if bs.CircuitBreakerSettings.Enabled {
+		// The circuit breaker is checked on every attempt, so retries of all the requests are paused while it is open.
+		be.cbSender = newCircuitBreakerSender(cfg.ID(), signal, bs.CircuitBreakerSettings, nextSender, set.Logger)
+		nextSender = be.cbSender
+	}
+	be.deadLetter = newDeadLetterSender(bs.DeadLetterSettings, cfg.ID(), signal, be.obsrep, set.Logger)
+	be.qrSender = newQueuedRetrySender(cfg.ID(), signal, bs.QueueSettings, bs.RetrySettings, reqUnmarshaler, nextSender, be.obsrep, be.deadLetter, set.Logger)
 	be.sender = be.qrSender
+	if bs.BatchSettings.Enabled {
+		be.batchSender = newBatchSender(bs.BatchSettings, signal, be.obsrep, be.qrSender, set.Logger)
+		if bs.QueueSettings.Enabled && bs.QueueSettings.Partition.enabled() {
+			partition := bs.QueueSettings.Partition
+			be.batchSender.partitionOf = partition.partitionOf
+		}
+		be.sender = be.batchSender
+	}
 
 	return be
 }
@@ -203,16 +284,54 @@ func (be *baseExporter) Start(ctx context.Context, host component.Host) error {
 		return err
 	}
 
+	// The dead-letter destination must be ready before any request can be dropped.
+	if err := be.deadLetter.start(ctx, host); err != nil {
+		return err
+	}
+
+	if be.cbSender != nil {
+		if err := be.cbSender.start(); err != nil {
+			return err
+		}
+	}
+
 	// If no error then start the queuedRetrySender.
-	return be.qrSender.start(ctx, host)
+	if err := be.qrSender.start(ctx, host); err != nil {
+		return err
+	}
+
+	// Start batching only after the queuedRetrySender is ready to accept batches.
+	if be.batchSender != nil {
+		return be.batchSender.start()
+	}
+	return nil
 }
 
 // Shutdown all senders and exporter and is invoked during service shutdown.
 func (be *baseExporter) Shutdown(ctx context.Context) error {
-	// First shutdown the queued retry sender
+	// First flush the pending batches into the queued retry sender.
+	if be.batchSender != nil {
+		be.batchSender.shutdown()
+	}
+	// Unblock the requests waiting for the circuit breaker, so the queue can be drained.
+	if be.cbSender != nil {
+		be.cbSender.shutdown()
+	}
+	// Then shutdown the queued retry sender
 	be.qrSender.shutdown()
+	// Then release the dead-letter destination, no more requests can be dropped.
+	err := be.deadLetter.shutdown(ctx)
 	// Last shutdown the wrapped exporter itself.
-	return be.Component.Shutdown(ctx)
+	return multierr.Append(err, be.Component.Shutdown(ctx))
+}
+
+// CircuitBreakerState returns the current state of the circuit breaker of the exporter,
+// or an empty string if the circuit breaker is not enabled.
+func (be *baseExporter) CircuitBreakerState() string {
+	if be.cbSender == nil {
+		return ""
+	}
+	return be.cbSender.getState().String()
 }
 
 // timeoutSender is a request sender that adds a `timeout` to every request that passes this sender.
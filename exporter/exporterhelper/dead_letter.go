@@ -0,0 +1,244 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+const (
+	// deadLetterStorageNamePrefix prefixes the signal to name the storage client of the dead-letter storage.
+	deadLetterStorageNamePrefix = "deadletter_"
+	// deadLetterWriteIndexKey is the storage key of the index of the next dead-letter item.
+	deadLetterWriteIndexKey = "wi"
+)
+
+var errDeadLetterBothDestinations = errors.New("only one of dead-letter exporter and storage can be configured")
+
+// DeadLetterSettings defines configuration for the destination of the data dropped because exporting failed
+// permanently or because all the retries were exhausted.
+type DeadLetterSettings struct {
+	// Exporter is the ID of the exporter the dropped data is sent to. The exporter is looked up among the
+	// exporters of the pipelines, so it must be part of a pipeline of the same data type.
+	Exporter *config.ComponentID `mapstructure:"exporter"`
+	// Storage is the ID of the storage extension the dropped data is written to, encoded as OTLP protobuf.
+	// The collector never reads the stored items back, they are left to external tools.
+	Storage *config.ComponentID `mapstructure:"storage"`
+}
+
+// Validate checks if the dead-letter configuration is valid.
+func (cfg *DeadLetterSettings) Validate() error {
+	if cfg.Exporter != nil && cfg.Storage != nil {
+		return errDeadLetterBothDestinations
+	}
+	return nil
+}
+
+func (cfg *DeadLetterSettings) enabled() bool {
+	return cfg.Exporter != nil || cfg.Storage != nil
+}
+
+// deadLetterItemKey returns the storage key of the dead-letter item with the given index. Items are written
+// with increasing indexes starting from 0, and the index of the next item is stored under the "wi" key.
+func deadLetterItemKey(index uint64) string {
+	return strconv.FormatUint(index, 10)
+}
+
+// deadLetterSender routes the requests dropped by the retrySender to the configured dead-letter destination.
+// A nil deadLetterSender drops the requests.
+type deadLetterSender struct {
+	cfg    DeadLetterSettings
+	id     config.ComponentID
+	signal config.DataType
+	obsrep *obsExporter
+	logger *zap.Logger
+
+	exporter component.Exporter
+
+	mu         sync.Mutex
+	client     storage.Client
+	writeIndex uint64
+}
+
+func newDeadLetterSender(cfg DeadLetterSettings, id config.ComponentID, signal config.DataType, obsrep *obsExporter, logger *zap.Logger) *deadLetterSender {
+	if !cfg.enabled() {
+		return nil
+	}
+	return &deadLetterSender{
+		cfg:    cfg,
+		id:     id,
+		signal: signal,
+		obsrep: obsrep,
+		logger: logger,
+	}
+}
+
+// start resolves the configured dead-letter destination.
+func (dls *deadLetterSender) start(ctx context.Context, host component.Host) error {
+	if dls == nil {
+		return nil
+	}
+	if err := dls.cfg.Validate(); err != nil {
+		return err
+	}
+
+	if dls.cfg.Exporter != nil {
+		exp, ok := host.GetExporters()[dls.signal][*dls.cfg.Exporter]
+		if !ok {
+			return fmt.Errorf("dead-letter exporter %q not found for %q data", dls.cfg.Exporter.String(), dls.signal)
+		}
+		dls.exporter = exp
+		return nil
+	}
+
+	ext, ok := host.GetExtensions()[*dls.cfg.Storage]
+	if !ok {
+		return fmt.Errorf("dead-letter storage %q not found", dls.cfg.Storage.String())
+	}
+	se, ok := ext.(storage.Extension)
+	if !ok {
+		return fmt.Errorf("dead-letter storage %q is not a storage extension", dls.cfg.Storage.String())
+	}
+	client, err := se.GetClient(ctx, component.KindExporter, dls.id, deadLetterStorageNamePrefix+string(dls.signal))
+	if err != nil {
+		return err
+	}
+	val, err := client.Get(ctx, deadLetterWriteIndexKey)
+	if err != nil {
+		return err
+	}
+	if val != nil {
+		if dls.writeIndex, err = strconv.ParseUint(string(val), 10, 64); err != nil {
+			return fmt.Errorf("invalid dead-letter write index: %w", err)
+		}
+	}
+	dls.client = client
+	return nil
+}
+
+// shutdown releases the dead-letter storage client, if any.
+func (dls *deadLetterSender) shutdown(ctx context.Context) error {
+	if dls == nil || dls.client == nil {
+		return nil
+	}
+	return dls.client.Close(ctx)
+}
+
+// onDropped routes a request dropped because of the given error to the dead-letter destination.
+func (dls *deadLetterSender) onDropped(req request, dropErr error) {
+	if dls == nil || req.count() == 0 {
+		return
+	}
+	// The request context may be already cancelled, the dead-letter destination must be reached anyway.
+	ctx := context.Background()
+	var err error
+	if dls.exporter != nil {
+		err = dls.export(ctx, req)
+	} else {
+		err = dls.store(ctx, req)
+	}
+	if err != nil {
+		dls.logger.Error(
+			"Failed to route dropped data to the dead-letter destination.",
+			zap.Error(err),
+			zap.NamedError("drop_error", dropErr),
+			zap.Int("dropped_items", req.count()),
+		)
+		return
+	}
+	dls.obsrep.recordDeadLetter(ctx, dls.signal, int64(req.count()))
+}
+
+// onPartiallyDropped routes the data permanently rejected by the partial failure err to the dead-letter destination.
+func (dls *deadLetterSender) onPartiallyDropped(ctx context.Context, err error) {
+	if dls == nil {
+		return
+	}
+	// The rejected data is cloned, since it is moved into the accumulated permanent error later on.
+	var tracesErr consumererror.PartialTraces
+	if errors.As(err, &tracesErr) {
+		dls.onDropped(newTracesRequest(ctx, tracesErr.GetPermanent().Clone(), nil), err)
+		return
+	}
+	var metricsErr consumererror.PartialMetrics
+	if errors.As(err, &metricsErr) {
+		dls.onDropped(newMetricsRequest(ctx, metricsErr.GetPermanent().Clone(), nil), err)
+		return
+	}
+	var logsErr consumererror.PartialLogs
+	if errors.As(err, &logsErr) {
+		dls.onDropped(newLogsRequest(ctx, logsErr.GetPermanent().Clone(), nil), err)
+	}
+}
+
+func (dls *deadLetterSender) export(ctx context.Context, req request) error {
+	// The dropped data may be shared with other exporters of the pipeline, so it is cloned
+	// if the dead-letter exporter mutates it.
+	switch r := req.(type) {
+	case *tracesRequest:
+		if tc, ok := dls.exporter.(consumer.Traces); ok {
+			if tc.Capabilities().MutatesData {
+				return tc.ConsumeTraces(ctx, r.td.Clone())
+			}
+			return tc.ConsumeTraces(ctx, r.td)
+		}
+	case *metricsRequest:
+		if mc, ok := dls.exporter.(consumer.Metrics); ok {
+			if mc.Capabilities().MutatesData {
+				return mc.ConsumeMetrics(ctx, r.md.Clone())
+			}
+			return mc.ConsumeMetrics(ctx, r.md)
+		}
+	case *logsRequest:
+		if lc, ok := dls.exporter.(consumer.Logs); ok {
+			if lc.Capabilities().MutatesData {
+				return lc.ConsumeLogs(ctx, r.ld.Clone())
+			}
+			return lc.ConsumeLogs(ctx, r.ld)
+		}
+	}
+	return fmt.Errorf("dead-letter exporter %q does not support the dropped data", dls.cfg.Exporter.String())
+}
+
+func (dls *deadLetterSender) store(ctx context.Context, req request) error {
+	buf, err := req.Marshal()
+	if err != nil {
+		return err
+	}
+
+	dls.mu.Lock()
+	defer dls.mu.Unlock()
+	nextIndex := dls.writeIndex + 1
+	err = dls.client.Batch(ctx,
+		storage.SetOperation(deadLetterItemKey(dls.writeIndex), buf),
+		storage.SetOperation(deadLetterWriteIndexKey, []byte(strconv.FormatUint(nextIndex, 10))))
+	if err != nil {
+		return err
+	}
+	dls.writeIndex = nextIndex
+	return nil
+}
@@ -0,0 +1,231 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/tag"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenthelper"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+var deadLetterID = config.NewComponentIDWithName("nop", "deadletter")
+
+// deadLetterHost is a component.Host that provides a dead-letter exporter and storage.
+type deadLetterHost struct {
+	component.Host
+	exporters  map[config.DataType]map[config.ComponentID]component.Exporter
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *deadLetterHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return h.exporters
+}
+
+func (h *deadLetterHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+type mockStorageExtension struct {
+	component.Component
+	client *mockStorageClient
+}
+
+func (m *mockStorageExtension) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return m.client, nil
+}
+
+type mockStorageClient struct {
+	mu sync.Mutex
+	st map[string][]byte
+}
+
+func (m *mockStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.st[key], nil
+}
+
+func (m *mockStorageClient) Set(_ context.Context, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.st[key] = value
+	return nil
+}
+
+func (m *mockStorageClient) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.st, key)
+	return nil
+}
+
+func (m *mockStorageClient) Batch(_ context.Context, ops ...storage.Operation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = m.st[op.Key]
+		case storage.Set:
+			m.st[op.Key] = op.Value
+		case storage.Delete:
+			delete(m.st, op.Key)
+		}
+	}
+	return nil
+}
+
+func (m *mockStorageClient) Close(context.Context) error {
+	return nil
+}
+
+func newDeadLetterExporterHost(t *testing.T, sink *consumertest.TracesSink) component.Host {
+	dlExp, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeTraces)
+	require.NoError(t, err)
+	return &deadLetterHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.DataType]map[config.ComponentID]component.Exporter{
+			config.TracesDataType: {deadLetterID: dlExp},
+		},
+	}
+}
+
+func TestDeadLetter_PermanentErrorToExporter(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	host := newDeadLetterExporterHost(t, sink)
+	expCfg := config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "deadletter_permanent"))
+	te, err := NewTracesExporter(&expCfg, componenttest.NewNopExporterCreateSettings(),
+		newTraceDataPusher(consumererror.NewPermanent(errors.New("bad data"))),
+		WithDeadLetter(DeadLetterSettings{Exporter: &deadLetterID}))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), host))
+
+	td := testdata.GenerateTracesTwoSpansSameResource()
+	assert.Error(t, te.ConsumeTraces(context.Background(), td))
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	assert.Equal(t, 2, sink.SpanCount())
+	exporterTags := []tag.Tag{{Key: exporterTag, Value: expCfg.ID().String()}}
+	checkValueForGlobalManager(t, exporterTags, int64(2), "exporter/dead_letter_spans")
+}
+
+func TestDeadLetter_RetriesExhaustedToExporter(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	host := newDeadLetterExporterHost(t, sink)
+	rCfg := DefaultRetrySettings()
+	rCfg.InitialInterval = time.Millisecond
+	rCfg.MaxElapsedTime = 10 * time.Millisecond
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(),
+		newTraceDataPusher(errors.New("transient error")),
+		WithRetry(rCfg), WithDeadLetter(DeadLetterSettings{Exporter: &deadLetterID}))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), host))
+
+	assert.Error(t, te.ConsumeTraces(context.Background(), testdata.GenerateTracesOneSpan()))
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	assert.Equal(t, 1, sink.SpanCount())
+}
+
+func TestDeadLetter_PartialFailureToExporter(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	host := newDeadLetterExporterHost(t, sink)
+	pusher := func(_ context.Context, td pdata.Traces) error {
+		return consumererror.NewPartialTraces(errors.New("partial error"), pdata.NewTraces(), testdata.GenerateTracesOneSpan())
+	}
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), pusher,
+		WithRetry(DefaultRetrySettings()), WithDeadLetter(DeadLetterSettings{Exporter: &deadLetterID}))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), host))
+
+	assert.Error(t, te.ConsumeTraces(context.Background(), testdata.GenerateTracesTwoSpansSameResource()))
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	// Only the permanently rejected data is routed to the dead-letter exporter.
+	assert.Equal(t, 1, sink.SpanCount())
+}
+
+func TestDeadLetter_Storage(t *testing.T) {
+	client := &mockStorageClient{st: map[string][]byte{}}
+	host := &deadLetterHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			deadLetterID: &mockStorageExtension{Component: componenthelper.New(), client: client},
+		},
+	}
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(),
+		newTraceDataPusher(consumererror.NewPermanent(errors.New("bad data"))),
+		WithDeadLetter(DeadLetterSettings{Storage: &deadLetterID}))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), host))
+
+	td := testdata.GenerateTracesTwoSpansSameResource()
+	assert.Error(t, te.ConsumeTraces(context.Background(), td))
+	assert.Error(t, te.ConsumeTraces(context.Background(), testdata.GenerateTracesOneSpan()))
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	assert.Equal(t, []byte("2"), client.st[deadLetterWriteIndexKey])
+	got, err := tracesUnmarshaler.UnmarshalTraces(client.st[deadLetterItemKey(0)])
+	require.NoError(t, err)
+	assert.Equal(t, td, got)
+	got, err = tracesUnmarshaler.UnmarshalTraces(client.st[deadLetterItemKey(1)])
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.SpanCount())
+
+	// The write index survives restarts.
+	te, err = NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(),
+		newTraceDataPusher(consumererror.NewPermanent(errors.New("bad data"))),
+		WithDeadLetter(DeadLetterSettings{Storage: &deadLetterID}))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), host))
+	assert.Error(t, te.ConsumeTraces(context.Background(), testdata.GenerateTracesOneSpan()))
+	require.NoError(t, te.Shutdown(context.Background()))
+	assert.Equal(t, []byte("3"), client.st[deadLetterWriteIndexKey])
+	assert.Contains(t, client.st, deadLetterItemKey(2))
+}
+
+func TestDeadLetter_StartErrors(t *testing.T) {
+	host := &deadLetterHost{Host: componenttest.NewNopHost()}
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), newTraceDataPusher(nil),
+		WithDeadLetter(DeadLetterSettings{Exporter: &deadLetterID}))
+	require.NoError(t, err)
+	assert.Error(t, te.Start(context.Background(), host))
+
+	te, err = NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), newTraceDataPusher(nil),
+		WithDeadLetter(DeadLetterSettings{Storage: &deadLetterID}))
+	require.NoError(t, err)
+	assert.Error(t, te.Start(context.Background(), host))
+
+	te, err = NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), newTraceDataPusher(nil),
+		WithDeadLetter(DeadLetterSettings{Exporter: &deadLetterID, Storage: &deadLetterID}))
+	require.NoError(t, err)
+	assert.ErrorIs(t, te.Start(context.Background(), host), errDeadLetterBothDestinations)
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+
+import (
+	"container/list"
+)
+
+// BytesSizedQueue is a ProducerConsumerQueue that also tracks the size in bytes of the queued items.
+type BytesSizedQueue interface {
+	ProducerConsumerQueue
+	// SizeBytes returns the current size in bytes of the items in the queue.
+	SizeBytes() int
+}
+
+// bytesBoundedMemoryQueue implements a producer-consumer exchange bounded by the total size in bytes
+// of the queued items instead of by the number of items. New items are rejected if accepting them
+// would exceed the capacity.
+type bytesBoundedMemoryQueue struct {
+	*listMemoryQueue
+	items         *list.List
+	sizeBytes     int
+	capacityBytes int
+	sizer         func(item interface{}) int
+}
+
+type sizedItem struct {
+	item interface{}
+	size int
+}
+
+var _ BytesSizedQueue = (*bytesBoundedMemoryQueue)(nil)
+
+// NewBytesBoundedMemoryQueue constructs a new queue that can hold items with a total size up to capacityBytes,
+// as measured by the given sizer, and with an optional callback for dropped items (e.g. useful to emit metrics).
+func NewBytesBoundedMemoryQueue(capacityBytes int, sizer func(item interface{}) int, onDroppedItem func(item interface{})) BytesSizedQueue {
+	q := &bytesBoundedMemoryQueue{
+		items:         list.New(),
+		capacityBytes: capacityBytes,
+		sizer:         sizer,
+	}
+	q.listMemoryQueue = newListMemoryQueue(q, func(item interface{}) {
+		if onDroppedItem != nil {
+			onDroppedItem(item.(sizedItem).item)
+		}
+	})
+	return q
+}
+
+// Produce is used by the producer to submit new item to the queue. Returns false in case of queue overflow.
+func (q *bytesBoundedMemoryQueue) Produce(item interface{}) bool {
+	// The item is measured before taking the lock of the queue, since it may be expensive.
+	return q.listMemoryQueue.Produce(sizedItem{item: item, size: q.sizer(item)})
+}
+
+func (q *bytesBoundedMemoryQueue) admit(item interface{}) bool {
+	si := item.(sizedItem)
+	if q.sizeBytes+si.size > q.capacityBytes {
+		// note that all items will be dropped if the capacity is 0
+		return false
+	}
+	q.items.PushBack(si)
+	q.sizeBytes += si.size
+	return true
+}
+
+// next removes the oldest item from the queue, if any.
+func (q *bytesBoundedMemoryQueue) next() (interface{}, bool) {
+	front := q.items.Front()
+	if front == nil {
+		return nil, false
+	}
+	si := q.items.Remove(front).(sizedItem)
+	q.sizeBytes -= si.size
+	return si.item, true
+}
+
+func (q *bytesBoundedMemoryQueue) len() int {
+	return q.items.Len()
+}
+
+// SizeBytes returns the current size in bytes of the items in the queue.
+func (q *bytesBoundedMemoryQueue) SizeBytes() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.sizeBytes
+}
+
+// Capacity returns the capacity of the queue in bytes.
+func (q *bytesBoundedMemoryQueue) Capacity() int {
+	return q.capacityBytes
+}
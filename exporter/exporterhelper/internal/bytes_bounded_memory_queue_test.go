@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+func stringSizer(item interface{}) int {
+	return len(item.(string))
+}
+
+func TestBytesBoundedQueue_Overflow(t *testing.T) {
+	dropped := atomic.NewInt32(0)
+	q := NewBytesBoundedMemoryQueue(10, stringSizer, func(item interface{}) {
+		dropped.Inc()
+	})
+	assert.Equal(t, 10, q.Capacity())
+
+	assert.True(t, q.Produce("aaaa"))
+	assert.True(t, q.Produce("bbbbbb"))
+	assert.Equal(t, 2, q.Size())
+	assert.Equal(t, 10, q.SizeBytes())
+
+	// Even a small item does not fit anymore.
+	assert.False(t, q.Produce("c"))
+	assert.EqualValues(t, 1, dropped.Load())
+
+	var mu sync.Mutex
+	var consumed []string
+	q.StartConsumers(1, func(item interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		consumed = append(consumed, item.(string))
+	})
+	assert.Eventually(t, func() bool {
+		return q.SizeBytes() == 0
+	}, time.Second, time.Millisecond)
+
+	assert.True(t, q.Produce("dddddddddd"))
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(consumed) == 3
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, []string{"aaaa", "bbbbbb", "dddddddddd"}, consumed)
+	mu.Unlock()
+
+	q.Stop()
+	assert.False(t, q.Produce("e"))
+	assert.EqualValues(t, 2, dropped.Load())
+}
+
+func TestBytesBoundedQueue_MultipleConsumers(t *testing.T) {
+	q := NewBytesBoundedMemoryQueue(1000, stringSizer, nil)
+	consumed := atomic.NewInt32(0)
+	var wg sync.WaitGroup
+	wg.Add(100)
+	q.StartConsumers(5, func(item interface{}) {
+		consumed.Inc()
+		wg.Done()
+	})
+	for i := 0; i < 100; i++ {
+		assert.True(t, q.Produce("item"))
+	}
+	wg.Wait()
+	q.Stop()
+	assert.EqualValues(t, 100, consumed.Load())
+	assert.Equal(t, 0, q.Size())
+}
@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+
+import (
+	"sync"
+
+	uatomic "go.uber.org/atomic"
+)
+
+// queuePolicy decides which items a listMemoryQueue admits and in which order they are consumed.
+// Its methods are called with the lock of the queue held.
+type queuePolicy interface {
+	// admit adds the item to the queue, or returns false if the item is rejected.
+	admit(item interface{}) bool
+	// next removes and returns the next item to consume, or returns false if the queue is empty.
+	next() (interface{}, bool)
+	// len returns the current number of items in the queue.
+	len() int
+}
+
+// listMemoryQueue implements the producer-consumer exchange of the memory queues storing their items in lists,
+// while the given policy decides of the admission and of the order of the items.
+type listMemoryQueue struct {
+	mu            sync.Mutex
+	policy        queuePolicy
+	onDroppedItem func(item interface{})
+
+	// notEmpty is signaled when items are added to the queue.
+	notEmpty chan struct{}
+	stopped  *uatomic.Bool
+	stopCh   chan struct{}
+	stopWG   sync.WaitGroup
+}
+
+func newListMemoryQueue(policy queuePolicy, onDroppedItem func(item interface{})) *listMemoryQueue {
+	return &listMemoryQueue{
+		policy:        policy,
+		onDroppedItem: onDroppedItem,
+		notEmpty:      make(chan struct{}, 1),
+		stopped:       uatomic.NewBool(false),
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// StartConsumers starts a given number of goroutines consuming items from the queue
+// and passing them into the consumer callback.
+func (q *listMemoryQueue) StartConsumers(num int, callback func(item interface{})) {
+	var startWG sync.WaitGroup
+	for i := 0; i < num; i++ {
+		q.stopWG.Add(1)
+		startWG.Add(1)
+		go func() {
+			startWG.Done()
+			defer q.stopWG.Done()
+			for {
+				item, ok := q.poll()
+				if ok {
+					callback(item)
+					continue
+				}
+				select {
+				case <-q.notEmpty:
+				case <-q.stopCh:
+					// the whole queue is closing, finish worker
+					return
+				}
+			}
+		}()
+	}
+	startWG.Wait()
+}
+
+// poll removes the next item selected by the policy, if any.
+func (q *listMemoryQueue) poll() (interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, ok := q.policy.next()
+	if ok && q.policy.len() > 0 {
+		// Wake up another consumer for the remaining items.
+		q.signal()
+	}
+	return item, ok
+}
+
+func (q *listMemoryQueue) signal() {
+	select {
+	case q.notEmpty <- struct{}{}:
+	default:
+	}
+}
+
+// Produce is used by the producer to submit new item to the queue. Returns false if the queue is stopped
+// or if the policy rejects the item.
+func (q *listMemoryQueue) Produce(item interface{}) bool {
+	if q.stopped.Load() {
+		q.dropItem(item)
+		return false
+	}
+
+	q.mu.Lock()
+	if !q.policy.admit(item) {
+		q.mu.Unlock()
+		q.dropItem(item)
+		return false
+	}
+	q.signal()
+	q.mu.Unlock()
+	return true
+}
+
+func (q *listMemoryQueue) dropItem(item interface{}) {
+	if q.onDroppedItem != nil {
+		q.onDroppedItem(item)
+	}
+}
+
+// Stop stops all consumers. It blocks until all consumers have stopped.
+func (q *listMemoryQueue) Stop() {
+	q.stopped.Store(true) // disable producer
+	close(q.stopCh)
+	q.stopWG.Wait()
+}
+
+// Size returns the current number of items in the queue.
+func (q *listMemoryQueue) Size() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.policy.len()
+}
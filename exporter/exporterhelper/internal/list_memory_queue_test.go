@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+// stackPolicy is a queuePolicy consuming the newest item first and admitting at most capacity items.
+type stackPolicy struct {
+	items    []interface{}
+	capacity int
+}
+
+func (p *stackPolicy) admit(item interface{}) bool {
+	if len(p.items) >= p.capacity {
+		return false
+	}
+	p.items = append(p.items, item)
+	return true
+}
+
+func (p *stackPolicy) next() (interface{}, bool) {
+	if len(p.items) == 0 {
+		return nil, false
+	}
+	item := p.items[len(p.items)-1]
+	p.items = p.items[:len(p.items)-1]
+	return item, true
+}
+
+func (p *stackPolicy) len() int {
+	return len(p.items)
+}
+
+func TestListMemoryQueue_Policy(t *testing.T) {
+	dropped := atomic.NewInt32(0)
+	q := newListMemoryQueue(&stackPolicy{capacity: 3}, func(item interface{}) {
+		dropped.Inc()
+	})
+	for _, item := range []string{"a", "b", "c", "d"} {
+		q.Produce(item)
+	}
+	assert.Equal(t, 3, q.Size())
+	assert.EqualValues(t, 1, dropped.Load())
+
+	consumed := make(chan string, 3)
+	q.StartConsumers(1, func(item interface{}) {
+		consumed <- item.(string)
+	})
+	assert.Equal(t, "c", <-consumed)
+	assert.Equal(t, "b", <-consumed)
+	assert.Equal(t, "a", <-consumed)
+
+	q.Stop()
+	assert.False(t, q.Produce("e"))
+	assert.EqualValues(t, 2, dropped.Load())
+}
@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+
+import (
+	"container/list"
+)
+
+// PartitionedQueue is a ProducerConsumerQueue split in partitions identified by a key, where consumers
+// take the items of the partitions in turn so that a partition receiving many items does not delay
+// the items of the other partitions.
+type PartitionedQueue interface {
+	ProducerConsumerQueue
+	// PartitionSize returns the current number of items in the given partition.
+	PartitionSize(key string) int
+}
+
+// partitionedMemoryQueue implements a producer-consumer exchange with a FIFO list per partition, each bounded
+// by the partition capacity, while the total number of items is bounded by the queue capacity. The keys of the
+// partitions holding items are kept in a round-robin list, a partition being removed once it is empty.
+type partitionedMemoryQueue struct {
+	*listMemoryQueue
+	partitions        map[string]*list.List
+	roundRobin        *list.List
+	size              int
+	capacity          int
+	partitionCapacity int
+	partitionOf       func(item interface{}) string
+}
+
+var _ PartitionedQueue = (*partitionedMemoryQueue)(nil)
+
+type partitionedItem struct {
+	item interface{}
+	key  string
+	// partitionFull is set if the item is rejected because its partition is full.
+	partitionFull bool
+}
+
+// NewPartitionedMemoryQueue constructs a new queue holding at most capacity items, and at most partitionCapacity
+// items per partition. partitionOf returns the partition key of an item, onDroppedItem is an optional callback
+// for dropped items (e.g. useful to emit metrics), and onPartitionFull is an optional callback for the items
+// dropped because their own partition is full, while the queue is not.
+func NewPartitionedMemoryQueue(capacity int, partitionCapacity int, partitionOf func(item interface{}) string,
+	onDroppedItem func(item interface{}), onPartitionFull func(item interface{}, key string)) PartitionedQueue {
+	q := &partitionedMemoryQueue{
+		partitions:        make(map[string]*list.List),
+		roundRobin:        list.New(),
+		capacity:          capacity,
+		partitionCapacity: partitionCapacity,
+		partitionOf:       partitionOf,
+	}
+	q.listMemoryQueue = newListMemoryQueue(q, func(item interface{}) {
+		pi := item.(*partitionedItem)
+		if onDroppedItem != nil {
+			onDroppedItem(pi.item)
+		}
+		if pi.partitionFull && onPartitionFull != nil {
+			onPartitionFull(pi.item, pi.key)
+		}
+	})
+	return q
+}
+
+// Produce is used by the producer to submit new item to the queue. Returns false in case of overflow
+// of the queue or of the item partition.
+func (q *partitionedMemoryQueue) Produce(item interface{}) bool {
+	// The partition key is computed before taking the lock of the queue, since it may be expensive.
+	return q.listMemoryQueue.Produce(&partitionedItem{item: item, key: q.partitionOf(item)})
+}
+
+// admit rejects the item in case of overflow of the queue or of the item partition.
+func (q *partitionedMemoryQueue) admit(item interface{}) bool {
+	pi := item.(*partitionedItem)
+	if q.size >= q.capacity {
+		return false
+	}
+	partition, ok := q.partitions[pi.key]
+	if (ok && partition.Len() >= q.partitionCapacity) || q.partitionCapacity <= 0 {
+		pi.partitionFull = true
+		return false
+	}
+	if !ok {
+		partition = list.New()
+		q.partitions[pi.key] = partition
+		q.roundRobin.PushBack(pi.key)
+	}
+	partition.PushBack(pi.item)
+	q.size++
+	return true
+}
+
+// next removes the oldest item of the next partition in turn, if any.
+func (q *partitionedMemoryQueue) next() (interface{}, bool) {
+	front := q.roundRobin.Front()
+	if front == nil {
+		return nil, false
+	}
+	key := front.Value.(string)
+	partition := q.partitions[key]
+	item := partition.Remove(partition.Front())
+	q.size--
+	if partition.Len() == 0 {
+		q.roundRobin.Remove(front)
+		delete(q.partitions, key)
+	} else {
+		// The partition takes its next turn after all the other partitions.
+		q.roundRobin.MoveToBack(front)
+	}
+	return item, true
+}
+
+func (q *partitionedMemoryQueue) len() int {
+	return q.size
+}
+
+// PartitionSize returns the current number of items in the given partition.
+func (q *partitionedMemoryQueue) PartitionSize(key string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if partition, ok := q.partitions[key]; ok {
+		return partition.Len()
+	}
+	return 0
+}
+
+// Capacity returns the capacity of the queue.
+func (q *partitionedMemoryQueue) Capacity() int {
+	return q.capacity
+}
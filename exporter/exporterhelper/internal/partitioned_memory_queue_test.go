@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+// tenantPartition returns the part of the item before the "/" as the partition key.
+func tenantPartition(item interface{}) string {
+	return strings.SplitN(item.(string), "/", 2)[0]
+}
+
+func TestPartitionedQueue_Overflow(t *testing.T) {
+	dropped := atomic.NewInt32(0)
+	var partitionFull []string
+	q := NewPartitionedMemoryQueue(3, 2, tenantPartition, func(item interface{}) {
+		dropped.Inc()
+	}, func(item interface{}, key string) {
+		partitionFull = append(partitionFull, key)
+	})
+	assert.Equal(t, 3, q.Capacity())
+
+	assert.True(t, q.Produce("a/1"))
+	assert.True(t, q.Produce("a/2"))
+	// The partition is full, but the other partitions still have room.
+	assert.False(t, q.Produce("a/3"))
+	assert.True(t, q.Produce("b/1"))
+	// The queue is full.
+	assert.False(t, q.Produce("c/1"))
+	assert.EqualValues(t, 2, dropped.Load())
+	// Only the item dropped because of its own partition capacity is reported as such.
+	assert.Equal(t, []string{"a"}, partitionFull)
+	assert.Equal(t, 3, q.Size())
+	assert.Equal(t, 2, q.PartitionSize("a"))
+	assert.Equal(t, 1, q.PartitionSize("b"))
+	assert.Equal(t, 0, q.PartitionSize("c"))
+
+	q.Stop()
+	assert.False(t, q.Produce("b/2"))
+	assert.EqualValues(t, 3, dropped.Load())
+}
+
+func TestPartitionedQueue_RoundRobin(t *testing.T) {
+	q := NewPartitionedMemoryQueue(10, 10, tenantPartition, nil, nil)
+	for _, item := range []string{"a/1", "a/2", "a/3", "b/1", "c/1", "b/2"} {
+		assert.True(t, q.Produce(item))
+	}
+
+	var mu sync.Mutex
+	var consumed []string
+	q.StartConsumers(1, func(item interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		consumed = append(consumed, item.(string))
+	})
+	assert.Eventually(t, func() bool {
+		return q.Size() == 0
+	}, time.Second, time.Millisecond)
+	q.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"a/1", "b/1", "c/1", "a/2", "b/2", "a/3"}, consumed)
+}
+
+func TestPartitionedQueue_MultipleConsumers(t *testing.T) {
+	q := NewPartitionedMemoryQueue(100, 50, tenantPartition, nil, nil)
+	consumed := atomic.NewInt32(0)
+	var wg sync.WaitGroup
+	wg.Add(100)
+	q.StartConsumers(5, func(item interface{}) {
+		consumed.Inc()
+		wg.Done()
+	})
+	for i := 0; i < 50; i++ {
+		assert.True(t, q.Produce("a/item"))
+		assert.True(t, q.Produce("b/item"))
+	}
+	wg.Wait()
+	q.Stop()
+	assert.EqualValues(t, 100, consumed.Load())
+	assert.Equal(t, 0, q.Size())
+}
@@ -12,9 +12,6 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-//go:build enable_unstable
-// +build enable_unstable
-
 package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
 
 import (
@@ -108,7 +105,7 @@ func (bof *batchStruct) getResult(key string, unmarshal func([]byte) (interface{
 // getRequestResult returns the result of a Get operation as a request
 func (bof *batchStruct) getRequestResult(key string) (PersistentRequest, error) {
 	reqIf, err := bof.getResult(key, bof.bytesToRequest)
-	if err != nil {
+	if err != nil || reqIf == nil {
 		return nil, err
 	}
 
@@ -0,0 +1,202 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+// PersistentQueueItem is an item stored in a persistent queue.
+type PersistentQueueItem struct {
+	// Key is the storage key of the item.
+	Key string
+	// Dispatched indicates whether the item was being processed by a consumer when the queue was stopped.
+	// Such items are put back in the queue when it is started again.
+	Dispatched bool
+	// Request is the unmarshaled item.
+	Request PersistentRequest
+}
+
+// PersistentStorageInspector provides offline access to the items of a persistent queue, to see what is
+// stuck in it and to drain it. It must not be used while a queue using the same storage client is running.
+type PersistentStorageInspector struct {
+	pcs *persistentContiguousStorage
+}
+
+// itemRef references an item of the queue.
+type itemRef struct {
+	index      itemIndex
+	dispatched bool
+}
+
+// NewPersistentStorageInspector creates an inspector for the persistent queue stored in the given client;
+// queueName is only used for logging.
+func NewPersistentStorageInspector(queueName string, logger *zap.Logger, client storage.Client, unmarshaler RequestUnmarshaler) *PersistentStorageInspector {
+	return &PersistentStorageInspector{
+		pcs: &persistentContiguousStorage{
+			logger:      logger,
+			queueName:   queueName,
+			client:      client,
+			unmarshaler: unmarshaler,
+		},
+	}
+}
+
+// Items returns the items of the queue in the order they are dispatched when the queue is started:
+// first the items left by consumers, then the items waiting in the queue.
+func (psi *PersistentStorageInspector) Items(ctx context.Context) ([]PersistentQueueItem, error) {
+	refs, err := psi.itemRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := newBatch(psi.pcs)
+	for _, ref := range refs {
+		batch.get(psi.pcs.itemKey(ref.index))
+	}
+	if _, err = batch.execute(ctx); err != nil {
+		return nil, err
+	}
+
+	items := make([]PersistentQueueItem, 0, len(refs))
+	for _, ref := range refs {
+		key := psi.pcs.itemKey(ref.index)
+		req, err := batch.getRequestResult(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed unmarshalling item %q: %w", key, err)
+		}
+		// Items may be missing if the queue was stopped while an item was being dispatched.
+		if req == nil {
+			continue
+		}
+		items = append(items, PersistentQueueItem{Key: key, Dispatched: ref.dispatched, Request: req})
+	}
+	return items, nil
+}
+
+// Purge removes all the items from the queue and returns the number of items removed.
+func (psi *PersistentStorageInspector) Purge(ctx context.Context) (int, error) {
+	refs, err := psi.itemRefs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	batch := newBatch(psi.pcs)
+	for _, ref := range refs {
+		batch.delete(psi.pcs.itemKey(ref.index))
+	}
+	if len(refs) > 0 {
+		// The read index catches up with the write index, which keeps increasing so keys are never reused.
+		batch.get(writeIndexKey)
+	}
+	if _, err = batch.execute(ctx); err != nil {
+		return 0, err
+	}
+
+	writeIndex, err := batch.getItemIndexResult(writeIndexKey)
+	if err != nil && err != errValueNotSet {
+		return 0, err
+	}
+	_, err = newBatch(psi.pcs).
+		setItemIndex(readIndexKey, writeIndex).
+		setItemIndexArray(currentlyDispatchedItemsKey, []itemIndex{}).
+		execute(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(refs), nil
+}
+
+// Replay passes the items of the queue to send, in the order they are dispatched when the queue is started,
+// removing each item after it was sent successfully. It stops at the first error, leaving the remaining items
+// in the queue, and returns the number of items sent.
+func (psi *PersistentStorageInspector) Replay(ctx context.Context, send func(PersistentRequest) error) (int, error) {
+	refs, dispatched, err := psi.readState(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, ref := range refs {
+		key := psi.pcs.itemKey(ref.index)
+		batch, err := newBatch(psi.pcs).get(key).execute(ctx)
+		if err != nil {
+			return sent, err
+		}
+		req, err := batch.getRequestResult(key)
+		if err != nil {
+			return sent, fmt.Errorf("failed unmarshalling item %q: %w", key, err)
+		}
+		if req != nil {
+			if err = send(req); err != nil {
+				return sent, fmt.Errorf("failed sending item %q: %w", key, err)
+			}
+			sent++
+		}
+
+		removeBatch := newBatch(psi.pcs).delete(key)
+		if ref.dispatched {
+			dispatched = dispatched[1:]
+			removeBatch.setItemIndexArray(currentlyDispatchedItemsKey, dispatched)
+		} else {
+			removeBatch.setItemIndex(readIndexKey, ref.index+1)
+		}
+		if _, err = removeBatch.execute(ctx); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// itemRefs returns the references to all the items of the queue, in dispatch order.
+func (psi *PersistentStorageInspector) itemRefs(ctx context.Context) ([]itemRef, error) {
+	refs, _, err := psi.readState(ctx)
+	return refs, err
+}
+
+// readState returns the references to all the items of the queue, in dispatch order,
+// and the list of items currently dispatched.
+func (psi *PersistentStorageInspector) readState(ctx context.Context) ([]itemRef, []itemIndex, error) {
+	batch, err := newBatch(psi.pcs).get(readIndexKey, writeIndexKey, currentlyDispatchedItemsKey).execute(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	readIndex, err := batch.getItemIndexResult(readIndexKey)
+	if err != nil && err != errValueNotSet {
+		return nil, nil, err
+	}
+	writeIndex, err := batch.getItemIndexResult(writeIndexKey)
+	if err != nil && err != errValueNotSet {
+		return nil, nil, err
+	}
+	dispatched, err := batch.getItemIndexArrayResult(currentlyDispatchedItemsKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refs := make([]itemRef, 0, len(dispatched)+int(writeIndex-readIndex))
+	for _, index := range dispatched {
+		refs = append(refs, itemRef{index: index, dispatched: true})
+	}
+	for index := readIndex; index < writeIndex; index++ {
+		refs = append(refs, itemRef{index: index})
+	}
+	return refs, dispatched, nil
+}
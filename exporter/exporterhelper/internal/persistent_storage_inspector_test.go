@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+// createTestInspectedStorage fills a persistent storage with three items of 1, 2 and 3 spans,
+// leaving the first two dispatched to consumers.
+func createTestInspectedStorage(t *testing.T) (storage.Client, *PersistentStorageInspector) {
+	client := createTestClient(newMockStorageExtension())
+	ps := createTestPersistentStorage(client)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, ps.put(newFakeTracesRequest(newTraces(1, i))))
+	}
+	getItemFromChannel(t, ps)
+	requireCurrentlyDispatchedItemsEqual(t, ps, []itemIndex{0, 1})
+	ps.stop()
+
+	return client, NewPersistentStorageInspector("foo", zap.NewNop(), client, newFakeTracesRequestUnmarshalerFunc())
+}
+
+func spanCounts(items []PersistentQueueItem) []int {
+	counts := make([]int, 0, len(items))
+	for _, item := range items {
+		counts = append(counts, item.Request.(*fakeTracesRequest).td.SpanCount())
+	}
+	return counts
+}
+
+func TestPersistentStorageInspector_Items(t *testing.T) {
+	_, psi := createTestInspectedStorage(t)
+
+	items, err := psi.Items(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, []int{1, 2, 3}, spanCounts(items))
+	assert.Equal(t, "0", items[0].Key)
+	assert.True(t, items[0].Dispatched)
+	assert.True(t, items[1].Dispatched)
+	assert.False(t, items[2].Dispatched)
+
+	empty := NewPersistentStorageInspector("foo", zap.NewNop(), createTestClient(newMockStorageExtension()), newFakeTracesRequestUnmarshalerFunc())
+	items, err = empty.Items(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestPersistentStorageInspector_Replay(t *testing.T) {
+	client, psi := createTestInspectedStorage(t)
+
+	var spans []int
+	sendErr := errors.New("send failed")
+	sent, err := psi.Replay(context.Background(), func(req PersistentRequest) error {
+		if len(spans) == 1 {
+			return sendErr
+		}
+		spans = append(spans, req.(*fakeTracesRequest).td.SpanCount())
+		return nil
+	})
+	assert.ErrorIs(t, err, sendErr)
+	assert.Equal(t, 1, sent)
+
+	// The items not sent are left in the queue.
+	items, err := psi.Items(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, spanCounts(items))
+
+	sent, err = psi.Replay(context.Background(), func(req PersistentRequest) error {
+		spans = append(spans, req.(*fakeTracesRequest).td.SpanCount())
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, sent)
+	assert.Equal(t, []int{1, 2, 3}, spans)
+
+	// A queue started on the drained storage is empty.
+	ps := createTestPersistentStorage(client)
+	assert.Equal(t, uint64(0), ps.size())
+	requireCurrentlyDispatchedItemsEqual(t, ps, nil)
+}
+
+func TestPersistentStorageInspector_Purge(t *testing.T) {
+	client, psi := createTestInspectedStorage(t)
+
+	purged, err := psi.Purge(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, purged)
+
+	items, err := psi.Items(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, items)
+	for i := 0; i < 3; i++ {
+		val, err := client.Get(context.Background(), psi.pcs.itemKey(itemIndex(i)))
+		require.NoError(t, err)
+		assert.Nil(t, val)
+	}
+
+	// New items do not reuse the keys of the purged ones.
+	ps := createTestPersistentStorage(client)
+	assert.Equal(t, uint64(0), ps.size())
+	assert.Equal(t, itemIndex(3), ps.writeIndex)
+}
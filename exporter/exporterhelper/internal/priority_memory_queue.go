@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal // import "go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+
+import (
+	"container/list"
+)
+
+// PriorityQueue is a ProducerConsumerQueue split in priority lanes, where consumers always take
+// the oldest item of the highest priority lane that is not empty.
+type PriorityQueue interface {
+	ProducerConsumerQueue
+	// LaneSize returns the current number of items in the given lane.
+	LaneSize(lane int) int
+}
+
+// priorityMemoryQueue implements a producer-consumer exchange with a FIFO list per lane, each bounded
+// by its own capacity. Lane 0 has the highest priority.
+type priorityMemoryQueue struct {
+	*listMemoryQueue
+	lanes      []*list.List
+	capacities []int
+	laneOf     func(item interface{}) int
+}
+
+var _ PriorityQueue = (*priorityMemoryQueue)(nil)
+
+// NewPriorityMemoryQueue constructs a new queue with a lane per given capacity, the first lane having the highest
+// priority. laneOf returns the lane of an item, and onDroppedItem is an optional callback for dropped items
+// (e.g. useful to emit metrics).
+func NewPriorityMemoryQueue(capacities []int, laneOf func(item interface{}) int, onDroppedItem func(item interface{})) PriorityQueue {
+	lanes := make([]*list.List, len(capacities))
+	for i := range lanes {
+		lanes[i] = list.New()
+	}
+	q := &priorityMemoryQueue{
+		lanes:      lanes,
+		capacities: capacities,
+		laneOf:     laneOf,
+	}
+	q.listMemoryQueue = newListMemoryQueue(q, onDroppedItem)
+	return q
+}
+
+// admit rejects the item in case of overflow of its lane.
+func (q *priorityMemoryQueue) admit(item interface{}) bool {
+	lane := q.clampLane(q.laneOf(item))
+	if q.lanes[lane].Len() >= q.capacities[lane] {
+		// note that all items will be dropped if the capacity is 0
+		return false
+	}
+	q.lanes[lane].PushBack(item)
+	return true
+}
+
+// next removes the oldest item of the highest priority lane that is not empty, if any.
+func (q *priorityMemoryQueue) next() (interface{}, bool) {
+	for _, lane := range q.lanes {
+		if front := lane.Front(); front != nil {
+			return lane.Remove(front), true
+		}
+	}
+	return nil, false
+}
+
+func (q *priorityMemoryQueue) len() int {
+	size := 0
+	for _, l := range q.lanes {
+		size += l.Len()
+	}
+	return size
+}
+
+// clampLane maps out of range lanes to the lowest priority lane.
+func (q *priorityMemoryQueue) clampLane(lane int) int {
+	if lane < 0 || lane >= len(q.lanes) {
+		return len(q.lanes) - 1
+	}
+	return lane
+}
+
+// LaneSize returns the current number of items in the given lane.
+func (q *priorityMemoryQueue) LaneSize(lane int) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.lanes[q.clampLane(lane)].Len()
+}
+
+// Capacity returns the total capacity of the lanes.
+func (q *priorityMemoryQueue) Capacity() int {
+	capacity := 0
+	for _, c := range q.capacities {
+		capacity += c
+	}
+	return capacity
+}
@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+// prefixLane assigns the items prefixed with "high" to lane 0, the ones prefixed with "low" to lane 1,
+// and the other ones to an unknown lane.
+func prefixLane(item interface{}) int {
+	switch {
+	case strings.HasPrefix(item.(string), "high"):
+		return 0
+	case strings.HasPrefix(item.(string), "low"):
+		return 1
+	}
+	return -1
+}
+
+func TestPriorityQueue_Overflow(t *testing.T) {
+	dropped := atomic.NewInt32(0)
+	q := NewPriorityMemoryQueue([]int{1, 2}, prefixLane, func(item interface{}) {
+		dropped.Inc()
+	})
+	assert.Equal(t, 3, q.Capacity())
+
+	assert.True(t, q.Produce("high1"))
+	assert.False(t, q.Produce("high2"))
+	assert.True(t, q.Produce("low1"))
+	// Items of unknown lanes go to the lowest priority lane.
+	assert.True(t, q.Produce("other1"))
+	assert.False(t, q.Produce("low2"))
+	assert.EqualValues(t, 2, dropped.Load())
+	assert.Equal(t, 3, q.Size())
+	assert.Equal(t, 1, q.LaneSize(0))
+	assert.Equal(t, 2, q.LaneSize(1))
+
+	q.Stop()
+	assert.False(t, q.Produce("high3"))
+	assert.EqualValues(t, 3, dropped.Load())
+}
+
+func TestPriorityQueue_HigherLanesFirst(t *testing.T) {
+	q := NewPriorityMemoryQueue([]int{10, 10}, prefixLane, nil)
+	for _, item := range []string{"low1", "high1", "low2", "high2"} {
+		assert.True(t, q.Produce(item))
+	}
+
+	var mu sync.Mutex
+	var consumed []string
+	q.StartConsumers(1, func(item interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		consumed = append(consumed, item.(string))
+	})
+	assert.Eventually(t, func() bool {
+		return q.Size() == 0
+	}, time.Second, time.Millisecond)
+	q.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"high1", "high2", "low1", "low2"}, consumed)
+}
+
+func TestPriorityQueue_MultipleConsumers(t *testing.T) {
+	q := NewPriorityMemoryQueue([]int{100, 100}, prefixLane, nil)
+	consumed := atomic.NewInt32(0)
+	var wg sync.WaitGroup
+	wg.Add(100)
+	q.StartConsumers(5, func(item interface{}) {
+		consumed.Inc()
+		wg.Done()
+	})
+	for i := 0; i < 50; i++ {
+		assert.True(t, q.Produce("high"))
+		assert.True(t, q.Produce("low"))
+	}
+	wg.Wait()
+	q.Stop()
+	assert.EqualValues(t, 100, consumed.Load())
+	assert.Equal(t, 0, q.Size())
+}
@@ -24,12 +24,14 @@ import (
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/consumerhelper"
 	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/internal/batchutil"
 	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
 var logsMarshaler = otlp.NewProtobufLogsMarshaler()
 var logsUnmarshaler = otlp.NewProtobufLogsUnmarshaler()
+var logsSizer = logsMarshaler.(pdata.LogsSizer)
 
 type logsRequest struct {
 	baseRequest
@@ -60,6 +62,10 @@ func (req *logsRequest) onError(err error) request {
 	if errors.As(err, &logError) {
 		return newLogsRequest(req.ctx, logError.GetLogs(), req.pusher)
 	}
+	var partialErr consumererror.PartialLogs
+	if errors.As(err, &partialErr) {
+		return newLogsRequest(req.ctx, partialErr.GetRetryable(), req.pusher)
+	}
 	return req
 }
 
@@ -75,6 +81,18 @@ func (req *logsRequest) count() int {
 	return req.ld.LogRecordCount()
 }
 
+func (req *logsRequest) bytesSize() int {
+	return logsSizer.LogsSize(req.ld)
+}
+
+func (req *logsRequest) merge(other request) {
+	other.(*logsRequest).ld.ResourceLogs().MoveAndAppendTo(req.ld.ResourceLogs())
+}
+
+func (req *logsRequest) split(maxItems, maxBytes int) request {
+	return newLogsRequest(req.ctx, batchutil.SplitLogsBySize(maxItems, maxBytes, logsSizer, req.ld), req.pusher)
+}
+
 type logsExporter struct {
 	*baseExporter
 	consumer.Logs
@@ -100,6 +118,10 @@ func NewLogsExporter(
 	}
 
 	bs := fromOptions(options...)
+	transform, err := newTransformer(bs.TransformSettings)
+	if err != nil {
+		return nil, err
+	}
 	be := newBaseExporter(cfg, set, bs, config.LogsDataType, newLogsRequestUnmarshalerFunc(pusher))
 	be.wrapConsumerSender(func(nextSender requestSender) requestSender {
 		return &logsExporterWithObservability{
@@ -109,6 +131,7 @@ func NewLogsExporter(
 	})
 
 	lc, err := consumerhelper.NewLogs(func(ctx context.Context, ld pdata.Logs) error {
+		transform.logs(ld)
 		req := newLogsRequest(ctx, ld, pusher)
 		err := be.sender.send(req)
 		if errors.Is(err, errSendingQueueIsFull) {
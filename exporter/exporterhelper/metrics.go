@@ -24,12 +24,14 @@ import (
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/consumerhelper"
 	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/internal/batchutil"
 	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
 var metricsMarshaler = otlp.NewProtobufMetricsMarshaler()
 var metricsUnmarshaler = otlp.NewProtobufMetricsUnmarshaler()
+var metricsSizer = metricsMarshaler.(pdata.MetricsSizer)
 
 type metricsRequest struct {
 	baseRequest
@@ -60,6 +62,10 @@ func (req *metricsRequest) onError(err error) request {
 	if errors.As(err, &metricsError) {
 		return newMetricsRequest(req.ctx, metricsError.GetMetrics(), req.pusher)
 	}
+	var partialErr consumererror.PartialMetrics
+	if errors.As(err, &partialErr) {
+		return newMetricsRequest(req.ctx, partialErr.GetRetryable(), req.pusher)
+	}
 	return req
 }
 
@@ -76,6 +82,18 @@ func (req *metricsRequest) count() int {
 	return req.md.DataPointCount()
 }
 
+func (req *metricsRequest) bytesSize() int {
+	return metricsSizer.MetricsSize(req.md)
+}
+
+func (req *metricsRequest) merge(other request) {
+	other.(*metricsRequest).md.ResourceMetrics().MoveAndAppendTo(req.md.ResourceMetrics())
+}
+
+func (req *metricsRequest) split(maxItems, maxBytes int) request {
+	return newMetricsRequest(req.ctx, batchutil.SplitMetricsBySize(maxItems, maxBytes, metricsSizer, req.md), req.pusher)
+}
+
 type metricsExporter struct {
 	*baseExporter
 	consumer.Metrics
@@ -101,6 +119,10 @@ func NewMetricsExporter(
 	}
 
 	bs := fromOptions(options...)
+	transform, err := newTransformer(bs.TransformSettings)
+	if err != nil {
+		return nil, err
+	}
 	be := newBaseExporter(cfg, set, bs, config.MetricsDataType, newMetricsRequestUnmarshalerFunc(pusher))
 	be.wrapConsumerSender(func(nextSender requestSender) requestSender {
 		return &metricsSenderWithObservability{
@@ -110,6 +132,7 @@ func NewMetricsExporter(
 	})
 
 	mc, err := consumerhelper.NewMetrics(func(ctx context.Context, md pdata.Metrics) error {
+		transform.metrics(md)
 		req := newMetricsRequest(ctx, md, pusher)
 		err := be.sender.send(req)
 		if errors.Is(err, errSendingQueueIsFull) {
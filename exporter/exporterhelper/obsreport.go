@@ -16,11 +16,14 @@ package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporte
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"go.opencensus.io/metric"
 	"go.opencensus.io/metric/metricdata"
 	"go.opencensus.io/metric/metricproducer"
 
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
 	"go.opentelemetry.io/collector/obsreport"
 )
@@ -29,6 +32,18 @@ import (
 //       into existing `obsreport` package once its functionally is not exposed
 //       as public API. For now this part is kept private.
 
+const (
+	// laneKey is the label key of the priority lane of the sending queue.
+	laneKey = "lane"
+	// partitionKey is the label key of the partition of the sending queue.
+	partitionKey = "partition"
+	// maxPartitionLabelValues is the maximum number of distinct partition label values reported by an exporter.
+	// The partitions come from the client requests, so their number is not bounded.
+	maxPartitionLabelValues = 100
+	// overflowPartitionLabelValue is the partition label value of the partitions beyond maxPartitionLabelValues.
+	overflowPartitionLabelValue = "_overflow"
+)
+
 var (
 	globalInstruments = newInstruments(metric.NewRegistry())
 )
@@ -40,9 +55,19 @@ func init() {
 type instruments struct {
 	registry                    *metric.Registry
 	queueSize                   *metric.Int64DerivedGauge
+	queueSizeBytes              *metric.Int64DerivedGauge
+	queueLaneSize               *metric.Int64DerivedGauge
 	failedToEnqueueTraceSpans   *metric.Int64Cumulative
 	failedToEnqueueMetricPoints *metric.Int64Cumulative
 	failedToEnqueueLogRecords   *metric.Int64Cumulative
+	failedToEnqueuePartition    *metric.Int64Cumulative
+	throttleWaits               *metric.Int64Cumulative
+	throttleWaitTime            *metric.Int64Cumulative
+	circuitBreakerState         *metric.Int64DerivedGauge
+	concurrencyLimit            *metric.Int64DerivedGauge
+	deadLetterTraceSpans        *metric.Int64Cumulative
+	deadLetterMetricPoints      *metric.Int64Cumulative
+	deadLetterLogRecords        *metric.Int64Cumulative
 }
 
 func newInstruments(registry *metric.Registry) *instruments {
@@ -55,6 +80,18 @@ func newInstruments(registry *metric.Registry) *instruments {
 		metric.WithLabelKeys(obsmetrics.ExporterKey),
 		metric.WithUnit(metricdata.UnitDimensionless))
 
+	insts.queueSizeBytes, _ = registry.AddInt64DerivedGauge(
+		obsmetrics.ExporterKey+"/queue_size_bytes",
+		metric.WithDescription("Current size of the retry queue (in bytes)"),
+		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithUnit(metricdata.UnitBytes))
+
+	insts.queueLaneSize, _ = registry.AddInt64DerivedGauge(
+		obsmetrics.ExporterKey+"/queue_lane_size",
+		metric.WithDescription("Current size of a priority lane of the retry queue (in batches)"),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, laneKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
 	insts.failedToEnqueueTraceSpans, _ = registry.AddInt64Cumulative(
 		obsmetrics.ExporterKey+"/enqueue_failed_spans",
 		metric.WithDescription("Number of spans failed to be added to the sending queue."),
@@ -73,15 +110,72 @@ func newInstruments(registry *metric.Registry) *instruments {
 		metric.WithLabelKeys(obsmetrics.ExporterKey),
 		metric.WithUnit(metricdata.UnitDimensionless))
 
+	insts.failedToEnqueuePartition, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/enqueue_failed_partition_items",
+		metric.WithDescription("Number of spans, metric points or log records of a partition failed to be added to the sending queue."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey, partitionKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.throttleWaits, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/throttle_waits",
+		metric.WithDescription("Number of times the exporter waited before retrying because the backend throttled it."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.throttleWaitTime, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/throttle_wait_time",
+		metric.WithDescription("Total time the exporter waited before retrying because the backend throttled it."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithUnit(metricdata.UnitMilliseconds))
+
+	insts.circuitBreakerState, _ = registry.AddInt64DerivedGauge(
+		obsmetrics.ExporterKey+"/circuit_breaker_state",
+		metric.WithDescription("Current state of the circuit breaker (0 closed, 1 open, 2 half-open)"),
+		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.concurrencyLimit, _ = registry.AddInt64DerivedGauge(
+		obsmetrics.ExporterKey+"/concurrency_limit",
+		metric.WithDescription("Current number of queue consumers allowed to send concurrently when adaptive concurrency is enabled"),
+		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.deadLetterTraceSpans, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/dead_letter_spans",
+		metric.WithDescription("Number of dropped spans routed to the dead-letter destination."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.deadLetterMetricPoints, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/dead_letter_metric_points",
+		metric.WithDescription("Number of dropped metric points routed to the dead-letter destination."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
+	insts.deadLetterLogRecords, _ = registry.AddInt64Cumulative(
+		obsmetrics.ExporterKey+"/dead_letter_log_records",
+		metric.WithDescription("Number of dropped log records routed to the dead-letter destination."),
+		metric.WithLabelKeys(obsmetrics.ExporterKey),
+		metric.WithUnit(metricdata.UnitDimensionless))
+
 	return insts
 }
 
 // obsExporter is a helper to add observability to a component.Exporter.
 type obsExporter struct {
 	*obsreport.Exporter
+	exporterLabel                    metricdata.LabelValue
+	failedToEnqueuePartition         *metric.Int64Cumulative
+	partitionLabelsMu                sync.Mutex
+	partitionLabels                  map[string]struct{}
 	failedToEnqueueTraceSpansEntry   *metric.Int64CumulativeEntry
 	failedToEnqueueMetricPointsEntry *metric.Int64CumulativeEntry
 	failedToEnqueueLogRecordsEntry   *metric.Int64CumulativeEntry
+	throttleWaitsEntry               *metric.Int64CumulativeEntry
+	throttleWaitTimeEntry            *metric.Int64CumulativeEntry
+	deadLetterTraceSpansEntry        *metric.Int64CumulativeEntry
+	deadLetterMetricPointsEntry      *metric.Int64CumulativeEntry
+	deadLetterLogRecordsEntry        *metric.Int64CumulativeEntry
 }
 
 // newObsExporter creates a new observability exporter.
@@ -90,12 +184,25 @@ func newObsExporter(cfg obsreport.ExporterSettings, insts *instruments) *obsExpo
 	failedToEnqueueTraceSpansEntry, _ := insts.failedToEnqueueTraceSpans.GetEntry(labelValue)
 	failedToEnqueueMetricPointsEntry, _ := insts.failedToEnqueueMetricPoints.GetEntry(labelValue)
 	failedToEnqueueLogRecordsEntry, _ := insts.failedToEnqueueLogRecords.GetEntry(labelValue)
+	throttleWaitsEntry, _ := insts.throttleWaits.GetEntry(labelValue)
+	throttleWaitTimeEntry, _ := insts.throttleWaitTime.GetEntry(labelValue)
+	deadLetterTraceSpansEntry, _ := insts.deadLetterTraceSpans.GetEntry(labelValue)
+	deadLetterMetricPointsEntry, _ := insts.deadLetterMetricPoints.GetEntry(labelValue)
+	deadLetterLogRecordsEntry, _ := insts.deadLetterLogRecords.GetEntry(labelValue)
 
 	return &obsExporter{
 		Exporter:                         obsreport.NewExporter(cfg),
+		exporterLabel:                    labelValue,
+		failedToEnqueuePartition:         insts.failedToEnqueuePartition,
+		partitionLabels:                  make(map[string]struct{}),
 		failedToEnqueueTraceSpansEntry:   failedToEnqueueTraceSpansEntry,
 		failedToEnqueueMetricPointsEntry: failedToEnqueueMetricPointsEntry,
 		failedToEnqueueLogRecordsEntry:   failedToEnqueueLogRecordsEntry,
+		throttleWaitsEntry:               throttleWaitsEntry,
+		throttleWaitTimeEntry:            throttleWaitTimeEntry,
+		deadLetterTraceSpansEntry:        deadLetterTraceSpansEntry,
+		deadLetterMetricPointsEntry:      deadLetterMetricPointsEntry,
+		deadLetterLogRecordsEntry:        deadLetterLogRecordsEntry,
 	}
 }
 
@@ -113,3 +220,56 @@ func (eor *obsExporter) recordMetricsEnqueueFailure(_ context.Context, numMetric
 func (eor *obsExporter) recordLogsEnqueueFailure(_ context.Context, numLogRecords int64) {
 	eor.failedToEnqueueLogRecordsEntry.Inc(numLogRecords)
 }
+
+// recordPartitionEnqueueFailure records number of items of the given partition that failed to be added to the sending queue.
+func (eor *obsExporter) recordPartitionEnqueueFailure(_ context.Context, partition string, numItems int64) {
+	entry, err := eor.failedToEnqueuePartition.GetEntry(eor.exporterLabel, eor.partitionLabel(partition))
+	if err != nil {
+		return
+	}
+	entry.Inc(numItems)
+}
+
+// partitionLabel returns the label value of the given partition, or the overflow label value once
+// maxPartitionLabelValues distinct partitions have been reported.
+func (eor *obsExporter) partitionLabel(partition string) metricdata.LabelValue {
+	eor.partitionLabelsMu.Lock()
+	defer eor.partitionLabelsMu.Unlock()
+	if _, ok := eor.partitionLabels[partition]; !ok {
+		if len(eor.partitionLabels) >= maxPartitionLabelValues {
+			return metricdata.NewLabelValue(overflowPartitionLabelValue)
+		}
+		eor.partitionLabels[partition] = struct{}{}
+	}
+	return metricdata.NewLabelValue(partition)
+}
+
+// recordThrottle records that the exporter waits for the given delay before retrying because the backend throttled it.
+func (eor *obsExporter) recordThrottle(_ context.Context, delay time.Duration) {
+	eor.throttleWaitsEntry.Inc(1)
+	eor.throttleWaitTimeEntry.Inc(delay.Milliseconds())
+}
+
+// recordEnqueueFailure records number of items of the given signal that failed to be added to the sending queue.
+func (eor *obsExporter) recordEnqueueFailure(ctx context.Context, signal config.DataType, numItems int64) {
+	switch signal {
+	case config.TracesDataType:
+		eor.recordTracesEnqueueFailure(ctx, numItems)
+	case config.MetricsDataType:
+		eor.recordMetricsEnqueueFailure(ctx, numItems)
+	case config.LogsDataType:
+		eor.recordLogsEnqueueFailure(ctx, numItems)
+	}
+}
+
+// recordDeadLetter records number of dropped items of the given signal routed to the dead-letter destination.
+func (eor *obsExporter) recordDeadLetter(_ context.Context, signal config.DataType, numItems int64) {
+	switch signal {
+	case config.TracesDataType:
+		eor.deadLetterTraceSpansEntry.Inc(numItems)
+	case config.MetricsDataType:
+		eor.deadLetterMetricPointsEntry.Inc(numItems)
+	case config.LogsDataType:
+		eor.deadLetterLogRecordsEntry.Inc(numItems)
+	}
+}
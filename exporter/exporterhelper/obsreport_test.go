@@ -16,6 +16,7 @@ package exporterhelper
 
 import (
 	"context"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -55,6 +56,33 @@ func TestExportEnqueueFailure(t *testing.T) {
 	checkExporterEnqueueFailedMetricsStats(t, insts, exporter, metricPoints)
 }
 
+func TestExportPartitionEnqueueFailure(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	defer tt.Shutdown(context.Background())
+
+	exporter := config.NewComponentID("fakeExporter")
+
+	insts := newInstruments(metric.NewRegistry())
+	obsrep := newObsExporter(obsreport.ExporterSettings{
+		Level:                  configtelemetry.LevelNormal,
+		ExporterID:             exporter,
+		ExporterCreateSettings: tt.ToExporterCreateSettings(),
+	}, insts)
+
+	for i := 0; i < maxPartitionLabelValues; i++ {
+		obsrep.recordPartitionEnqueueFailure(context.Background(), strconv.Itoa(i), 1)
+	}
+	// The partitions beyond the maximum number of label values are reported together.
+	obsrep.recordPartitionEnqueueFailure(context.Background(), "late", 3)
+	obsrep.recordPartitionEnqueueFailure(context.Background(), "later", 4)
+	obsrep.recordPartitionEnqueueFailure(context.Background(), "0", 2)
+
+	partitionTag, _ := tag.NewKey(partitionKey)
+	checkValueForProducer(t, insts.registry, append(tagsForExporterView(exporter), tag.Tag{Key: partitionTag, Value: "0"}), 3, "exporter/enqueue_failed_partition_items")
+	checkValueForProducer(t, insts.registry, append(tagsForExporterView(exporter), tag.Tag{Key: partitionTag, Value: overflowPartitionLabelValue}), 7, "exporter/enqueue_failed_partition_items")
+}
+
 // checkExporterEnqueueFailedTracesStats checks that reported number of spans failed to enqueue match given values.
 // When this function is called it is required to also call SetupTelemetry as first thing.
 func checkExporterEnqueueFailedTracesStats(t *testing.T, insts *instruments, exporter config.ComponentID, spans int64) {
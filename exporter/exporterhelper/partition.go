@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+	"strings"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+var (
+	errPartitionBothKeys      = errors.New("only one of sending_queue.partition.metadata_key and sending_queue.partition.resource_attribute can be set")
+	errPartitionQueueSize     = errors.New("sending_queue.partition.queue_size must be positive")
+	errPartitionNotSupported  = errors.New("sending_queue.partition is not supported with sending_queue.storage, sending_queue.queue_size_bytes or sending_queue.priority_lanes")
+	errPartitionQueueTooLarge = errors.New("sending_queue.partition.queue_size must not be greater than sending_queue.queue_size")
+)
+
+// PartitionSettings defines how the sending queue is partitioned, e.g. per tenant, so that the requests of a partition
+// filling its part of the queue do not delay or cause dropping the requests of the other partitions.
+type PartitionSettings struct {
+	// MetadataKey is the client metadata key, e.g. a gRPC metadata key or an HTTP header, whose value identifies
+	// the partition of a request. It is looked up case-insensitively.
+	MetadataKey string `mapstructure:"metadata_key"`
+	// ResourceAttribute is the resource attribute whose value identifies the partition of a request. The value of
+	// the first resource of the request having the attribute is used.
+	ResourceAttribute string `mapstructure:"resource_attribute"`
+	// QueueSize is the maximum number of batches of a partition allowed in queue at a given time.
+	QueueSize int `mapstructure:"queue_size"`
+}
+
+func (cfg *PartitionSettings) enabled() bool {
+	return cfg.MetadataKey != "" || cfg.ResourceAttribute != ""
+}
+
+// validatePartition checks if the partitioning of the queue configuration is valid.
+func validatePartition(cfg QueueSettings) error {
+	if !cfg.Partition.enabled() {
+		return nil
+	}
+	if cfg.Partition.MetadataKey != "" && cfg.Partition.ResourceAttribute != "" {
+		return errPartitionBothKeys
+	}
+	if cfg.StorageID != nil || cfg.QueueSizeBytes > 0 || len(cfg.PriorityLanes) > 0 {
+		return errPartitionNotSupported
+	}
+	if cfg.Partition.QueueSize <= 0 {
+		return errPartitionQueueSize
+	}
+	if cfg.Partition.QueueSize > cfg.QueueSize {
+		return errPartitionQueueTooLarge
+	}
+	return nil
+}
+
+// partitionOf returns the partition of the request, or an empty string if the request does not have a partition key.
+func (cfg *PartitionSettings) partitionOf(req request) string {
+	if cfg.MetadataKey != "" {
+		c, ok := client.FromContext(req.context())
+		if !ok {
+			return ""
+		}
+		values := c.Metadata[strings.ToLower(cfg.MetadataKey)]
+		if len(values) == 0 {
+			return ""
+		}
+		return strings.Join(values, ",")
+	}
+
+	switch r := req.(type) {
+	case *tracesRequest:
+		rss := r.td.ResourceSpans()
+		for i := 0; i < rss.Len(); i++ {
+			if key, ok := cfg.resourceKey(rss.At(i).Resource()); ok {
+				return key
+			}
+		}
+	case *metricsRequest:
+		rms := r.md.ResourceMetrics()
+		for i := 0; i < rms.Len(); i++ {
+			if key, ok := cfg.resourceKey(rms.At(i).Resource()); ok {
+				return key
+			}
+		}
+	case *logsRequest:
+		rls := r.ld.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			if key, ok := cfg.resourceKey(rls.At(i).Resource()); ok {
+				return key
+			}
+		}
+	}
+	return ""
+}
+
+func (cfg *PartitionSettings) resourceKey(resource pdata.Resource) (string, bool) {
+	v, ok := resource.Attributes().Get(cfg.ResourceAttribute)
+	if !ok {
+		return "", false
+	}
+	return v.AsString(), true
+}
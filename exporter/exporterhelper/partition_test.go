@@ -0,0 +1,118 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc/metadata"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func newTenantTraces(tenant string) pdata.Traces {
+	td := testdata.GenerateTracesOneSpan()
+	td.ResourceSpans().At(0).Resource().Attributes().UpsertString("tenant", tenant)
+	return td
+}
+
+func TestPartitionOf(t *testing.T) {
+	cfg := PartitionSettings{ResourceAttribute: "tenant"}
+	ctx := context.Background()
+	assert.Equal(t, "acme", cfg.partitionOf(newTracesRequest(ctx, newTenantTraces("acme"), nil)))
+	assert.Equal(t, "", cfg.partitionOf(newTracesRequest(ctx, testdata.GenerateTracesOneSpan(), nil)))
+	ld := testdata.GenerateLogsOneLogRecord()
+	ld.ResourceLogs().At(0).Resource().Attributes().UpsertString("tenant", "acme")
+	assert.Equal(t, "acme", cfg.partitionOf(newLogsRequest(ctx, ld, nil)))
+	md := testdata.GenerateMetricsOneMetric()
+	md.ResourceMetrics().At(0).Resource().Attributes().UpsertString("tenant", "acme")
+	assert.Equal(t, "acme", cfg.partitionOf(newMetricsRequest(ctx, md, nil)))
+
+	cfg = PartitionSettings{MetadataKey: "X-Tenant"}
+	assert.Equal(t, "", cfg.partitionOf(newTracesRequest(ctx, newTenantTraces("acme"), nil)))
+	ctx = client.NewContext(ctx, &client.Client{Metadata: map[string][]string{"x-tenant": {"acme"}}})
+	assert.Equal(t, "acme", cfg.partitionOf(newTracesRequest(ctx, testdata.GenerateTracesOneSpan(), nil)))
+	// The gRPC metadata is only used through the client of the receivers.
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-tenant", "acme"))
+	assert.Equal(t, "", cfg.partitionOf(newTracesRequest(ctx, testdata.GenerateTracesOneSpan(), nil)))
+}
+
+func TestValidatePartition(t *testing.T) {
+	qCfg := DefaultQueueSettings()
+	assert.NoError(t, validatePartition(qCfg))
+	qCfg.Partition = PartitionSettings{ResourceAttribute: "tenant", QueueSize: 100}
+	assert.NoError(t, validatePartition(qCfg))
+
+	tests := []struct {
+		name   string
+		modify func(*QueueSettings)
+		err    error
+	}{
+		{name: "both keys", modify: func(cfg *QueueSettings) { cfg.Partition.MetadataKey = "tenant" }, err: errPartitionBothKeys},
+		{name: "storage", modify: func(cfg *QueueSettings) {
+			storageID := config.NewComponentID("file_storage")
+			cfg.StorageID = &storageID
+		}, err: errPartitionNotSupported},
+		{name: "queue_size_bytes", modify: func(cfg *QueueSettings) { cfg.QueueSizeBytes = 100 }, err: errPartitionNotSupported},
+		{name: "priority_lanes", modify: func(cfg *QueueSettings) { cfg.PriorityLanes = newPriorityLanes() }, err: errPartitionNotSupported},
+		{name: "queue_size", modify: func(cfg *QueueSettings) { cfg.Partition.QueueSize = 0 }, err: errPartitionQueueSize},
+		{name: "queue_size too large", modify: func(cfg *QueueSettings) { cfg.Partition.QueueSize = cfg.QueueSize + 1 }, err: errPartitionQueueTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultQueueSettings()
+			cfg.Partition = PartitionSettings{ResourceAttribute: "tenant", QueueSize: 100}
+			tt.modify(&cfg)
+			assert.ErrorIs(t, validatePartition(cfg), tt.err)
+		})
+	}
+}
+
+func TestQueuedRetry_Partition(t *testing.T) {
+	qCfg := DefaultQueueSettings()
+	qCfg.NumConsumers = 0 // to make every request go straight to the queue
+	qCfg.Partition = PartitionSettings{ResourceAttribute: "tenant", QueueSize: 2}
+	rCfg := DefaultRetrySettings()
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+
+	ctx := context.Background()
+	require.NoError(t, be.sender.send(newTracesRequest(ctx, newTenantTraces("noisy"), nil)))
+	require.NoError(t, be.sender.send(newTracesRequest(ctx, newTenantTraces("noisy"), nil)))
+	assert.ErrorIs(t, be.sender.send(newTracesRequest(ctx, newTenantTraces("noisy"), nil)), errSendingQueueIsFull)
+	assert.ErrorIs(t, be.sender.send(newTracesRequest(ctx, newTenantTraces("noisy"), nil)), errSendingQueueIsFull)
+	// The other tenants are not affected by the noisy one.
+	require.NoError(t, be.sender.send(newTracesRequest(ctx, newTenantTraces("quiet"), nil)))
+	checkValueForGlobalManager(t, defaultExporterTags, int64(3), "exporter/queue_size")
+	partitionTag, _ := tag.NewKey(partitionKey)
+	checkValueForGlobalManager(t, append(defaultExporterTags, tag.Tag{Key: partitionTag, Value: "noisy"}), int64(2), "exporter/enqueue_failed_partition_items")
+
+	assert.NoError(t, be.Shutdown(context.Background()))
+}
+
+func TestQueuedRetry_PartitionInvalid(t *testing.T) {
+	qCfg := DefaultQueueSettings()
+	qCfg.Partition = PartitionSettings{MetadataKey: "tenant"}
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	assert.ErrorIs(t, be.Start(context.Background(), componenttest.NewNopHost()), errPartitionQueueSize)
+}
@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/model/otlp"
+)
+
+var (
+	errReplayUnsupportedExporter = errors.New("the replay exporter must be created with exporterhelper")
+	errReplayPersistentQueue     = errors.New("the replay exporter must not use a persistent queue")
+)
+
+var (
+	tracesJSONMarshaler  = otlp.NewJSONTracesMarshaler()
+	metricsJSONMarshaler = otlp.NewJSONMetricsMarshaler()
+	logsJSONMarshaler    = otlp.NewJSONLogsMarshaler()
+)
+
+// PersistentQueueItem is an item stored in the persistent queue of an exporter.
+type PersistentQueueItem struct {
+	// Key is the storage key of the item.
+	Key string
+	// Dispatched indicates whether the item was being exported when the exporter stopped.
+	Dispatched bool
+	// Count is the number of spans, metric data points or log records of the item.
+	Count int
+	// Data is the content of the item encoded as OTLP JSON.
+	Data []byte
+}
+
+// PersistentQueueInspector provides offline access to the persistent queue of an exporter, to see what is
+// stuck in it, to purge it, or to re-export its content. It must not be used while the exporter is running.
+type PersistentQueueInspector struct {
+	signal    config.DataType
+	client    storage.Client
+	inspector *internal.PersistentStorageInspector
+}
+
+// NewPersistentQueueInspector opens the persistent queue of the exporter with the given ID for the given data type,
+// as stored by the storage extension. The inspector must be closed to release the storage client.
+func NewPersistentQueueInspector(ctx context.Context, ext storage.Extension, id config.ComponentID, signal config.DataType, logger *zap.Logger) (*PersistentQueueInspector, error) {
+	var unmarshaler internal.RequestUnmarshaler
+	switch signal {
+	case config.TracesDataType:
+		unmarshaler = newTraceRequestUnmarshalerFunc(nil)
+	case config.MetricsDataType:
+		unmarshaler = newMetricsRequestUnmarshalerFunc(nil)
+	case config.LogsDataType:
+		unmarshaler = newLogsRequestUnmarshalerFunc(nil)
+	default:
+		return nil, fmt.Errorf("unsupported data type %q", signal)
+	}
+
+	client, err := ext.GetClient(ctx, component.KindExporter, id, string(signal))
+	if err != nil {
+		return nil, err
+	}
+	queueName := fmt.Sprintf("%s-%s", id.String(), signal)
+	return &PersistentQueueInspector{
+		signal:    signal,
+		client:    client,
+		inspector: internal.NewPersistentStorageInspector(queueName, logger, client, unmarshaler),
+	}, nil
+}
+
+// Items returns the items of the queue in the order they are exported when the exporter is started.
+func (pqi *PersistentQueueInspector) Items(ctx context.Context) ([]PersistentQueueItem, error) {
+	items, err := pqi.inspector.Items(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PersistentQueueItem, 0, len(items))
+	for _, item := range items {
+		req := item.Request.(request)
+		data, err := requestToJSON(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed encoding item %q: %w", item.Key, err)
+		}
+		result = append(result, PersistentQueueItem{
+			Key:        item.Key,
+			Dispatched: item.Dispatched,
+			Count:      req.count(),
+			Data:       data,
+		})
+	}
+	return result, nil
+}
+
+// Purge removes all the items from the queue and returns the number of items removed.
+func (pqi *PersistentQueueInspector) Purge(ctx context.Context) (int, error) {
+	return pqi.inspector.Purge(ctx)
+}
+
+// Replay exports the items of the queue using the given exporter, which must be started, removing every item
+// exported successfully. It stops at the first export error and returns the number of items exported.
+// The exporter must be created with this package and must not use a persistent queue. The items are sent
+// synchronously, bypassing the batching and the sending queue of the exporter, so that an item is only
+// removed once it is actually exported.
+func (pqi *PersistentQueueInspector) Replay(ctx context.Context, exp component.Exporter) (int, error) {
+	be := baseExporterOf(exp)
+	if be == nil {
+		return 0, errReplayUnsupportedExporter
+	}
+	if be.qrSender.cfg.Enabled && be.qrSender.cfg.StorageID != nil {
+		return 0, errReplayPersistentQueue
+	}
+	ctx = context.WithValue(ctx, synchronousSendKey{}, true)
+	return pqi.inspector.Replay(ctx, func(req internal.PersistentRequest) error {
+		switch r := req.(type) {
+		case *tracesRequest:
+			if tc, ok := exp.(consumer.Traces); ok {
+				return tc.ConsumeTraces(ctx, r.td)
+			}
+		case *metricsRequest:
+			if mc, ok := exp.(consumer.Metrics); ok {
+				return mc.ConsumeMetrics(ctx, r.md)
+			}
+		case *logsRequest:
+			if lc, ok := exp.(consumer.Logs); ok {
+				return lc.ConsumeLogs(ctx, r.ld)
+			}
+		}
+		return fmt.Errorf("exporter does not support %q data", pqi.signal)
+	})
+}
+
+// Close releases the storage client of the queue.
+func (pqi *PersistentQueueInspector) Close(ctx context.Context) error {
+	return pqi.client.Close(ctx)
+}
+
+// baseExporterOf returns the baseExporter of an exporter created with this package, or nil.
+func baseExporterOf(exp component.Exporter) *baseExporter {
+	switch e := exp.(type) {
+	case *traceExporter:
+		return e.baseExporter
+	case *metricsExporter:
+		return e.baseExporter
+	case *logsExporter:
+		return e.baseExporter
+	}
+	return nil
+}
+
+func requestToJSON(req request) ([]byte, error) {
+	switch r := req.(type) {
+	case *tracesRequest:
+		return tracesJSONMarshaler.MarshalTraces(r.td)
+	case *metricsRequest:
+		return metricsJSONMarshaler.MarshalMetrics(r.md)
+	case *logsRequest:
+		return logsJSONMarshaler.MarshalLogs(r.ld)
+	}
+	return nil, fmt.Errorf("unsupported request type %T", req)
+}
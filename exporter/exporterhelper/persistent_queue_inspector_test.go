@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component/componenthelper"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/otlp"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// newTracesQueueStorage returns a storage extension holding a persistent queue with the given traces.
+func newTracesQueueStorage(t *testing.T, traces ...pdata.Traces) *mockStorageExtension {
+	client := &mockStorageClient{st: map[string][]byte{}}
+	for i, td := range traces {
+		buf, err := newTracesRequest(context.Background(), td, nil).Marshal()
+		require.NoError(t, err)
+		client.st[deadLetterItemKey(uint64(i))] = buf
+	}
+	writeIndex := make([]byte, 8)
+	binary.LittleEndian.PutUint64(writeIndex, uint64(len(traces)))
+	client.st["wi"] = writeIndex
+	return &mockStorageExtension{Component: componenthelper.New(), client: client}
+}
+
+func TestPersistentQueueInspector_Items(t *testing.T) {
+	td := testdata.GenerateTracesTwoSpansSameResource()
+	ext := newTracesQueueStorage(t, testdata.GenerateTracesOneSpan(), td)
+	pqi, err := NewPersistentQueueInspector(context.Background(), ext, fakeTracesExporterName, config.TracesDataType, zap.NewNop())
+	require.NoError(t, err)
+
+	items, err := pqi.Items(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, "0", items[0].Key)
+	assert.Equal(t, 1, items[0].Count)
+	assert.False(t, items[0].Dispatched)
+	assert.Equal(t, 2, items[1].Count)
+	got, err := otlp.NewJSONTracesUnmarshaler().UnmarshalTraces(items[1].Data)
+	require.NoError(t, err)
+	assert.Equal(t, td, got)
+
+	assert.NoError(t, pqi.Close(context.Background()))
+}
+
+func TestPersistentQueueInspector_Replay(t *testing.T) {
+	ext := newTracesQueueStorage(t, testdata.GenerateTracesOneSpan(), testdata.GenerateTracesTwoSpansSameResource())
+	pqi, err := NewPersistentQueueInspector(context.Background(), ext, fakeTracesExporterName, config.TracesDataType, zap.NewNop())
+	require.NoError(t, err)
+
+	sink := new(consumertest.TracesSink)
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeTraces)
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), componenttest.NewNopHost()))
+
+	replayed, err := pqi.Replay(context.Background(), te)
+	require.NoError(t, err)
+	assert.Equal(t, 2, replayed)
+	assert.Equal(t, 3, sink.SpanCount())
+	require.NoError(t, te.Shutdown(context.Background()))
+
+	items, err := pqi.Items(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, items)
+
+	// Exporters not supporting the data type leave the items in the queue.
+	ext = newTracesQueueStorage(t, testdata.GenerateTracesOneSpan())
+	pqi, err = NewPersistentQueueInspector(context.Background(), ext, fakeTracesExporterName, config.TracesDataType, zap.NewNop())
+	require.NoError(t, err)
+	me, err := NewMetricsExporter(&fakeMetricsExporterConfig, componenttest.NewNopExporterCreateSettings(), newPushMetricsData(nil))
+	require.NoError(t, err)
+	replayed, err = pqi.Replay(context.Background(), me)
+	assert.Error(t, err)
+	assert.Equal(t, 0, replayed)
+	items, err = pqi.Items(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestPersistentQueueInspector_Purge(t *testing.T) {
+	ext := newTracesQueueStorage(t, testdata.GenerateTracesOneSpan(), testdata.GenerateTracesOneSpan())
+	pqi, err := NewPersistentQueueInspector(context.Background(), ext, fakeTracesExporterName, config.TracesDataType, zap.NewNop())
+	require.NoError(t, err)
+
+	purged, err := pqi.Purge(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	items, err := pqi.Items(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestPersistentQueueInspector_Errors(t *testing.T) {
+	ext := newTracesQueueStorage(t)
+	_, err := NewPersistentQueueInspector(context.Background(), ext, fakeTracesExporterName, config.DataType("unknown"), zap.NewNop())
+	assert.Error(t, err)
+
+	ext.client.st["0"] = []byte("not a request")
+	ext.client.st["wi"] = []byte{1, 0, 0, 0, 0, 0, 0, 0}
+	pqi, err := NewPersistentQueueInspector(context.Background(), ext, fakeTracesExporterName, config.TracesDataType, zap.NewNop())
+	require.NoError(t, err)
+	_, err = pqi.Items(context.Background())
+	assert.Error(t, err)
+	_, err = pqi.Replay(context.Background(), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// defaultLaneName is the name of the lowest priority lane, holding the requests not matching any configured lane.
+const defaultLaneName = "default"
+
+var errPriorityLanesNotSupported = errors.New("sending_queue.priority_lanes is not supported with sending_queue.storage or sending_queue.queue_size_bytes")
+
+// spanStatusCodes maps the span statuses that can be matched by a lane to their code.
+var spanStatusCodes = map[string]pdata.StatusCode{
+	"unset": pdata.StatusCodeUnset,
+	"ok":    pdata.StatusCodeOk,
+	"error": pdata.StatusCodeError,
+}
+
+// PriorityLaneSettings defines a lane of the sending queue. Consumers always send the requests of the first lanes
+// before the ones of the following lanes.
+type PriorityLaneSettings struct {
+	// Name identifies the lane in the exporter/queue_lane_size metric.
+	Name string `mapstructure:"name"`
+	// QueueSize is the maximum number of batches allowed in the lane at a given time.
+	QueueSize int `mapstructure:"queue_size"`
+	// ResourceAttributes are the attributes a resource of the request must have, with the given values,
+	// for the request to be assigned to the lane.
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+	// SpanStatus is the status ("unset", "ok" or "error") a span of the request must have for the request
+	// to be assigned to the lane. Only applies to traces, metrics and logs requests never match a lane setting it.
+	SpanStatus string `mapstructure:"span_status"`
+}
+
+// validatePriorityLanes checks if the priority lanes of the queue configuration are valid.
+func validatePriorityLanes(cfg QueueSettings) error {
+	if len(cfg.PriorityLanes) == 0 {
+		return nil
+	}
+	if cfg.StorageID != nil || cfg.QueueSizeBytes > 0 {
+		return errPriorityLanesNotSupported
+	}
+	names := map[string]bool{defaultLaneName: true}
+	for _, lane := range cfg.PriorityLanes {
+		if lane.Name == "" {
+			return errors.New("sending_queue.priority_lanes: name must be set")
+		}
+		if names[lane.Name] {
+			return fmt.Errorf("sending_queue.priority_lanes: duplicate or reserved lane name %q", lane.Name)
+		}
+		names[lane.Name] = true
+		if lane.QueueSize <= 0 {
+			return fmt.Errorf("sending_queue.priority_lanes: queue_size of lane %q must be positive", lane.Name)
+		}
+		if _, ok := spanStatusCodes[lane.SpanStatus]; lane.SpanStatus != "" && !ok {
+			return fmt.Errorf("sending_queue.priority_lanes: invalid span_status %q of lane %q", lane.SpanStatus, lane.Name)
+		}
+	}
+	return nil
+}
+
+// laneNames returns the names of the lanes of the queue, in priority order.
+func laneNames(cfg QueueSettings) []string {
+	names := make([]string, 0, len(cfg.PriorityLanes)+1)
+	for _, lane := range cfg.PriorityLanes {
+		names = append(names, lane.Name)
+	}
+	return append(names, defaultLaneName)
+}
+
+// laneCapacities returns the capacities of the lanes of the queue, in priority order. The default lane
+// is bounded by the queue size.
+func laneCapacities(cfg QueueSettings) []int {
+	capacities := make([]int, 0, len(cfg.PriorityLanes)+1)
+	for _, lane := range cfg.PriorityLanes {
+		capacities = append(capacities, lane.QueueSize)
+	}
+	return append(capacities, cfg.QueueSize)
+}
+
+// laneOf returns the index of the first lane matching the request, or the index of the default lane.
+func laneOf(lanes []PriorityLaneSettings, req request) int {
+	for i, lane := range lanes {
+		if lane.matches(req) {
+			return i
+		}
+	}
+	return len(lanes)
+}
+
+// matches returns true if a resource of the request matches the lane.
+func (lane *PriorityLaneSettings) matches(req request) bool {
+	switch r := req.(type) {
+	case *tracesRequest:
+		rss := r.td.ResourceSpans()
+		for i := 0; i < rss.Len(); i++ {
+			rs := rss.At(i)
+			if lane.matchesResource(rs.Resource()) && lane.matchesSpans(rs) {
+				return true
+			}
+		}
+	case *metricsRequest:
+		if lane.SpanStatus != "" {
+			return false
+		}
+		rms := r.md.ResourceMetrics()
+		for i := 0; i < rms.Len(); i++ {
+			if lane.matchesResource(rms.At(i).Resource()) {
+				return true
+			}
+		}
+	case *logsRequest:
+		if lane.SpanStatus != "" {
+			return false
+		}
+		rls := r.ld.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			if lane.matchesResource(rls.At(i).Resource()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (lane *PriorityLaneSettings) matchesResource(resource pdata.Resource) bool {
+	attrs := resource.Attributes()
+	for key, value := range lane.ResourceAttributes {
+		v, ok := attrs.Get(key)
+		if !ok || v.AsString() != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (lane *PriorityLaneSettings) matchesSpans(rs pdata.ResourceSpans) bool {
+	if lane.SpanStatus == "" {
+		return true
+	}
+	code := spanStatusCodes[lane.SpanStatus]
+	ilss := rs.InstrumentationLibrarySpans()
+	for i := 0; i < ilss.Len(); i++ {
+		spans := ilss.At(i).Spans()
+		for j := 0; j < spans.Len(); j++ {
+			if spans.At(j).Status().Code() == code {
+				return true
+			}
+		}
+	}
+	return false
+}
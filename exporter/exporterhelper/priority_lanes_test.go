@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/tag"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func newPriorityLanes() []PriorityLaneSettings {
+	return []PriorityLaneSettings{
+		{Name: "errors", QueueSize: 1, SpanStatus: "error"},
+		{Name: "prod", QueueSize: 1, ResourceAttributes: map[string]string{"deployment.environment": "prod"}},
+	}
+}
+
+func newTracesWithStatus(code pdata.StatusCode) pdata.Traces {
+	td := testdata.GenerateTracesOneSpan()
+	td.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Status().SetCode(code)
+	return td
+}
+
+func newProdLogs() pdata.Logs {
+	ld := testdata.GenerateLogsOneLogRecord()
+	ld.ResourceLogs().At(0).Resource().Attributes().UpsertString("deployment.environment", "prod")
+	return ld
+}
+
+func TestLaneOf(t *testing.T) {
+	lanes := newPriorityLanes()
+	ctx := context.Background()
+	assert.Equal(t, 0, laneOf(lanes, newTracesRequest(ctx, newTracesWithStatus(pdata.StatusCodeError), nil)))
+	assert.Equal(t, 2, laneOf(lanes, newTracesRequest(ctx, newTracesWithStatus(pdata.StatusCodeOk), nil)))
+	assert.Equal(t, 1, laneOf(lanes, newLogsRequest(ctx, newProdLogs(), nil)))
+	assert.Equal(t, 2, laneOf(lanes, newLogsRequest(ctx, testdata.GenerateLogsOneLogRecord(), nil)))
+	assert.Equal(t, 2, laneOf(lanes, newMetricsRequest(ctx, testdata.GenerateMetricsOneMetric(), nil)))
+
+	// Span status lanes never match metrics and logs.
+	lanes = []PriorityLaneSettings{{Name: "ok", QueueSize: 1, SpanStatus: "ok"}}
+	assert.Equal(t, 0, laneOf(lanes, newTracesRequest(ctx, newTracesWithStatus(pdata.StatusCodeOk), nil)))
+	assert.Equal(t, 1, laneOf(lanes, newLogsRequest(ctx, testdata.GenerateLogsOneLogRecord(), nil)))
+}
+
+func TestValidatePriorityLanes(t *testing.T) {
+	qCfg := DefaultQueueSettings()
+	assert.NoError(t, validatePriorityLanes(qCfg))
+	qCfg.PriorityLanes = newPriorityLanes()
+	assert.NoError(t, validatePriorityLanes(qCfg))
+
+	tests := []struct {
+		name   string
+		modify func(*QueueSettings)
+	}{
+		{name: "storage", modify: func(cfg *QueueSettings) {
+			storageID := config.NewComponentID("file_storage")
+			cfg.StorageID = &storageID
+		}},
+		{name: "queue_size_bytes", modify: func(cfg *QueueSettings) { cfg.QueueSizeBytes = 100 }},
+		{name: "missing name", modify: func(cfg *QueueSettings) { cfg.PriorityLanes[0].Name = "" }},
+		{name: "duplicate name", modify: func(cfg *QueueSettings) { cfg.PriorityLanes[1].Name = "errors" }},
+		{name: "reserved name", modify: func(cfg *QueueSettings) { cfg.PriorityLanes[1].Name = defaultLaneName }},
+		{name: "queue_size", modify: func(cfg *QueueSettings) { cfg.PriorityLanes[0].QueueSize = 0 }},
+		{name: "span_status", modify: func(cfg *QueueSettings) { cfg.PriorityLanes[0].SpanStatus = "failed" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultQueueSettings()
+			cfg.PriorityLanes = newPriorityLanes()
+			tt.modify(&cfg)
+			assert.Error(t, validatePriorityLanes(cfg))
+		})
+	}
+}
+
+func TestQueuedRetry_PriorityLanes(t *testing.T) {
+	qCfg := DefaultQueueSettings()
+	qCfg.NumConsumers = 0 // to make every request go straight to the queue
+	qCfg.QueueSize = 1
+	qCfg.PriorityLanes = newPriorityLanes()
+	rCfg := DefaultRetrySettings()
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+
+	ctx := context.Background()
+	require.NoError(t, be.sender.send(newTracesRequest(ctx, newTracesWithStatus(pdata.StatusCodeError), nil)))
+	assert.ErrorIs(t, be.sender.send(newTracesRequest(ctx, newTracesWithStatus(pdata.StatusCodeError), nil)), errSendingQueueIsFull)
+	require.NoError(t, be.sender.send(newLogsRequest(ctx, newProdLogs(), nil)))
+	require.NoError(t, be.sender.send(newTracesRequest(ctx, newTracesWithStatus(pdata.StatusCodeOk), nil)))
+	checkValueForGlobalManager(t, defaultExporterTags, int64(3), "exporter/queue_size")
+	laneTag, _ := tag.NewKey(laneKey)
+	for _, name := range []string{"errors", "prod", defaultLaneName} {
+		laneTags := append(defaultExporterTags, tag.Tag{Key: laneTag, Value: name})
+		checkValueForGlobalManager(t, laneTags, int64(1), "exporter/queue_lane_size")
+	}
+
+	assert.NoError(t, be.Shutdown(context.Background()))
+	laneTags := append(defaultExporterTags, tag.Tag{Key: laneTag, Value: "errors"})
+	checkValueForGlobalManager(t, laneTags, int64(0), "exporter/queue_lane_size")
+}
+
+func TestQueuedRetry_PriorityLanesInvalid(t *testing.T) {
+	qCfg := DefaultQueueSettings()
+	qCfg.QueueSizeBytes = 100
+	qCfg.PriorityLanes = newPriorityLanes()
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	assert.ErrorIs(t, be.Start(context.Background(), componenttest.NewNopHost()), errPriorityLanesNotSupported)
+}
@@ -21,18 +21,95 @@ import (
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
+	"go.opencensus.io/metric/metricdata"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
+	"go.opentelemetry.io/collector/model/pdata"
 )
 
+// persistentQueueGateID is the ID of the feature gate allowing the sending queue to be persisted in a storage extension.
+const persistentQueueGateID = "exporter.persistentQueue"
+
+func init() {
+	featuregate.Register(featuregate.Gate{
+		ID:          persistentQueueGateID,
+		Description: "Allows exporters to persist the sending queue in the storage extension set by sending_queue.storage",
+		Enabled:     false,
+	})
+}
+
 var (
-	errSendingQueueIsFull = errors.New("sending_queue is full")
+	errSendingQueueIsFull        = errors.New("sending_queue is full")
+	errNoStorageClient           = errors.New("no storage client extension found")
+	errWrongExtensionType        = errors.New("requested extension is not a storage extension")
+	errQueueSizeBytesWithStorage = errors.New("sending_queue.queue_size_bytes is not supported with sending_queue.storage")
 )
 
+// QueueSettings defines configuration for queueing batches before sending to the consumerSender.
+type QueueSettings struct {
+	// Enabled indicates whether to not enqueue batches before sending to the consumerSender.
+	Enabled bool `mapstructure:"enabled"`
+	// NumConsumers is the number of consumers from the queue.
+	NumConsumers int `mapstructure:"num_consumers"`
+	// QueueSize is the maximum number of batches allowed in queue at a given time.
+	QueueSize int `mapstructure:"queue_size"`
+	// QueueSizeBytes is the maximum size in bytes of the batches allowed in queue at a given time, measured as
+	// OTLP protobuf encoded size. When set, it bounds the queue instead of QueueSize.
+	QueueSizeBytes int `mapstructure:"queue_size_bytes"`
+	// AdaptiveConcurrency configures adapting the number of concurrent consumers to the backend health,
+	// in which case NumConsumers is only the initial number of concurrent consumers.
+	AdaptiveConcurrency AdaptiveConcurrencySettings `mapstructure:"adaptive_concurrency"`
+	// StorageID is the ID of the storage extension used to persist the queue, so the queued data survives
+	// collector restarts. If not set, the queue is kept in memory. Requires the exporter.persistentQueue feature gate.
+	StorageID *config.ComponentID `mapstructure:"storage"`
+	// PriorityLanes splits the queue in lanes, each request being assigned to the first lane it matches.
+	// Consumers send the requests of the first lanes before the ones of the following lanes, and the requests
+	// not matching any lane go to a last "default" lane bounded by QueueSize.
+	PriorityLanes []PriorityLaneSettings `mapstructure:"priority_lanes"`
+	// Partition configures partitioning the queue, e.g. per tenant, with a capacity per partition and the
+	// consumers taking the requests of the partitions in turn.
+	Partition PartitionSettings `mapstructure:"partition"`
+}
+
+// Validate checks if the queue configuration is valid. The persistent queue is bounded by the number of batches only,
+// and does not support priority lanes or partitions.
+func (cfg *QueueSettings) Validate() error {
+	if cfg.StorageID != nil && cfg.QueueSizeBytes > 0 {
+		return errQueueSizeBytesWithStorage
+	}
+	if err := cfg.AdaptiveConcurrency.Validate(); err != nil {
+		return err
+	}
+	if err := validatePriorityLanes(*cfg); err != nil {
+		return err
+	}
+	return validatePartition(*cfg)
+}
+
+// DefaultQueueSettings returns the default settings for QueueSettings.
+func DefaultQueueSettings() QueueSettings {
+	return QueueSettings{
+		Enabled:      true,
+		NumConsumers: 10,
+		// For 5000 queue elements at 100 requests/sec gives about 50 sec of survival of destination outage.
+		// This is a pretty decent value for production.
+		// User should calculate this from the perspective of how many seconds to buffer in case of a backend outage,
+		// multiply that by the number of requests per seconds.
+		QueueSize:           5000,
+		AdaptiveConcurrency: DefaultAdaptiveConcurrencySettings(),
+	}
+}
+
 // RetrySettings defines configuration for retrying batches in case of export failure.
 // The current supported strategy is exponential backoff.
 type RetrySettings struct {
@@ -77,9 +154,289 @@ func createSampledLogger(logger *zap.Logger) *zap.Logger {
 	return logger.WithOptions(opts)
 }
 
+// newMemoryQueue creates the in-memory queue, bounded either by the number of requests or by their size in bytes,
+// and split in priority lanes or partitions if configured.
+func newMemoryQueue(cfg QueueSettings, obsrep *obsExporter) internal.ProducerConsumerQueue {
+	if cfg.QueueSizeBytes > 0 {
+		return internal.NewBytesBoundedMemoryQueue(cfg.QueueSizeBytes, func(item interface{}) int {
+			return item.(request).bytesSize()
+		}, func(item interface{}) {})
+	}
+	if len(cfg.PriorityLanes) > 0 {
+		return internal.NewPriorityMemoryQueue(laneCapacities(cfg), func(item interface{}) int {
+			return laneOf(cfg.PriorityLanes, item.(request))
+		}, func(item interface{}) {})
+	}
+	if cfg.Partition.enabled() {
+		return internal.NewPartitionedMemoryQueue(cfg.QueueSize, cfg.Partition.QueueSize, func(item interface{}) string {
+			return cfg.Partition.partitionOf(item.(request))
+		}, func(item interface{}) {}, func(item interface{}, partition string) {
+			req := item.(request)
+			obsrep.recordPartitionEnqueueFailure(req.context(), partition, int64(req.count()))
+		})
+	}
+	return internal.NewBoundedMemoryQueue(cfg.QueueSize, func(item interface{}) {})
+}
+
+type queuedRetrySender struct {
+	id                 config.ComponentID
+	signal             config.DataType
+	cfg                QueueSettings
+	consumerSender     requestSender
+	queue              internal.ProducerConsumerQueue
+	limiter            *concurrencyLimiter
+	obsrep             *obsExporter
+	deadLetter         *deadLetterSender
+	retryStopCh        chan struct{}
+	traceAttributes    []attribute.KeyValue
+	logger             *zap.Logger
+	requeuingEnabled   bool
+	requestUnmarshaler internal.RequestUnmarshaler
+}
+
+func (qrs *queuedRetrySender) fullName() string {
+	if qrs.signal == "" {
+		return qrs.id.String()
+	}
+	return fmt.Sprintf("%s-%s", qrs.id.String(), qrs.signal)
+}
+
+func newQueuedRetrySender(id config.ComponentID, signal config.DataType, qCfg QueueSettings, rCfg RetrySettings, reqUnmarshaler internal.RequestUnmarshaler, nextSender requestSender, obsrep *obsExporter, deadLetter *deadLetterSender, logger *zap.Logger) *queuedRetrySender {
+	var limiter *concurrencyLimiter
+	if qCfg.Enabled && qCfg.AdaptiveConcurrency.Enabled {
+		limiter = newConcurrencyLimiter(qCfg.AdaptiveConcurrency, qCfg.NumConsumers, nextSender)
+		nextSender = limiter
+	}
+	retryStopCh := make(chan struct{})
+	sampledLogger := createSampledLogger(logger)
+	traceAttr := attribute.String(obsmetrics.ExporterKey, id.String())
+
+	qrs := &queuedRetrySender{
+		id:                 id,
+		signal:             signal,
+		cfg:                qCfg,
+		retryStopCh:        retryStopCh,
+		traceAttributes:    []attribute.KeyValue{traceAttr},
+		logger:             sampledLogger,
+		requestUnmarshaler: reqUnmarshaler,
+		limiter:            limiter,
+		obsrep:             obsrep,
+		deadLetter:         deadLetter,
+	}
+
+	qrs.consumerSender = &retrySender{
+		traceAttribute: traceAttr,
+		cfg:            rCfg,
+		nextSender:     nextSender,
+		obsrep:         obsrep,
+		stopCh:         retryStopCh,
+		logger:         sampledLogger,
+		deadLetter:     deadLetter,
+		// Following three functions actually depend on queuedRetrySender
+		onTemporaryFailure: qrs.onTemporaryFailure,
+	}
+
+	if !qCfg.Enabled || qCfg.StorageID == nil {
+		qrs.queue = newMemoryQueue(qrs.cfg, obsrep)
+	}
+	// The persistent queue is initialized on start as it needs the storage extension from the host
+
+	return qrs
+}
+
+// getStorageClient returns the client of the storage extension with the given ID for the queue of the given exporter and signal.
+func getStorageClient(ctx context.Context, host component.Host, storageID config.ComponentID, id config.ComponentID, signal config.DataType) (storage.Client, error) {
+	ext, found := host.GetExtensions()[storageID]
+	if !found {
+		return nil, fmt.Errorf("storage extension %q: %w", storageID.String(), errNoStorageClient)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q: %w", storageID.String(), errWrongExtensionType)
+	}
+	return storageExt.GetClient(ctx, component.KindExporter, id, string(signal))
+}
+
+// initializePersistentQueue uses extra information for initialization available from component.Host
+func (qrs *queuedRetrySender) initializePersistentQueue(ctx context.Context, host component.Host) error {
+	if qrs.queue != nil {
+		return nil
+	}
+	if !featuregate.IsEnabled(persistentQueueGateID) {
+		return fmt.Errorf("sending_queue.storage requires the %q feature gate to be enabled", persistentQueueGateID)
+	}
+
+	storageClient, err := getStorageClient(ctx, host, *qrs.cfg.StorageID, qrs.id, qrs.signal)
+	if err != nil {
+		return err
+	}
+
+	qrs.queue = internal.NewPersistentQueue(ctx, qrs.fullName(), qrs.cfg.QueueSize, qrs.logger, storageClient, qrs.requestUnmarshaler)
+
+	// TODO: this can be further exposed as a config param rather than relying on a type of queue
+	qrs.requeuingEnabled = true
+	return nil
+}
+
+func (qrs *queuedRetrySender) onTemporaryFailure(logger *zap.Logger, req request, err error) error {
+	if !qrs.requeuingEnabled || qrs.queue == nil {
+		logger.Error(
+			"Exporting failed. No more retries left. Dropping data.",
+			zap.Error(err),
+			zap.Int("dropped_items", req.count()),
+		)
+		qrs.deadLetter.onDropped(req, err)
+		return err
+	}
+
+	if qrs.queue.Produce(req) {
+		logger.Error(
+			"Exporting failed. Putting back to the end of the queue.",
+			zap.Error(err),
+		)
+	} else {
+		logger.Error(
+			"Exporting failed. Queue did not accept requeuing request. Dropping data.",
+			zap.Error(err),
+			zap.Int("dropped_items", req.count()),
+		)
+		qrs.deadLetter.onDropped(req, err)
+	}
+	return err
+}
+
+// start is invoked during service startup.
+func (qrs *queuedRetrySender) start(ctx context.Context, host component.Host) error {
+	if err := qrs.cfg.Validate(); err != nil {
+		return err
+	}
+	err := qrs.initializePersistentQueue(ctx, host)
+	if err != nil {
+		return err
+	}
+
+	qrs.queue.StartConsumers(qrs.numConsumers(), qrs.consume)
+
+	// Start reporting queue length metric
+	if qrs.cfg.Enabled {
+		err := globalInstruments.queueSize.UpsertEntry(func() int64 {
+			return int64(qrs.queue.Size())
+		}, metricdata.NewLabelValue(qrs.fullName()))
+		if err != nil {
+			return fmt.Errorf("failed to create retry queue size metric: %v", err)
+		}
+		if bq, ok := qrs.queue.(internal.BytesSizedQueue); ok {
+			err = globalInstruments.queueSizeBytes.UpsertEntry(func() int64 {
+				return int64(bq.SizeBytes())
+			}, metricdata.NewLabelValue(qrs.fullName()))
+			if err != nil {
+				return fmt.Errorf("failed to create retry queue size in bytes metric: %v", err)
+			}
+		}
+		if pq, ok := qrs.queue.(internal.PriorityQueue); ok {
+			for i, name := range laneNames(qrs.cfg) {
+				lane := i
+				err = globalInstruments.queueLaneSize.UpsertEntry(func() int64 {
+					return int64(pq.LaneSize(lane))
+				}, metricdata.NewLabelValue(qrs.fullName()), metricdata.NewLabelValue(name))
+				if err != nil {
+					return fmt.Errorf("failed to create retry queue lane size metric: %v", err)
+				}
+			}
+		}
+		if err = qrs.startConcurrencyLimitMetric(qrs.fullName()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// shutdown is invoked during service shutdown.
+func (qrs *queuedRetrySender) shutdown() {
+	// Cleanup queue metrics reporting
+	if qrs.cfg.Enabled {
+		_ = globalInstruments.queueSize.UpsertEntry(func() int64 {
+			return int64(0)
+		}, metricdata.NewLabelValue(qrs.fullName()))
+		if _, ok := qrs.queue.(internal.BytesSizedQueue); ok {
+			_ = globalInstruments.queueSizeBytes.UpsertEntry(func() int64 {
+				return int64(0)
+			}, metricdata.NewLabelValue(qrs.fullName()))
+		}
+		if _, ok := qrs.queue.(internal.PriorityQueue); ok {
+			for _, name := range laneNames(qrs.cfg) {
+				_ = globalInstruments.queueLaneSize.UpsertEntry(func() int64 {
+					return int64(0)
+				}, metricdata.NewLabelValue(qrs.fullName()), metricdata.NewLabelValue(name))
+			}
+		}
+	}
+
+	// First Stop the retry goroutines, so that unblocks the queue numWorkers.
+	close(qrs.retryStopCh)
+
+	// Remove the concurrency limit, so all the consumers drain the queue.
+	qrs.stopConcurrencyLimiter(qrs.fullName())
+
+	// Stop the queued sender, this will drain the queue and will call the retry (which is stopped) that will only
+	// try once every request.
+	if qrs.queue != nil {
+		qrs.queue.Stop()
+	}
+}
+
+// numConsumers returns the number of goroutines consuming from the queue.
+func (qrs *queuedRetrySender) numConsumers() int {
+	if qrs.limiter != nil {
+		// Enough consumers are started for the maximum limit, the limiter controls how many of them send concurrently.
+		if qrs.cfg.AdaptiveConcurrency.MaxConsumers < 1 {
+			return 1
+		}
+		return qrs.cfg.AdaptiveConcurrency.MaxConsumers
+	}
+	return qrs.cfg.NumConsumers
+}
+
+// consume sends a request taken from the queue, respecting the adaptive concurrency limit if enabled.
+func (qrs *queuedRetrySender) consume(item interface{}) {
+	req := item.(request)
+	if qrs.limiter != nil {
+		qrs.limiter.acquire()
+		defer qrs.limiter.release()
+	}
+	_ = qrs.consumerSender.send(req)
+	req.OnProcessingFinished()
+}
+
+// startConcurrencyLimitMetric starts reporting the concurrency limit metric, if adaptive concurrency is enabled.
+func (qrs *queuedRetrySender) startConcurrencyLimitMetric(name string) error {
+	if qrs.limiter == nil {
+		return nil
+	}
+	err := globalInstruments.concurrencyLimit.UpsertEntry(func() int64 {
+		return int64(qrs.limiter.currentLimit())
+	}, metricdata.NewLabelValue(name))
+	if err != nil {
+		return fmt.Errorf("failed to create concurrency limit metric: %v", err)
+	}
+	return nil
+}
+
+// stopConcurrencyLimiter removes the concurrency limit and cleans up its metric reporting.
+func (qrs *queuedRetrySender) stopConcurrencyLimiter(name string) {
+	if qrs.limiter == nil {
+		return
+	}
+	qrs.limiter.stop()
+	_ = globalInstruments.concurrencyLimit.UpsertEntry(func() int64 {
+		return int64(0)
+	}, metricdata.NewLabelValue(name))
+}
+
 // send implements the requestSender interface
 func (qrs *queuedRetrySender) send(req request) error {
-	if !qrs.cfg.Enabled {
+	if !qrs.cfg.Enabled || isSynchronousSend(req.context()) {
 		err := qrs.consumerSender.send(req)
 		if err != nil {
 			qrs.logger.Error(
@@ -97,7 +454,7 @@ func (qrs *queuedRetrySender) send(req request) error {
 	span := trace.SpanFromContext(req.context())
 	if !qrs.queue.Produce(req) {
 		qrs.logger.Error(
-			"Dropping data because sending_queue is full. Try increasing queue_size.",
+			"Dropping data because sending_queue is full. Try increasing queue_size or queue_size_bytes.",
 			zap.Int("dropped_items", req.count()),
 		)
 		span.AddEvent("Dropped item, sending_queue is full.", trace.WithAttributes(qrs.traceAttributes...))
@@ -108,6 +465,9 @@ func (qrs *queuedRetrySender) send(req request) error {
 	return nil
 }
 
+// throttleRetry is the standard error used by exporters to signal that the backend throttled the request
+// and asked to wait for the given delay before retrying, e.g. via the HTTP Retry-After header or the
+// gRPC RetryInfo status details. The retrySender waits for the delay, clamped to RetrySettings.MaxInterval.
 // TODO: Clean this by forcing all exporters to return an internal error type that always include the information about retries.
 type throttleRetry struct {
 	err   error
@@ -136,8 +496,10 @@ type retrySender struct {
 	traceAttribute     attribute.KeyValue
 	cfg                RetrySettings
 	nextSender         requestSender
+	obsrep             *obsExporter
 	stopCh             chan struct{}
 	logger             *zap.Logger
+	deadLetter         *deadLetterSender
 	onTemporaryFailure onRequestHandlingFinishedFunc
 }
 
@@ -150,6 +512,9 @@ func (rs *retrySender) send(req request) error {
 				"Exporting failed. Try enabling retry_on_failure config option.",
 				zap.Error(err),
 			)
+			// Without retries all the failed data is dropped.
+			rs.deadLetter.onPartiallyDropped(req.context(), err)
+			rs.deadLetter.onDropped(req.onError(err), err)
 		}
 		return err
 	}
@@ -168,6 +533,8 @@ func (rs *retrySender) send(req request) error {
 	expBackoff.Reset()
 	span := trace.SpanFromContext(req.context())
 	retryNum := int64(0)
+	// permanentErr accumulates the data permanently rejected by partial failures, which is never retried.
+	var permanentErr error
 	for {
 		span.AddEvent(
 			"Sending request.",
@@ -175,7 +542,7 @@ func (rs *retrySender) send(req request) error {
 
 		err := rs.nextSender.send(req)
 		if err == nil {
-			return nil
+			return permanentErr
 		}
 
 		// Immediately drop data on permanent errors.
@@ -185,12 +552,28 @@ func (rs *retrySender) send(req request) error {
 				zap.Error(err),
 				zap.Int("dropped_items", req.count()),
 			)
+			rs.deadLetter.onDropped(req, err)
 			return err
 		}
 
+		// Drop the permanently rejected part of a partial failure, only the retryable part is retried.
+		rs.deadLetter.onPartiallyDropped(req.context(), err)
+		var droppedItems int
+		if permanentErr, droppedItems = mergePermanentFailures(permanentErr, err); droppedItems > 0 {
+			rs.logger.Error(
+				"Exporting partially failed. The rejected data is not retryable. Dropping data.",
+				zap.Error(err),
+				zap.Int("dropped_items", droppedItems),
+			)
+		}
+
 		// Give the request a chance to extract signal data to retry if only some data
 		// failed to process.
 		req = req.onError(err)
+		if req.count() == 0 {
+			// Nothing left that can be retried.
+			return permanentErr
+		}
 
 		backoffDelay := expBackoff.NextBackOff()
 		if backoffDelay == backoff.Stop {
@@ -200,9 +583,14 @@ func (rs *retrySender) send(req request) error {
 		}
 
 		throttleErr := throttleRetry{}
-		isThrottle := errors.As(err, &throttleErr)
-		if isThrottle {
-			backoffDelay = max(backoffDelay, throttleErr.delay)
+		if errors.As(err, &throttleErr) && throttleErr.delay > 0 {
+			// Honor the delay requested by the backend, but never wait longer than MaxInterval.
+			throttleDelay := throttleErr.delay
+			if rs.cfg.MaxInterval > 0 && throttleDelay > rs.cfg.MaxInterval {
+				throttleDelay = rs.cfg.MaxInterval
+			}
+			backoffDelay = max(backoffDelay, throttleDelay)
+			rs.obsrep.recordThrottle(req.context(), backoffDelay)
 		}
 
 		backoffDelayStr := backoffDelay.String()
@@ -230,6 +618,46 @@ func (rs *retrySender) send(req request) error {
 	}
 }
 
+// mergePermanentFailures returns a permanent error that reports the data permanently rejected by the
+// partial failure err, together with the data already reported by prev, and the number of items newly
+// rejected by err. If err does not report any permanently rejected data prev is returned.
+func mergePermanentFailures(prev error, err error) (error, int) {
+	var tracesErr consumererror.PartialTraces
+	if errors.As(err, &tracesErr) && tracesErr.GetPermanent().SpanCount() > 0 {
+		permanent := tracesErr.GetPermanent()
+		dropped := permanent.SpanCount()
+		var prevErr consumererror.PartialTraces
+		if errors.As(prev, &prevErr) {
+			prevErr.GetPermanent().ResourceSpans().MoveAndAppendTo(permanent.ResourceSpans())
+		}
+		return consumererror.NewPermanent(consumererror.NewPartialTraces(err, pdata.NewTraces(), permanent)), dropped
+	}
+
+	var metricsErr consumererror.PartialMetrics
+	if errors.As(err, &metricsErr) && metricsErr.GetPermanent().DataPointCount() > 0 {
+		permanent := metricsErr.GetPermanent()
+		dropped := permanent.DataPointCount()
+		var prevErr consumererror.PartialMetrics
+		if errors.As(prev, &prevErr) {
+			prevErr.GetPermanent().ResourceMetrics().MoveAndAppendTo(permanent.ResourceMetrics())
+		}
+		return consumererror.NewPermanent(consumererror.NewPartialMetrics(err, pdata.NewMetrics(), permanent)), dropped
+	}
+
+	var logsErr consumererror.PartialLogs
+	if errors.As(err, &logsErr) && logsErr.GetPermanent().LogRecordCount() > 0 {
+		permanent := logsErr.GetPermanent()
+		dropped := permanent.LogRecordCount()
+		var prevErr consumererror.PartialLogs
+		if errors.As(prev, &prevErr) {
+			prevErr.GetPermanent().ResourceLogs().MoveAndAppendTo(permanent.ResourceLogs())
+		}
+		return consumererror.NewPermanent(consumererror.NewPartialLogs(err, pdata.NewLogs(), permanent)), dropped
+	}
+
+	return prev, 0
+}
+
 // max returns the larger of x or y.
 func max(x, y time.Duration) time.Duration {
 	if x < y {
@@ -28,10 +28,16 @@ import (
 	"go.opencensus.io/metric/metricdata"
 	"go.opencensus.io/metric/metricproducer"
 	"go.opencensus.io/tag"
+	"go.uber.org/zap"
 
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenthelper"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/featuregate"
 	"go.opentelemetry.io/collector/internal/testdata"
 	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
@@ -44,6 +50,24 @@ func mockRequestUnmarshaler(mr *mockRequest) internal.RequestUnmarshaler {
 	}
 }
 
+func TestQueueSettings_ValidateStorage(t *testing.T) {
+	storageID := config.NewComponentID("file_storage")
+	qCfg := DefaultQueueSettings()
+	qCfg.StorageID = &storageID
+	assert.NoError(t, qCfg.Validate())
+
+	qCfg.QueueSizeBytes = 100
+	assert.ErrorIs(t, qCfg.Validate(), errQueueSizeBytesWithStorage)
+
+	qCfg.QueueSizeBytes = 0
+	qCfg.PriorityLanes = newPriorityLanes()
+	assert.ErrorIs(t, qCfg.Validate(), errPriorityLanesNotSupported)
+
+	qCfg.PriorityLanes = nil
+	qCfg.Partition = PartitionSettings{ResourceAttribute: "tenant", QueueSize: 100}
+	assert.ErrorIs(t, qCfg.Validate(), errPartitionNotSupported)
+}
+
 func TestQueuedRetry_DropOnPermanentError(t *testing.T) {
 	qCfg := DefaultQueueSettings()
 	rCfg := DefaultRetrySettings()
@@ -256,6 +280,39 @@ func TestQueuedRetry_ThrottleError(t *testing.T) {
 	require.Zero(t, be.qrSender.queue.Size())
 }
 
+func TestQueuedRetry_ThrottleErrorClampedToMaxInterval(t *testing.T) {
+	exporterCfg := config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "throttled"))
+	qCfg := DefaultQueueSettings()
+	qCfg.NumConsumers = 1
+	rCfg := DefaultRetrySettings()
+	rCfg.InitialInterval = 10 * time.Millisecond
+	rCfg.MaxInterval = 50 * time.Millisecond
+	be := newBaseExporter(&exporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	ocs := newObservabilityConsumerSender(be.qrSender.consumerSender)
+	be.qrSender.consumerSender = ocs
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, be.Shutdown(context.Background()))
+	})
+
+	// The backend asks to wait for an hour, but the delay is clamped to MaxInterval.
+	retry := NewThrottleRetry(errors.New("throttle error"), time.Hour)
+	mockR := newMockRequest(context.Background(), 2, retry)
+	start := time.Now()
+	ocs.run(func() {
+		// This is asynchronous so it should just enqueue, no errors expected.
+		require.NoError(t, be.sender.send(mockR))
+	})
+	ocs.awaitAsyncProcessing()
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	mockR.checkNumRequests(t, 2)
+	ocs.checkSendItemsCount(t, 2)
+	exporterTags := []tag.Tag{{Key: exporterTag, Value: exporterCfg.ID().String()}}
+	checkValueForGlobalManager(t, exporterTags, int64(1), "exporter/throttle_waits")
+	checkValueForGlobalManager(t, exporterTags, int64(50), "exporter/throttle_wait_time")
+}
+
 func TestQueuedRetry_RetryOnError(t *testing.T) {
 	qCfg := DefaultQueueSettings()
 	qCfg.NumConsumers = 1
@@ -352,6 +409,172 @@ func TestQueuedRetry_QueueMetricsReported(t *testing.T) {
 	checkValueForGlobalManager(t, defaultExporterTags, int64(0), "exporter/queue_size")
 }
 
+func TestQueuedRetry_QueueSizeBytes(t *testing.T) {
+	qCfg := DefaultQueueSettings()
+	qCfg.NumConsumers = 0 // to make every request go straight to the queue
+	qCfg.QueueSizeBytes = 20
+	rCfg := DefaultRetrySettings()
+	be := newBaseExporter(&defaultExporterCfg, componenttest.NewNopExporterCreateSettings(), fromOptions(WithRetry(rCfg), WithQueue(qCfg)), "", nopRequestUnmarshaler())
+	require.NoError(t, be.Start(context.Background(), componenttest.NewNopHost()))
+
+	// Every error request is 7 bytes, so only two of them fit into the queue.
+	require.NoError(t, be.sender.send(newErrorRequest(context.Background())))
+	require.NoError(t, be.sender.send(newErrorRequest(context.Background())))
+	assert.ErrorIs(t, be.sender.send(newErrorRequest(context.Background())), errSendingQueueIsFull)
+	checkValueForGlobalManager(t, defaultExporterTags, int64(2), "exporter/queue_size")
+	checkValueForGlobalManager(t, defaultExporterTags, int64(14), "exporter/queue_size_bytes")
+
+	assert.NoError(t, be.Shutdown(context.Background()))
+	checkValueForGlobalManager(t, defaultExporterTags, int64(0), "exporter/queue_size_bytes")
+}
+
+func TestMergePermanentFailures(t *testing.T) {
+	err, dropped := mergePermanentFailures(nil, errors.New("transient error"))
+	assert.NoError(t, err)
+	assert.Zero(t, dropped)
+
+	logsErr := consumererror.NewPartialLogs(errors.New("partial error"), pdata.NewLogs(), testdata.GenerateLogsOneLogRecord())
+	err, dropped = mergePermanentFailures(nil, logsErr)
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.Equal(t, 1, dropped)
+
+	logsErr = consumererror.NewPartialLogs(errors.New("partial error"), testdata.GenerateLogsOneLogRecord(), testdata.GenerateLogsTwoLogRecordsSameResource())
+	err, dropped = mergePermanentFailures(err, logsErr)
+	assert.Equal(t, 2, dropped)
+	var partialErr consumererror.PartialLogs
+	require.True(t, errors.As(err, &partialErr))
+	assert.Equal(t, 0, partialErr.GetRetryable().LogRecordCount())
+	assert.Equal(t, 3, partialErr.GetPermanent().LogRecordCount())
+
+	metricsErr := consumererror.NewPartialMetrics(errors.New("partial error"), testdata.GenerateMetricsOneMetric(), pdata.NewMetrics())
+	err, dropped = mergePermanentFailures(nil, metricsErr)
+	assert.NoError(t, err)
+	assert.Zero(t, dropped)
+}
+
+// enablePersistentQueueGate enables the persistent queue feature gate for the duration of the test.
+func enablePersistentQueueGate(t *testing.T) {
+	featuregate.Apply(map[string]bool{persistentQueueGateID: true})
+	t.Cleanup(func() {
+		featuregate.Apply(map[string]bool{persistentQueueGateID: false})
+	})
+}
+
+// newPersistentQueueHost returns a host with the given storage client provided by the storageID extension.
+func newPersistentQueueHost(storageID config.ComponentID, client *mockStorageClient) component.Host {
+	return &deadLetterHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			storageID: &mockStorageExtension{Component: componenthelper.New(), client: client},
+		},
+	}
+}
+
+func TestQueuedRetry_PersistentQueueRequiresFeatureGate(t *testing.T) {
+	storageID := config.NewComponentIDWithName("file_storage", "queue")
+	qCfg := DefaultQueueSettings()
+	qCfg.StorageID = &storageID
+	host := newPersistentQueueHost(storageID, &mockStorageClient{st: map[string][]byte{}})
+
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), newTraceDataPusher(nil), WithQueue(qCfg))
+	require.NoError(t, err)
+	assert.Error(t, te.Start(context.Background(), host))
+
+	enablePersistentQueueGate(t)
+	te, err = NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), newTraceDataPusher(nil), WithQueue(qCfg))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), host))
+	assert.NoError(t, te.Shutdown(context.Background()))
+}
+
+func TestGetStorageClient(t *testing.T) {
+	storageID := config.NewComponentIDWithName("file_storage", "queue")
+	client := &mockStorageClient{st: map[string][]byte{}}
+	host := &deadLetterHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			storageID:                    &mockStorageExtension{Component: componenthelper.New(), client: client},
+			config.NewComponentID("nop"): componenthelper.New(),
+		},
+	}
+
+	got, err := getStorageClient(context.Background(), host, storageID, fakeTracesExporterName, config.TracesDataType)
+	require.NoError(t, err)
+	assert.Equal(t, client, got)
+
+	_, err = getStorageClient(context.Background(), host, config.NewComponentID("missing"), fakeTracesExporterName, config.TracesDataType)
+	assert.ErrorIs(t, err, errNoStorageClient)
+
+	_, err = getStorageClient(context.Background(), host, config.NewComponentID("nop"), fakeTracesExporterName, config.TracesDataType)
+	assert.ErrorIs(t, err, errWrongExtensionType)
+}
+
+func TestQueuedRetry_PersistentQueueCrashRecovery(t *testing.T) {
+	enablePersistentQueueGate(t)
+	storageID := config.NewComponentIDWithName("file_storage", "queue")
+	qCfg := DefaultQueueSettings()
+	qCfg.NumConsumers = 2
+	qCfg.StorageID = &storageID
+	rCfg := DefaultRetrySettings()
+	rCfg.Enabled = false
+
+	// The backend hangs, so the collector is killed while exports are in flight.
+	client := &mockStorageClient{st: map[string][]byte{}}
+	unblock := make(chan struct{})
+	var inFlight int32
+	hangingPusher := func(context.Context, pdata.Traces) error {
+		atomic.AddInt32(&inFlight, 1)
+		<-unblock
+		return nil
+	}
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), hangingPusher, WithQueue(qCfg), WithRetry(rCfg))
+	require.NoError(t, err)
+	require.NoError(t, te.Start(context.Background(), newPersistentQueueHost(storageID, client)))
+	t.Cleanup(func() {
+		close(unblock)
+		assert.NoError(t, te.Shutdown(context.Background()))
+	})
+
+	const numRequests = 10
+	for i := 0; i < numRequests; i++ {
+		require.NoError(t, te.ConsumeTraces(context.Background(), testdata.GenerateTracesOneSpan()))
+	}
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&inFlight) == int32(qCfg.NumConsumers)
+	}, time.Second, 10*time.Millisecond)
+
+	// Killing the collector leaves the storage as it is at this point, without any shutdown.
+	client.mu.Lock()
+	crashed := &mockStorageClient{st: make(map[string][]byte, len(client.st))}
+	for k, v := range client.st {
+		crashed.st[k] = v
+	}
+	client.mu.Unlock()
+	pqi, err := NewPersistentQueueInspector(context.Background(), &mockStorageExtension{client: crashed}, fakeTracesExporterConfig.ID(), config.TracesDataType, zap.NewNop())
+	require.NoError(t, err)
+	items, err := pqi.Items(context.Background())
+	require.NoError(t, err)
+	require.Len(t, items, numRequests)
+	assert.True(t, items[0].Dispatched)
+	assert.True(t, items[1].Dispatched)
+
+	// After the restart, the requests being exported and the ones waiting in the queue are all exported.
+	sink := new(consumertest.TracesSink)
+	restarted, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeTraces, WithQueue(qCfg), WithRetry(rCfg))
+	require.NoError(t, err)
+	require.NoError(t, restarted.Start(context.Background(), newPersistentQueueHost(storageID, crashed)))
+	assert.Eventually(t, func() bool {
+		return sink.SpanCount() == numRequests
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, restarted.Shutdown(context.Background()))
+	assert.Equal(t, numRequests, sink.SpanCount())
+
+	// Nothing is left in the storage for a later restart.
+	items, err = pqi.Items(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
 func TestNoCancellationContext(t *testing.T) {
 	deadline := time.Now().Add(1 * time.Second)
 	ctx, cancelFunc := context.WithDeadline(context.Background(), deadline)
@@ -388,6 +611,16 @@ func (mer *mockErrorRequest) count() int {
 	return 7
 }
 
+func (mer *mockErrorRequest) bytesSize() int {
+	return 7
+}
+
+func (mer *mockErrorRequest) merge(request) {}
+
+func (mer *mockErrorRequest) split(int, int) request {
+	return mer
+}
+
 func newErrorRequest(ctx context.Context) request {
 	return &mockErrorRequest{
 		baseRequest: baseRequest{ctx: ctx},
@@ -438,6 +671,31 @@ func (m *mockRequest) count() int {
 	return m.cnt
 }
 
+func (m *mockRequest) bytesSize() int {
+	return m.cnt
+}
+
+func (m *mockRequest) merge(other request) {
+	m.cnt += other.count()
+}
+
+func (m *mockRequest) split(maxItems, maxBytes int) request {
+	// Every item of a mockRequest is one byte in size.
+	size := m.cnt
+	if maxItems > 0 && maxItems < size {
+		size = maxItems
+	}
+	if maxBytes > 0 && maxBytes < size {
+		size = maxBytes
+	}
+	m.cnt -= size
+	return &mockRequest{
+		baseRequest:  m.baseRequest,
+		cnt:          size,
+		requestCount: m.requestCount,
+	}
+}
+
 func newMockRequest(ctx context.Context, cnt int, consumeError error) *mockRequest {
 	return &mockRequest{
 		baseRequest:  baseRequest{ctx: ctx},
@@ -501,10 +759,13 @@ func checkValueForGlobalManager(t *testing.T, wantTags []tag.Tag, value int64, v
 // checkValueForProducer checks that the given metrics with wantTags is reported by the metric producer
 func checkValueForProducer(t *testing.T, producer metricproducer.Producer, wantTags []tag.Tag, value int64, vName string) bool {
 	for _, metric := range producer.Read() {
-		if metric.Descriptor.Name == vName && len(metric.TimeSeries) > 0 {
-			lastValue := metric.TimeSeries[len(metric.TimeSeries)-1]
-			if tagsMatchLabelKeys(wantTags, metric.Descriptor.LabelKeys, lastValue.LabelValues) {
-				require.Equal(t, value, lastValue.Points[len(lastValue.Points)-1].Value.(int64))
+		if metric.Descriptor.Name != vName {
+			continue
+		}
+		// Other exporters may report the same metric, so look for the time series with the wanted tags.
+		for _, ts := range metric.TimeSeries {
+			if tagsMatchLabelKeys(wantTags, metric.Descriptor.LabelKeys, ts.LabelValues) {
+				require.Equal(t, value, ts.Points[len(ts.Points)-1].Value.(int64))
 				return true
 			}
 		}
@@ -24,12 +24,14 @@ import (
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/consumerhelper"
 	"go.opentelemetry.io/collector/exporter/exporterhelper/internal"
+	"go.opentelemetry.io/collector/internal/batchutil"
 	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
 var tracesMarshaler = otlp.NewProtobufTracesMarshaler()
 var tracesUnmarshaler = otlp.NewProtobufTracesUnmarshaler()
+var tracesSizer = tracesMarshaler.(pdata.TracesSizer)
 
 type tracesRequest struct {
 	baseRequest
@@ -65,6 +67,10 @@ func (req *tracesRequest) onError(err error) request {
 	if errors.As(err, &traceError) {
 		return newTracesRequest(req.ctx, traceError.GetTraces(), req.pusher)
 	}
+	var partialErr consumererror.PartialTraces
+	if errors.As(err, &partialErr) {
+		return newTracesRequest(req.ctx, partialErr.GetRetryable(), req.pusher)
+	}
 	return req
 }
 
@@ -76,6 +82,18 @@ func (req *tracesRequest) count() int {
 	return req.td.SpanCount()
 }
 
+func (req *tracesRequest) bytesSize() int {
+	return tracesSizer.TracesSize(req.td)
+}
+
+func (req *tracesRequest) merge(other request) {
+	other.(*tracesRequest).td.ResourceSpans().MoveAndAppendTo(req.td.ResourceSpans())
+}
+
+func (req *tracesRequest) split(maxItems, maxBytes int) request {
+	return newTracesRequest(req.ctx, batchutil.SplitTracesBySize(maxItems, maxBytes, tracesSizer, req.td), req.pusher)
+}
+
 type traceExporter struct {
 	*baseExporter
 	consumer.Traces
@@ -102,6 +120,10 @@ func NewTracesExporter(
 	}
 
 	bs := fromOptions(options...)
+	transform, err := newTransformer(bs.TransformSettings)
+	if err != nil {
+		return nil, err
+	}
 	be := newBaseExporter(cfg, set, bs, config.TracesDataType, newTraceRequestUnmarshalerFunc(pusher))
 	be.wrapConsumerSender(func(nextSender requestSender) requestSender {
 		return &tracesExporterWithObservability{
@@ -111,6 +133,7 @@ func NewTracesExporter(
 	})
 
 	tc, err := consumerhelper.NewTraces(func(ctx context.Context, td pdata.Traces) error {
+		transform.traces(td)
 		req := newTracesRequest(ctx, td, pusher)
 		err := be.sender.send(req)
 		if errors.Is(err, errSendingQueueIsFull) {
@@ -17,6 +17,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -121,6 +122,32 @@ func TestTracesExporter_WithRecordMetrics_ReturnError(t *testing.T) {
 	checkRecordedMetricsForTracesExporter(t, te, want)
 }
 
+func TestTracesExporter_PartialRetry(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	defer tt.Shutdown(context.Background())
+
+	var pushed []int
+	pusher := func(ctx context.Context, td pdata.Traces) error {
+		pushed = append(pushed, td.SpanCount())
+		if len(pushed) == 1 {
+			// One span can be retried, two spans are permanently rejected.
+			return consumererror.NewPartialTraces(errors.New("partial error"), testdata.GenerateTracesOneSpan(), testdata.GenerateTracesTwoSpansSameResource())
+		}
+		return nil
+	}
+	rCfg := DefaultRetrySettings()
+	rCfg.InitialInterval = time.Millisecond
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, tt.ToExporterCreateSettings(), pusher, WithRetry(rCfg))
+	require.NoError(t, err)
+
+	err = te.ConsumeTraces(context.Background(), testdata.GenerateTracesManySpansSameResource(5))
+	require.Error(t, err)
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.Equal(t, []int{5, 1}, pushed)
+	require.NoError(t, obsreporttest.CheckExporterTraces(tt, fakeTracesExporterName, 3, 2))
+}
+
 func TestTracesExporter_WithRecordEnqueueFailedMetrics(t *testing.T) {
 	tt, err := obsreporttest.SetupTelemetry()
 	require.NoError(t, err)
@@ -0,0 +1,211 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper // import "go.opentelemetry.io/collector/exporter/exporterhelper"
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// Transform actions.
+const (
+	transformActionDelete = "delete"
+	transformActionRename = "rename"
+	transformActionUpsert = "upsert"
+)
+
+// Transform scopes.
+const (
+	transformScopeResource = "resource"
+	transformScopeItem     = "item"
+)
+
+// TransformSettings defines the transforms applied to the data of an exporter only, so they do not affect the
+// other exporters of the pipeline.
+type TransformSettings struct {
+	// Actions are the transforms, applied in order.
+	Actions []TransformActionSettings `mapstructure:"actions"`
+}
+
+// TransformActionSettings defines a transform of an attribute.
+type TransformActionSettings struct {
+	// Action is "delete" to remove the attribute, "rename" to move its value to NewKey, or "upsert" to set it to Value.
+	Action string `mapstructure:"action"`
+	// Scope is "resource" to transform the resource attributes, or "item" (the default) to transform the
+	// attributes of the spans, metric data points and log records.
+	Scope string `mapstructure:"scope"`
+	// Key is the key of the attribute.
+	Key string `mapstructure:"key"`
+	// NewKey is the key the attribute is renamed to by the "rename" action.
+	NewKey string `mapstructure:"new_key"`
+	// Value is the string value set by the "upsert" action.
+	Value string `mapstructure:"value"`
+}
+
+// Validate checks if the transform configuration is valid.
+func (cfg *TransformSettings) Validate() error {
+	for _, action := range cfg.Actions {
+		if action.Key == "" {
+			return errors.New("transform.actions: key must be set")
+		}
+		switch action.Action {
+		case transformActionDelete, transformActionUpsert:
+		case transformActionRename:
+			if action.NewKey == "" {
+				return fmt.Errorf("transform.actions: new_key of the rename of %q must be set", action.Key)
+			}
+		default:
+			return fmt.Errorf("transform.actions: invalid action %q of %q", action.Action, action.Key)
+		}
+		switch action.Scope {
+		case "", transformScopeResource, transformScopeItem:
+		default:
+			return fmt.Errorf("transform.actions: invalid scope %q of %q", action.Scope, action.Key)
+		}
+	}
+	return nil
+}
+
+// transformer applies the transforms to the data the exporter owns. A nil transformer does nothing.
+type transformer struct {
+	resourceActions []TransformActionSettings
+	itemActions     []TransformActionSettings
+}
+
+// newTransformer returns the transformer of the configuration, or nil if it has no transforms.
+func newTransformer(cfg TransformSettings) (*transformer, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if len(cfg.Actions) == 0 {
+		return nil, nil
+	}
+	// The transforms of different scopes never touch the same attributes, so their relative order does not matter.
+	t := &transformer{}
+	for _, action := range cfg.Actions {
+		if action.Scope == transformScopeResource {
+			t.resourceActions = append(t.resourceActions, action)
+		} else {
+			t.itemActions = append(t.itemActions, action)
+		}
+	}
+	return t, nil
+}
+
+func applyTransforms(actions []TransformActionSettings, attrs pdata.AttributeMap) {
+	for _, action := range actions {
+		switch action.Action {
+		case transformActionDelete:
+			attrs.Delete(action.Key)
+		case transformActionRename:
+			if v, ok := attrs.Get(action.Key); ok {
+				attrs.Upsert(action.NewKey, v)
+				attrs.Delete(action.Key)
+			}
+		case transformActionUpsert:
+			attrs.UpsertString(action.Key, action.Value)
+		}
+	}
+}
+
+func (t *transformer) traces(td pdata.Traces) {
+	if t == nil {
+		return
+	}
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		applyTransforms(t.resourceActions, rs.Resource().Attributes())
+		if len(t.itemActions) == 0 {
+			continue
+		}
+		ilss := rs.InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				applyTransforms(t.itemActions, spans.At(k).Attributes())
+			}
+		}
+	}
+}
+
+func (t *transformer) metrics(md pdata.Metrics) {
+	if t == nil {
+		return
+	}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		applyTransforms(t.resourceActions, rm.Resource().Attributes())
+		if len(t.itemActions) == 0 {
+			continue
+		}
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				t.metricDataPoints(metrics.At(k))
+			}
+		}
+	}
+}
+
+func (t *transformer) metricDataPoints(metric pdata.Metric) {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			applyTransforms(t.itemActions, dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			applyTransforms(t.itemActions, dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			applyTransforms(t.itemActions, dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			applyTransforms(t.itemActions, dps.At(i).Attributes())
+		}
+	}
+}
+
+func (t *transformer) logs(ld pdata.Logs) {
+	if t == nil {
+		return
+	}
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		applyTransforms(t.resourceActions, rl.Resource().Attributes())
+		if len(t.itemActions) == 0 {
+			continue
+		}
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				applyTransforms(t.itemActions, logs.At(k).Attributes())
+			}
+		}
+	}
+}
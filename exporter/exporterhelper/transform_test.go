@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exporterhelper
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestTransformSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		action TransformActionSettings
+		errMsg string
+	}{
+		{
+			name:   "valid",
+			action: TransformActionSettings{Action: "rename", Scope: "resource", Key: "a", NewKey: "b"},
+		},
+		{
+			name:   "missing key",
+			action: TransformActionSettings{Action: "delete"},
+			errMsg: "transform.actions: key must be set",
+		},
+		{
+			name:   "invalid action",
+			action: TransformActionSettings{Action: "insert", Key: "a"},
+			errMsg: `transform.actions: invalid action "insert" of "a"`,
+		},
+		{
+			name:   "missing new key",
+			action: TransformActionSettings{Action: "rename", Key: "a"},
+			errMsg: `transform.actions: new_key of the rename of "a" must be set`,
+		},
+		{
+			name:   "invalid scope",
+			action: TransformActionSettings{Action: "delete", Scope: "span", Key: "a"},
+			errMsg: `transform.actions: invalid scope "span" of "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := TransformSettings{Actions: []TransformActionSettings{tt.action}}
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func testTransformSettings() TransformSettings {
+	return TransformSettings{Actions: []TransformActionSettings{
+		{Action: "delete", Key: "secret"},
+		{Action: "rename", Key: "old", NewKey: "new"},
+		{Action: "upsert", Key: "env", Value: "prod"},
+		{Action: "upsert", Scope: "resource", Key: "env", Value: "resource-prod"},
+	}}
+}
+
+func fillTransformAttributes(attrs pdata.AttributeMap) {
+	attrs.InsertString("secret", "s")
+	attrs.InsertString("old", "v")
+	attrs.InsertString("env", "dev")
+}
+
+func assertTransformedAttributes(t *testing.T, attrs pdata.AttributeMap) {
+	assert.Equal(t, pdata.NewAttributeMap().InitFromMap(map[string]pdata.AttributeValue{
+		"new": pdata.NewAttributeValueString("v"),
+		"env": pdata.NewAttributeValueString("prod"),
+	}).Sort(), attrs.Sort())
+}
+
+func TestNewTransformer_Empty(t *testing.T) {
+	tr, err := newTransformer(TransformSettings{})
+	require.NoError(t, err)
+	assert.Nil(t, tr)
+	// A nil transformer does nothing.
+	tr.traces(pdata.NewTraces())
+	tr.metrics(pdata.NewMetrics())
+	tr.logs(pdata.NewLogs())
+}
+
+func TestTracesExporter_WithTransform(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeTraces, WithTransform(testTransformSettings()))
+	require.NoError(t, err)
+	assert.True(t, te.Capabilities().MutatesData)
+
+	td := pdata.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().InsertString("env", "dev")
+	fillTransformAttributes(rs.InstrumentationLibrarySpans().AppendEmpty().Spans().AppendEmpty().Attributes())
+	require.NoError(t, te.ConsumeTraces(context.Background(), td))
+
+	require.Len(t, sink.AllTraces(), 1)
+	got := sink.AllTraces()[0].ResourceSpans().At(0)
+	assert.Equal(t, "resource-prod", got.Resource().Attributes().AsRaw()["env"])
+	assertTransformedAttributes(t, got.InstrumentationLibrarySpans().At(0).Spans().At(0).Attributes())
+}
+
+func TestMetricsExporter_WithTransform(t *testing.T) {
+	sink := new(consumertest.MetricsSink)
+	me, err := NewMetricsExporter(&fakeMetricsExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeMetrics, WithTransform(testTransformSettings()))
+	require.NoError(t, err)
+	assert.True(t, me.Capabilities().MutatesData)
+
+	md := pdata.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("env", "dev")
+	metrics := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+	gauge := metrics.AppendEmpty()
+	gauge.SetDataType(pdata.MetricDataTypeGauge)
+	fillTransformAttributes(gauge.Gauge().DataPoints().AppendEmpty().Attributes())
+	sum := metrics.AppendEmpty()
+	sum.SetDataType(pdata.MetricDataTypeSum)
+	fillTransformAttributes(sum.Sum().DataPoints().AppendEmpty().Attributes())
+	histogram := metrics.AppendEmpty()
+	histogram.SetDataType(pdata.MetricDataTypeHistogram)
+	fillTransformAttributes(histogram.Histogram().DataPoints().AppendEmpty().Attributes())
+	summary := metrics.AppendEmpty()
+	summary.SetDataType(pdata.MetricDataTypeSummary)
+	fillTransformAttributes(summary.Summary().DataPoints().AppendEmpty().Attributes())
+	require.NoError(t, me.ConsumeMetrics(context.Background(), md))
+
+	require.Len(t, sink.AllMetrics(), 1)
+	got := sink.AllMetrics()[0].ResourceMetrics().At(0)
+	assert.Equal(t, "resource-prod", got.Resource().Attributes().AsRaw()["env"])
+	gotMetrics := got.InstrumentationLibraryMetrics().At(0).Metrics()
+	assertTransformedAttributes(t, gotMetrics.At(0).Gauge().DataPoints().At(0).Attributes())
+	assertTransformedAttributes(t, gotMetrics.At(1).Sum().DataPoints().At(0).Attributes())
+	assertTransformedAttributes(t, gotMetrics.At(2).Histogram().DataPoints().At(0).Attributes())
+	assertTransformedAttributes(t, gotMetrics.At(3).Summary().DataPoints().At(0).Attributes())
+}
+
+func TestLogsExporter_WithTransform(t *testing.T) {
+	sink := new(consumertest.LogsSink)
+	le, err := NewLogsExporter(&fakeLogsExporterConfig, componenttest.NewNopExporterCreateSettings(), sink.ConsumeLogs, WithTransform(testTransformSettings()))
+	require.NoError(t, err)
+	assert.True(t, le.Capabilities().MutatesData)
+
+	ld := pdata.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("env", "dev")
+	fillTransformAttributes(rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty().Attributes())
+	require.NoError(t, le.ConsumeLogs(context.Background(), ld))
+
+	require.Len(t, sink.AllLogs(), 1)
+	got := sink.AllLogs()[0].ResourceLogs().At(0)
+	assert.Equal(t, "resource-prod", got.Resource().Attributes().AsRaw()["env"])
+	assertTransformedAttributes(t, got.InstrumentationLibraryLogs().At(0).Logs().At(0).Attributes())
+}
+
+func TestTracesExporter_WithInvalidTransform(t *testing.T) {
+	te, err := NewTracesExporter(&fakeTracesExporterConfig, componenttest.NewNopExporterCreateSettings(), newTraceDataPusher(nil),
+		WithTransform(TransformSettings{Actions: []TransformActionSettings{{Action: "delete"}}}))
+	assert.EqualError(t, err, "transform.actions: key must be set")
+	assert.Nil(t, te)
+}
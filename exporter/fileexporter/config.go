@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter // import "go.opentelemetry.io/collector/exporter/fileexporter"
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	formatJSON  = "json"
+	formatProto = "proto"
+
+	compressionGzip = "gzip"
+	compressionZstd = "zstd"
+)
+
+// Config defines configuration for file exporter.
+type Config struct {
+	config.ExporterSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Path of the file to write to. The directory must exist.
+	Path string `mapstructure:"path"`
+
+	// Format is the encoding of the records, "json" (OTLP JSON, one record per line) or "proto"
+	// (OTLP protobuf, every record prefixed by its size as a 4 bytes big-endian integer).
+	// The "proto" format supports pipelines of a single data type only.
+	Format string `mapstructure:"format"`
+
+	// Compression of the files, "gzip" or "zstd". Empty means no compression.
+	Compression string `mapstructure:"compression"`
+
+	// Rotation defines when the file is rotated and how many rotated files are kept.
+	Rotation RotationSettings `mapstructure:"rotation"`
+}
+
+// RotationSettings defines when the file is rotated and how many rotated files are kept.
+type RotationSettings struct {
+	// MaxMegabytes is the maximum size of the file in megabytes before it is rotated. 0 means no size limit.
+	MaxMegabytes int `mapstructure:"max_megabytes"`
+
+	// Interval is the maximum time data is written to the file before it is rotated. 0 means no time limit.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// MaxBackups is the maximum number of rotated files kept, the oldest ones being removed. 0 means all
+	// the rotated files are kept.
+	MaxBackups int `mapstructure:"max_backups"`
+}
+
+var _ config.Exporter = (*Config)(nil)
+
+// Validate checks if the exporter configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Path == "" {
+		return errors.New("path must be non-empty")
+	}
+	switch cfg.Format {
+	case formatJSON, formatProto:
+	default:
+		return fmt.Errorf("format must be %q or %q, got %q", formatJSON, formatProto, cfg.Format)
+	}
+	switch cfg.Compression {
+	case "", compressionGzip, compressionZstd:
+	default:
+		return fmt.Errorf("compression must be empty, %q or %q, got %q", compressionGzip, compressionZstd, cfg.Compression)
+	}
+	if cfg.Rotation.MaxMegabytes < 0 || cfg.Rotation.Interval < 0 || cfg.Rotation.MaxBackups < 0 {
+		return errors.New("rotation settings must not be negative")
+	}
+	return nil
+}
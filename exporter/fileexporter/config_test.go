@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[typeStr] = factory
+	cfg, err := configtest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Exporters[config.NewComponentID(typeStr)]
+	assert.Equal(t, e0,
+		&Config{
+			ExporterSettings: config.NewExporterSettings(config.NewComponentID(typeStr)),
+			Path:             "./filename.json",
+			Format:           formatJSON,
+		})
+
+	e1 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "2")]
+	assert.Equal(t, e1,
+		&Config{
+			ExporterSettings: config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "2")),
+			Path:             "./filename.pb.zst",
+			Format:           formatProto,
+			Compression:      compressionZstd,
+			Rotation: RotationSettings{
+				MaxMegabytes: 10,
+				Interval:     time.Hour,
+				MaxBackups:   3,
+			},
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "missing path", modify: func(cfg *Config) { cfg.Path = "" }},
+		{name: "invalid format", modify: func(cfg *Config) { cfg.Format = "text" }},
+		{name: "invalid compression", modify: func(cfg *Config) { cfg.Compression = "lz4" }},
+		{name: "negative rotation", modify: func(cfg *Config) { cfg.Rotation.MaxBackups = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Path = "file.json"
+			assert.NoError(t, cfg.Validate())
+			tt.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
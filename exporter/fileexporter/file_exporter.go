@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter // import "go.opentelemetry.io/collector/exporter/fileexporter"
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/model/otlp"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// fileExporter writes the data of all the signals as records of the configured format to the same file.
+type fileExporter struct {
+	format string
+	// dataTypes are the data types of the pipelines the exporter is used in.
+	dataTypes        map[config.DataType]struct{}
+	writer           *fileWriter
+	tracesMarshaler  pdata.TracesMarshaler
+	metricsMarshaler pdata.MetricsMarshaler
+	logsMarshaler    pdata.LogsMarshaler
+}
+
+func newFileExporter(cfg *Config) *fileExporter {
+	fe := &fileExporter{
+		format:    cfg.Format,
+		dataTypes: make(map[config.DataType]struct{}),
+		writer:    newFileWriter(cfg.Path, cfg.Compression, cfg.Rotation),
+	}
+	if cfg.Format == formatProto {
+		fe.tracesMarshaler = otlp.NewProtobufTracesMarshaler()
+		fe.metricsMarshaler = otlp.NewProtobufMetricsMarshaler()
+		fe.logsMarshaler = otlp.NewProtobufLogsMarshaler()
+	} else {
+		fe.tracesMarshaler = otlp.NewJSONTracesMarshaler()
+		fe.metricsMarshaler = otlp.NewJSONMetricsMarshaler()
+		fe.logsMarshaler = otlp.NewJSONLogsMarshaler()
+	}
+	return fe
+}
+
+func (fe *fileExporter) pushTraces(_ context.Context, td pdata.Traces) error {
+	buf, err := fe.tracesMarshaler.MarshalTraces(td)
+	if err != nil {
+		return err
+	}
+	return fe.writer.write(fe.frame(buf))
+}
+
+func (fe *fileExporter) pushMetrics(_ context.Context, md pdata.Metrics) error {
+	buf, err := fe.metricsMarshaler.MarshalMetrics(md)
+	if err != nil {
+		return err
+	}
+	return fe.writer.write(fe.frame(buf))
+}
+
+func (fe *fileExporter) pushLogs(_ context.Context, ld pdata.Logs) error {
+	buf, err := fe.logsMarshaler.MarshalLogs(ld)
+	if err != nil {
+		return err
+	}
+	return fe.writer.write(fe.frame(buf))
+}
+
+// frame returns the record of the encoded data: JSON records are terminated by a new line, while protobuf
+// records, that may contain new lines, are prefixed by their size.
+func (fe *fileExporter) frame(buf []byte) []byte {
+	if fe.format == formatProto {
+		record := make([]byte, 4, 4+len(buf))
+		binary.BigEndian.PutUint32(record, uint32(len(buf)))
+		return append(record, buf...)
+	}
+	return append(buf, '\n')
+}
+
+// addDataType records that the exporter is used in pipelines of the given data type.
+func (fe *fileExporter) addDataType(dataType config.DataType) {
+	fe.dataTypes[dataType] = struct{}{}
+}
+
+// Start opens the file. Since nothing tells the signal of a protobuf record, it fails if the exporter
+// writes protobuf records of several data types.
+func (fe *fileExporter) Start(context.Context, component.Host) error {
+	if fe.format == formatProto && len(fe.dataTypes) > 1 {
+		dataTypes := make([]string, 0, len(fe.dataTypes))
+		for dataType := range fe.dataTypes {
+			dataTypes = append(dataTypes, string(dataType))
+		}
+		sort.Strings(dataTypes)
+		return fmt.Errorf("format %q supports pipelines of a single data type, the exporter is used in %s pipelines",
+			formatProto, strings.Join(dataTypes, ", "))
+	}
+	return fe.writer.open()
+}
+
+// Shutdown closes the file.
+func (fe *fileExporter) Shutdown(context.Context) error {
+	return fe.writer.close()
+}
@@ -0,0 +1,228 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/otlp"
+)
+
+// readRecords returns the records of the file written with the given format and compression.
+func readRecords(t *testing.T, path string, format string, compression string) [][]byte {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var r io.Reader = f
+	switch compression {
+	case compressionGzip:
+		gr, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		r = gr
+	case compressionZstd:
+		zr, err := zstd.NewReader(f)
+		require.NoError(t, err)
+		defer zr.Close()
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	var records [][]byte
+	if format == formatJSON {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(nil, len(data)+1)
+		for scanner.Scan() {
+			records = append(records, append([]byte(nil), scanner.Bytes()...))
+		}
+		require.NoError(t, scanner.Err())
+		return records
+	}
+	for len(data) > 0 {
+		require.GreaterOrEqual(t, len(data), 4)
+		size := int(binary.BigEndian.Uint32(data))
+		records = append(records, data[4:4+size])
+		data = data[4+size:]
+	}
+	return records
+}
+
+func TestFileExporter(t *testing.T) {
+	for _, compression := range []string{"", compressionGzip} {
+		t.Run(formatJSON+"_"+compression, func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.Path = filepath.Join(t.TempDir(), "data")
+			cfg.Compression = compression
+
+			ctx := context.Background()
+			set := componenttest.NewNopExporterCreateSettings()
+			te, err := factory.CreateTracesExporter(ctx, set, cfg)
+			require.NoError(t, err)
+			me, err := factory.CreateMetricsExporter(ctx, set, cfg)
+			require.NoError(t, err)
+			le, err := factory.CreateLogsExporter(ctx, set, cfg)
+			require.NoError(t, err)
+			host := componenttest.NewNopHost()
+			require.NoError(t, te.Start(ctx, host))
+			require.NoError(t, me.Start(ctx, host))
+			require.NoError(t, le.Start(ctx, host))
+
+			td := testdata.GenerateTracesTwoSpansSameResource()
+			md := testdata.GenerateMetricsTwoMetrics()
+			ld := testdata.GenerateLogsTwoLogRecordsSameResource()
+			require.NoError(t, te.ConsumeTraces(ctx, td))
+			require.NoError(t, me.ConsumeMetrics(ctx, md))
+			require.NoError(t, le.ConsumeLogs(ctx, ld))
+
+			require.NoError(t, te.Shutdown(ctx))
+			require.NoError(t, me.Shutdown(ctx))
+			require.NoError(t, le.Shutdown(ctx))
+
+			records := readRecords(t, cfg.Path, formatJSON, compression)
+			require.Len(t, records, 3)
+			gotTraces, err := otlp.NewJSONTracesUnmarshaler().UnmarshalTraces(records[0])
+			require.NoError(t, err)
+			assert.Equal(t, td, gotTraces)
+			gotMetrics, err := otlp.NewJSONMetricsUnmarshaler().UnmarshalMetrics(records[1])
+			require.NoError(t, err)
+			assert.Equal(t, md, gotMetrics)
+			gotLogs, err := otlp.NewJSONLogsUnmarshaler().UnmarshalLogs(records[2])
+			require.NoError(t, err)
+			assert.Equal(t, ld, gotLogs)
+		})
+	}
+}
+
+func TestFileExporter_Proto(t *testing.T) {
+	td := testdata.GenerateTracesTwoSpansSameResource()
+	md := testdata.GenerateMetricsTwoMetrics()
+	ld := testdata.GenerateLogsTwoLogRecordsSameResource()
+	tests := []struct {
+		name    string
+		create  func(ctx context.Context, factory component.ExporterFactory, cfg config.Exporter) (component.Exporter, error)
+		consume func(ctx context.Context, exp component.Exporter) error
+		check   func(t *testing.T, record []byte)
+	}{
+		{
+			name: "traces",
+			create: func(ctx context.Context, factory component.ExporterFactory, cfg config.Exporter) (component.Exporter, error) {
+				return factory.CreateTracesExporter(ctx, componenttest.NewNopExporterCreateSettings(), cfg)
+			},
+			consume: func(ctx context.Context, exp component.Exporter) error {
+				return exp.(component.TracesExporter).ConsumeTraces(ctx, td)
+			},
+			check: func(t *testing.T, record []byte) {
+				got, err := otlp.NewProtobufTracesUnmarshaler().UnmarshalTraces(record)
+				require.NoError(t, err)
+				assert.Equal(t, td, got)
+			},
+		},
+		{
+			name: "metrics",
+			create: func(ctx context.Context, factory component.ExporterFactory, cfg config.Exporter) (component.Exporter, error) {
+				return factory.CreateMetricsExporter(ctx, componenttest.NewNopExporterCreateSettings(), cfg)
+			},
+			consume: func(ctx context.Context, exp component.Exporter) error {
+				return exp.(component.MetricsExporter).ConsumeMetrics(ctx, md)
+			},
+			check: func(t *testing.T, record []byte) {
+				got, err := otlp.NewProtobufMetricsUnmarshaler().UnmarshalMetrics(record)
+				require.NoError(t, err)
+				assert.Equal(t, md, got)
+			},
+		},
+		{
+			name: "logs",
+			create: func(ctx context.Context, factory component.ExporterFactory, cfg config.Exporter) (component.Exporter, error) {
+				return factory.CreateLogsExporter(ctx, componenttest.NewNopExporterCreateSettings(), cfg)
+			},
+			consume: func(ctx context.Context, exp component.Exporter) error {
+				return exp.(component.LogsExporter).ConsumeLogs(ctx, ld)
+			},
+			check: func(t *testing.T, record []byte) {
+				got, err := otlp.NewProtobufLogsUnmarshaler().UnmarshalLogs(record)
+				require.NoError(t, err)
+				assert.Equal(t, ld, got)
+			},
+		},
+	}
+	for _, tt := range tests {
+		for _, compression := range []string{"", compressionZstd} {
+			t.Run(tt.name+"_"+compression, func(t *testing.T) {
+				factory := NewFactory()
+				cfg := factory.CreateDefaultConfig().(*Config)
+				cfg.Path = filepath.Join(t.TempDir(), "data")
+				cfg.Format = formatProto
+				cfg.Compression = compression
+
+				ctx := context.Background()
+				exp, err := tt.create(ctx, factory, cfg)
+				require.NoError(t, err)
+				require.NoError(t, exp.Start(ctx, componenttest.NewNopHost()))
+				require.NoError(t, tt.consume(ctx, exp))
+				require.NoError(t, exp.Shutdown(ctx))
+
+				records := readRecords(t, cfg.Path, formatProto, compression)
+				require.Len(t, records, 1)
+				tt.check(t, records[0])
+			})
+		}
+	}
+}
+
+func TestFileExporter_ProtoSeveralDataTypes(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Path = filepath.Join(t.TempDir(), "data")
+	cfg.Format = formatProto
+
+	ctx := context.Background()
+	set := componenttest.NewNopExporterCreateSettings()
+	te, err := factory.CreateTracesExporter(ctx, set, cfg)
+	require.NoError(t, err)
+	le, err := factory.CreateLogsExporter(ctx, set, cfg)
+	require.NoError(t, err)
+	assert.EqualError(t, te.Start(ctx, componenttest.NewNopHost()),
+		`format "proto" supports pipelines of a single data type, the exporter is used in logs, traces pipelines`)
+	require.NoError(t, te.Shutdown(ctx))
+	require.NoError(t, le.Shutdown(ctx))
+	_, err = os.Stat(cfg.Path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestFileExporter_NotStarted(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Path = filepath.Join(t.TempDir(), "data.json")
+	fe := newFileExporter(cfg)
+	assert.ErrorIs(t, fe.pushTraces(context.Background(), testdata.GenerateTracesOneSpan()), errNotOpen)
+}
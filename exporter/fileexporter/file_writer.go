@@ -0,0 +1,233 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter // import "go.opentelemetry.io/collector/exporter/fileexporter"
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"go.uber.org/multierr"
+)
+
+// backupTimeFormat is the format of the time of rotation in the name of the rotated files, which sorts
+// the rotated files by time when sorted by name.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+var errNotOpen = errors.New("file is not open")
+
+// compressor is a compressing writer whose compressed data must be flushed after every record, so the
+// written records can be read even if the collector is killed.
+type compressor interface {
+	io.Writer
+	Flush() error
+	Close() error
+}
+
+// countingWriter counts the bytes written to the file.
+type countingWriter struct {
+	w    io.Writer
+	size int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.size += int64(n)
+	return n, err
+}
+
+// fileWriter appends records to a file, rotating it when it reaches the configured size or age. The
+// rotated files are renamed with the time of rotation, e.g. "data-2021-11-04T10-30-00.000.json" for "data.json".
+type fileWriter struct {
+	path        string
+	compression string
+	rotation    RotationSettings
+	now         func() time.Time
+
+	mu         sync.Mutex
+	file       *os.File
+	counter    *countingWriter
+	compressor compressor
+	openedAt   time.Time
+}
+
+func newFileWriter(path string, compression string, rotation RotationSettings) *fileWriter {
+	return &fileWriter{
+		path:        path,
+		compression: compression,
+		rotation:    rotation,
+		now:         time.Now,
+	}
+}
+
+// open opens the file, appending to it if it already exists.
+func (fw *fileWriter) open() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.openLocked()
+}
+
+func (fw *fileWriter) openLocked() error {
+	file, err := os.OpenFile(fw.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	fw.file = file
+	fw.counter = &countingWriter{w: file, size: info.Size()}
+	fw.openedAt = fw.now()
+	// Compressed streams are appended to the existing data, which is valid since concatenated gzip members
+	// and zstd frames are decompressed as a single stream.
+	fw.compressor = nil
+	switch fw.compression {
+	case compressionGzip:
+		fw.compressor = gzip.NewWriter(fw.counter)
+	case compressionZstd:
+		enc, err := zstd.NewWriter(fw.counter, zstd.WithEncoderConcurrency(1))
+		if err != nil {
+			fw.file = nil
+			return multierr.Append(err, file.Close())
+		}
+		fw.compressor = enc
+	}
+	return nil
+}
+
+// write appends the record to the file, rotating the file first if needed.
+func (fw *fileWriter) write(record []byte) error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.file == nil {
+		return errNotOpen
+	}
+	if fw.shouldRotate(len(record)) {
+		if err := fw.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if fw.compressor == nil {
+		_, err := fw.counter.Write(record)
+		return err
+	}
+	if _, err := fw.compressor.Write(record); err != nil {
+		return err
+	}
+	return fw.compressor.Flush()
+}
+
+// shouldRotate returns true if writing a record of the given size would make the file exceed the maximum size,
+// or if the file has been written to for longer than the rotation interval. A file is never rotated while empty.
+func (fw *fileWriter) shouldRotate(recordSize int) bool {
+	if fw.counter.size == 0 {
+		return false
+	}
+	if fw.rotation.MaxMegabytes > 0 && fw.counter.size+int64(recordSize) > int64(fw.rotation.MaxMegabytes)*1024*1024 {
+		return true
+	}
+	return fw.rotation.Interval > 0 && fw.now().Sub(fw.openedAt) >= fw.rotation.Interval
+}
+
+// rotate renames the file with the time of rotation, opens a new file and removes the old rotated files.
+func (fw *fileWriter) rotate() error {
+	if err := fw.closeLocked(); err != nil {
+		return err
+	}
+	if err := os.Rename(fw.path, fw.backupPath(fw.now())); err != nil {
+		return err
+	}
+	if err := fw.openLocked(); err != nil {
+		return err
+	}
+	return fw.removeOldBackups()
+}
+
+// backupPath returns the path of the file rotated at the given time.
+func (fw *fileWriter) backupPath(t time.Time) string {
+	ext := filepath.Ext(fw.path)
+	return strings.TrimSuffix(fw.path, ext) + "-" + t.UTC().Format(backupTimeFormat) + ext
+}
+
+// backups returns the paths of the rotated files, from the oldest to the newest.
+func (fw *fileWriter) backups() ([]string, error) {
+	dir := filepath.Dir(fw.path)
+	ext := filepath.Ext(fw.path)
+	prefix := strings.TrimSuffix(filepath.Base(fw.path), ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)); err == nil {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// removeOldBackups removes the oldest rotated files exceeding the maximum number of backups.
+func (fw *fileWriter) removeOldBackups() error {
+	if fw.rotation.MaxBackups == 0 {
+		return nil
+	}
+	backups, err := fw.backups()
+	if err != nil || len(backups) <= fw.rotation.MaxBackups {
+		return err
+	}
+	var errs error
+	for _, backup := range backups[:len(backups)-fw.rotation.MaxBackups] {
+		errs = multierr.Append(errs, os.Remove(backup))
+	}
+	return errs
+}
+
+// close flushes and closes the file.
+func (fw *fileWriter) close() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	return fw.closeLocked()
+}
+
+func (fw *fileWriter) closeLocked() error {
+	if fw.file == nil {
+		return nil
+	}
+	var errs error
+	if fw.compressor != nil {
+		errs = multierr.Append(errs, fw.compressor.Close())
+	}
+	errs = multierr.Append(errs, fw.file.Close())
+	fw.file = nil
+	return errs
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fileexporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFileWriter returns a fileWriter of the "data.json" file in a temporary directory, whose clock is
+// advanced by a second every time it is read.
+func newTestFileWriter(t *testing.T, compression string, rotation RotationSettings) *fileWriter {
+	fw := newFileWriter(filepath.Join(t.TempDir(), "data.json"), compression, rotation)
+	now := time.Date(2021, 11, 4, 10, 30, 0, 0, time.UTC)
+	fw.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+	return fw
+}
+
+func TestFileWriter_NoRotation(t *testing.T) {
+	fw := newTestFileWriter(t, "", RotationSettings{})
+	require.NoError(t, fw.open())
+	require.NoError(t, fw.write([]byte("a\n")))
+	require.NoError(t, fw.write([]byte("b\n")))
+	require.NoError(t, fw.close())
+
+	// Reopening appends to the existing file.
+	require.NoError(t, fw.open())
+	require.NoError(t, fw.write([]byte("c\n")))
+	require.NoError(t, fw.close())
+
+	data, err := os.ReadFile(fw.path)
+	require.NoError(t, err)
+	assert.Equal(t, "a\nb\nc\n", string(data))
+	backups, err := fw.backups()
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestFileWriter_RotateBySize(t *testing.T) {
+	fw := newTestFileWriter(t, "", RotationSettings{MaxMegabytes: 1})
+	require.NoError(t, fw.open())
+	record := make([]byte, 600*1024)
+	require.NoError(t, fw.write(record))
+	require.NoError(t, fw.write(record))
+	require.NoError(t, fw.write(record))
+	require.NoError(t, fw.close())
+
+	backups, err := fw.backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	assert.Equal(t, filepath.Join(filepath.Dir(fw.path), "data-2021-11-04T10-30-02.000.json"), backups[0])
+	for _, path := range append(backups, fw.path) {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.EqualValues(t, len(record), info.Size())
+	}
+}
+
+func TestFileWriter_RotateByInterval(t *testing.T) {
+	// The clock advances by a second on every read, so the file is rotated every other write.
+	fw := newTestFileWriter(t, "", RotationSettings{Interval: 2 * time.Second})
+	require.NoError(t, fw.open())
+	for i := 0; i < 4; i++ {
+		require.NoError(t, fw.write([]byte("record\n")))
+	}
+	require.NoError(t, fw.close())
+
+	backups, err := fw.backups()
+	require.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestFileWriter_MaxBackups(t *testing.T) {
+	fw := newTestFileWriter(t, compressionGzip, RotationSettings{MaxMegabytes: 1, MaxBackups: 2})
+	// Files not written by the fileWriter are never removed.
+	other := filepath.Join(filepath.Dir(fw.path), "data-other.json")
+	require.NoError(t, os.WriteFile(other, nil, 0600))
+
+	require.NoError(t, fw.open())
+	for i := 0; i < 5; i++ {
+		// Force rotation on every write after the first one.
+		fw.counter.size += 1024 * 1024
+		require.NoError(t, fw.write([]byte("record\n")))
+	}
+	require.NoError(t, fw.close())
+
+	backups, err := fw.backups()
+	require.NoError(t, err)
+	assert.Len(t, backups, 2)
+	assert.FileExists(t, other)
+}
+
+func TestFileWriter_NotOpen(t *testing.T) {
+	fw := newTestFileWriter(t, "", RotationSettings{})
+	assert.ErrorIs(t, fw.write([]byte("a\n")), errNotOpen)
+	assert.NoError(t, fw.close())
+}
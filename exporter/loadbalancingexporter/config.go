@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter // import "go.opentelemetry.io/collector/exporter/loadbalancingexporter"
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+)
+
+// Config defines configuration for the load balancing exporter.
+type Config struct {
+	config.ExporterSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Protocol defines the exporters the data is sent to the backends with.
+	Protocol Protocol `mapstructure:"protocol"`
+
+	// Resolver resolves the endpoints of the backends.
+	Resolver otlpexporter.ResolverSettings `mapstructure:"resolver"`
+
+	// ResourceAttribute is the resource attribute whose value is hashed to route the metrics and logs. The
+	// resources without the attribute are routed as if its value was empty.
+	ResourceAttribute string `mapstructure:"resource_attribute"`
+}
+
+// Protocol defines the exporters the data is sent to the backends with.
+type Protocol struct {
+	// OTLP is the configuration of the OTLP exporters, one per endpoint. Their endpoint is set by the resolver.
+	OTLP otlpexporter.Config `mapstructure:"otlp"`
+}
+
+var _ config.Exporter = (*Config)(nil)
+
+// Validate checks if the exporter configuration is valid
+func (cfg *Config) Validate() error {
+	switch {
+	case cfg.Resolver.Static == nil && cfg.Resolver.File == nil:
+		return errors.New("resolver must have one of the static and file resolvers")
+	case cfg.Resolver.Static != nil && cfg.Resolver.File != nil:
+		return errors.New("resolver must have only one of the static and file resolvers")
+	case cfg.Resolver.Static != nil && len(cfg.Resolver.Static.Endpoints) == 0:
+		return errors.New("static resolver requires at least one endpoint")
+	case cfg.Resolver.File != nil && cfg.Resolver.File.Path == "":
+		return errors.New("file resolver requires a path")
+	case cfg.Resolver.File != nil && cfg.Resolver.File.Interval < 0:
+		return errors.New("file resolver interval must not be negative")
+	case cfg.ResourceAttribute == "":
+		return errors.New("resource_attribute must not be empty")
+	case cfg.Protocol.OTLP.Endpoint != "":
+		return errors.New("protocol otlp endpoint must not be set, the endpoints are set by the resolver")
+	case cfg.Protocol.OTLP.LoadBalancing.Resolver.Static != nil || cfg.Protocol.OTLP.LoadBalancing.Resolver.File != nil:
+		return errors.New("protocol otlp load_balancing must not be set")
+	}
+	return cfg.Protocol.OTLP.Validate()
+}
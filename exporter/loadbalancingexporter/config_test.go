@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[typeStr] = factory
+
+	cfg, err := configtest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := factory.CreateDefaultConfig().(*Config)
+	e0.Resolver.Static = &otlpexporter.StaticResolverSettings{Endpoints: []string{"backend-1:4317"}}
+	assert.Equal(t, e0, cfg.Exporters[config.NewComponentID(typeStr)])
+
+	e1 := factory.CreateDefaultConfig().(*Config)
+	e1.SetIDName("2")
+	e1.Protocol.OTLP.TimeoutSettings.Timeout = time.Second
+	e1.Protocol.OTLP.TLSSetting.Insecure = true
+	e1.Resolver.File = &otlpexporter.FileResolverSettings{Path: "/etc/otel/backends", Interval: 10 * time.Second}
+	e1.ResourceAttribute = "host.name"
+	assert.Equal(t, e1, cfg.Exporters[config.NewComponentIDWithName(typeStr, "2")])
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		errMsg string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *Config) {},
+		},
+		{
+			name:   "no resolver",
+			modify: func(cfg *Config) { cfg.Resolver.Static = nil },
+			errMsg: "resolver must have one of the static and file resolvers",
+		},
+		{
+			name: "both resolvers",
+			modify: func(cfg *Config) {
+				cfg.Resolver.File = &otlpexporter.FileResolverSettings{Path: "/etc/otel/backends"}
+			},
+			errMsg: "resolver must have only one of the static and file resolvers",
+		},
+		{
+			name:   "no static endpoints",
+			modify: func(cfg *Config) { cfg.Resolver.Static.Endpoints = nil },
+			errMsg: "static resolver requires at least one endpoint",
+		},
+		{
+			name: "no file path",
+			modify: func(cfg *Config) {
+				cfg.Resolver.Static = nil
+				cfg.Resolver.File = &otlpexporter.FileResolverSettings{}
+			},
+			errMsg: "file resolver requires a path",
+		},
+		{
+			name: "negative file interval",
+			modify: func(cfg *Config) {
+				cfg.Resolver.Static = nil
+				cfg.Resolver.File = &otlpexporter.FileResolverSettings{Path: "/etc/otel/backends", Interval: -time.Second}
+			},
+			errMsg: "file resolver interval must not be negative",
+		},
+		{
+			name:   "no resource attribute",
+			modify: func(cfg *Config) { cfg.ResourceAttribute = "" },
+			errMsg: "resource_attribute must not be empty",
+		},
+		{
+			name:   "otlp endpoint",
+			modify: func(cfg *Config) { cfg.Protocol.OTLP.Endpoint = "backend:4317" },
+			errMsg: "protocol otlp endpoint must not be set, the endpoints are set by the resolver",
+		},
+		{
+			name: "otlp load balancing",
+			modify: func(cfg *Config) {
+				cfg.Protocol.OTLP.LoadBalancing.Resolver.Static = &otlpexporter.StaticResolverSettings{Endpoints: []string{"backend:4317"}}
+			},
+			errMsg: "protocol otlp load_balancing must not be set",
+		},
+		{
+			name: "invalid queue priority lane",
+			modify: func(cfg *Config) {
+				cfg.Protocol.OTLP.QueueSettings.PriorityLanes = []exporterhelper.PriorityLaneSettings{{QueueSize: 10}}
+			},
+			errMsg: "sending_queue.priority_lanes: name must be set",
+		},
+		{
+			name: "invalid transform",
+			modify: func(cfg *Config) {
+				cfg.Protocol.OTLP.TransformSettings.Actions = []exporterhelper.TransformActionSettings{{Action: "delete"}}
+			},
+			errMsg: "transform.actions: key must be set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Resolver.Static = &otlpexporter.StaticResolverSettings{Endpoints: []string{"backend-1:4317"}}
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.errMsg)
+			}
+		})
+	}
+}
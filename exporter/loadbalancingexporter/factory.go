@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter // import "go.opentelemetry.io/collector/exporter/loadbalancingexporter"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "loadbalancing"
+
+	defaultResourceAttribute = "service.name"
+)
+
+// NewFactory creates a factory for the load balancing exporter.
+func NewFactory() component.ExporterFactory {
+	return exporterhelper.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		exporterhelper.WithTraces(createTracesExporter),
+		exporterhelper.WithMetrics(createMetricsExporter),
+		exporterhelper.WithLogs(createLogsExporter))
+}
+
+func createDefaultConfig() config.Exporter {
+	otlpCfg := otlpexporter.NewFactory().CreateDefaultConfig().(*otlpexporter.Config)
+	return &Config{
+		ExporterSettings:  config.NewExporterSettings(config.NewComponentID(typeStr)),
+		Protocol:          Protocol{OTLP: *otlpCfg},
+		ResourceAttribute: defaultResourceAttribute,
+	}
+}
+
+func createTracesExporter(_ context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.TracesExporter, error) {
+	lb := newLoadBalancer(cfg.(*Config), set, func(ctx context.Context, subCfg config.Exporter, subSet component.ExporterCreateSettings) (component.Exporter, error) {
+		return otlpexporter.NewFactory().CreateTracesExporter(ctx, subSet, subCfg)
+	})
+	return exporterhelper.NewTracesExporter(
+		cfg,
+		set,
+		lb.pushTraces,
+		exporterOptions(cfg.(*Config), lb)...,
+	)
+}
+
+func createMetricsExporter(_ context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.MetricsExporter, error) {
+	lb := newLoadBalancer(cfg.(*Config), set, func(ctx context.Context, subCfg config.Exporter, subSet component.ExporterCreateSettings) (component.Exporter, error) {
+		return otlpexporter.NewFactory().CreateMetricsExporter(ctx, subSet, subCfg)
+	})
+	return exporterhelper.NewMetricsExporter(
+		cfg,
+		set,
+		lb.pushMetrics,
+		exporterOptions(cfg.(*Config), lb)...,
+	)
+}
+
+func createLogsExporter(_ context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.LogsExporter, error) {
+	lb := newLoadBalancer(cfg.(*Config), set, func(ctx context.Context, subCfg config.Exporter, subSet component.ExporterCreateSettings) (component.Exporter, error) {
+		return otlpexporter.NewFactory().CreateLogsExporter(ctx, subSet, subCfg)
+	})
+	return exporterhelper.NewLogsExporter(
+		cfg,
+		set,
+		lb.pushLogs,
+		exporterOptions(cfg.(*Config), lb)...,
+	)
+}
+
+// exporterOptions returns the options of the load balancing exporter.
+func exporterOptions(cfg *Config, lb *loadBalancer) []exporterhelper.Option {
+	return []exporterhelper.Option{
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		// The OTLP exporters of the endpoints have their own timeout.
+		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
+		// The data is batched, queued and retried before being routed, so the queued data of a removed endpoint
+		// is routed to the remaining ones, and only the data of the failed endpoints is retried.
+		exporterhelper.WithRetry(cfg.Protocol.OTLP.RetrySettings),
+		exporterhelper.WithQueue(cfg.Protocol.OTLP.QueueSettings),
+		exporterhelper.WithBatch(cfg.Protocol.OTLP.BatchSettings),
+		exporterhelper.WithDeadLetter(cfg.Protocol.OTLP.DeadLetterSettings),
+		exporterhelper.WithStart(lb.start),
+		exporterhelper.WithShutdown(lb.shutdown),
+	}
+}
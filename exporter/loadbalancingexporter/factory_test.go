@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	cfg := NewFactory().CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configtest.CheckConfigStruct(cfg))
+	assert.Equal(t, defaultResourceAttribute, cfg.(*Config).ResourceAttribute)
+}
+
+func TestCreateExporters(t *testing.T) {
+	cfg := createTestConfig("backend:4317")
+	set := componenttest.NewNopExporterCreateSettings()
+	factory := NewFactory()
+
+	te, err := factory.CreateTracesExporter(context.Background(), set, cfg)
+	assert.NoError(t, err)
+	assert.NotNil(t, te)
+
+	me, err := factory.CreateMetricsExporter(context.Background(), set, cfg)
+	assert.NoError(t, err)
+	assert.NotNil(t, me)
+
+	le, err := factory.CreateLogsExporter(context.Background(), set, cfg)
+	assert.NoError(t, err)
+	assert.NotNil(t, le)
+}
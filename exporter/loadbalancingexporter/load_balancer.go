@@ -0,0 +1,275 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter // import "go.opentelemetry.io/collector/exporter/loadbalancingexporter"
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/internal/batchutil"
+	"go.opentelemetry.io/collector/internal/loadbalancing"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// createExporterFunc creates the exporter of an endpoint, of the data type of the load balancer.
+type createExporterFunc func(ctx context.Context, cfg config.Exporter, set component.ExporterCreateSettings) (component.Exporter, error)
+
+// loadBalancer manages one OTLP exporter per endpoint returned by the resolver, and routes the data to them
+// using a consistent hash ring so only the data of the added or removed endpoints moves when they change.
+// The exporters of the endpoints have no queue, retry, batching nor dead-letter destination: the load balancer
+// does all of them, so the queued data of a removed endpoint is routed to the remaining ones, and a failing
+// endpoint holds the read lock for the timeout of a single request at most.
+type loadBalancer struct {
+	cfg            *Config
+	set            component.ExporterCreateSettings
+	createExporter createExporterFunc
+	resolver       loadbalancing.Resolver
+	host           component.Host
+
+	mu        sync.RWMutex
+	exporters map[string]component.Exporter
+	ring      *loadbalancing.HashRing
+}
+
+func newLoadBalancer(cfg *Config, set component.ExporterCreateSettings, createExporter createExporterFunc) *loadBalancer {
+	resolverSettings := loadbalancing.ResolverSettings{Logger: set.Logger}
+	if cfg.Resolver.Static != nil {
+		resolverSettings.StaticEndpoints = cfg.Resolver.Static.Endpoints
+	}
+	if cfg.Resolver.File != nil {
+		resolverSettings.FilePath = cfg.Resolver.File.Path
+		resolverSettings.FileInterval = cfg.Resolver.File.Interval
+	}
+	return &loadBalancer{
+		cfg:            cfg,
+		set:            set,
+		createExporter: createExporter,
+		resolver:       loadbalancing.NewResolver(resolverSettings),
+		exporters:      map[string]component.Exporter{},
+		ring:           loadbalancing.NewHashRing(nil),
+	}
+}
+
+func (lb *loadBalancer) start(ctx context.Context, host component.Host) error {
+	lb.host = host
+	return lb.resolver.Start(ctx, lb.onChange)
+}
+
+func (lb *loadBalancer) shutdown(ctx context.Context) error {
+	err := lb.resolver.Shutdown(ctx)
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	for _, exp := range lb.exporters {
+		err = multierr.Append(err, exp.Shutdown(ctx))
+	}
+	lb.exporters = map[string]component.Exporter{}
+	lb.ring = loadbalancing.NewHashRing(nil)
+	return err
+}
+
+// onChange starts the exporters of the new endpoints and shuts down the exporters of the removed ones.
+func (lb *loadBalancer) onChange(endpoints []string) {
+	ctx := context.Background()
+	exporters := make(map[string]component.Exporter, len(endpoints))
+	lb.mu.RLock()
+	for _, endpoint := range endpoints {
+		if exp, ok := lb.exporters[endpoint]; ok {
+			exporters[endpoint] = exp
+		}
+	}
+	lb.mu.RUnlock()
+
+	for _, endpoint := range endpoints {
+		if _, ok := exporters[endpoint]; ok {
+			continue
+		}
+		exp, err := lb.startExporter(ctx, endpoint)
+		if err != nil {
+			lb.set.Logger.Error("Failed to start the exporter of the endpoint", zap.String("endpoint", endpoint), zap.Error(err))
+			continue
+		}
+		exporters[endpoint] = exp
+	}
+
+	resolved := make([]string, 0, len(exporters))
+	for _, endpoint := range endpoints {
+		if _, ok := exporters[endpoint]; ok {
+			resolved = append(resolved, endpoint)
+		}
+	}
+
+	lb.mu.Lock()
+	removed := lb.exporters
+	lb.exporters = exporters
+	lb.ring = loadbalancing.NewHashRing(resolved)
+	lb.mu.Unlock()
+
+	for endpoint, exp := range removed {
+		if _, ok := exporters[endpoint]; ok {
+			continue
+		}
+		// No data is being sent to the exporter anymore, since the data is sent with the read lock held, and
+		// it neither queues nor batches the data, so no data is lost.
+		if err := exp.Shutdown(ctx); err != nil {
+			lb.set.Logger.Warn("Failed to shut down the exporter of the removed endpoint", zap.String("endpoint", endpoint), zap.Error(err))
+		}
+	}
+	lb.set.Logger.Info("Load balancing endpoints updated", zap.Strings("endpoints", resolved))
+}
+
+func (lb *loadBalancer) startExporter(ctx context.Context, endpoint string) (component.Exporter, error) {
+	cfg := lb.cfg.Protocol.OTLP
+	cfg.ExporterSettings = config.NewExporterSettings(config.NewComponentIDWithName(cfg.ID().Type(), lb.cfg.ID().String()+"/"+endpoint))
+	cfg.Endpoint = endpoint
+	// The data is queued, batched and retried by the load balancer, and the send to a failing endpoint must
+	// not hold the read lock longer than the timeout.
+	cfg.QueueSettings.Enabled = false
+	cfg.RetrySettings.Enabled = false
+	cfg.BatchSettings.Enabled = false
+	cfg.DeadLetterSettings = exporterhelper.DeadLetterSettings{}
+	set := lb.set
+	set.Logger = set.Logger.With(zap.String("endpoint", endpoint))
+
+	exp, err := lb.createExporter(ctx, &cfg, set)
+	if err != nil {
+		return nil, err
+	}
+	if err = exp.Start(ctx, lb.host); err != nil {
+		return nil, err
+	}
+	return exp, nil
+}
+
+// endpoint returns the endpoint of the key. It must be called with the read lock held.
+func (lb *loadBalancer) endpoint(key []byte) string {
+	return lb.ring.Endpoint(key, nil)
+}
+
+// resourceKey returns the value of the routing resource attribute of the resource.
+func (lb *loadBalancer) resourceKey(res pdata.Resource) []byte {
+	if v, ok := res.Attributes().Get(lb.cfg.ResourceAttribute); ok {
+		return []byte(v.AsString())
+	}
+	return nil
+}
+
+func (lb *loadBalancer) pushTraces(ctx context.Context, td pdata.Traces) error {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+	if len(lb.exporters) == 0 {
+		return loadbalancing.ErrNoEndpoints
+	}
+	groups := batchutil.GroupTraces(td, func(span pdata.Span) string {
+		traceID := span.TraceID().Bytes()
+		return lb.endpoint(traceID[:])
+	})
+	var errs, permanentErrs error
+	failed := pdata.NewTraces()
+	rejected := pdata.NewTraces()
+	for endpoint, group := range groups {
+		err := lb.exporters[endpoint].(component.TracesExporter).ConsumeTraces(ctx, group)
+		switch {
+		case err == nil:
+		case consumererror.IsPermanent(err):
+			permanentErrs = multierr.Append(permanentErrs, err)
+			group.ResourceSpans().MoveAndAppendTo(rejected.ResourceSpans())
+		default:
+			errs = multierr.Append(errs, err)
+			group.ResourceSpans().MoveAndAppendTo(failed.ResourceSpans())
+		}
+	}
+	return partialError(errs, permanentErrs, func(err error) error {
+		return consumererror.NewPartialTraces(err, failed, rejected)
+	})
+}
+
+func (lb *loadBalancer) pushMetrics(ctx context.Context, md pdata.Metrics) error {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+	if len(lb.exporters) == 0 {
+		return loadbalancing.ErrNoEndpoints
+	}
+	groups := batchutil.GroupMetricsByResource(md, func(res pdata.Resource) string {
+		return lb.endpoint(lb.resourceKey(res))
+	})
+	var errs, permanentErrs error
+	failed := pdata.NewMetrics()
+	rejected := pdata.NewMetrics()
+	for endpoint, group := range groups {
+		err := lb.exporters[endpoint].(component.MetricsExporter).ConsumeMetrics(ctx, group)
+		switch {
+		case err == nil:
+		case consumererror.IsPermanent(err):
+			permanentErrs = multierr.Append(permanentErrs, err)
+			group.ResourceMetrics().MoveAndAppendTo(rejected.ResourceMetrics())
+		default:
+			errs = multierr.Append(errs, err)
+			group.ResourceMetrics().MoveAndAppendTo(failed.ResourceMetrics())
+		}
+	}
+	return partialError(errs, permanentErrs, func(err error) error {
+		return consumererror.NewPartialMetrics(err, failed, rejected)
+	})
+}
+
+func (lb *loadBalancer) pushLogs(ctx context.Context, ld pdata.Logs) error {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+	if len(lb.exporters) == 0 {
+		return loadbalancing.ErrNoEndpoints
+	}
+	groups := batchutil.GroupLogsByResource(ld, func(res pdata.Resource) string {
+		return lb.endpoint(lb.resourceKey(res))
+	})
+	var errs, permanentErrs error
+	failed := pdata.NewLogs()
+	rejected := pdata.NewLogs()
+	for endpoint, group := range groups {
+		err := lb.exporters[endpoint].(component.LogsExporter).ConsumeLogs(ctx, group)
+		switch {
+		case err == nil:
+		case consumererror.IsPermanent(err):
+			permanentErrs = multierr.Append(permanentErrs, err)
+			group.ResourceLogs().MoveAndAppendTo(rejected.ResourceLogs())
+		default:
+			errs = multierr.Append(errs, err)
+			group.ResourceLogs().MoveAndAppendTo(failed.ResourceLogs())
+		}
+	}
+	return partialError(errs, permanentErrs, func(err error) error {
+		return consumererror.NewPartialLogs(err, failed, rejected)
+	})
+}
+
+// partialError returns the error of the sends to the endpoints, newPartial reporting only the data of the
+// failed endpoints, so the data delivered to the other endpoints is neither retried nor reported as failed.
+func partialError(errs, permanentErrs error, newPartial func(err error) error) error {
+	switch {
+	case errs == nil && permanentErrs == nil:
+		return nil
+	case permanentErrs == nil:
+		return newPartial(errs)
+	}
+	// The permanent errors are flattened so the partial failure is not mistaken for a permanent one.
+	return newPartial(multierr.Append(errs, errors.New(permanentErrs.Error())))
+}
@@ -0,0 +1,462 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancingexporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenthelper"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+	"go.opentelemetry.io/collector/internal/loadbalancing"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// sinkExporter is the exporter of an endpoint, recording the data it is sent.
+type sinkExporter struct {
+	component.Component
+	consumertest.TracesSink
+	consumertest.MetricsSink
+	consumertest.LogsSink
+	// gate, if not nil, blocks the traces until it is closed, started being notified once they are received.
+	gate    chan struct{}
+	started chan struct{}
+	// err, if not nil, is returned instead of consuming the traces.
+	err error
+
+	mu                    sync.Mutex
+	shutdown              bool
+	consumedAfterShutdown bool
+}
+
+func (e *sinkExporter) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
+	if e.gate != nil {
+		e.started <- struct{}{}
+		<-e.gate
+	}
+	if e.err != nil {
+		return e.err
+	}
+	e.mu.Lock()
+	e.consumedAfterShutdown = e.consumedAfterShutdown || e.shutdown
+	e.mu.Unlock()
+	return e.TracesSink.ConsumeTraces(ctx, td)
+}
+
+func (e *sinkExporter) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (e *sinkExporter) isShutdown() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.shutdown
+}
+
+// sinkExporters creates the sinkExporter of the endpoints, keeping track of them by endpoint.
+type sinkExporters struct {
+	mu        sync.Mutex
+	exporters map[string]*sinkExporter
+	configs   map[string]*otlpexporter.Config
+	// gates are the gates of the exporters of the endpoints, if any.
+	gates map[string]chan struct{}
+	// errs are the errors returned by the exporters of the endpoints, if any.
+	errs map[string]error
+}
+
+func (s *sinkExporters) create(_ context.Context, cfg config.Exporter, _ component.ExporterCreateSettings) (component.Exporter, error) {
+	endpoint := cfg.(*otlpexporter.Config).Endpoint
+	exp := &sinkExporter{gate: s.gates[endpoint], started: make(chan struct{}, 1), err: s.errs[endpoint]}
+	exp.Component = componenthelper.New(componenthelper.WithShutdown(func(context.Context) error {
+		exp.mu.Lock()
+		defer exp.mu.Unlock()
+		exp.shutdown = true
+		return nil
+	}))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exporters[endpoint] = exp
+	s.configs[endpoint] = cfg.(*otlpexporter.Config)
+	return exp, nil
+}
+
+func (s *sinkExporters) get(endpoint string) *sinkExporter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.exporters[endpoint]
+}
+
+func createTestConfig(endpoints ...string) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Resolver.Static = &otlpexporter.StaticResolverSettings{Endpoints: endpoints}
+	return cfg
+}
+
+func startLoadBalancer(t *testing.T, cfg *Config) (*loadBalancer, *sinkExporters) {
+	sinks := &sinkExporters{exporters: map[string]*sinkExporter{}, configs: map[string]*otlpexporter.Config{}}
+	lb := newLoadBalancer(cfg, componenttest.NewNopExporterCreateSettings(), sinks.create)
+	require.NoError(t, lb.start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, lb.shutdown(context.Background()))
+	})
+	return lb, sinks
+}
+
+// generateTraces returns a trace with one span for every one of the given trace IDs.
+func generateTraces(traceIDs ...byte) pdata.Traces {
+	td := pdata.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().InstrumentationLibrarySpans().AppendEmpty().Spans()
+	for _, id := range traceIDs {
+		span := spans.AppendEmpty()
+		span.SetName("span")
+		span.SetTraceID(pdata.NewTraceID([16]byte{id, id, id}))
+	}
+	return td
+}
+
+// receivedTraceIDs returns the number of spans received by the exporter for every trace ID.
+func receivedTraceIDs(exp *sinkExporter) map[pdata.TraceID]int {
+	traceIDs := map[pdata.TraceID]int{}
+	for _, td := range exp.AllTraces() {
+		rss := td.ResourceSpans()
+		for i := 0; i < rss.Len(); i++ {
+			ilss := rss.At(i).InstrumentationLibrarySpans()
+			for j := 0; j < ilss.Len(); j++ {
+				spans := ilss.At(j).Spans()
+				for k := 0; k < spans.Len(); k++ {
+					traceIDs[spans.At(k).TraceID()]++
+				}
+			}
+		}
+	}
+	return traceIDs
+}
+
+func TestLoadBalancerTraceIDRouting(t *testing.T) {
+	lb, sinks := startLoadBalancer(t, createTestConfig("backend-1:4317", "backend-2:4317"))
+
+	var traceIDs []byte
+	for i := 0; i < 50; i++ {
+		traceIDs = append(traceIDs, byte(i))
+	}
+	require.NoError(t, lb.pushTraces(context.Background(), generateTraces(traceIDs...)))
+	require.NoError(t, lb.pushTraces(context.Background(), generateTraces(traceIDs...)))
+
+	// Both exporters got spans, and all the spans of a trace went to the same exporter.
+	received1 := receivedTraceIDs(sinks.get("backend-1:4317"))
+	received2 := receivedTraceIDs(sinks.get("backend-2:4317"))
+	assert.NotEmpty(t, received1)
+	assert.NotEmpty(t, received2)
+	assert.Equal(t, 50, len(received1)+len(received2))
+	for traceID, count := range received1 {
+		assert.Equal(t, 2, count)
+		assert.NotContains(t, received2, traceID)
+	}
+}
+
+func TestLoadBalancerPartialFailure(t *testing.T) {
+	cfg := createTestConfig("backend-1:4317", "backend-2:4317", "backend-3:4317")
+	sinks := &sinkExporters{
+		exporters: map[string]*sinkExporter{},
+		configs:   map[string]*otlpexporter.Config{},
+		errs: map[string]error{
+			"backend-2:4317": errors.New("unavailable"),
+			"backend-3:4317": consumererror.NewPermanent(errors.New("invalid")),
+		},
+	}
+	lb := newLoadBalancer(cfg, componenttest.NewNopExporterCreateSettings(), sinks.create)
+	require.NoError(t, lb.start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, lb.shutdown(context.Background()))
+	}()
+
+	// The exporters of the endpoints neither retry nor batch, so a failing endpoint does not hold the lock.
+	for _, endpointCfg := range sinks.configs {
+		assert.False(t, endpointCfg.RetrySettings.Enabled)
+		assert.False(t, endpointCfg.BatchSettings.Enabled)
+	}
+
+	var traceIDs []byte
+	for i := 0; i < 50; i++ {
+		traceIDs = append(traceIDs, byte(i))
+	}
+	err := lb.pushTraces(context.Background(), generateTraces(traceIDs...))
+
+	// Only the spans of the failed endpoints are reported, the retryable ones apart from the rejected ones.
+	require.Error(t, err)
+	assert.False(t, consumererror.IsPermanent(err))
+	var partialErr consumererror.PartialTraces
+	require.ErrorAs(t, err, &partialErr)
+	delivered := len(receivedTraceIDs(sinks.get("backend-1:4317")))
+	retryable := partialErr.GetRetryable().SpanCount()
+	permanent := partialErr.GetPermanent().SpanCount()
+	assert.Greater(t, delivered, 0)
+	assert.Greater(t, retryable, 0)
+	assert.Greater(t, permanent, 0)
+	assert.Equal(t, 50, delivered+retryable+permanent)
+}
+
+// generateMetrics returns metrics with one resource, and one metric, for every one of the service names.
+func generateMetrics(serviceNames ...string) pdata.Metrics {
+	md := pdata.NewMetrics()
+	for _, name := range serviceNames {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().InsertString("service.name", name)
+		metric := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName("metric")
+		metric.SetDataType(pdata.MetricDataTypeGauge)
+		metric.Gauge().DataPoints().AppendEmpty().SetIntVal(1)
+	}
+	return md
+}
+
+// generateLogs returns logs with one resource, and one log record, for every one of the service names.
+func generateLogs(serviceNames ...string) pdata.Logs {
+	ld := pdata.NewLogs()
+	for _, name := range serviceNames {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().InsertString("service.name", name)
+		rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty().SetName("log")
+	}
+	return ld
+}
+
+// receivedServiceNames returns the service names of the resources received by the exporter.
+func receivedServiceNames(exp *sinkExporter) map[string]bool {
+	names := map[string]bool{}
+	for _, md := range exp.AllMetrics() {
+		for i := 0; i < md.ResourceMetrics().Len(); i++ {
+			v, _ := md.ResourceMetrics().At(i).Resource().Attributes().Get("service.name")
+			names[v.StringVal()] = true
+		}
+	}
+	for _, ld := range exp.AllLogs() {
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			v, _ := ld.ResourceLogs().At(i).Resource().Attributes().Get("service.name")
+			names[v.StringVal()] = true
+		}
+	}
+	return names
+}
+
+func TestLoadBalancerResourceAttributeRouting(t *testing.T) {
+	lb, sinks := startLoadBalancer(t, createTestConfig("backend-1:4317", "backend-2:4317"))
+
+	var names []string
+	for i := 0; i < 20; i++ {
+		names = append(names, fmt.Sprintf("service-%d", i))
+	}
+	require.NoError(t, lb.pushMetrics(context.Background(), generateMetrics(names...)))
+	require.NoError(t, lb.pushLogs(context.Background(), generateLogs(names...)))
+
+	// The metrics and logs of a service went to the same exporter.
+	exp1 := sinks.get("backend-1:4317")
+	exp2 := sinks.get("backend-2:4317")
+	assert.Equal(t, 20, exp1.DataPointCount()+exp2.DataPointCount())
+	assert.Equal(t, 20, exp1.LogRecordCount()+exp2.LogRecordCount())
+	received1 := receivedServiceNames(exp1)
+	received2 := receivedServiceNames(exp2)
+	assert.NotEmpty(t, received1)
+	assert.NotEmpty(t, received2)
+	assert.Equal(t, 20, len(received1)+len(received2))
+	for name := range received1 {
+		assert.NotContains(t, received2, name)
+	}
+}
+
+func TestLoadBalancerRebalance(t *testing.T) {
+	lb, sinks := startLoadBalancer(t, createTestConfig("backend-1:4317", "backend-2:4317"))
+
+	endpoints := func() map[string]string {
+		lb.mu.RLock()
+		defer lb.mu.RUnlock()
+		keys := map[string]string{}
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			keys[key] = lb.endpoint([]byte(key))
+		}
+		return keys
+	}
+	before := endpoints()
+	exp2 := sinks.get("backend-2:4317")
+
+	// Only the keys moving to the added endpoint change of endpoint.
+	lb.onChange([]string{"backend-1:4317", "backend-2:4317", "backend-3:4317"})
+	after := endpoints()
+	moved := 0
+	for key, endpoint := range after {
+		if endpoint != before[key] {
+			assert.Equal(t, "backend-3:4317", endpoint)
+			moved++
+		}
+	}
+	assert.Greater(t, moved, 0)
+	assert.Same(t, exp2, sinks.get("backend-2:4317"))
+	assert.False(t, exp2.isShutdown())
+
+	// The keys of the removed endpoint move to the remaining ones, and its exporter is shut down.
+	lb.onChange([]string{"backend-1:4317", "backend-3:4317"})
+	for key, endpoint := range endpoints() {
+		if after[key] != "backend-2:4317" {
+			assert.Equal(t, after[key], endpoint)
+		}
+	}
+	assert.True(t, exp2.isShutdown())
+}
+
+func TestLoadBalancerRemovedEndpointQueuedData(t *testing.T) {
+	cfg := createTestConfig("backend-1:4317", "backend-2:4317")
+	cfg.Protocol.OTLP.QueueSettings.NumConsumers = 1
+	gate := make(chan struct{})
+	sinks := &sinkExporters{
+		exporters: map[string]*sinkExporter{},
+		configs:   map[string]*otlpexporter.Config{},
+		gates:     map[string]chan struct{}{"backend-1:4317": gate},
+	}
+	set := componenttest.NewNopExporterCreateSettings()
+	lb := newLoadBalancer(cfg, set, sinks.create)
+	exp, err := exporterhelper.NewTracesExporter(cfg, set, lb.pushTraces, exporterOptions(cfg, lb)...)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+
+	// The data is queued by the load balancer, not by the exporters of the endpoints.
+	assert.False(t, sinks.configs["backend-1:4317"].QueueSettings.Enabled)
+	assert.False(t, sinks.configs["backend-2:4317"].QueueSettings.Enabled)
+
+	var toBackend1, toBackend2 []byte
+	lb.mu.RLock()
+	for i := 0; i < 50; i++ {
+		traceID := pdata.NewTraceID([16]byte{byte(i), byte(i), byte(i)}).Bytes()
+		if lb.endpoint(traceID[:]) == "backend-1:4317" {
+			toBackend1 = append(toBackend1, byte(i))
+		} else {
+			toBackend2 = append(toBackend2, byte(i))
+		}
+	}
+	lb.mu.RUnlock()
+	require.NotEmpty(t, toBackend1)
+	require.NotEmpty(t, toBackend2)
+
+	// The single queue consumer is blocked sending to backend-1, the spans of backend-2 stay in the queue.
+	require.NoError(t, exp.ConsumeTraces(context.Background(), generateTraces(toBackend1...)))
+	exp1 := sinks.get("backend-1:4317")
+	<-exp1.started
+	for _, id := range toBackend2 {
+		require.NoError(t, exp.ConsumeTraces(context.Background(), generateTraces(id)))
+	}
+
+	// backend-2 is removed while its spans are queued, they are sent to backend-1 instead.
+	go func() {
+		// Let the removal wait for the blocked request.
+		time.Sleep(100 * time.Millisecond)
+		close(gate)
+		for range exp1.started {
+		}
+	}()
+	exp2 := sinks.get("backend-2:4317")
+	lb.onChange([]string{"backend-1:4317"})
+	assert.True(t, exp2.isShutdown())
+	assert.Eventually(t, func() bool {
+		return exp1.SpanCount() == 50
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, exp.Shutdown(context.Background()))
+	close(exp1.started)
+
+	assert.Zero(t, exp2.SpanCount())
+	assert.False(t, exp2.consumedAfterShutdown)
+}
+
+func TestLoadBalancerNoEndpoints(t *testing.T) {
+	sinks := &sinkExporters{exporters: map[string]*sinkExporter{}, configs: map[string]*otlpexporter.Config{}}
+	lb := newLoadBalancer(createTestConfig("backend:4317"), componenttest.NewNopExporterCreateSettings(), sinks.create)
+	assert.ErrorIs(t, lb.pushTraces(context.Background(), testdata.GenerateTracesOneSpan()), loadbalancing.ErrNoEndpoints)
+	assert.ErrorIs(t, lb.pushMetrics(context.Background(), testdata.GenerateMetricsOneMetric()), loadbalancing.ErrNoEndpoints)
+	assert.ErrorIs(t, lb.pushLogs(context.Background(), testdata.GenerateLogsOneLogRecord()), loadbalancing.ErrNoEndpoints)
+}
+
+// tracesReceiver counts the spans it receives.
+type tracesReceiver struct {
+	srv       *grpc.Server
+	addr      string
+	mu        sync.Mutex
+	spanCount int
+}
+
+func (r *tracesReceiver) Export(_ context.Context, req otlpgrpc.TracesRequest) (otlpgrpc.TracesResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spanCount += req.Traces().SpanCount()
+	return otlpgrpc.NewTracesResponse(), nil
+}
+
+func (r *tracesReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.spanCount
+}
+
+func startTracesReceiver(t *testing.T) *tracesReceiver {
+	ln, err := net.Listen("tcp", "localhost:")
+	require.NoError(t, err)
+	rcv := &tracesReceiver{srv: grpc.NewServer(), addr: ln.Addr().String()}
+	otlpgrpc.RegisterTracesServer(rcv.srv, rcv)
+	go func() {
+		_ = rcv.srv.Serve(ln)
+	}()
+	t.Cleanup(rcv.srv.Stop)
+	return rcv
+}
+
+func TestTracesExporterOTLP(t *testing.T) {
+	rcv1 := startTracesReceiver(t)
+	rcv2 := startTracesReceiver(t)
+	cfg := createTestConfig(rcv1.addr, rcv2.addr)
+	cfg.Protocol.OTLP.TLSSetting.Insecure = true
+	cfg.Protocol.OTLP.QueueSettings.Enabled = false
+	cfg.Protocol.OTLP.RetrySettings.Enabled = false
+
+	exp, err := NewFactory().CreateTracesExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+
+	var traceIDs []byte
+	for i := 0; i < 50; i++ {
+		traceIDs = append(traceIDs, byte(i))
+	}
+	require.NoError(t, exp.ConsumeTraces(context.Background(), generateTraces(traceIDs...)))
+	assert.NoError(t, exp.Shutdown(context.Background()))
+
+	assert.Greater(t, rcv1.count(), 0)
+	assert.Greater(t, rcv2.count(), 0)
+	assert.Equal(t, 50, rcv1.count()+rcv2.count())
+}
@@ -15,9 +15,23 @@
 package loggingexporter // import "go.opentelemetry.io/collector/exporter/loggingexporter"
 
 import (
+	"errors"
+	"fmt"
+
 	"go.opentelemetry.io/collector/config"
 )
 
+const (
+	modeText    = "text"
+	modeSummary = "summary"
+	modeJSON    = "json"
+
+	destinationLogger = "logger"
+	destinationStdout = "stdout"
+	destinationStderr = "stderr"
+	destinationFile   = "file"
+)
+
 // Config defines configuration for logging exporter.
 type Config struct {
 	config.ExporterSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
@@ -30,11 +44,49 @@ type Config struct {
 
 	// SamplingThereafter defines the sampling rate after the initial samples are logged.
 	SamplingThereafter int `mapstructure:"sampling_thereafter"`
+
+	// Mode defines how the pipeline data is written; options are text (the detailed text of every item),
+	// summary (one line per span, metric or log record) and json (OTLP JSON, one line per batch).
+	Mode string `mapstructure:"mode"`
+
+	// Attributes is the list of attributes appended to the lines of the summary mode, looked up in the
+	// span or log record attributes then in the resource attributes.
+	Attributes []string `mapstructure:"attributes"`
+
+	// Destination defines where the pipeline data is written; options are logger, stdout, stderr and file.
+	// The data is written to the logger only if the log level is debug, and to the other destinations whatever
+	// the log level.
+	Destination string `mapstructure:"destination"`
+
+	// Path is the path of the file the data is appended to, required by the file destination.
+	Path string `mapstructure:"path"`
 }
 
 var _ config.Exporter = (*Config)(nil)
 
 // Validate checks if the exporter configuration is valid
 func (cfg *Config) Validate() error {
+	switch cfg.Mode {
+	case modeText, modeJSON:
+		if len(cfg.Attributes) > 0 {
+			return fmt.Errorf("attributes are only supported by the %q mode", modeSummary)
+		}
+	case modeSummary:
+	default:
+		return fmt.Errorf("mode must be %q, %q or %q, got %q", modeText, modeSummary, modeJSON, cfg.Mode)
+	}
+	switch cfg.Destination {
+	case destinationLogger, destinationStdout, destinationStderr:
+		if cfg.Path != "" {
+			return fmt.Errorf("path is only supported by the %q destination", destinationFile)
+		}
+	case destinationFile:
+		if cfg.Path == "" {
+			return errors.New("path must be set for the file destination")
+		}
+	default:
+		return fmt.Errorf("destination must be %q, %q, %q or %q, got %q",
+			destinationLogger, destinationStdout, destinationStderr, destinationFile, cfg.Destination)
+	}
 	return nil
 }
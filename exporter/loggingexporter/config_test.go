@@ -47,5 +47,81 @@ func TestLoadConfig(t *testing.T) {
 			LogLevel:           "debug",
 			SamplingInitial:    10,
 			SamplingThereafter: 50,
+			Mode:               modeText,
+			Destination:        destinationLogger,
 		})
+
+	e2 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "3")]
+	assert.Equal(t, e2,
+		&Config{
+			ExporterSettings:   config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "3")),
+			LogLevel:           "info",
+			SamplingInitial:    defaultSamplingInitial,
+			SamplingThereafter: defaultSamplingThereafter,
+			Mode:               modeSummary,
+			Attributes:         []string{"service.name", "http.status_code"},
+			Destination:        destinationFile,
+			Path:               "/var/log/otelcol/spans.log",
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		errMsg string
+	}{
+		{
+			name:   "default",
+			modify: func(*Config) {},
+		},
+		{
+			name: "summary to stderr",
+			modify: func(cfg *Config) {
+				cfg.Mode = modeSummary
+				cfg.Attributes = []string{"service.name"}
+				cfg.Destination = destinationStderr
+			},
+		},
+		{
+			name:   "invalid mode",
+			modify: func(cfg *Config) { cfg.Mode = "yaml" },
+			errMsg: `mode must be "text", "summary" or "json", got "yaml"`,
+		},
+		{
+			name: "attributes without summary",
+			modify: func(cfg *Config) {
+				cfg.Mode = modeJSON
+				cfg.Attributes = []string{"service.name"}
+			},
+			errMsg: `attributes are only supported by the "summary" mode`,
+		},
+		{
+			name:   "invalid destination",
+			modify: func(cfg *Config) { cfg.Destination = "syslog" },
+			errMsg: `destination must be "logger", "stdout", "stderr" or "file", got "syslog"`,
+		},
+		{
+			name:   "file without path",
+			modify: func(cfg *Config) { cfg.Destination = destinationFile },
+			errMsg: "path must be set for the file destination",
+		},
+		{
+			name:   "path without file",
+			modify: func(cfg *Config) { cfg.Path = "/tmp/data.log" },
+			errMsg: `path is only supported by the "file" destination`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.errMsg)
+			}
+		})
+	}
 }
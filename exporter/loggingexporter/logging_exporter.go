@@ -15,23 +15,31 @@
 package loggingexporter // import "go.opentelemetry.io/collector/exporter/loggingexporter"
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"os"
 	"strings"
 
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 
 	"go.opentelemetry.io/collector/component"
-	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/collector/internal/otlptext"
+	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
 type loggingExporter struct {
 	logger           *zap.Logger
 	debug            bool
+	lines            bool
+	destination      string
+	path             string
+	out              io.Writer
+	file             *os.File
 	logsMarshaler    pdata.LogsMarshaler
 	metricsMarshaler pdata.MetricsMarshaler
 	tracesMarshaler  pdata.TracesMarshaler
@@ -40,7 +48,7 @@ type loggingExporter struct {
 func (s *loggingExporter) pushTraces(_ context.Context, td pdata.Traces) error {
 	s.logger.Info("TracesExporter", zap.Int("#spans", td.SpanCount()))
 
-	if !s.debug {
+	if !s.enabled() {
 		return nil
 	}
 
@@ -48,14 +56,13 @@ func (s *loggingExporter) pushTraces(_ context.Context, td pdata.Traces) error {
 	if err != nil {
 		return err
 	}
-	s.logger.Debug(string(buf))
-	return nil
+	return s.write(buf)
 }
 
 func (s *loggingExporter) pushMetrics(_ context.Context, md pdata.Metrics) error {
 	s.logger.Info("MetricsExporter", zap.Int("#metrics", md.MetricCount()))
 
-	if !s.debug {
+	if !s.enabled() {
 		return nil
 	}
 
@@ -63,14 +70,13 @@ func (s *loggingExporter) pushMetrics(_ context.Context, md pdata.Metrics) error
 	if err != nil {
 		return err
 	}
-	s.logger.Debug(string(buf))
-	return nil
+	return s.write(buf)
 }
 
 func (s *loggingExporter) pushLogs(_ context.Context, ld pdata.Logs) error {
 	s.logger.Info("LogsExporter", zap.Int("#logs", ld.LogRecordCount()))
 
-	if !s.debug {
+	if !s.enabled() {
 		return nil
 	}
 
@@ -78,26 +84,95 @@ func (s *loggingExporter) pushLogs(_ context.Context, ld pdata.Logs) error {
 	if err != nil {
 		return err
 	}
-	s.logger.Debug(string(buf))
+	return s.write(buf)
+}
+
+// enabled returns true if the pipeline data must be written: always for the stdout, stderr and file
+// destinations, only at debug level for the logger.
+func (s *loggingExporter) enabled() bool {
+	return s.destination != destinationLogger || s.debug
+}
+
+// write writes the marshaled data to the destination. The data is written to the stdout, stderr and file
+// destinations with a single write, so the data of concurrent pipelines is not interleaved.
+func (s *loggingExporter) write(buf []byte) error {
+	if len(buf) == 0 {
+		return nil
+	}
+	if s.out != nil {
+		if buf[len(buf)-1] != '\n' {
+			buf = append(buf, '\n')
+		}
+		_, err := s.out.Write(buf)
+		return err
+	}
+	if !s.lines {
+		s.logger.Debug(string(buf))
+		return nil
+	}
+	for _, line := range bytes.Split(bytes.TrimSuffix(buf, []byte("\n")), []byte("\n")) {
+		s.logger.Debug(string(line))
+	}
 	return nil
 }
 
-func newLoggingExporter(level string, logger *zap.Logger) *loggingExporter {
-	return &loggingExporter{
-		debug:            strings.ToLower(level) == "debug",
-		logger:           logger,
-		logsMarshaler:    otlptext.NewTextLogsMarshaler(),
-		metricsMarshaler: otlptext.NewTextMetricsMarshaler(),
-		tracesMarshaler:  otlptext.NewTextTracesMarshaler(),
+func (s *loggingExporter) start(context.Context, component.Host) error {
+	switch s.destination {
+	case destinationStdout:
+		s.out = os.Stdout
+	case destinationStderr:
+		s.out = os.Stderr
+	case destinationFile:
+		file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return err
+		}
+		s.file = file
+		s.out = file
+	}
+	return nil
+}
+
+func (s *loggingExporter) shutdown(ctx context.Context) error {
+	var err error
+	if s.file != nil {
+		err = s.file.Close()
+	}
+	return multierr.Append(err, loggerSync(s.logger)(ctx))
+}
+
+func newLoggingExporter(cfg *Config, logger *zap.Logger) *loggingExporter {
+	s := &loggingExporter{
+		debug:       strings.ToLower(cfg.LogLevel) == "debug",
+		destination: cfg.Destination,
+		path:        cfg.Path,
+		logger:      logger,
+	}
+	switch cfg.Mode {
+	case modeSummary:
+		s.lines = true
+		s.logsMarshaler = otlptext.NewSummaryLogsMarshaler(cfg.Attributes)
+		s.metricsMarshaler = otlptext.NewSummaryMetricsMarshaler(cfg.Attributes)
+		s.tracesMarshaler = otlptext.NewSummaryTracesMarshaler(cfg.Attributes)
+	case modeJSON:
+		s.lines = true
+		s.logsMarshaler = otlp.NewJSONLogsMarshaler()
+		s.metricsMarshaler = otlp.NewJSONMetricsMarshaler()
+		s.tracesMarshaler = otlp.NewJSONTracesMarshaler()
+	default:
+		s.logsMarshaler = otlptext.NewTextLogsMarshaler()
+		s.metricsMarshaler = otlptext.NewTextMetricsMarshaler()
+		s.tracesMarshaler = otlptext.NewTextTracesMarshaler()
 	}
+	return s
 }
 
 // newTracesExporter creates an exporter.TracesExporter that just drops the
-// received data and logs debugging messages.
-func newTracesExporter(config config.Exporter, level string, logger *zap.Logger, set component.ExporterCreateSettings) (component.TracesExporter, error) {
-	s := newLoggingExporter(level, logger)
+// received data and logs or writes debugging messages.
+func newTracesExporter(cfg *Config, logger *zap.Logger, set component.ExporterCreateSettings) (component.TracesExporter, error) {
+	s := newLoggingExporter(cfg, logger)
 	return exporterhelper.NewTracesExporter(
-		config,
+		cfg,
 		set,
 		s.pushTraces,
 		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
@@ -105,16 +180,17 @@ func newTracesExporter(config config.Exporter, level string, logger *zap.Logger,
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
 		exporterhelper.WithRetry(exporterhelper.RetrySettings{Enabled: false}),
 		exporterhelper.WithQueue(exporterhelper.QueueSettings{Enabled: false}),
-		exporterhelper.WithShutdown(loggerSync(logger)),
+		exporterhelper.WithStart(s.start),
+		exporterhelper.WithShutdown(s.shutdown),
 	)
 }
 
 // newMetricsExporter creates an exporter.MetricsExporter that just drops the
-// received data and logs debugging messages.
-func newMetricsExporter(config config.Exporter, level string, logger *zap.Logger, set component.ExporterCreateSettings) (component.MetricsExporter, error) {
-	s := newLoggingExporter(level, logger)
+// received data and logs or writes debugging messages.
+func newMetricsExporter(cfg *Config, logger *zap.Logger, set component.ExporterCreateSettings) (component.MetricsExporter, error) {
+	s := newLoggingExporter(cfg, logger)
 	return exporterhelper.NewMetricsExporter(
-		config,
+		cfg,
 		set,
 		s.pushMetrics,
 		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
@@ -122,16 +198,17 @@ func newMetricsExporter(config config.Exporter, level string, logger *zap.Logger
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
 		exporterhelper.WithRetry(exporterhelper.RetrySettings{Enabled: false}),
 		exporterhelper.WithQueue(exporterhelper.QueueSettings{Enabled: false}),
-		exporterhelper.WithShutdown(loggerSync(logger)),
+		exporterhelper.WithStart(s.start),
+		exporterhelper.WithShutdown(s.shutdown),
 	)
 }
 
 // newLogsExporter creates an exporter.LogsExporter that just drops the
-// received data and logs debugging messages.
-func newLogsExporter(config config.Exporter, level string, logger *zap.Logger, set component.ExporterCreateSettings) (component.LogsExporter, error) {
-	s := newLoggingExporter(level, logger)
+// received data and logs or writes debugging messages.
+func newLogsExporter(cfg *Config, logger *zap.Logger, set component.ExporterCreateSettings) (component.LogsExporter, error) {
+	s := newLoggingExporter(cfg, logger)
 	return exporterhelper.NewLogsExporter(
-		config,
+		cfg,
 		set,
 		s.pushLogs,
 		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
@@ -139,7 +216,8 @@ func newLogsExporter(config config.Exporter, level string, logger *zap.Logger, s
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
 		exporterhelper.WithRetry(exporterhelper.RetrySettings{Enabled: false}),
 		exporterhelper.WithQueue(exporterhelper.QueueSettings{Enabled: false}),
-		exporterhelper.WithShutdown(loggerSync(logger)),
+		exporterhelper.WithStart(s.start),
+		exporterhelper.WithShutdown(s.shutdown),
 	)
 }
 
@@ -16,20 +16,32 @@ package loggingexporter
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"go.opentelemetry.io/collector/component/componenttest"
-	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/internal/otlptext"
 	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
+func newDebugConfig(level string) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.LogLevel = level
+	return cfg
+}
+
 func TestLoggingTracesExporterNoErrors(t *testing.T) {
-	lte, err := newTracesExporter(&config.ExporterSettings{}, "Debug", zap.NewNop(), componenttest.NewNopExporterCreateSettings())
+	lte, err := newTracesExporter(newDebugConfig("Debug"), zap.NewNop(), componenttest.NewNopExporterCreateSettings())
 	require.NotNil(t, lte)
 	assert.NoError(t, err)
 
@@ -40,7 +52,7 @@ func TestLoggingTracesExporterNoErrors(t *testing.T) {
 }
 
 func TestLoggingMetricsExporterNoErrors(t *testing.T) {
-	lme, err := newMetricsExporter(&config.ExporterSettings{}, "DEBUG", zap.NewNop(), componenttest.NewNopExporterCreateSettings())
+	lme, err := newMetricsExporter(newDebugConfig("DEBUG"), zap.NewNop(), componenttest.NewNopExporterCreateSettings())
 	require.NotNil(t, lme)
 	assert.NoError(t, err)
 
@@ -53,7 +65,7 @@ func TestLoggingMetricsExporterNoErrors(t *testing.T) {
 }
 
 func TestLoggingLogsExporterNoErrors(t *testing.T) {
-	lle, err := newLogsExporter(&config.ExporterSettings{}, "debug", zap.NewNop(), componenttest.NewNopExporterCreateSettings())
+	lle, err := newLogsExporter(newDebugConfig("debug"), zap.NewNop(), componenttest.NewNopExporterCreateSettings())
 	require.NotNil(t, lle)
 	assert.NoError(t, err)
 
@@ -66,7 +78,7 @@ func TestLoggingLogsExporterNoErrors(t *testing.T) {
 }
 
 func TestLoggingExporterErrors(t *testing.T) {
-	le := newLoggingExporter("Debug", zap.NewNop())
+	le := newLoggingExporter(newDebugConfig("Debug"), zap.NewNop())
 	require.NotNil(t, le)
 
 	errWant := errors.New("my error")
@@ -78,6 +90,97 @@ func TestLoggingExporterErrors(t *testing.T) {
 	assert.Equal(t, errWant, le.pushLogs(context.Background(), pdata.NewLogs()))
 }
 
+func TestLoggingExporterSummaryToLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	cfg := newDebugConfig("debug")
+	cfg.Mode = modeSummary
+	cfg.Attributes = []string{"resource-attr"}
+	lte, err := newTracesExporter(cfg, zap.New(core), componenttest.NewNopExporterCreateSettings())
+	require.NoError(t, err)
+	require.NoError(t, lte.Start(context.Background(), componenttest.NewNopHost()))
+
+	td := testdata.GenerateTracesTwoSpansSameResource()
+	assert.NoError(t, lte.ConsumeTraces(context.Background(), td))
+	assert.NoError(t, lte.Shutdown(context.Background()))
+
+	// Every span is logged as a separate message.
+	buf, err := otlptext.NewSummaryTracesMarshaler(cfg.Attributes).MarshalTraces(td)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(buf), "\n"), "\n")
+	debugLogs := logs.FilterLevelExact(zapcore.DebugLevel).All()
+	require.Len(t, debugLogs, len(lines))
+	for i, line := range lines {
+		assert.Equal(t, line, debugLogs[i].Message)
+	}
+}
+
+func TestLoggingExporterNotDebugToLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	cfg := newDebugConfig("info")
+	cfg.Mode = modeJSON
+	lle, err := newLogsExporter(cfg, zap.New(core), componenttest.NewNopExporterCreateSettings())
+	require.NoError(t, err)
+	require.NoError(t, lle.Start(context.Background(), componenttest.NewNopHost()))
+	assert.NoError(t, lle.ConsumeLogs(context.Background(), testdata.GenerateLogsOneLogRecord()))
+	assert.NoError(t, lle.Shutdown(context.Background()))
+
+	assert.Equal(t, 1, logs.Len())
+	assert.Equal(t, 0, logs.FilterLevelExact(zapcore.DebugLevel).Len())
+}
+
+func TestLoggingExporterJSONToFile(t *testing.T) {
+	cfg := newDebugConfig("info")
+	cfg.Mode = modeJSON
+	cfg.Destination = destinationFile
+	cfg.Path = filepath.Join(t.TempDir(), "data.json")
+	lme, err := newMetricsExporter(cfg, zap.NewNop(), componenttest.NewNopExporterCreateSettings())
+	require.NoError(t, err)
+	require.NoError(t, lme.Start(context.Background(), componenttest.NewNopHost()))
+
+	// The data is written whatever the log level, one batch per line.
+	assert.NoError(t, lme.ConsumeMetrics(context.Background(), testdata.GenerateMetricsOneMetric()))
+	assert.NoError(t, lme.ConsumeMetrics(context.Background(), testdata.GenerateMetricsTwoMetrics()))
+	assert.NoError(t, lme.Shutdown(context.Background()))
+
+	buf, err := os.ReadFile(cfg.Path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(buf), "\n"), "\n")
+	require.Len(t, lines, 2)
+	for i, want := range []pdata.Metrics{testdata.GenerateMetricsOneMetric(), testdata.GenerateMetricsTwoMetrics()} {
+		got, err := otlp.NewJSONMetricsUnmarshaler().UnmarshalMetrics([]byte(lines[i]))
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestLoggingExporterSummaryToFile(t *testing.T) {
+	cfg := newDebugConfig("info")
+	cfg.Mode = modeSummary
+	cfg.Destination = destinationFile
+	cfg.Path = filepath.Join(t.TempDir(), "data.log")
+	require.NoError(t, os.WriteFile(cfg.Path, []byte("existing\n"), 0600))
+	lle, err := newLogsExporter(cfg, zap.NewNop(), componenttest.NewNopExporterCreateSettings())
+	require.NoError(t, err)
+	require.NoError(t, lle.Start(context.Background(), componenttest.NewNopHost()))
+	assert.NoError(t, lle.ConsumeLogs(context.Background(), testdata.GenerateLogsTwoLogRecordsSameResource()))
+	assert.NoError(t, lle.Shutdown(context.Background()))
+
+	want, err := otlptext.NewSummaryLogsMarshaler(nil).MarshalLogs(testdata.GenerateLogsTwoLogRecordsSameResource())
+	require.NoError(t, err)
+	buf, err := os.ReadFile(cfg.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "existing\n"+string(want), string(buf))
+}
+
+func TestLoggingExporterFileError(t *testing.T) {
+	cfg := newDebugConfig("info")
+	cfg.Destination = destinationFile
+	cfg.Path = filepath.Join(t.TempDir(), "missing", "data.log")
+	lte, err := newTracesExporter(cfg, zap.NewNop(), componenttest.NewNopExporterCreateSettings())
+	require.NoError(t, err)
+	assert.Error(t, lte.Start(context.Background(), componenttest.NewNopHost()))
+}
+
 type errMarshaler struct {
 	err error
 }
@@ -15,6 +15,10 @@
 package otlpexporter // import "go.opentelemetry.io/collector/exporter/otlpexporter"
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
@@ -22,17 +26,132 @@ import (
 
 // Config defines configuration for OpenCensus exporter.
 type Config struct {
-	config.ExporterSettings        `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
-	exporterhelper.TimeoutSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
-	exporterhelper.QueueSettings   `mapstructure:"sending_queue"`
-	exporterhelper.RetrySettings   `mapstructure:"retry_on_failure"`
+	config.ExporterSettings               `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	exporterhelper.TimeoutSettings        `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+	exporterhelper.QueueSettings          `mapstructure:"sending_queue"`
+	exporterhelper.RetrySettings          `mapstructure:"retry_on_failure"`
+	exporterhelper.BatchSettings          `mapstructure:"sending_batch"`
+	exporterhelper.CircuitBreakerSettings `mapstructure:"circuit_breaker"`
+	exporterhelper.DeadLetterSettings     `mapstructure:"dead_letter"`
+	exporterhelper.TransformSettings      `mapstructure:"transform"`
 
 	configgrpc.GRPCClientSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+
+	// LoadBalancing sends the data to several endpoints instead of the endpoint.
+	LoadBalancing LoadBalancingSettings `mapstructure:"load_balancing"`
+}
+
+const (
+	routingRoundRobin = "round_robin"
+	routingTraceID    = "trace_id"
+)
+
+// LoadBalancingSettings defines how the data is balanced across several endpoints. The settings of the
+// gRPC client, except the endpoint, apply to the connections to all the endpoints.
+type LoadBalancingSettings struct {
+	// Resolver resolves the endpoints to send the data to. Load balancing is enabled when a resolver is set.
+	Resolver ResolverSettings `mapstructure:"resolver"`
+
+	// Routing defines how a backend is picked for the data, round_robin or trace_id. With trace_id the spans
+	// of a trace are always sent to the same endpoint, as long as it is healthy, using consistent hashing;
+	// metrics and logs are always balanced round-robin.
+	Routing string `mapstructure:"routing"`
+
+	// Ejection temporarily removes the failing endpoints from the balancing.
+	Ejection EjectionSettings `mapstructure:"ejection"`
+}
+
+// ResolverSettings defines the resolver of the endpoints, exactly one must be set.
+type ResolverSettings struct {
+	// Static is a fixed list of endpoints.
+	Static *StaticResolverSettings `mapstructure:"static"`
+
+	// File is a file listing the endpoints, watched for changes.
+	File *FileResolverSettings `mapstructure:"file"`
+}
+
+// StaticResolverSettings defines a fixed list of endpoints.
+type StaticResolverSettings struct {
+	// Endpoints is the list of endpoints, with the syntax of the endpoint setting.
+	Endpoints []string `mapstructure:"endpoints"`
+}
+
+// FileResolverSettings defines a file listing the endpoints, one per line.
+type FileResolverSettings struct {
+	// Path is the path of the file. Empty lines and lines starting with # are ignored.
+	Path string `mapstructure:"path"`
+
+	// Interval is the interval at which the file is checked for changes, 5s if not set.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// EjectionSettings defines when the failing endpoints are ejected from the balancing.
+type EjectionSettings struct {
+	// ConsecutiveFailures is the number of consecutive failed requests, with a retryable error, after which an
+	// endpoint is ejected. 0 disables the ejection.
+	ConsecutiveFailures int `mapstructure:"consecutive_failures"`
+
+	// Duration is the time an endpoint is ejected for, after which it is tried again.
+	Duration time.Duration `mapstructure:"duration"`
+}
+
+func (lbs *LoadBalancingSettings) enabled() bool {
+	return lbs.Resolver.Static != nil || lbs.Resolver.File != nil
 }
 
 var _ config.Exporter = (*Config)(nil)
 
 // Validate checks if the exporter configuration is valid
 func (cfg *Config) Validate() error {
+	if err := cfg.TransformSettings.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.QueueSettings.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.BatchSettings.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.CircuitBreakerSettings.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.DeadLetterSettings.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.GRPCClientSettings.Validate(); err != nil {
+		return err
+	}
+	lbs := &cfg.LoadBalancing
+	if !lbs.enabled() {
+		return nil
+	}
+	if cfg.Endpoint != "" {
+		return errors.New("endpoint and load_balancing must not be both set")
+	}
+	if lbs.Resolver.Static != nil && lbs.Resolver.File != nil {
+		return errors.New("load_balancing must have only one of the static and file resolvers")
+	}
+	if lbs.Resolver.Static != nil && len(lbs.Resolver.Static.Endpoints) == 0 {
+		return errors.New("load_balancing static resolver requires at least one endpoint")
+	}
+	if lbs.Resolver.File != nil {
+		if lbs.Resolver.File.Path == "" {
+			return errors.New("load_balancing file resolver requires a path")
+		}
+		if lbs.Resolver.File.Interval < 0 {
+			return errors.New("load_balancing file resolver interval must not be negative")
+		}
+	}
+	switch lbs.Routing {
+	case routingRoundRobin, routingTraceID:
+	default:
+		return fmt.Errorf("load_balancing routing must be %q or %q, got %q", routingRoundRobin, routingTraceID, lbs.Routing)
+	}
+	if lbs.Ejection.ConsecutiveFailures < 0 {
+		return errors.New("load_balancing ejection consecutive_failures must not be negative")
+	}
+	if lbs.Ejection.ConsecutiveFailures > 0 && lbs.Ejection.Duration <= 0 {
+		return errors.New("load_balancing ejection duration must be positive")
+	}
 	return nil
 }
@@ -47,6 +47,8 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, e0, factory.CreateDefaultConfig())
 
 	e1 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "2")]
+	deadLetterStorageID := config.NewComponentIDWithName("file_storage", "deadletter")
+	queueStorageID := config.NewComponentIDWithName("file_storage", "queue")
 	assert.Equal(t, e1,
 		&Config{
 			ExporterSettings: config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "2")),
@@ -63,6 +65,34 @@ func TestLoadConfig(t *testing.T) {
 				Enabled:      true,
 				NumConsumers: 2,
 				QueueSize:    10,
+				StorageID:    &queueStorageID,
+				AdaptiveConcurrency: exporterhelper.AdaptiveConcurrencySettings{
+					Enabled:          true,
+					MinConsumers:     1,
+					MaxConsumers:     20,
+					LatencyThreshold: 2 * time.Second,
+				},
+			},
+			BatchSettings: exporterhelper.BatchSettings{
+				Enabled:               true,
+				Timeout:               time.Second,
+				SendBatchSize:         1000,
+				SendBatchMaxSize:      2000,
+				SendBatchMaxSizeBytes: 4 * 1024 * 1024,
+			},
+			CircuitBreakerSettings: exporterhelper.CircuitBreakerSettings{
+				Enabled:          true,
+				FailureThreshold: 3,
+				ProbeInterval:    30 * time.Second,
+			},
+			DeadLetterSettings: exporterhelper.DeadLetterSettings{
+				Storage: &deadLetterStorageID,
+			},
+			TransformSettings: exporterhelper.TransformSettings{
+				Actions: []exporterhelper.TransformActionSettings{
+					{Action: "delete", Key: "internal.debug"},
+					{Action: "upsert", Scope: "resource", Key: "deployment.environment", Value: "production"},
+				},
 			},
 			GRPCClientSettings: configgrpc.GRPCClientSettings{
 				Headers: map[string]string{
@@ -71,7 +101,7 @@ func TestLoadConfig(t *testing.T) {
 					"another":                "somevalue",
 				},
 				Endpoint:    "1.2.3.4:1234",
-				Compression: "on",
+				Compression: "gzip",
 				TLSSetting: configtls.TLSClientSetting{
 					TLSSetting: configtls.TLSSetting{
 						CAFile: "/var/lib/mycert.pem",
@@ -87,5 +117,182 @@ func TestLoadConfig(t *testing.T) {
 				BalancerName:    "round_robin",
 				Auth:            &configauth.Authentication{AuthenticatorID: config.NewComponentID("nop")},
 			},
+			LoadBalancing: LoadBalancingSettings{
+				Routing: routingRoundRobin,
+				Ejection: EjectionSettings{
+					ConsecutiveFailures: 5,
+					Duration:            30 * time.Second,
+				},
+			},
+		})
+
+	e2 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "3")].(*Config)
+	assert.Equal(t,
+		LoadBalancingSettings{
+			Resolver: ResolverSettings{
+				Static: &StaticResolverSettings{Endpoints: []string{"backend-1:4317", "backend-2:4317"}},
+			},
+			Routing: routingTraceID,
+			Ejection: EjectionSettings{
+				ConsecutiveFailures: 3,
+				Duration:            time.Minute,
+			},
+		}, e2.LoadBalancing)
+
+	e3 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "4")].(*Config)
+	assert.Equal(t,
+		LoadBalancingSettings{
+			Resolver: ResolverSettings{
+				File: &FileResolverSettings{Path: "/etc/otelcol/backends", Interval: 10 * time.Second},
+			},
+			Routing: routingRoundRobin,
+			Ejection: EjectionSettings{
+				ConsecutiveFailures: 5,
+				Duration:            30 * time.Second,
+			},
+		}, e3.LoadBalancing)
+}
+
+func TestValidateLoadBalancing(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		errMsg string
+	}{
+		{
+			name:   "disabled",
+			modify: func(cfg *Config) { cfg.Endpoint = "backend:4317" },
+		},
+		{
+			name: "static",
+			modify: func(cfg *Config) {
+				cfg.LoadBalancing.Resolver.Static = &StaticResolverSettings{Endpoints: []string{"backend:4317"}}
+			},
+		},
+		{
+			name: "invalid transform",
+			modify: func(cfg *Config) {
+				cfg.TransformSettings.Actions = []exporterhelper.TransformActionSettings{{Action: "delete"}}
+			},
+			errMsg: "transform.actions: key must be set",
+		},
+		{
+			name: "invalid priority lane",
+			modify: func(cfg *Config) {
+				cfg.Endpoint = "backend:4317"
+				cfg.QueueSettings.PriorityLanes = []exporterhelper.PriorityLaneSettings{{QueueSize: 10}}
+			},
+			errMsg: "sending_queue.priority_lanes: name must be set",
+		},
+		{
+			name: "invalid batch",
+			modify: func(cfg *Config) {
+				cfg.Endpoint = "backend:4317"
+				cfg.BatchSettings.Enabled = true
+				cfg.BatchSettings.SendBatchMaxSize = 100
+			},
+			errMsg: "sending_batch.send_batch_max_size must be greater or equal to send_batch_size",
+		},
+		{
+			name: "invalid circuit breaker",
+			modify: func(cfg *Config) {
+				cfg.Endpoint = "backend:4317"
+				cfg.CircuitBreakerSettings.Enabled = true
+				cfg.CircuitBreakerSettings.FailureThreshold = 0
+			},
+			errMsg: "circuit_breaker.failure_threshold must be positive",
+		},
+		{
+			name: "invalid dead letter",
+			modify: func(cfg *Config) {
+				cfg.Endpoint = "backend:4317"
+				id := config.NewComponentID("file_storage")
+				cfg.DeadLetterSettings.Exporter = &id
+				cfg.DeadLetterSettings.Storage = &id
+			},
+			errMsg: "only one of dead-letter exporter and storage can be configured",
+		},
+		{
+			name: "invalid compression level",
+			modify: func(cfg *Config) {
+				cfg.Endpoint = "backend:4317"
+				cfg.Compression = "zstd"
+				cfg.CompressionLevel = 23
+			},
+			errMsg: "zstd compression level must be between 1 and 22, got 23",
+		},
+		{
+			name: "with endpoint",
+			modify: func(cfg *Config) {
+				cfg.Endpoint = "backend:4317"
+				cfg.LoadBalancing.Resolver.Static = &StaticResolverSettings{Endpoints: []string{"backend:4317"}}
+			},
+			errMsg: "endpoint and load_balancing must not be both set",
+		},
+		{
+			name: "two resolvers",
+			modify: func(cfg *Config) {
+				cfg.LoadBalancing.Resolver.Static = &StaticResolverSettings{Endpoints: []string{"backend:4317"}}
+				cfg.LoadBalancing.Resolver.File = &FileResolverSettings{Path: "/etc/otelcol/backends"}
+			},
+			errMsg: "load_balancing must have only one of the static and file resolvers",
+		},
+		{
+			name: "no static endpoints",
+			modify: func(cfg *Config) {
+				cfg.LoadBalancing.Resolver.Static = &StaticResolverSettings{}
+			},
+			errMsg: "load_balancing static resolver requires at least one endpoint",
+		},
+		{
+			name: "no file path",
+			modify: func(cfg *Config) {
+				cfg.LoadBalancing.Resolver.File = &FileResolverSettings{}
+			},
+			errMsg: "load_balancing file resolver requires a path",
+		},
+		{
+			name: "negative file interval",
+			modify: func(cfg *Config) {
+				cfg.LoadBalancing.Resolver.File = &FileResolverSettings{Path: "/etc/otelcol/backends", Interval: -time.Second}
+			},
+			errMsg: "load_balancing file resolver interval must not be negative",
+		},
+		{
+			name: "invalid routing",
+			modify: func(cfg *Config) {
+				cfg.LoadBalancing.Resolver.Static = &StaticResolverSettings{Endpoints: []string{"backend:4317"}}
+				cfg.LoadBalancing.Routing = "random"
+			},
+			errMsg: `load_balancing routing must be "round_robin" or "trace_id", got "random"`,
+		},
+		{
+			name: "negative consecutive failures",
+			modify: func(cfg *Config) {
+				cfg.LoadBalancing.Resolver.Static = &StaticResolverSettings{Endpoints: []string{"backend:4317"}}
+				cfg.LoadBalancing.Ejection.ConsecutiveFailures = -1
+			},
+			errMsg: "load_balancing ejection consecutive_failures must not be negative",
+		},
+		{
+			name: "no ejection duration",
+			modify: func(cfg *Config) {
+				cfg.LoadBalancing.Resolver.Static = &StaticResolverSettings{Endpoints: []string{"backend:4317"}}
+				cfg.LoadBalancing.Ejection.Duration = 0
+			},
+			errMsg: "load_balancing ejection duration must be positive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.errMsg)
+			}
 		})
+	}
 }
@@ -0,0 +1,369 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpexporter // import "go.opentelemetry.io/collector/exporter/otlpexporter"
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/internal/batchutil"
+	"go.opentelemetry.io/collector/internal/loadbalancing"
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// backend is the connection to one of the endpoints of the load balancer.
+type backend struct {
+	endpoint       string
+	clientConn     *grpc.ClientConn
+	traceExporter  otlpgrpc.TracesClient
+	metricExporter otlpgrpc.MetricsClient
+	logExporter    otlpgrpc.LogsClient
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	ejectedUntil        time.Time
+	// refs is the number of sends in flight on the connection, which is closed once the backend is removed
+	// and refs drops to 0.
+	refs    int
+	removed bool
+}
+
+// loadBalancer sends the data to the endpoints returned by the resolver, skipping the ejected ones.
+type loadBalancer struct {
+	exp      *exporter
+	settings LoadBalancingSettings
+	resolver loadbalancing.Resolver
+	logger   *zap.Logger
+	host     component.Host
+	now      func() time.Time
+
+	mu       sync.RWMutex
+	backends map[string]*backend
+	// endpoints are the sorted endpoints of the backends.
+	endpoints []string
+	ring      *loadbalancing.HashRing
+	next      uint64
+}
+
+func newLoadBalancer(exp *exporter, logger *zap.Logger) *loadBalancer {
+	settings := exp.config.LoadBalancing
+	lb := &loadBalancer{
+		exp:      exp,
+		settings: settings,
+		logger:   logger,
+		now:      time.Now,
+		backends: map[string]*backend{},
+		ring:     loadbalancing.NewHashRing(nil),
+		resolver: loadbalancing.NewResolver(settings.Resolver.toResolverSettings(logger)),
+	}
+	return lb
+}
+
+// toResolverSettings returns the settings of the resolver of the endpoints.
+func (rs ResolverSettings) toResolverSettings(logger *zap.Logger) loadbalancing.ResolverSettings {
+	set := loadbalancing.ResolverSettings{Logger: logger}
+	if rs.Static != nil {
+		set.StaticEndpoints = rs.Static.Endpoints
+	}
+	if rs.File != nil {
+		set.FilePath = rs.File.Path
+		set.FileInterval = rs.File.Interval
+	}
+	return set
+}
+
+func (lb *loadBalancer) start(ctx context.Context, host component.Host) error {
+	lb.host = host
+	return lb.resolver.Start(ctx, lb.onChange)
+}
+
+func (lb *loadBalancer) shutdown(ctx context.Context) error {
+	err := lb.resolver.Shutdown(ctx)
+	lb.mu.Lock()
+	removed := lb.backends
+	lb.backends = map[string]*backend{}
+	lb.endpoints = nil
+	lb.ring = loadbalancing.NewHashRing(nil)
+	lb.mu.Unlock()
+	for _, b := range removed {
+		err = multierr.Append(err, lb.retire(b))
+	}
+	return err
+}
+
+// onChange connects to the new endpoints and disconnects from the removed ones.
+func (lb *loadBalancer) onChange(endpoints []string) {
+	backends := make(map[string]*backend, len(endpoints))
+	var added []string
+	lb.mu.RLock()
+	for _, endpoint := range endpoints {
+		if b, ok := lb.backends[endpoint]; ok {
+			backends[endpoint] = b
+		} else {
+			added = append(added, endpoint)
+		}
+	}
+	lb.mu.RUnlock()
+
+	for _, endpoint := range added {
+		b, err := lb.dial(endpoint)
+		if err != nil {
+			lb.logger.Error("Failed to connect to the endpoint", zap.String("endpoint", endpoint), zap.Error(err))
+			continue
+		}
+		backends[endpoint] = b
+	}
+
+	resolved := make([]string, 0, len(backends))
+	for _, endpoint := range endpoints {
+		if _, ok := backends[endpoint]; ok {
+			resolved = append(resolved, endpoint)
+		}
+	}
+
+	lb.mu.Lock()
+	removed := lb.backends
+	lb.backends = backends
+	lb.endpoints = resolved
+	lb.ring = loadbalancing.NewHashRing(resolved)
+	lb.mu.Unlock()
+
+	for endpoint, b := range removed {
+		if _, ok := backends[endpoint]; ok {
+			continue
+		}
+		if err := lb.retire(b); err != nil {
+			lb.logger.Warn("Failed to close the connection to the removed endpoint", zap.String("endpoint", endpoint), zap.Error(err))
+		}
+	}
+	lb.logger.Info("Load balancing endpoints updated", zap.Strings("endpoints", resolved))
+}
+
+func (lb *loadBalancer) dial(endpoint string) (*backend, error) {
+	settings := lb.exp.config.GRPCClientSettings
+	settings.Endpoint = endpoint
+	dialOpts, err := settings.ToDialOptions(lb.host)
+	if err != nil {
+		return nil, err
+	}
+	clientConn, err := grpc.Dial(settings.SanitizedEndpoint(), dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &backend{
+		endpoint:       endpoint,
+		clientConn:     clientConn,
+		traceExporter:  otlpgrpc.NewTracesClient(clientConn),
+		metricExporter: otlpgrpc.NewMetricsClient(clientConn),
+		logExporter:    otlpgrpc.NewLogsClient(clientConn),
+	}, nil
+}
+
+// acquire marks a send in flight on the backend. It must be called with the read lock of the load balancer
+// held, so the backend cannot be removed in between.
+func (b *backend) acquire() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refs++
+}
+
+// release marks the end of a send on the backend, closing its connection if it was removed meanwhile.
+func (lb *loadBalancer) release(b *backend) {
+	b.mu.Lock()
+	b.refs--
+	closeConn := b.removed && b.refs == 0
+	b.mu.Unlock()
+	if !closeConn {
+		return
+	}
+	if err := b.clientConn.Close(); err != nil {
+		lb.logger.Warn("Failed to close the connection to the removed endpoint", zap.String("endpoint", b.endpoint), zap.Error(err))
+	}
+}
+
+// retire closes the connection of a backend removed from the load balancer, or lets the last send in flight
+// on it close the connection.
+func (lb *loadBalancer) retire(b *backend) error {
+	b.mu.Lock()
+	b.removed = true
+	closeConn := b.refs == 0
+	b.mu.Unlock()
+	if !closeConn {
+		return nil
+	}
+	return b.clientConn.Close()
+}
+
+// ejected returns true if the backend is ejected from the balancing.
+func (b *backend) ejected(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return now.Before(b.ejectedUntil)
+}
+
+// report records the result of a request sent to the backend, ejecting it after too many consecutive failures.
+func (lb *loadBalancer) report(b *backend, err error) {
+	if lb.settings.Ejection.ConsecutiveFailures == 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil || !shouldRetry(status.Code(err)) {
+		b.consecutiveFailures = 0
+		return
+	}
+	b.consecutiveFailures++
+	if b.consecutiveFailures >= lb.settings.Ejection.ConsecutiveFailures {
+		b.consecutiveFailures = 0
+		b.ejectedUntil = lb.now().Add(lb.settings.Ejection.Duration)
+		lb.logger.Warn("Ejecting the failing endpoint from the load balancing",
+			zap.String("endpoint", b.endpoint), zap.Duration("duration", lb.settings.Ejection.Duration), zap.Error(err))
+	}
+}
+
+// pick returns the next backend in round-robin order, skipping the ejected ones unless all of them are. The
+// backend must be released once the data is sent.
+func (lb *loadBalancer) pick() (*backend, error) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+	if len(lb.endpoints) == 0 {
+		return nil, loadbalancing.ErrNoEndpoints
+	}
+	now := lb.now()
+	start := atomic.AddUint64(&lb.next, 1)
+	b := lb.backends[lb.endpoints[start%uint64(len(lb.endpoints))]]
+	for i := 0; i < len(lb.endpoints); i++ {
+		if candidate := lb.backends[lb.endpoints[(start+uint64(i))%uint64(len(lb.endpoints))]]; !candidate.ejected(now) {
+			b = candidate
+			break
+		}
+	}
+	b.acquire()
+	return b, nil
+}
+
+func (lb *loadBalancer) pushTraces(ctx context.Context, td pdata.Traces) error {
+	if lb.settings.Routing != routingTraceID {
+		b, err := lb.pick()
+		if err != nil {
+			return err
+		}
+		defer lb.release(b)
+		return lb.sendTraces(ctx, b, td)
+	}
+
+	lb.mu.RLock()
+	if len(lb.endpoints) == 0 {
+		lb.mu.RUnlock()
+		return loadbalancing.ErrNoEndpoints
+	}
+	now := lb.now()
+	backends := lb.backends
+	ring := lb.ring
+	for _, b := range backends {
+		b.acquire()
+	}
+	lb.mu.RUnlock()
+	defer func() {
+		for _, b := range backends {
+			lb.release(b)
+		}
+	}()
+
+	skip := func(endpoint string) bool {
+		return backends[endpoint].ejected(now)
+	}
+	groups := batchutil.GroupTraces(td, func(span pdata.Span) string {
+		traceID := span.TraceID().Bytes()
+		return ring.Endpoint(traceID[:], skip)
+	})
+	var errs, permanentErrs error
+	delivered := 0
+	failed := pdata.NewTraces()
+	rejected := pdata.NewTraces()
+	for endpoint, group := range groups {
+		err := lb.sendTraces(ctx, backends[endpoint], group)
+		switch {
+		case err == nil:
+			delivered++
+		case consumererror.IsPermanent(err):
+			permanentErrs = multierr.Append(permanentErrs, err)
+			group.ResourceSpans().MoveAndAppendTo(rejected.ResourceSpans())
+		default:
+			errs = multierr.Append(errs, err)
+			group.ResourceSpans().MoveAndAppendTo(failed.ResourceSpans())
+		}
+	}
+	switch {
+	case errs == nil && permanentErrs == nil:
+		return nil
+	case errs == nil && delivered == 0:
+		// Nothing was delivered nor can be retried.
+		return permanentErrs
+	case len(groups) == 1:
+		return errs
+	case permanentErrs == nil:
+		// Only the spans of the failed endpoints are retried.
+		return consumererror.NewTraces(errs, failed)
+	}
+	// Only the spans of the endpoints failing with a retryable error are retried, the rejected ones are dropped,
+	// and the delivered ones are not reported as failed. The permanent errors are flattened so the partial failure is not mistaken for a permanent one.
+	return consumererror.NewPartialTraces(multierr.Append(errs, errors.New(permanentErrs.Error())), failed, rejected)
+}
+
+func (lb *loadBalancer) sendTraces(ctx context.Context, b *backend, td pdata.Traces) error {
+	req := otlpgrpc.NewTracesRequest()
+	req.SetTraces(td)
+	_, err := b.traceExporter.Export(lb.exp.enhanceContext(ctx), req, lb.exp.callOptions...)
+	lb.report(b, err)
+	return processError(err)
+}
+
+func (lb *loadBalancer) pushMetrics(ctx context.Context, md pdata.Metrics) error {
+	b, err := lb.pick()
+	if err != nil {
+		return err
+	}
+	defer lb.release(b)
+	req := otlpgrpc.NewMetricsRequest()
+	req.SetMetrics(md)
+	_, err = b.metricExporter.Export(lb.exp.enhanceContext(ctx), req, lb.exp.callOptions...)
+	lb.report(b, err)
+	return processError(err)
+}
+
+func (lb *loadBalancer) pushLogs(ctx context.Context, ld pdata.Logs) error {
+	b, err := lb.pick()
+	if err != nil {
+		return err
+	}
+	defer lb.release(b)
+	req := otlpgrpc.NewLogsRequest()
+	req.SetLogs(ld)
+	_, err = b.logExporter.Export(lb.exp.enhanceContext(ctx), req, lb.exp.callOptions...)
+	lb.report(b, err)
+	return processError(err)
+}
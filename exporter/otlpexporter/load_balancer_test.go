@@ -0,0 +1,353 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpexporter
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/status"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/internal/loadbalancing"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// traceIDsReceiver records the trace IDs of the received spans.
+type traceIDsReceiver struct {
+	srv  *grpc.Server
+	addr string
+	// err is returned by Export when set, without recording the trace IDs.
+	err      error
+	mu       sync.Mutex
+	traceIDs map[pdata.TraceID]int
+}
+
+func (r *traceIDsReceiver) Export(_ context.Context, req otlpgrpc.TracesRequest) (otlpgrpc.TracesResponse, error) {
+	if r.err != nil {
+		return otlpgrpc.NewTracesResponse(), r.err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rss := req.Traces().ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		ilss := rss.At(i).InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				r.traceIDs[spans.At(k).TraceID()]++
+			}
+		}
+	}
+	return otlpgrpc.NewTracesResponse(), nil
+}
+
+func (r *traceIDsReceiver) received() map[pdata.TraceID]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	received := make(map[pdata.TraceID]int, len(r.traceIDs))
+	for traceID, count := range r.traceIDs {
+		received[traceID] = count
+	}
+	return received
+}
+
+func (r *traceIDsReceiver) spanCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, c := range r.traceIDs {
+		count += c
+	}
+	return count
+}
+
+func startTraceIDsReceiver(t *testing.T) *traceIDsReceiver {
+	return startFailingTraceIDsReceiver(t, nil)
+}
+
+// startFailingTraceIDsReceiver starts a receiver failing every request with the given error, if not nil.
+func startFailingTraceIDsReceiver(t *testing.T, exportErr error) *traceIDsReceiver {
+	ln, err := net.Listen("tcp", "localhost:")
+	require.NoError(t, err)
+	rcv := &traceIDsReceiver{srv: grpc.NewServer(), addr: ln.Addr().String(), err: exportErr, traceIDs: map[pdata.TraceID]int{}}
+	otlpgrpc.RegisterTracesServer(rcv.srv, rcv)
+	go func() {
+		_ = rcv.srv.Serve(ln)
+	}()
+	t.Cleanup(rcv.srv.Stop)
+	return rcv
+}
+
+func createLoadBalancingConfig(endpoints ...string) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.TLSSetting.Insecure = true
+	cfg.QueueSettings.Enabled = false
+	cfg.RetrySettings.Enabled = false
+	cfg.LoadBalancing.Resolver.Static = &StaticResolverSettings{Endpoints: endpoints}
+	return cfg
+}
+
+func startTracesLoadBalancer(t *testing.T, cfg *Config) component.TracesExporter {
+	exp, err := NewFactory().CreateTracesExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, exp.Shutdown(context.Background()))
+	})
+	return exp
+}
+
+// generateTraces returns a trace with one span for every one of the given trace IDs.
+func generateTraces(traceIDs ...byte) pdata.Traces {
+	td := pdata.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().InstrumentationLibrarySpans().AppendEmpty().Spans()
+	for _, id := range traceIDs {
+		span := spans.AppendEmpty()
+		span.SetName("span")
+		span.SetTraceID(pdata.NewTraceID([16]byte{id, id, id}))
+	}
+	return td
+}
+
+func TestLoadBalancerRoundRobin(t *testing.T) {
+	rcv1 := startTraceIDsReceiver(t)
+	rcv2 := startTraceIDsReceiver(t)
+	exp := startTracesLoadBalancer(t, createLoadBalancingConfig(rcv1.addr, rcv2.addr))
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, exp.ConsumeTraces(context.Background(), testdata.GenerateTracesOneSpan()))
+	}
+	assert.Equal(t, 2, rcv1.spanCount())
+	assert.Equal(t, 2, rcv2.spanCount())
+}
+
+func TestLoadBalancerTraceID(t *testing.T) {
+	rcv1 := startTraceIDsReceiver(t)
+	rcv2 := startTraceIDsReceiver(t)
+	cfg := createLoadBalancingConfig(rcv1.addr, rcv2.addr)
+	cfg.LoadBalancing.Routing = routingTraceID
+	exp := startTracesLoadBalancer(t, cfg)
+
+	var traceIDs []byte
+	for i := 0; i < 50; i++ {
+		traceIDs = append(traceIDs, byte(i))
+	}
+	require.NoError(t, exp.ConsumeTraces(context.Background(), generateTraces(traceIDs...)))
+	require.NoError(t, exp.ConsumeTraces(context.Background(), generateTraces(traceIDs...)))
+
+	// Both receivers got spans, and all the spans of a trace went to the same receiver.
+	received1 := rcv1.received()
+	received2 := rcv2.received()
+	assert.NotEmpty(t, received1)
+	assert.NotEmpty(t, received2)
+	assert.Equal(t, 50, len(received1)+len(received2))
+	for traceID, count := range received1 {
+		assert.Equal(t, 2, count)
+		assert.NotContains(t, received2, traceID)
+	}
+}
+
+func TestLoadBalancerTraceIDPartialFailure(t *testing.T) {
+	rcv := startTraceIDsReceiver(t)
+	unavailable := startFailingTraceIDsReceiver(t, errUnavailable)
+	invalid := startFailingTraceIDsReceiver(t, status.Error(codes.InvalidArgument, "invalid"))
+	cfg := createLoadBalancingConfig(rcv.addr, unavailable.addr, invalid.addr)
+	cfg.LoadBalancing.Routing = routingTraceID
+	exp, err := newExporter(cfg, componenttest.NewNopExporterCreateSettings().Logger)
+	require.NoError(t, err)
+	require.NoError(t, exp.start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, exp.shutdown(context.Background()))
+	}()
+
+	var traceIDs []byte
+	for i := 0; i < 50; i++ {
+		traceIDs = append(traceIDs, byte(i))
+	}
+	err = exp.pushTraces(context.Background(), generateTraces(traceIDs...))
+
+	// Only the spans sent to the unavailable endpoint are retried, the spans rejected by the other are dropped.
+	require.Error(t, err)
+	assert.False(t, consumererror.IsPermanent(err))
+	var partialErr consumererror.PartialTraces
+	require.ErrorAs(t, err, &partialErr)
+	retryable := partialErr.GetRetryable().SpanCount()
+	permanent := partialErr.GetPermanent().SpanCount()
+	assert.Greater(t, retryable, 0)
+	assert.Greater(t, permanent, 0)
+	assert.Greater(t, rcv.spanCount(), 0)
+	assert.Equal(t, 50, rcv.spanCount()+retryable+permanent)
+	exp.lb.mu.RLock()
+	ring := exp.lb.ring
+	exp.lb.mu.RUnlock()
+	spans := partialErr.GetRetryable().ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans()
+	for i := 0; i < spans.Len(); i++ {
+		traceID := spans.At(i).TraceID().Bytes()
+		assert.Equal(t, unavailable.addr, ring.Endpoint(traceID[:], nil))
+	}
+}
+
+func TestLoadBalancerTraceIDPartialRejection(t *testing.T) {
+	rcv := startTraceIDsReceiver(t)
+	invalid := startFailingTraceIDsReceiver(t, status.Error(codes.InvalidArgument, "invalid"))
+	cfg := createLoadBalancingConfig(rcv.addr, invalid.addr)
+	cfg.LoadBalancing.Routing = routingTraceID
+	exp, err := newExporter(cfg, componenttest.NewNopExporterCreateSettings().Logger)
+	require.NoError(t, err)
+	require.NoError(t, exp.start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, exp.shutdown(context.Background()))
+	}()
+
+	var traceIDs []byte
+	for i := 0; i < 50; i++ {
+		traceIDs = append(traceIDs, byte(i))
+	}
+	err = exp.pushTraces(context.Background(), generateTraces(traceIDs...))
+
+	// Only the rejected spans are reported as failed, not the delivered ones.
+	require.Error(t, err)
+	assert.False(t, consumererror.IsPermanent(err))
+	var partialErr consumererror.PartialTraces
+	require.ErrorAs(t, err, &partialErr)
+	assert.Equal(t, 0, partialErr.GetRetryable().SpanCount())
+	permanent := partialErr.GetPermanent().SpanCount()
+	assert.Greater(t, permanent, 0)
+	assert.Greater(t, rcv.spanCount(), 0)
+	assert.Equal(t, 50, rcv.spanCount()+permanent)
+}
+
+func TestLoadBalancerRemovedEndpointInFlight(t *testing.T) {
+	cfg := createLoadBalancingConfig("backend-1:4317")
+	exp, err := newExporter(cfg, componenttest.NewNopExporterCreateSettings().Logger)
+	require.NoError(t, err)
+	lb := exp.lb
+	lb.host = componenttest.NewNopHost()
+	lb.onChange([]string{"backend-1:4317"})
+
+	b, err := lb.pick()
+	require.NoError(t, err)
+
+	// The connection of the removed endpoint is closed only once the send in flight is done.
+	lb.onChange(nil)
+	assert.NotEqual(t, connectivity.Shutdown, b.clientConn.GetState())
+	lb.release(b)
+	assert.Equal(t, connectivity.Shutdown, b.clientConn.GetState())
+}
+
+func TestLoadBalancerEjection(t *testing.T) {
+	rcv := startTraceIDsReceiver(t)
+	// Nothing listens on the address of the closed listener.
+	ln, err := net.Listen("tcp", "localhost:")
+	require.NoError(t, err)
+	deadAddr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	cfg := createLoadBalancingConfig(rcv.addr, deadAddr)
+	cfg.LoadBalancing.Ejection.ConsecutiveFailures = 2
+	cfg.LoadBalancing.Ejection.Duration = time.Minute
+	exp := startTracesLoadBalancer(t, cfg)
+
+	// The dead endpoint is picked every other request until it fails twice.
+	failures := 0
+	for i := 0; i < 10; i++ {
+		if exp.ConsumeTraces(context.Background(), testdata.GenerateTracesOneSpan()) != nil {
+			failures++
+		}
+	}
+	assert.Equal(t, 2, failures)
+	assert.Equal(t, 8, rcv.spanCount())
+}
+
+func TestLoadBalancerEjectionExpiry(t *testing.T) {
+	cfg := createLoadBalancingConfig("backend-1:4317", "backend-2:4317")
+	cfg.LoadBalancing.Ejection.ConsecutiveFailures = 1
+	exp, err := newExporter(cfg, componenttest.NewNopExporterCreateSettings().Logger)
+	require.NoError(t, err)
+	lb := exp.lb
+	now := time.Now()
+	lb.now = func() time.Time { return now }
+	require.NoError(t, exp.start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, exp.shutdown(context.Background()))
+	}()
+
+	ejected := lb.backends["backend-1:4317"]
+	lb.report(ejected, errUnavailable)
+	for i := 0; i < 4; i++ {
+		b, err := lb.pick()
+		require.NoError(t, err)
+		assert.Equal(t, "backend-2:4317", b.endpoint)
+	}
+
+	// Once all the endpoints are ejected, they are picked anyway.
+	lb.report(lb.backends["backend-2:4317"], errUnavailable)
+	_, err = lb.pick()
+	assert.NoError(t, err)
+
+	// The endpoints are tried again after the ejection duration.
+	now = now.Add(cfg.LoadBalancing.Ejection.Duration)
+	picked := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		b, err := lb.pick()
+		require.NoError(t, err)
+		picked[b.endpoint] = true
+	}
+	assert.Len(t, picked, 2)
+}
+
+func TestLoadBalancerFileResolver(t *testing.T) {
+	rcv1 := startTraceIDsReceiver(t)
+	rcv2 := startTraceIDsReceiver(t)
+	path := filepath.Join(t.TempDir(), "backends")
+	require.NoError(t, os.WriteFile(path, []byte(rcv1.addr+"\n"), 0600))
+
+	cfg := createLoadBalancingConfig()
+	cfg.LoadBalancing.Resolver.Static = nil
+	cfg.LoadBalancing.Resolver.File = &FileResolverSettings{Path: path, Interval: 10 * time.Millisecond}
+	exp := startTracesLoadBalancer(t, cfg)
+	require.NoError(t, exp.ConsumeTraces(context.Background(), testdata.GenerateTracesOneSpan()))
+	assert.Equal(t, 1, rcv1.spanCount())
+
+	require.NoError(t, os.WriteFile(path, []byte(rcv2.addr+"\n"), 0600))
+	assert.Eventually(t, func() bool {
+		return exp.ConsumeTraces(context.Background(), testdata.GenerateTracesOneSpan()) == nil && rcv2.spanCount() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestLoadBalancerNoEndpoints(t *testing.T) {
+	exp, err := newExporter(createLoadBalancingConfig("backend:4317"), componenttest.NewNopExporterCreateSettings().Logger)
+	require.NoError(t, err)
+	assert.ErrorIs(t, exp.pushTraces(context.Background(), testdata.GenerateTracesOneSpan()), loadbalancing.ErrNoEndpoints)
+	assert.ErrorIs(t, exp.pushMetrics(context.Background(), testdata.GenerateMetricsOneMetric()), loadbalancing.ErrNoEndpoints)
+	assert.ErrorIs(t, exp.pushLogs(context.Background(), testdata.GenerateLogsOneLogRecord()), loadbalancing.ErrNoEndpoints)
+}
+
+var errUnavailable = status.Error(codes.Unavailable, "unavailable")
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"time"
 
+	"go.uber.org/zap"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -44,13 +45,22 @@ type exporter struct {
 	clientConn     *grpc.ClientConn
 	metadata       metadata.MD
 	callOptions    []grpc.CallOption
+
+	// lb sends the data to several endpoints instead of clientConn if load balancing is enabled.
+	lb *loadBalancer
 }
 
 // Crete new exporter and start it. The exporter will begin connecting but
 // this function may return before the connection is established.
-func newExporter(cfg config.Exporter) (*exporter, error) {
+func newExporter(cfg config.Exporter, logger *zap.Logger) (*exporter, error) {
 	oCfg := cfg.(*Config)
 
+	if oCfg.LoadBalancing.enabled() {
+		e := &exporter{config: oCfg}
+		e.lb = newLoadBalancer(e, logger)
+		return e, nil
+	}
+
 	if oCfg.Endpoint == "" {
 		return nil, errors.New("OTLP exporter config requires an Endpoint")
 	}
@@ -60,7 +70,15 @@ func newExporter(cfg config.Exporter) (*exporter, error) {
 
 // start actually creates the gRPC connection. The client construction is deferred till this point as this
 // is the only place we get hold of Extensions which are required to construct auth round tripper.
-func (e *exporter) start(_ context.Context, host component.Host) (err error) {
+func (e *exporter) start(ctx context.Context, host component.Host) (err error) {
+	e.metadata = metadata.New(e.config.GRPCClientSettings.Headers)
+	e.callOptions = []grpc.CallOption{
+		grpc.WaitForReady(e.config.GRPCClientSettings.WaitForReady),
+	}
+	if e.lb != nil {
+		return e.lb.start(ctx, host)
+	}
+
 	dialOpts, err := e.config.GRPCClientSettings.ToDialOptions(host)
 	if err != nil {
 		return err
@@ -73,19 +91,21 @@ func (e *exporter) start(_ context.Context, host component.Host) (err error) {
 	e.traceExporter = otlpgrpc.NewTracesClient(e.clientConn)
 	e.metricExporter = otlpgrpc.NewMetricsClient(e.clientConn)
 	e.logExporter = otlpgrpc.NewLogsClient(e.clientConn)
-	e.metadata = metadata.New(e.config.GRPCClientSettings.Headers)
-	e.callOptions = []grpc.CallOption{
-		grpc.WaitForReady(e.config.GRPCClientSettings.WaitForReady),
-	}
 
 	return
 }
 
-func (e *exporter) shutdown(context.Context) error {
+func (e *exporter) shutdown(ctx context.Context) error {
+	if e.lb != nil {
+		return e.lb.shutdown(ctx)
+	}
 	return e.clientConn.Close()
 }
 
 func (e *exporter) pushTraces(ctx context.Context, td pdata.Traces) error {
+	if e.lb != nil {
+		return e.lb.pushTraces(ctx, td)
+	}
 	req := otlpgrpc.NewTracesRequest()
 	req.SetTraces(td)
 	_, err := e.traceExporter.Export(e.enhanceContext(ctx), req, e.callOptions...)
@@ -93,6 +113,9 @@ func (e *exporter) pushTraces(ctx context.Context, td pdata.Traces) error {
 }
 
 func (e *exporter) pushMetrics(ctx context.Context, md pdata.Metrics) error {
+	if e.lb != nil {
+		return e.lb.pushMetrics(ctx, md)
+	}
 	req := otlpgrpc.NewMetricsRequest()
 	req.SetMetrics(md)
 	_, err := e.metricExporter.Export(e.enhanceContext(ctx), req, e.callOptions...)
@@ -100,6 +123,9 @@ func (e *exporter) pushMetrics(ctx context.Context, md pdata.Metrics) error {
 }
 
 func (e *exporter) pushLogs(ctx context.Context, ld pdata.Logs) error {
+	if e.lb != nil {
+		return e.lb.pushLogs(ctx, ld)
+	}
 	req := otlpgrpc.NewLogsRequest()
 	req.SetLogs(ld)
 	_, err := e.logExporter.Export(e.enhanceContext(ctx), req, e.callOptions...)
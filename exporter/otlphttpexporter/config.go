@@ -15,17 +15,26 @@
 package otlphttpexporter // import "go.opentelemetry.io/collector/exporter/otlphttpexporter"
 
 import (
+	"errors"
+	"fmt"
+
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configauth"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/internal/compression"
 )
 
 // Config defines configuration for OTLP/HTTP exporter.
 type Config struct {
-	config.ExporterSettings       `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
-	confighttp.HTTPClientSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
-	exporterhelper.QueueSettings  `mapstructure:"sending_queue"`
-	exporterhelper.RetrySettings  `mapstructure:"retry_on_failure"`
+	config.ExporterSettings               `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+	confighttp.HTTPClientSettings         `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+	exporterhelper.QueueSettings          `mapstructure:"sending_queue"`
+	exporterhelper.RetrySettings          `mapstructure:"retry_on_failure"`
+	exporterhelper.BatchSettings          `mapstructure:"sending_batch"`
+	exporterhelper.CircuitBreakerSettings `mapstructure:"circuit_breaker"`
+	exporterhelper.DeadLetterSettings     `mapstructure:"dead_letter"`
+	exporterhelper.TransformSettings      `mapstructure:"transform"`
 
 	// The URL to send traces to. If omitted the Endpoint + "/v1/traces" will be used.
 	TracesEndpoint string `mapstructure:"traces_endpoint"`
@@ -36,14 +45,69 @@ type Config struct {
 	// The URL to send logs to. If omitted the Endpoint + "/v1/logs" will be used.
 	LogsEndpoint string `mapstructure:"logs_endpoint"`
 
+	// The headers sent with the traces requests, in addition to the headers setting. They override the
+	// headers of the same name.
+	TracesHeaders map[string]string `mapstructure:"traces_headers"`
+
+	// The headers sent with the metrics requests, in addition to the headers setting. They override the
+	// headers of the same name.
+	MetricsHeaders map[string]string `mapstructure:"metrics_headers"`
+
+	// The headers sent with the logs requests, in addition to the headers setting. They override the
+	// headers of the same name.
+	LogsHeaders map[string]string `mapstructure:"logs_headers"`
+
+	// The authentication of the traces requests. If omitted the auth setting will be used.
+	TracesAuth *configauth.Authentication `mapstructure:"traces_auth"`
+
+	// The authentication of the metrics requests. If omitted the auth setting will be used.
+	MetricsAuth *configauth.Authentication `mapstructure:"metrics_auth"`
+
+	// The authentication of the logs requests. If omitted the auth setting will be used.
+	LogsAuth *configauth.Authentication `mapstructure:"logs_auth"`
+
 	// The compression key for supported compression types within
-	// collector. Currently the only supported mode is `gzip`.
+	// collector: `gzip`, `zstd` or `snappy`.
 	Compression string `mapstructure:"compression"`
+
+	// CompressionLevel is the level of the gzip (1 to 9) or zstd (1 to 22) compression. If 0, the default
+	// level of the compression type is used.
+	CompressionLevel int `mapstructure:"compression_level"`
+
+	// The encoding of the requests, `proto` (OTLP/HTTP binary protobuf) or `json` (OTLP/HTTP JSON).
+	Encoding string `mapstructure:"encoding"`
 }
 
 var _ config.Exporter = (*Config)(nil)
 
 // Validate checks if the exporter configuration is valid
 func (cfg *Config) Validate() error {
+	if err := cfg.TransformSettings.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.QueueSettings.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.BatchSettings.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.CircuitBreakerSettings.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.DeadLetterSettings.Validate(); err != nil {
+		return err
+	}
+	if cfg.Compression != "" {
+		if err := compression.Validate(cfg.Compression, cfg.CompressionLevel); err != nil {
+			return err
+		}
+	} else if cfg.CompressionLevel != 0 {
+		return errors.New("compression_level requires compression to be set")
+	}
+	switch cfg.Encoding {
+	case encodingProto, encodingJSON:
+	default:
+		return fmt.Errorf("encoding must be %q or %q, got %q", encodingProto, encodingJSON, cfg.Encoding)
+	}
 	return nil
 }
@@ -24,6 +24,7 @@ import (
 
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configauth"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/config/configtest"
 	"go.opentelemetry.io/collector/config/configtls"
@@ -45,6 +46,7 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, e0, factory.CreateDefaultConfig())
 
 	e1 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "2")]
+	deadLetterStorageID := config.NewComponentIDWithName("file_storage", "deadletter")
 	assert.Equal(t, e1,
 		&Config{
 			ExporterSettings: config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "2")),
@@ -58,6 +60,33 @@ func TestLoadConfig(t *testing.T) {
 				Enabled:      true,
 				NumConsumers: 2,
 				QueueSize:    10,
+				AdaptiveConcurrency: exporterhelper.AdaptiveConcurrencySettings{
+					Enabled:          true,
+					MinConsumers:     1,
+					MaxConsumers:     20,
+					LatencyThreshold: 2 * time.Second,
+				},
+			},
+			BatchSettings: exporterhelper.BatchSettings{
+				Enabled:               true,
+				Timeout:               time.Second,
+				SendBatchSize:         1000,
+				SendBatchMaxSize:      2000,
+				SendBatchMaxSizeBytes: 4 * 1024 * 1024,
+			},
+			CircuitBreakerSettings: exporterhelper.CircuitBreakerSettings{
+				Enabled:          true,
+				FailureThreshold: 3,
+				ProbeInterval:    30 * time.Second,
+			},
+			DeadLetterSettings: exporterhelper.DeadLetterSettings{
+				Storage: &deadLetterStorageID,
+			},
+			TransformSettings: exporterhelper.TransformSettings{
+				Actions: []exporterhelper.TransformActionSettings{
+					{Action: "delete", Key: "internal.debug"},
+					{Action: "upsert", Scope: "resource", Key: "deployment.environment", Value: "production"},
+				},
 			},
 			HTTPClientSettings: confighttp.HTTPClientSettings{
 				Headers: map[string]string{
@@ -78,6 +107,50 @@ func TestLoadConfig(t *testing.T) {
 				WriteBufferSize: 345,
 				Timeout:         time.Second * 10,
 			},
-			Compression: "gzip",
+			TracesHeaders: map[string]string{
+				"x-scope-orgid": "traces",
+			},
+			LogsAuth:         &configauth.Authentication{AuthenticatorID: config.NewComponentID("nop")},
+			Compression:      "gzip",
+			CompressionLevel: 9,
+			Encoding:         encodingJSON,
 		})
 }
+
+func TestValidateConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.NoError(t, cfg.Validate())
+	cfg.Encoding = encodingJSON
+	assert.NoError(t, cfg.Validate())
+	cfg.Encoding = "xml"
+	assert.EqualError(t, cfg.Validate(), `encoding must be "proto" or "json", got "xml"`)
+	cfg.Encoding = encodingProto
+	cfg.TransformSettings.Actions = []exporterhelper.TransformActionSettings{{Action: "rename", Key: "a"}}
+	assert.EqualError(t, cfg.Validate(), `transform.actions: new_key of the rename of "a" must be set`)
+	cfg.TransformSettings.Actions = nil
+	cfg.QueueSettings.PriorityLanes = []exporterhelper.PriorityLaneSettings{{Name: "errors"}}
+	assert.EqualError(t, cfg.Validate(), `sending_queue.priority_lanes: queue_size of lane "errors" must be positive`)
+	cfg.QueueSettings.PriorityLanes = nil
+	cfg.BatchSettings.Enabled = true
+	cfg.BatchSettings.Timeout = 0
+	assert.EqualError(t, cfg.Validate(), "sending_batch.timeout must be positive")
+	cfg.BatchSettings.Enabled = false
+	cfg.CircuitBreakerSettings.Enabled = true
+	cfg.CircuitBreakerSettings.ProbeInterval = 0
+	assert.EqualError(t, cfg.Validate(), "circuit_breaker.probe_interval must be positive")
+	cfg.CircuitBreakerSettings.Enabled = false
+	id := config.NewComponentID("file_storage")
+	cfg.DeadLetterSettings.Exporter = &id
+	cfg.DeadLetterSettings.Storage = &id
+	assert.EqualError(t, cfg.Validate(), "only one of dead-letter exporter and storage can be configured")
+	cfg.DeadLetterSettings = exporterhelper.DeadLetterSettings{}
+	cfg.Compression = "lz4"
+	assert.EqualError(t, cfg.Validate(), `unsupported compression type "lz4"`)
+	cfg.Compression = "gzip"
+	cfg.CompressionLevel = 10
+	assert.EqualError(t, cfg.Validate(), "gzip compression level must be between 1 and 9, got 10")
+	cfg.Compression = ""
+	assert.EqualError(t, cfg.Validate(), "compression_level requires compression to be set")
+	cfg.CompressionLevel = 0
+	assert.NoError(t, cfg.Validate())
+}
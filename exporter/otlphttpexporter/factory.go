@@ -17,11 +17,13 @@ package otlphttpexporter // import "go.opentelemetry.io/collector/exporter/otlph
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configauth"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
@@ -44,9 +46,12 @@ func NewFactory() component.ExporterFactory {
 
 func createDefaultConfig() config.Exporter {
 	return &Config{
-		ExporterSettings: config.NewExporterSettings(config.NewComponentID(typeStr)),
-		RetrySettings:    exporterhelper.DefaultRetrySettings(),
-		QueueSettings:    exporterhelper.DefaultQueueSettings(),
+		ExporterSettings:       config.NewExporterSettings(config.NewComponentID(typeStr)),
+		RetrySettings:          exporterhelper.DefaultRetrySettings(),
+		QueueSettings:          exporterhelper.DefaultQueueSettings(),
+		BatchSettings:          exporterhelper.DefaultBatchSettings(),
+		CircuitBreakerSettings: exporterhelper.DefaultCircuitBreakerSettings(),
+		Encoding:               encodingProto,
 		HTTPClientSettings: confighttp.HTTPClientSettings{
 			Endpoint: "",
 			Timeout:  30 * time.Second,
@@ -72,6 +77,25 @@ func composeSignalURL(oCfg *Config, signalOverrideURL string, signalName string)
 	}
 }
 
+// signalClientSettings returns the HTTP client settings of a signal: the signal headers are added to the
+// headers, overriding the headers of the same name, and the signal authentication replaces the authentication.
+func signalClientSettings(oCfg *Config, headers map[string]string, auth *configauth.Authentication) confighttp.HTTPClientSettings {
+	settings := oCfg.HTTPClientSettings
+	if len(headers) > 0 {
+		settings.Headers = make(map[string]string, len(oCfg.Headers)+len(headers))
+		for k, v := range oCfg.Headers {
+			settings.Headers[http.CanonicalHeaderKey(k)] = v
+		}
+		for k, v := range headers {
+			settings.Headers[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	if auth != nil {
+		settings.Auth = auth
+	}
+	return settings
+}
+
 func createTracesExporter(
 	_ context.Context,
 	set component.ExporterCreateSettings,
@@ -87,6 +111,7 @@ func createTracesExporter(
 	if err != nil {
 		return nil, err
 	}
+	oce.clientSettings = signalClientSettings(oCfg, oCfg.TracesHeaders, oCfg.TracesAuth)
 
 	return exporterhelper.NewTracesExporter(
 		cfg,
@@ -97,7 +122,11 @@ func createTracesExporter(
 		// explicitly disable since we rely on http.Client timeout logic.
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
 		exporterhelper.WithRetry(oCfg.RetrySettings),
-		exporterhelper.WithQueue(oCfg.QueueSettings))
+		exporterhelper.WithQueue(oCfg.QueueSettings),
+		exporterhelper.WithBatch(oCfg.BatchSettings),
+		exporterhelper.WithCircuitBreaker(oCfg.CircuitBreakerSettings),
+		exporterhelper.WithDeadLetter(oCfg.DeadLetterSettings),
+		exporterhelper.WithTransform(oCfg.TransformSettings))
 }
 
 func createMetricsExporter(
@@ -115,6 +144,7 @@ func createMetricsExporter(
 	if err != nil {
 		return nil, err
 	}
+	oce.clientSettings = signalClientSettings(oCfg, oCfg.MetricsHeaders, oCfg.MetricsAuth)
 
 	return exporterhelper.NewMetricsExporter(
 		cfg,
@@ -125,7 +155,11 @@ func createMetricsExporter(
 		// explicitly disable since we rely on http.Client timeout logic.
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
 		exporterhelper.WithRetry(oCfg.RetrySettings),
-		exporterhelper.WithQueue(oCfg.QueueSettings))
+		exporterhelper.WithQueue(oCfg.QueueSettings),
+		exporterhelper.WithBatch(oCfg.BatchSettings),
+		exporterhelper.WithCircuitBreaker(oCfg.CircuitBreakerSettings),
+		exporterhelper.WithDeadLetter(oCfg.DeadLetterSettings),
+		exporterhelper.WithTransform(oCfg.TransformSettings))
 }
 
 func createLogsExporter(
@@ -143,6 +177,7 @@ func createLogsExporter(
 	if err != nil {
 		return nil, err
 	}
+	oce.clientSettings = signalClientSettings(oCfg, oCfg.LogsHeaders, oCfg.LogsAuth)
 
 	return exporterhelper.NewLogsExporter(
 		cfg,
@@ -153,5 +188,9 @@ func createLogsExporter(
 		// explicitly disable since we rely on http.Client timeout logic.
 		exporterhelper.WithTimeout(exporterhelper.TimeoutSettings{Timeout: 0}),
 		exporterhelper.WithRetry(oCfg.RetrySettings),
-		exporterhelper.WithQueue(oCfg.QueueSettings))
+		exporterhelper.WithQueue(oCfg.QueueSettings),
+		exporterhelper.WithBatch(oCfg.BatchSettings),
+		exporterhelper.WithCircuitBreaker(oCfg.CircuitBreakerSettings),
+		exporterhelper.WithDeadLetter(oCfg.DeadLetterSettings),
+		exporterhelper.WithTransform(oCfg.TransformSettings))
 }
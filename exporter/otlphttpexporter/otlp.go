@@ -29,31 +29,44 @@ import (
 
 	"go.uber.org/zap"
 	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
-	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/collector/internal/middleware"
-	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
 type exporter struct {
 	// Input configuration.
-	config     *Config
-	client     *http.Client
-	tracesURL  string
-	metricsURL string
-	logsURL    string
-	logger     *zap.Logger
+	config *Config
+	// The HTTP client settings of the signal, see signalClientSettings.
+	clientSettings   confighttp.HTTPClientSettings
+	client           *http.Client
+	tracesURL        string
+	metricsURL       string
+	logsURL          string
+	tracesMarshaler  pdata.TracesMarshaler
+	metricsMarshaler pdata.MetricsMarshaler
+	logsMarshaler    pdata.LogsMarshaler
+	contentType      string
+	logger           *zap.Logger
 }
 
 const (
 	headerRetryAfter         = "Retry-After"
 	maxHTTPResponseReadBytes = 64 * 1024
+
+	encodingProto = "proto"
+	encodingJSON  = "json"
+
+	protobufContentType = "application/x-protobuf"
+	jsonContentType     = "application/json"
 )
 
 // Crete new exporter.
@@ -68,25 +81,36 @@ func newExporter(cfg config.Exporter, logger *zap.Logger) (*exporter, error) {
 	}
 
 	// client construction is deferred to start
-	return &exporter{
-		config: oCfg,
-		logger: logger,
-	}, nil
+	e := &exporter{
+		config:         oCfg,
+		clientSettings: oCfg.HTTPClientSettings,
+		logger:         logger,
+	}
+	if oCfg.Encoding == encodingJSON {
+		e.tracesMarshaler = otlp.NewJSONTracesMarshaler()
+		e.metricsMarshaler = otlp.NewJSONMetricsMarshaler()
+		e.logsMarshaler = otlp.NewJSONLogsMarshaler()
+		e.contentType = jsonContentType
+	} else {
+		e.tracesMarshaler = otlp.NewProtobufTracesMarshaler()
+		e.metricsMarshaler = otlp.NewProtobufMetricsMarshaler()
+		e.logsMarshaler = otlp.NewProtobufLogsMarshaler()
+		e.contentType = protobufContentType
+	}
+	return e, nil
 }
 
 // start actually creates the HTTP client. The client construction is deferred till this point as this
 // is the only place we get hold of Extensions which are required to construct auth round tripper.
 func (e *exporter) start(_ context.Context, host component.Host) error {
-	client, err := e.config.HTTPClientSettings.ToClient(host.GetExtensions())
+	client, err := e.clientSettings.ToClient(host.GetExtensions())
 	if err != nil {
 		return err
 	}
 
 	if e.config.Compression != "" {
-		if strings.ToLower(e.config.Compression) == configgrpc.CompressionGzip {
-			client.Transport = middleware.NewCompressRoundTripper(client.Transport)
-		} else {
-			return fmt.Errorf("unsupported compression type %q", e.config.Compression)
+		if client.Transport, err = middleware.NewCompressRoundTripperWithEncoding(client.Transport, e.config.Compression, e.config.CompressionLevel); err != nil {
+			return err
 		}
 	}
 	e.client = client
@@ -94,9 +118,7 @@ func (e *exporter) start(_ context.Context, host component.Host) error {
 }
 
 func (e *exporter) pushTraces(ctx context.Context, td pdata.Traces) error {
-	tr := otlpgrpc.NewTracesRequest()
-	tr.SetTraces(td)
-	request, err := tr.Marshal()
+	request, err := e.tracesMarshaler.MarshalTraces(td)
 	if err != nil {
 		return consumererror.NewPermanent(err)
 	}
@@ -105,9 +127,7 @@ func (e *exporter) pushTraces(ctx context.Context, td pdata.Traces) error {
 }
 
 func (e *exporter) pushMetrics(ctx context.Context, md pdata.Metrics) error {
-	tr := otlpgrpc.NewMetricsRequest()
-	tr.SetMetrics(md)
-	request, err := tr.Marshal()
+	request, err := e.metricsMarshaler.MarshalMetrics(md)
 	if err != nil {
 		return consumererror.NewPermanent(err)
 	}
@@ -115,9 +135,7 @@ func (e *exporter) pushMetrics(ctx context.Context, md pdata.Metrics) error {
 }
 
 func (e *exporter) pushLogs(ctx context.Context, ld pdata.Logs) error {
-	tr := otlpgrpc.NewLogsRequest()
-	tr.SetLogs(ld)
-	request, err := tr.Marshal()
+	request, err := e.logsMarshaler.MarshalLogs(ld)
 	if err != nil {
 		return consumererror.NewPermanent(err)
 	}
@@ -131,7 +149,7 @@ func (e *exporter) export(ctx context.Context, url string, request []byte) error
 	if err != nil {
 		return consumererror.NewPermanent(err)
 	}
-	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("Content-Type", e.contentType)
 
 	resp, err := e.client.Do(req)
 	if err != nil {
@@ -166,16 +184,8 @@ func (e *exporter) export(ctx context.Context, url string, request []byte) error
 	// Check if the server is overwhelmed.
 	// See spec https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/protocol/otlp.md#throttling-1
 	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
-		// Fallback to 0 if the Retry-After header is not present. This will trigger the
-		// default backoff policy by our caller (retry handler).
-		retryAfter := 0
-		if val := resp.Header.Get(headerRetryAfter); val != "" {
-			if seconds, err2 := strconv.Atoi(val); err2 == nil {
-				retryAfter = seconds
-			}
-		}
-		// Indicate to our caller to pause for the specified number of seconds.
-		return exporterhelper.NewThrottleRetry(formattedErr, time.Duration(retryAfter)*time.Second)
+		// Indicate to our caller to pause for the duration requested by the server.
+		return exporterhelper.NewThrottleRetry(formattedErr, parseRetryAfter(resp.Header.Get(headerRetryAfter), time.Now()))
 	}
 
 	if resp.StatusCode == http.StatusBadRequest {
@@ -187,6 +197,25 @@ func (e *exporter) export(ctx context.Context, url string, request []byte) error
 	return formattedErr
 }
 
+// parseRetryAfter returns the delay requested by the Retry-After header value, which can either be a number
+// of seconds or an HTTP date. Falls back to 0 if the header is not present or invalid. This will trigger the
+// default backoff policy by our caller (retry handler).
+func parseRetryAfter(val string, now time.Time) time.Duration {
+	if val == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(val); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(val); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
 // Read the response and decode the status.Status from the body.
 // Returns nil if the response is empty or cannot be decoded.
 func readResponse(resp *http.Response) *status.Status {
@@ -195,6 +224,7 @@ func readResponse(resp *http.Response) *status.Status {
 		// Request failed. Read the body. OTLP spec says:
 		// "Response body for all HTTP 4xx and HTTP 5xx responses MUST be a
 		// Protobuf-encoded Status message that describes the problem."
+		// The Status message is JSON-encoded if the request was.
 		maxRead := resp.ContentLength
 		if maxRead == -1 || maxRead > maxHTTPResponseReadBytes {
 			maxRead = maxHTTPResponseReadBytes
@@ -204,7 +234,11 @@ func readResponse(resp *http.Response) *status.Status {
 		if err == nil && n > 0 {
 			// Decode it as Status struct. See https://github.com/open-telemetry/opentelemetry-specification/blob/main/specification/protocol/otlp.md#failures
 			respStatus = &status.Status{}
-			err = proto.Unmarshal(respBytes, respStatus)
+			if strings.HasPrefix(resp.Header.Get("Content-Type"), jsonContentType) {
+				err = protojson.Unmarshal(respBytes, respStatus)
+			} else {
+				err = proto.Unmarshal(respBytes, respStatus)
+			}
 			if err != nil {
 				respStatus = nil
 			}
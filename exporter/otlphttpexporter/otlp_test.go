@@ -31,11 +31,13 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configauth"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumererror"
@@ -141,6 +143,7 @@ func TestCompressionOptions(t *testing.T) {
 		name        string
 		baseURL     string
 		compression string
+		level       int
 		err         bool
 	}{
 		{
@@ -153,12 +156,41 @@ func TestCompressionOptions(t *testing.T) {
 			baseURL:     fmt.Sprintf("http://%s", addr),
 			compression: "gzip",
 		},
+		{
+			name:        "gzip level",
+			baseURL:     fmt.Sprintf("http://%s", addr),
+			compression: "gzip",
+			level:       1,
+		},
+		{
+			name:        "zstd",
+			baseURL:     fmt.Sprintf("http://%s", addr),
+			compression: "zstd",
+		},
+		{
+			name:        "zstd level",
+			baseURL:     fmt.Sprintf("http://%s", addr),
+			compression: "zstd",
+			level:       19,
+		},
+		{
+			name:        "snappy",
+			baseURL:     fmt.Sprintf("http://%s", addr),
+			compression: "snappy",
+		},
 		{
 			name:        "incorrect compression",
 			baseURL:     fmt.Sprintf("http://%s", addr),
 			compression: "gzip2",
 			err:         true,
 		},
+		{
+			name:        "incorrect compression level",
+			baseURL:     fmt.Sprintf("http://%s", addr),
+			compression: "zstd",
+			level:       23,
+			err:         true,
+		},
 	}
 
 	for _, test := range tests {
@@ -169,6 +201,7 @@ func TestCompressionOptions(t *testing.T) {
 			factory := NewFactory()
 			cfg := createExporterConfig(test.baseURL, factory.CreateDefaultConfig())
 			cfg.Compression = test.compression
+			cfg.CompressionLevel = test.level
 			exp, _ := factory.CreateTracesExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
 			err := exp.Start(context.Background(), componenttest.NewNopHost())
 			t.Cleanup(func() {
@@ -191,6 +224,134 @@ func TestCompressionOptions(t *testing.T) {
 	}
 }
 
+func TestJSONEncodingRoundTrip(t *testing.T) {
+	addr := testutil.GetAvailableLocalAddress(t)
+	factory := NewFactory()
+	cfg := createExporterConfig(fmt.Sprintf("http://%s", addr), factory.CreateDefaultConfig())
+	cfg.Encoding = encodingJSON
+
+	t.Run("traces", func(t *testing.T) {
+		sink := new(consumertest.TracesSink)
+		startTracesReceiver(t, addr, sink)
+		exp, err := factory.CreateTracesExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+		require.NoError(t, err)
+		startAndCleanup(t, exp)
+
+		td := testdata.GenerateTracesOneSpan()
+		assert.NoError(t, exp.ConsumeTraces(context.Background(), td))
+		require.Eventually(t, func() bool {
+			return sink.SpanCount() > 0
+		}, 1*time.Second, 10*time.Millisecond)
+		assert.EqualValues(t, []pdata.Traces{td}, sink.AllTraces())
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		sink := new(consumertest.MetricsSink)
+		startMetricsReceiver(t, addr, sink)
+		exp, err := factory.CreateMetricsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+		require.NoError(t, err)
+		startAndCleanup(t, exp)
+
+		md := testdata.GenerateMetricsOneMetric()
+		assert.NoError(t, exp.ConsumeMetrics(context.Background(), md))
+		require.Eventually(t, func() bool {
+			return sink.DataPointCount() > 0
+		}, 1*time.Second, 10*time.Millisecond)
+		assert.EqualValues(t, []pdata.Metrics{md}, sink.AllMetrics())
+	})
+
+	t.Run("logs", func(t *testing.T) {
+		sink := new(consumertest.LogsSink)
+		startLogsReceiver(t, addr, sink)
+		exp, err := factory.CreateLogsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+		require.NoError(t, err)
+		startAndCleanup(t, exp)
+
+		ld := testdata.GenerateLogsOneLogRecord()
+		assert.NoError(t, exp.ConsumeLogs(context.Background(), ld))
+		require.Eventually(t, func() bool {
+			return sink.LogRecordCount() > 0
+		}, 1*time.Second, 10*time.Millisecond)
+		assert.EqualValues(t, []pdata.Logs{ld}, sink.AllLogs())
+	})
+}
+
+func TestSignalHeaders(t *testing.T) {
+	received := make(chan http.Header, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		header := request.Header.Clone()
+		header.Set("Path", request.URL.Path)
+		received <- header
+		writer.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	factory := NewFactory()
+	cfg := createExporterConfig(srv.URL, factory.CreateDefaultConfig())
+	cfg.Encoding = encodingJSON
+	cfg.Headers = map[string]string{"x-tenant": "default", "x-region": "eu"}
+	cfg.TracesHeaders = map[string]string{"X-Tenant": "traces"}
+
+	traces, err := factory.CreateTracesExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	startAndCleanup(t, traces)
+	require.NoError(t, traces.ConsumeTraces(context.Background(), testdata.GenerateTracesOneSpan()))
+	header := <-received
+	assert.Equal(t, "/v1/traces", header.Get("Path"))
+	assert.Equal(t, "traces", header.Get("X-Tenant"))
+	assert.Equal(t, "eu", header.Get("X-Region"))
+	assert.Equal(t, "application/json", header.Get("Content-Type"))
+
+	metrics, err := factory.CreateMetricsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	startAndCleanup(t, metrics)
+	require.NoError(t, metrics.ConsumeMetrics(context.Background(), testdata.GenerateMetricsOneMetric()))
+	header = <-received
+	assert.Equal(t, "/v1/metrics", header.Get("Path"))
+	assert.Equal(t, "default", header.Get("X-Tenant"))
+	assert.Equal(t, "eu", header.Get("X-Region"))
+}
+
+func TestSignalClientSettings(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Headers = map[string]string{"x-tenant": "default"}
+	cfg.Auth = &configauth.Authentication{AuthenticatorID: config.NewComponentID("oauth2client")}
+	logsAuth := &configauth.Authentication{AuthenticatorID: config.NewComponentIDWithName("oauth2client", "logs")}
+
+	settings := signalClientSettings(cfg, nil, nil)
+	assert.Equal(t, cfg.HTTPClientSettings, settings)
+
+	settings = signalClientSettings(cfg, map[string]string{"X-TENANT": "logs"}, logsAuth)
+	assert.Equal(t, map[string]string{"X-Tenant": "logs"}, settings.Headers)
+	assert.Equal(t, logsAuth, settings.Auth)
+	// The exporter settings are not modified.
+	assert.Equal(t, map[string]string{"x-tenant": "default"}, cfg.Headers)
+}
+
+func TestJSONErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+		msg, err := protojson.Marshal(status.New(codes.InvalidArgument, "Bad field").Proto())
+		require.NoError(t, err)
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(http.StatusBadRequest)
+		_, err = writer.Write(msg)
+		require.NoError(t, err)
+	}))
+	defer srv.Close()
+
+	factory := NewFactory()
+	cfg := createExporterConfig(srv.URL, factory.CreateDefaultConfig())
+	cfg.Encoding = encodingJSON
+	exp, err := factory.CreateLogsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	startAndCleanup(t, exp)
+
+	err = exp.ConsumeLogs(context.Background(), testdata.GenerateLogsOneLogRecord())
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.Contains(t, err.Error(), "HTTP Status Code 400, Message=Bad field")
+}
+
 func TestMetricsError(t *testing.T) {
 	addr := testutil.GetAvailableLocalAddress(t)
 
@@ -423,6 +584,16 @@ func startAndCleanup(t *testing.T, cmp component.Component) {
 	})
 }
 
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2021, 11, 1, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("-5", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("invalid", now))
+}
+
 func TestErrorResponses(t *testing.T) {
 	addr := testutil.GetAvailableLocalAddress(t)
 	errMsgPrefix := fmt.Sprintf("error exporting items, request to http://%s/v1/traces responded with HTTP Status Code ", addr)
@@ -0,0 +1,32 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorylimiter // import "go.opentelemetry.io/collector/extension/experimental/memorylimiter"
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Extension is the interface that memory limiter extensions must implement
+type Extension interface {
+	component.Extension
+
+	// MustRefuse returns true when the incoming data must be refused so memory resources are released.
+	MustRefuse() bool
+
+	// RetryDelay returns the delay the refused clients are asked to wait before retrying.
+	RetryDelay() time.Duration
+}
@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorylimiterextension
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[typeStr] = factory
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	ext0 := cfg.Extensions[config.NewComponentID(typeStr)]
+	assert.Equal(t, factory.CreateDefaultConfig(), ext0)
+
+	ext1 := cfg.Extensions[config.NewComponentIDWithName(typeStr, "1")]
+	assert.Equal(t,
+		&Config{
+			ExtensionSettings:   config.NewExtensionSettings(config.NewComponentIDWithName(typeStr, "1")),
+			CheckInterval:       5 * time.Second,
+			MemoryLimitMiB:      4000,
+			MemorySpikeLimitMiB: 500,
+		},
+		ext1)
+	assert.NoError(t, ext1.Validate())
+
+	assert.Equal(t, 1, len(cfg.Service.Extensions))
+	assert.Equal(t, config.NewComponentIDWithName(typeStr, "1"), cfg.Service.Extensions[0])
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		errMsg string
+	}{
+		{
+			name:   "fixed limits",
+			modify: func(cfg *Config) { cfg.MemoryLimitMiB = 100 },
+		},
+		{
+			name: "percentage limits",
+			modify: func(cfg *Config) {
+				cfg.MemoryLimitPercentage = 80
+				cfg.MemorySpikePercentage = 20
+			},
+		},
+		{
+			name: "no check interval",
+			modify: func(cfg *Config) {
+				cfg.MemoryLimitMiB = 100
+				cfg.CheckInterval = 0
+			},
+			errMsg: "check_interval must be greater than zero",
+		},
+		{
+			name:   "no limit",
+			modify: func(cfg *Config) {},
+			errMsg: "limit_mib or limit_percentage must be greater than zero",
+		},
+		{
+			name: "spike above fixed limit",
+			modify: func(cfg *Config) {
+				cfg.MemoryLimitMiB = 100
+				cfg.MemorySpikeLimitMiB = 100
+			},
+			errMsg: "spike_limit_mib must be smaller than limit_mib",
+		},
+		{
+			name: "percentage out of range",
+			modify: func(cfg *Config) {
+				cfg.MemoryLimitPercentage = 101
+				cfg.MemorySpikePercentage = 20
+			},
+			errMsg: "limit_percentage and spike_limit_percentage must be greater than zero and less than or equal to hundred",
+		},
+		{
+			name:   "no spike percentage",
+			modify: func(cfg *Config) { cfg.MemoryLimitPercentage = 80 },
+			errMsg: "limit_percentage and spike_limit_percentage must be greater than zero and less than or equal to hundred",
+		},
+		{
+			name: "spike above percentage limit",
+			modify: func(cfg *Config) {
+				cfg.MemoryLimitPercentage = 50
+				cfg.MemorySpikePercentage = 60
+			},
+			errMsg: "spike_limit_percentage must be smaller than limit_percentage",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorylimiterextension // import "go.opentelemetry.io/collector/extension/memorylimiterextension"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/extensionhelper"
+	"go.opentelemetry.io/collector/internal/memorylimiter"
+)
+
+const (
+	// The value of extension "type" in configuration.
+	typeStr = "memory_limiter"
+
+	defaultCheckInterval = time.Second
+)
+
+// NewFactory creates a factory for the memory limiter extension.
+func NewFactory() component.ExtensionFactory {
+	return extensionhelper.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		createExtension)
+}
+
+// createDefaultConfig creates the default configuration for the extension. Notice
+// that the default configuration is expected to fail, a limit must be set.
+func createDefaultConfig() config.Extension {
+	return &Config{
+		ExtensionSettings: config.NewExtensionSettings(config.NewComponentID(typeStr)),
+		CheckInterval:     defaultCheckInterval,
+	}
+}
+
+func createExtension(_ context.Context, set component.ExtensionCreateSettings, cfg config.Extension) (component.Extension, error) {
+	mlCfg := cfg.(*Config)
+	return memorylimiter.New(memorylimiter.Settings{
+		CheckInterval:         mlCfg.CheckInterval,
+		MemoryLimitMiB:        mlCfg.MemoryLimitMiB,
+		MemorySpikeLimitMiB:   mlCfg.MemorySpikeLimitMiB,
+		MemoryLimitPercentage: mlCfg.MemoryLimitPercentage,
+		MemorySpikePercentage: mlCfg.MemorySpikePercentage,
+		Logger:                set.Logger,
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchutil // import "go.opentelemetry.io/collector/internal/batchutil"
+
+import (
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// GroupTracesByResource groups the resource spans of the input traces by the key of their resource returned by keyOf.
+// The input traces are not modified.
+func GroupTracesByResource(src pdata.Traces, keyOf func(pdata.Resource) string) map[string]pdata.Traces {
+	groups := make(map[string]pdata.Traces)
+	srcRss := src.ResourceSpans()
+	for i := 0; i < srcRss.Len(); i++ {
+		srcRs := srcRss.At(i)
+		key := keyOf(srcRs.Resource())
+		dest, ok := groups[key]
+		if !ok {
+			dest = pdata.NewTraces()
+			groups[key] = dest
+		}
+		srcRs.CopyTo(dest.ResourceSpans().AppendEmpty())
+	}
+	return groups
+}
+
+// GroupMetricsByResource groups the resource metrics of the input metrics by the key of their resource returned by keyOf.
+// The input metrics are not modified.
+func GroupMetricsByResource(src pdata.Metrics, keyOf func(pdata.Resource) string) map[string]pdata.Metrics {
+	groups := make(map[string]pdata.Metrics)
+	srcRms := src.ResourceMetrics()
+	for i := 0; i < srcRms.Len(); i++ {
+		srcRm := srcRms.At(i)
+		key := keyOf(srcRm.Resource())
+		dest, ok := groups[key]
+		if !ok {
+			dest = pdata.NewMetrics()
+			groups[key] = dest
+		}
+		srcRm.CopyTo(dest.ResourceMetrics().AppendEmpty())
+	}
+	return groups
+}
+
+// GroupLogsByResource groups the resource logs of the input logs by the key of their resource returned by keyOf.
+// The input logs are not modified.
+func GroupLogsByResource(src pdata.Logs, keyOf func(pdata.Resource) string) map[string]pdata.Logs {
+	groups := make(map[string]pdata.Logs)
+	srcRls := src.ResourceLogs()
+	for i := 0; i < srcRls.Len(); i++ {
+		srcRl := srcRls.At(i)
+		key := keyOf(srcRl.Resource())
+		dest, ok := groups[key]
+		if !ok {
+			dest = pdata.NewLogs()
+			groups[key] = dest
+		}
+		srcRl.CopyTo(dest.ResourceLogs().AppendEmpty())
+	}
+	return groups
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func resourceAttr(res pdata.Resource) string {
+	if v, ok := res.Attributes().Get("resource-attr"); ok {
+		return v.StringVal()
+	}
+	return ""
+}
+
+func TestGroupTracesByResource(t *testing.T) {
+	td := testdata.GenerateTracesTwoSpansSameResource()
+	td.ResourceSpans().At(0).CopyTo(td.ResourceSpans().AppendEmpty())
+	td.ResourceSpans().At(1).Resource().Attributes().UpsertString("resource-attr", "other")
+
+	groups := GroupTracesByResource(td, resourceAttr)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, 2, groups["resource-attr-val-1"].SpanCount())
+	assert.Equal(t, 2, groups["other"].SpanCount())
+	assert.Equal(t, 2, td.ResourceSpans().Len())
+}
+
+func TestGroupMetricsByResource(t *testing.T) {
+	md := testdata.GenerateMetricsOneMetric()
+	md.ResourceMetrics().At(0).CopyTo(md.ResourceMetrics().AppendEmpty())
+	md.ResourceMetrics().At(1).Resource().Attributes().UpsertString("resource-attr", "other")
+	md.ResourceMetrics().At(0).CopyTo(md.ResourceMetrics().AppendEmpty())
+
+	groups := GroupMetricsByResource(md, resourceAttr)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, 2, groups["resource-attr-val-1"].ResourceMetrics().Len())
+	assert.Equal(t, 1, groups["other"].ResourceMetrics().Len())
+	assert.Equal(t, md.ResourceMetrics().At(1), groups["other"].ResourceMetrics().At(0))
+	assert.Equal(t, 3, md.ResourceMetrics().Len())
+}
+
+func TestGroupLogsByResource(t *testing.T) {
+	ld := testdata.GenerateLogsOneLogRecord()
+	ld.ResourceLogs().At(0).CopyTo(ld.ResourceLogs().AppendEmpty())
+	ld.ResourceLogs().At(1).Resource().Attributes().Delete("resource-attr")
+
+	groups := GroupLogsByResource(ld, resourceAttr)
+	assert.Len(t, groups, 2)
+	assert.Equal(t, 1, groups["resource-attr-val-1"].LogRecordCount())
+	assert.Equal(t, 1, groups[""].LogRecordCount())
+	assert.Equal(t, 0, groups[""].ResourceLogs().At(0).Resource().Attributes().Len())
+	assert.Equal(t, 2, ld.ResourceLogs().Len())
+}
@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchutil // import "go.opentelemetry.io/collector/internal/batchutil"
+
+import (
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// GroupTraces groups the spans of the input trace by the key returned by keyOf, e.g. the destination of the span.
+// The spans keep their resource and instrumentation library, and the input trace is not modified.
+func GroupTraces(src pdata.Traces, keyOf func(pdata.Span) string) map[string]pdata.Traces {
+	groups := make(map[string]pdata.Traces)
+	srcRss := src.ResourceSpans()
+	for i := 0; i < srcRss.Len(); i++ {
+		srcRs := srcRss.At(i)
+		destRss := make(map[string]pdata.ResourceSpans)
+		srcIlss := srcRs.InstrumentationLibrarySpans()
+		for j := 0; j < srcIlss.Len(); j++ {
+			srcIls := srcIlss.At(j)
+			destIlss := make(map[string]pdata.InstrumentationLibrarySpans)
+			srcSpans := srcIls.Spans()
+			for k := 0; k < srcSpans.Len(); k++ {
+				span := srcSpans.At(k)
+				key := keyOf(span)
+				destIls, ok := destIlss[key]
+				if !ok {
+					destRs, ok := destRss[key]
+					if !ok {
+						dest, ok := groups[key]
+						if !ok {
+							dest = pdata.NewTraces()
+							groups[key] = dest
+						}
+						destRs = dest.ResourceSpans().AppendEmpty()
+						srcRs.Resource().CopyTo(destRs.Resource())
+						destRs.SetSchemaUrl(srcRs.SchemaUrl())
+						destRss[key] = destRs
+					}
+					destIls = destRs.InstrumentationLibrarySpans().AppendEmpty()
+					srcIls.InstrumentationLibrary().CopyTo(destIls.InstrumentationLibrary())
+					destIls.SetSchemaUrl(srcIls.SchemaUrl())
+					destIlss[key] = destIls
+				}
+				span.CopyTo(destIls.Spans().AppendEmpty())
+			}
+		}
+	}
+	return groups
+}
@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestGroupTraces(t *testing.T) {
+	td := testdata.GenerateTracesTwoSpansSameResourceOneDifferent()
+	groups := GroupTraces(td, func(span pdata.Span) string {
+		return span.Name()
+	})
+	assert.Len(t, groups, 3)
+	for name, group := range groups {
+		assert.Equal(t, 1, group.SpanCount())
+		rs := group.ResourceSpans().At(0)
+		assert.Equal(t, name, rs.InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
+	}
+	// The spans keep their resource.
+	assert.Equal(t, td.ResourceSpans().At(1).Resource(), groups["operationC"].ResourceSpans().At(0).Resource())
+	// The input is not modified.
+	assert.Equal(t, testdata.GenerateTracesTwoSpansSameResourceOneDifferent(), td)
+}
+
+func TestGroupTracesSameKey(t *testing.T) {
+	td := testdata.GenerateTracesTwoSpansSameResourceOneDifferent()
+	groups := GroupTraces(td, func(pdata.Span) string {
+		return "key"
+	})
+	assert.Equal(t, map[string]pdata.Traces{"key": td}, groups)
+}
+
+func TestGroupTracesEmpty(t *testing.T) {
+	assert.Empty(t, GroupTraces(pdata.NewTraces(), func(pdata.Span) string { return "" }))
+}
@@ -0,0 +1,207 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchutil // import "go.opentelemetry.io/collector/internal/batchutil"
+
+import (
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// SplitLogs removes logrecords from the input data and returns a new data of the specified size.
+func SplitLogs(size int, src pdata.Logs) pdata.Logs {
+	if src.LogRecordCount() <= size {
+		return src
+	}
+	totalCopiedLogRecords := 0
+	dest := pdata.NewLogs()
+
+	src.ResourceLogs().RemoveIf(func(srcRl pdata.ResourceLogs) bool {
+		// If we are done skip everything else.
+		if totalCopiedLogRecords == size {
+			return false
+		}
+
+		// If it fully fits
+		srcRlLRC := resourceLRC(srcRl)
+		if (totalCopiedLogRecords + srcRlLRC) <= size {
+			totalCopiedLogRecords += srcRlLRC
+			srcRl.MoveTo(dest.ResourceLogs().AppendEmpty())
+			return true
+		}
+
+		destRl := dest.ResourceLogs().AppendEmpty()
+		srcRl.Resource().CopyTo(destRl.Resource())
+		srcRl.InstrumentationLibraryLogs().RemoveIf(func(srcIll pdata.InstrumentationLibraryLogs) bool {
+			// If we are done skip everything else.
+			if totalCopiedLogRecords == size {
+				return false
+			}
+
+			// If possible to move all metrics do that.
+			srcIllLRC := srcIll.Logs().Len()
+			if size >= srcIllLRC+totalCopiedLogRecords {
+				totalCopiedLogRecords += srcIllLRC
+				srcIll.MoveTo(destRl.InstrumentationLibraryLogs().AppendEmpty())
+				return true
+			}
+
+			destIll := destRl.InstrumentationLibraryLogs().AppendEmpty()
+			srcIll.InstrumentationLibrary().CopyTo(destIll.InstrumentationLibrary())
+			srcIll.Logs().RemoveIf(func(srcMetric pdata.LogRecord) bool {
+				// If we are done skip everything else.
+				if totalCopiedLogRecords == size {
+					return false
+				}
+				srcMetric.MoveTo(destIll.Logs().AppendEmpty())
+				totalCopiedLogRecords++
+				return true
+			})
+			return false
+		})
+		return srcRl.InstrumentationLibraryLogs().Len() == 0
+	})
+
+	return dest
+}
+
+// SplitLogsBySize removes log records from the input logs and returns new logs of at most maxLogs log records and
+// of at most maxBytes bytes once encoded, as measured by sizer. A limit of 0 means no limit. At least one log
+// record is returned, even if it is larger than maxBytes.
+func SplitLogsBySize(maxLogs, maxBytes int, sizer pdata.LogsSizer, src pdata.Logs) pdata.Logs {
+	size := src.LogRecordCount()
+	if maxLogs > 0 && maxLogs < size {
+		size = maxLogs
+	}
+	if maxBytes > 0 {
+		size = logsInBudget(size, maxBytes, sizer, src)
+	}
+	return SplitLogs(size, src)
+}
+
+// SplitLogsOverhead returns an upper bound of the encoded size duplicated by SplitLogsBySize, given the logs it
+// left in src: when the split falls within the first resource or instrumentation library of src, their
+// descriptions are kept in both parts. The size of src is at most its size before the split, minus the size of
+// the returned logs, plus the overhead.
+func SplitLogsOverhead(sizer pdata.LogsSizer, src pdata.Logs) int {
+	rls := src.ResourceLogs()
+	if rls.Len() == 0 {
+		return 0
+	}
+	overhead := resourceLogsHeaderSize(sizer, rls.At(0)) + lengthSlack
+	if ills := rls.At(0).InstrumentationLibraryLogs(); ills.Len() > 0 {
+		overhead += illHeaderSize(sizer, ills.At(0)) + lengthSlack
+	}
+	return overhead
+}
+
+// logsInBudget returns the number of leading log records of src, in the order SplitLogs moves them, that fit
+// into maxBytes, at most limit and at least 1.
+func logsInBudget(limit, maxBytes int, sizer pdata.LogsSizer, src pdata.Logs) int {
+	count, bytes := 0, 0
+	rls := src.ResourceLogs()
+	for i := 0; i < rls.Len() && count < limit; i++ {
+		srcRl := rls.At(i)
+		if rlSize := resourceLogsSize(sizer, srcRl); bytes+rlSize <= maxBytes {
+			bytes += rlSize
+			count += resourceLRC(srcRl)
+			continue
+		}
+
+		// The budget ends in this resource, only the log records that fit are counted.
+		bytes += resourceLogsHeaderSize(sizer, srcRl) + lengthSlack
+		ills := srcRl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			srcIll := ills.At(j)
+			if illSize := illSize(sizer, srcIll); bytes+illSize <= maxBytes {
+				bytes += illSize
+				count += srcIll.Logs().Len()
+				continue
+			}
+
+			bytes += illHeaderSize(sizer, srcIll) + lengthSlack
+			scratch := newILLScratch()
+			scratchLog := scratch.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().AppendEmpty()
+			emptySize := sizer.LogsSize(newILLScratch())
+			logs := srcIll.Logs()
+			for k := 0; k < logs.Len(); k++ {
+				logs.At(k).MoveTo(scratchLog)
+				logSize := sizer.LogsSize(scratch) - emptySize
+				scratchLog.MoveTo(logs.At(k))
+				if bytes+logSize > maxBytes {
+					break
+				}
+				bytes += logSize
+				count++
+			}
+			break
+		}
+		break
+	}
+	if count > limit {
+		return limit
+	}
+	if count < 1 {
+		return 1
+	}
+	return count
+}
+
+// resourceLogsSize returns the encoded size of rl within logs.
+func resourceLogsSize(sizer pdata.LogsSizer, rl pdata.ResourceLogs) int {
+	scratch := pdata.NewLogs()
+	scratchRl := scratch.ResourceLogs().AppendEmpty()
+	rl.MoveTo(scratchRl)
+	size := sizer.LogsSize(scratch)
+	scratchRl.MoveTo(rl)
+	return size
+}
+
+// resourceLogsHeaderSize returns the encoded size of rl within logs, without its log records.
+func resourceLogsHeaderSize(sizer pdata.LogsSizer, rl pdata.ResourceLogs) int {
+	scratch := pdata.NewLogs()
+	rl.Resource().CopyTo(scratch.ResourceLogs().AppendEmpty().Resource())
+	return sizer.LogsSize(scratch)
+}
+
+// illSize returns the encoded size of ill within its resource.
+func illSize(sizer pdata.LogsSizer, ill pdata.InstrumentationLibraryLogs) int {
+	scratch := newILLScratch()
+	scratchIll := scratch.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0)
+	ill.MoveTo(scratchIll)
+	size := sizer.LogsSize(scratch)
+	scratchIll.MoveTo(ill)
+	return size - resourceLogsHeaderSize(sizer, pdata.NewResourceLogs())
+}
+
+// illHeaderSize returns the encoded size of ill within its resource, without its log records.
+func illHeaderSize(sizer pdata.LogsSizer, ill pdata.InstrumentationLibraryLogs) int {
+	scratch := newILLScratch()
+	ill.InstrumentationLibrary().CopyTo(scratch.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).InstrumentationLibrary())
+	return sizer.LogsSize(scratch) - resourceLogsHeaderSize(sizer, pdata.NewResourceLogs())
+}
+
+// newILLScratch returns logs with an empty resource and an empty instrumentation library.
+func newILLScratch() pdata.Logs {
+	scratch := pdata.NewLogs()
+	scratch.ResourceLogs().AppendEmpty().InstrumentationLibraryLogs().AppendEmpty()
+	return scratch
+}
+
+// resourceLRC calculates the total number of log records in the pdata.ResourceLogs.
+func resourceLRC(rs pdata.ResourceLogs) (count int) {
+	for k := 0; k < rs.InstrumentationLibraryLogs().Len(); k++ {
+		count += rs.InstrumentationLibraryLogs().At(k).Logs().Len()
+	}
+	return
+}
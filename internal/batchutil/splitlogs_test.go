@@ -12,21 +12,24 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package batchprocessor
+package batchutil
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
 func TestSplitLogs_noop(t *testing.T) {
 	td := testdata.GenerateLogsManyLogRecordsSameResource(20)
 	splitSize := 40
-	split := splitLogs(splitSize, td)
+	split := SplitLogs(splitSize, td)
 	assert.Equal(t, td, split)
 
 	i := 0
@@ -57,24 +60,24 @@ func TestSplitLogs(t *testing.T) {
 	logs.At(4).CopyTo(cpLogs.AppendEmpty())
 
 	splitSize := 5
-	split := splitLogs(splitSize, ld)
+	split := SplitLogs(splitSize, ld)
 	assert.Equal(t, splitSize, split.LogRecordCount())
 	assert.Equal(t, cp, split)
 	assert.Equal(t, 15, ld.LogRecordCount())
 	assert.Equal(t, "test-log-int-0-0", split.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Name())
 	assert.Equal(t, "test-log-int-0-4", split.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(4).Name())
 
-	split = splitLogs(splitSize, ld)
+	split = SplitLogs(splitSize, ld)
 	assert.Equal(t, 10, ld.LogRecordCount())
 	assert.Equal(t, "test-log-int-0-5", split.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Name())
 	assert.Equal(t, "test-log-int-0-9", split.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(4).Name())
 
-	split = splitLogs(splitSize, ld)
+	split = SplitLogs(splitSize, ld)
 	assert.Equal(t, 5, ld.LogRecordCount())
 	assert.Equal(t, "test-log-int-0-10", split.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Name())
 	assert.Equal(t, "test-log-int-0-14", split.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(4).Name())
 
-	split = splitLogs(splitSize, ld)
+	split = SplitLogs(splitSize, ld)
 	assert.Equal(t, 5, ld.LogRecordCount())
 	assert.Equal(t, "test-log-int-0-15", split.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Name())
 	assert.Equal(t, "test-log-int-0-19", split.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(4).Name())
@@ -95,7 +98,7 @@ func TestSplitLogsMultipleResourceLogs(t *testing.T) {
 	}
 
 	splitSize := 5
-	split := splitLogs(splitSize, td)
+	split := SplitLogs(splitSize, td)
 	assert.Equal(t, splitSize, split.LogRecordCount())
 	assert.Equal(t, 35, td.LogRecordCount())
 	assert.Equal(t, "test-log-int-0-0", split.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Name())
@@ -117,7 +120,7 @@ func TestSplitLogsMultipleResourceLogs_split_size_greater_than_log_size(t *testi
 	}
 
 	splitSize := 25
-	split := splitLogs(splitSize, td)
+	split := SplitLogs(splitSize, td)
 	assert.Equal(t, splitSize, split.LogRecordCount())
 	assert.Equal(t, 40-splitSize, td.LogRecordCount())
 	assert.Equal(t, 1, td.ResourceLogs().Len())
@@ -150,7 +153,7 @@ func TestSplitLogsMultipleILL(t *testing.T) {
 	}
 
 	splitSize := 40
-	split := splitLogs(splitSize, td)
+	split := SplitLogs(splitSize, td)
 	assert.Equal(t, splitSize, split.LogRecordCount())
 	assert.Equal(t, 20, td.LogRecordCount())
 	assert.Equal(t, "test-log-int-0-0", split.ResourceLogs().At(0).InstrumentationLibraryLogs().At(0).Logs().At(0).Name())
@@ -181,9 +184,63 @@ func BenchmarkSplitLogs(b *testing.B) {
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
 		cloneReq := clones[n]
-		split := splitLogs(128, cloneReq)
+		split := SplitLogs(128, cloneReq)
 		if split.LogRecordCount() != 128 || cloneReq.LogRecordCount() != 400-128 {
 			b.Fail()
 		}
 	}
 }
+
+func getTestLogName(requestNum, index int) string {
+	return fmt.Sprintf("test-log-int-%d-%d", requestNum, index)
+}
+
+func TestSplitLogsBySize(t *testing.T) {
+	sizer := otlp.NewProtobufLogsMarshaler().(pdata.LogsSizer)
+	src := func() pdata.Logs {
+		ld := testdata.GenerateLogsManyLogRecordsSameResource(30)
+		testdata.GenerateLogsTwoLogRecordsSameResourceOneDifferent().ResourceLogs().MoveAndAppendTo(ld.ResourceLogs())
+		testdata.GenerateLogsManyLogRecordsSameResource(40).ResourceLogs().MoveAndAppendTo(ld.ResourceLogs())
+		return ld
+	}()
+	total := src.LogRecordCount()
+	maxBytes := sizer.LogsSize(src) / 5
+
+	count := 0
+	estimatedSize := sizer.LogsSize(src)
+	for {
+		remaining := src.LogRecordCount()
+		split := SplitLogsBySize(0, maxBytes, sizer, src)
+		require.Greater(t, split.LogRecordCount(), 0)
+		assert.LessOrEqual(t, sizer.LogsSize(split), maxBytes)
+		count += split.LogRecordCount()
+		if split.LogRecordCount() == remaining {
+			break
+		}
+		// The size left can be tracked without measuring it again.
+		estimatedSize += SplitLogsOverhead(sizer, src) - sizer.LogsSize(split)
+		assert.LessOrEqual(t, sizer.LogsSize(src), estimatedSize)
+		// Splits are not much smaller than the budget.
+		assert.Greater(t, sizer.LogsSize(split), maxBytes*3/4)
+	}
+	assert.Equal(t, total, count)
+}
+
+func TestSplitLogsBySize_Limits(t *testing.T) {
+	sizer := otlp.NewProtobufLogsMarshaler().(pdata.LogsSizer)
+	src := func() pdata.Logs {
+		ld := testdata.GenerateLogsManyLogRecordsSameResource(30)
+		testdata.GenerateLogsTwoLogRecordsSameResourceOneDifferent().ResourceLogs().MoveAndAppendTo(ld.ResourceLogs())
+		testdata.GenerateLogsManyLogRecordsSameResource(40).ResourceLogs().MoveAndAppendTo(ld.ResourceLogs())
+		return ld
+	}()
+	total := src.LogRecordCount()
+
+	split := SplitLogsBySize(3, 1<<20, sizer, src)
+	assert.Equal(t, 3, split.LogRecordCount())
+	assert.Equal(t, total-3, src.LogRecordCount())
+
+	// An item larger than the budget is sent alone.
+	split = SplitLogsBySize(0, 1, sizer, src)
+	assert.Equal(t, 1, split.LogRecordCount())
+}
@@ -0,0 +1,403 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchutil // import "go.opentelemetry.io/collector/internal/batchutil"
+
+import (
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// SplitMetrics removes metrics from the input data and returns a new data of the specified size.
+func SplitMetrics(size int, src pdata.Metrics) pdata.Metrics {
+	dataPoints := src.DataPointCount()
+	if dataPoints <= size {
+		return src
+	}
+	totalCopiedDataPoints := 0
+	dest := pdata.NewMetrics()
+
+	src.ResourceMetrics().RemoveIf(func(srcRs pdata.ResourceMetrics) bool {
+		// If we are done skip everything else.
+		if totalCopiedDataPoints == size {
+			return false
+		}
+
+		// If it fully fits
+		srcRsDataPointCount := resourceMetricsDPC(srcRs)
+		if (totalCopiedDataPoints + srcRsDataPointCount) <= size {
+			totalCopiedDataPoints += srcRsDataPointCount
+			srcRs.MoveTo(dest.ResourceMetrics().AppendEmpty())
+			return true
+		}
+
+		destRs := dest.ResourceMetrics().AppendEmpty()
+		srcRs.Resource().CopyTo(destRs.Resource())
+		srcRs.InstrumentationLibraryMetrics().RemoveIf(func(srcIlm pdata.InstrumentationLibraryMetrics) bool {
+			// If we are done skip everything else.
+			if totalCopiedDataPoints == size {
+				return false
+			}
+
+			// If possible to move all metrics do that.
+			srcIlmDataPointCount := instrumentationLibraryMetricsDPC(srcIlm)
+			if srcIlmDataPointCount+totalCopiedDataPoints <= size {
+				totalCopiedDataPoints += srcIlmDataPointCount
+				srcIlm.MoveTo(destRs.InstrumentationLibraryMetrics().AppendEmpty())
+				return true
+			}
+
+			destIlm := destRs.InstrumentationLibraryMetrics().AppendEmpty()
+			srcIlm.InstrumentationLibrary().CopyTo(destIlm.InstrumentationLibrary())
+			srcIlm.Metrics().RemoveIf(func(srcMetric pdata.Metric) bool {
+				// If we are done skip everything else.
+				if totalCopiedDataPoints == size {
+					return false
+				}
+
+				// If possible to move all points do that.
+				srcMetricDataPointCount := metricDPC(srcMetric)
+				if srcMetricDataPointCount+totalCopiedDataPoints <= size {
+					totalCopiedDataPoints += srcMetricDataPointCount
+					srcMetric.MoveTo(destIlm.Metrics().AppendEmpty())
+					return true
+				}
+
+				// If the metric has more data points than free slots we should split it.
+				copiedDataPoints, remove := splitMetric(srcMetric, destIlm.Metrics().AppendEmpty(), size-totalCopiedDataPoints)
+				totalCopiedDataPoints += copiedDataPoints
+				return remove
+			})
+			return false
+		})
+		return srcRs.InstrumentationLibraryMetrics().Len() == 0
+	})
+
+	return dest
+}
+
+// SplitMetricsBySize removes data points from the input metrics and returns new metrics of at most maxDataPoints
+// data points and of at most maxBytes bytes once encoded, as measured by sizer. A limit of 0 means no limit. At
+// least one data point is returned, even if it is larger than maxBytes.
+func SplitMetricsBySize(maxDataPoints, maxBytes int, sizer pdata.MetricsSizer, src pdata.Metrics) pdata.Metrics {
+	size := src.DataPointCount()
+	if maxDataPoints > 0 && maxDataPoints < size {
+		size = maxDataPoints
+	}
+	if maxBytes > 0 {
+		size = dataPointsInBudget(size, maxBytes, sizer, src)
+	}
+	return SplitMetrics(size, src)
+}
+
+// SplitMetricsOverhead returns an upper bound of the encoded size duplicated by SplitMetricsBySize, given the
+// metrics it left in src: when the split falls within the first resource, instrumentation library or metric of
+// src, their descriptions are kept in both parts. The size of src is at most its size before the split, minus
+// the size of the returned metrics, plus the overhead.
+func SplitMetricsOverhead(sizer pdata.MetricsSizer, src pdata.Metrics) int {
+	rms := src.ResourceMetrics()
+	if rms.Len() == 0 {
+		return 0
+	}
+	overhead := resourceMetricsHeaderSize(sizer, rms.At(0)) + lengthSlack
+	ilms := rms.At(0).InstrumentationLibraryMetrics()
+	if ilms.Len() == 0 {
+		return overhead
+	}
+	overhead += ilmHeaderSize(sizer, ilms.At(0)) + lengthSlack
+	if metrics := ilms.At(0).Metrics(); metrics.Len() > 0 {
+		overhead += metricHeaderSize(sizer, metrics.At(0)) + lengthSlack
+	}
+	return overhead
+}
+
+// dataPointsInBudget returns the number of leading data points of src, in the order SplitMetrics moves them,
+// that fit into maxBytes, at most limit and at least 1.
+func dataPointsInBudget(limit, maxBytes int, sizer pdata.MetricsSizer, src pdata.Metrics) int {
+	count, bytes := 0, 0
+	rms := src.ResourceMetrics()
+	for i := 0; i < rms.Len() && count < limit; i++ {
+		srcRm := rms.At(i)
+		if rmSize := resourceMetricsSize(sizer, srcRm); bytes+rmSize <= maxBytes {
+			bytes += rmSize
+			count += resourceMetricsDPC(srcRm)
+			continue
+		}
+
+		// The budget ends in this resource, only the data points that fit are counted.
+		bytes += resourceMetricsHeaderSize(sizer, srcRm) + lengthSlack
+		ilms := srcRm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			srcIlm := ilms.At(j)
+			if ilmSize := ilmSize(sizer, srcIlm); bytes+ilmSize <= maxBytes {
+				bytes += ilmSize
+				count += instrumentationLibraryMetricsDPC(srcIlm)
+				continue
+			}
+
+			bytes += ilmHeaderSize(sizer, srcIlm) + lengthSlack
+			metrics := srcIlm.Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				srcMetric := metrics.At(k)
+				if metricSize := metricSize(sizer, srcMetric); bytes+metricSize <= maxBytes {
+					bytes += metricSize
+					count += metricDPC(srcMetric)
+					continue
+				}
+
+				count += metricDataPointsInBudget(maxBytes-bytes-lengthSlack, sizer, srcMetric)
+				break
+			}
+			break
+		}
+		break
+	}
+	if count > limit {
+		return limit
+	}
+	if count < 1 {
+		return 1
+	}
+	return count
+}
+
+// metricDataPointsInBudget returns the number of leading data points of ms that fit, with the description of
+// ms, into maxBytes.
+func metricDataPointsInBudget(maxBytes int, sizer pdata.MetricsSizer, ms pdata.Metric) int {
+	scratch := newILMScratch()
+	scratchMetric := scratch.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().AppendEmpty()
+	splitMetric(ms, scratchMetric, 0)
+	emptySize := sizer.MetricsSize(scratch)
+	count := 0
+	bytes := emptySize - sizer.MetricsSize(newILMScratch())
+	fits := func() bool {
+		dpSize := sizer.MetricsSize(scratch) - emptySize
+		if bytes+dpSize > maxBytes {
+			return false
+		}
+		bytes += dpSize
+		count++
+		return true
+	}
+
+	switch ms.DataType() {
+	case pdata.MetricDataTypeGauge:
+		measureNumberDataPoints(ms.Gauge().DataPoints(), scratchMetric.Gauge().DataPoints(), fits)
+	case pdata.MetricDataTypeSum:
+		measureNumberDataPoints(ms.Sum().DataPoints(), scratchMetric.Sum().DataPoints(), fits)
+	case pdata.MetricDataTypeHistogram:
+		measureHistogramDataPoints(ms.Histogram().DataPoints(), scratchMetric.Histogram().DataPoints(), fits)
+	case pdata.MetricDataTypeSummary:
+		measureSummaryDataPoints(ms.Summary().DataPoints(), scratchMetric.Summary().DataPoints(), fits)
+	}
+	return count
+}
+
+// measureNumberDataPoints moves the data points of src, one after the other, alone into scratch and calls fits,
+// until it returns false.
+func measureNumberDataPoints(src, scratch pdata.NumberDataPointSlice, fits func() bool) {
+	scratchDp := scratch.AppendEmpty()
+	for i := 0; i < src.Len(); i++ {
+		src.At(i).MoveTo(scratchDp)
+		ok := fits()
+		scratchDp.MoveTo(src.At(i))
+		if !ok {
+			return
+		}
+	}
+}
+
+// measureHistogramDataPoints is measureNumberDataPoints for histogram data points.
+func measureHistogramDataPoints(src, scratch pdata.HistogramDataPointSlice, fits func() bool) {
+	scratchDp := scratch.AppendEmpty()
+	for i := 0; i < src.Len(); i++ {
+		src.At(i).MoveTo(scratchDp)
+		ok := fits()
+		scratchDp.MoveTo(src.At(i))
+		if !ok {
+			return
+		}
+	}
+}
+
+// measureSummaryDataPoints is measureNumberDataPoints for summary data points.
+func measureSummaryDataPoints(src, scratch pdata.SummaryDataPointSlice, fits func() bool) {
+	scratchDp := scratch.AppendEmpty()
+	for i := 0; i < src.Len(); i++ {
+		src.At(i).MoveTo(scratchDp)
+		ok := fits()
+		scratchDp.MoveTo(src.At(i))
+		if !ok {
+			return
+		}
+	}
+}
+
+// resourceMetricsSize returns the encoded size of rm within metrics.
+func resourceMetricsSize(sizer pdata.MetricsSizer, rm pdata.ResourceMetrics) int {
+	scratch := pdata.NewMetrics()
+	scratchRm := scratch.ResourceMetrics().AppendEmpty()
+	rm.MoveTo(scratchRm)
+	size := sizer.MetricsSize(scratch)
+	scratchRm.MoveTo(rm)
+	return size
+}
+
+// resourceMetricsHeaderSize returns the encoded size of rm within metrics, without its metrics.
+func resourceMetricsHeaderSize(sizer pdata.MetricsSizer, rm pdata.ResourceMetrics) int {
+	scratch := pdata.NewMetrics()
+	rm.Resource().CopyTo(scratch.ResourceMetrics().AppendEmpty().Resource())
+	return sizer.MetricsSize(scratch)
+}
+
+// ilmSize returns the encoded size of ilm within its resource.
+func ilmSize(sizer pdata.MetricsSizer, ilm pdata.InstrumentationLibraryMetrics) int {
+	scratch := newILMScratch()
+	scratchIlm := scratch.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0)
+	ilm.MoveTo(scratchIlm)
+	size := sizer.MetricsSize(scratch)
+	scratchIlm.MoveTo(ilm)
+	return size - resourceMetricsHeaderSize(sizer, pdata.NewResourceMetrics())
+}
+
+// ilmHeaderSize returns the encoded size of ilm within its resource, without its metrics.
+func ilmHeaderSize(sizer pdata.MetricsSizer, ilm pdata.InstrumentationLibraryMetrics) int {
+	scratch := newILMScratch()
+	ilm.InstrumentationLibrary().CopyTo(scratch.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).InstrumentationLibrary())
+	return sizer.MetricsSize(scratch) - resourceMetricsHeaderSize(sizer, pdata.NewResourceMetrics())
+}
+
+// metricSize returns the encoded size of ms within its instrumentation library.
+func metricSize(sizer pdata.MetricsSizer, ms pdata.Metric) int {
+	scratch := newILMScratch()
+	scratchMetric := scratch.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().AppendEmpty()
+	ms.MoveTo(scratchMetric)
+	size := sizer.MetricsSize(scratch)
+	scratchMetric.MoveTo(ms)
+	return size - sizer.MetricsSize(newILMScratch())
+}
+
+// metricHeaderSize returns the encoded size of ms within its instrumentation library, without its data points.
+func metricHeaderSize(sizer pdata.MetricsSizer, ms pdata.Metric) int {
+	scratch := newILMScratch()
+	splitMetric(ms, scratch.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().AppendEmpty(), 0)
+	return sizer.MetricsSize(scratch) - sizer.MetricsSize(newILMScratch())
+}
+
+// newILMScratch returns metrics with an empty resource and an empty instrumentation library.
+func newILMScratch() pdata.Metrics {
+	scratch := pdata.NewMetrics()
+	scratch.ResourceMetrics().AppendEmpty().InstrumentationLibraryMetrics().AppendEmpty()
+	return scratch
+}
+
+// resourceMetricsDPC calculates the total number of data points in the pdata.ResourceMetrics.
+func resourceMetricsDPC(rs pdata.ResourceMetrics) int {
+	dataPointCount := 0
+	ilms := rs.InstrumentationLibraryMetrics()
+	for k := 0; k < ilms.Len(); k++ {
+		dataPointCount += instrumentationLibraryMetricsDPC(ilms.At(k))
+	}
+	return dataPointCount
+}
+
+// instrumentationLibraryMetricsDPC calculates the total number of data points in the pdata.InstrumentationLibraryMetrics.
+func instrumentationLibraryMetricsDPC(ilm pdata.InstrumentationLibraryMetrics) int {
+	dataPointCount := 0
+	ms := ilm.Metrics()
+	for k := 0; k < ms.Len(); k++ {
+		dataPointCount += metricDPC(ms.At(k))
+	}
+	return dataPointCount
+}
+
+// metricDPC calculates the total number of data points in the pdata.Metric.
+func metricDPC(ms pdata.Metric) int {
+	switch ms.DataType() {
+	case pdata.MetricDataTypeGauge:
+		return ms.Gauge().DataPoints().Len()
+	case pdata.MetricDataTypeSum:
+		return ms.Sum().DataPoints().Len()
+	case pdata.MetricDataTypeHistogram:
+		return ms.Histogram().DataPoints().Len()
+	case pdata.MetricDataTypeSummary:
+		return ms.Summary().DataPoints().Len()
+	}
+	return 0
+}
+
+// splitMetric removes metric points from the input data and moves data of the specified size to destination.
+// Returns size of moved data and boolean describing, whether the metric should be removed from original slice.
+func splitMetric(ms, dest pdata.Metric, size int) (int, bool) {
+	dest.SetDataType(ms.DataType())
+	dest.SetName(ms.Name())
+	dest.SetDescription(ms.Description())
+	dest.SetUnit(ms.Unit())
+
+	switch ms.DataType() {
+	case pdata.MetricDataTypeGauge:
+		return splitNumberDataPoints(ms.Gauge().DataPoints(), dest.Gauge().DataPoints(), size)
+	case pdata.MetricDataTypeSum:
+		dest.Sum().SetAggregationTemporality(ms.Sum().AggregationTemporality())
+		dest.Sum().SetIsMonotonic(ms.Sum().IsMonotonic())
+		return splitNumberDataPoints(ms.Sum().DataPoints(), dest.Sum().DataPoints(), size)
+	case pdata.MetricDataTypeHistogram:
+		dest.Histogram().SetAggregationTemporality(ms.Histogram().AggregationTemporality())
+		return splitHistogramDataPoints(ms.Histogram().DataPoints(), dest.Histogram().DataPoints(), size)
+	case pdata.MetricDataTypeSummary:
+		return splitSummaryDataPoints(ms.Summary().DataPoints(), dest.Summary().DataPoints(), size)
+	}
+	return size, false
+}
+
+func splitNumberDataPoints(src, dst pdata.NumberDataPointSlice, size int) (int, bool) {
+	dst.EnsureCapacity(size)
+	i := 0
+	src.RemoveIf(func(dp pdata.NumberDataPoint) bool {
+		if i < size {
+			dp.MoveTo(dst.AppendEmpty())
+			i++
+			return true
+		}
+		return false
+	})
+	return size, false
+}
+
+func splitHistogramDataPoints(src, dst pdata.HistogramDataPointSlice, size int) (int, bool) {
+	dst.EnsureCapacity(size)
+	i := 0
+	src.RemoveIf(func(dp pdata.HistogramDataPoint) bool {
+		if i < size {
+			dp.MoveTo(dst.AppendEmpty())
+			i++
+			return true
+		}
+		return false
+	})
+	return size, false
+}
+
+func splitSummaryDataPoints(src, dst pdata.SummaryDataPointSlice, size int) (int, bool) {
+	dst.EnsureCapacity(size)
+	i := 0
+	src.RemoveIf(func(dp pdata.SummaryDataPoint) bool {
+		if i < size {
+			dp.MoveTo(dst.AppendEmpty())
+			i++
+			return true
+		}
+		return false
+	})
+	return size, false
+}
@@ -12,21 +12,24 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package batchprocessor
+package batchutil
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
 func TestSplitMetrics_noop(t *testing.T) {
 	td := testdata.GenerateMetricsManyMetricsSameResource(20)
 	splitSize := 40
-	split := splitMetrics(splitSize, td)
+	split := SplitMetrics(splitSize, td)
 	assert.Equal(t, td, split)
 
 	i := 0
@@ -60,24 +63,24 @@ func TestSplitMetrics(t *testing.T) {
 
 	splitMetricCount := 5
 	splitSize := splitMetricCount * dataPointCount
-	split := splitMetrics(splitSize, md)
+	split := SplitMetrics(splitSize, md)
 	assert.Equal(t, splitMetricCount, split.MetricCount())
 	assert.Equal(t, cp, split)
 	assert.Equal(t, 15, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-0", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-4", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(4).Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	assert.Equal(t, 10, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-5", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-9", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(4).Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	assert.Equal(t, 5, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-10", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-14", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(4).Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	assert.Equal(t, 5, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-15", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-19", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(4).Name())
@@ -101,7 +104,7 @@ func TestSplitMetricsMultipleResourceSpans(t *testing.T) {
 
 	splitMetricCount := 5
 	splitSize := splitMetricCount * dataPointCount
-	split := splitMetrics(splitSize, md)
+	split := SplitMetrics(splitSize, md)
 	assert.Equal(t, splitMetricCount, split.MetricCount())
 	assert.Equal(t, 35, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-0", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Name())
@@ -126,7 +129,7 @@ func TestSplitMetricsMultipleResourceSpans_SplitSizeGreaterThanMetricSize(t *tes
 
 	splitMetricCount := 25
 	splitSize := splitMetricCount * dataPointCount
-	split := splitMetrics(splitSize, td)
+	split := SplitMetrics(splitSize, td)
 	assert.Equal(t, splitMetricCount, split.MetricCount())
 	assert.Equal(t, 40-splitMetricCount, td.MetricCount())
 	assert.Equal(t, 1, td.ResourceMetrics().Len())
@@ -146,19 +149,19 @@ func TestSplitMetricsUneven(t *testing.T) {
 	}
 
 	splitSize := 9
-	split := splitMetrics(splitSize, md)
+	split := SplitMetrics(splitSize, md)
 	assert.Equal(t, 5, split.MetricCount())
 	assert.Equal(t, 6, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-0", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-4", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(4).Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	assert.Equal(t, 5, split.MetricCount())
 	assert.Equal(t, 1, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-4", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Name())
 	assert.Equal(t, "test-metric-int-0-8", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(4).Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, "test-metric-int-0-9", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Name())
 }
@@ -177,14 +180,14 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	// and then split by 2 for the rest so that each metric is split in half.
 	// Verify that descriptors are preserved for all data types across splits.
 
-	split := splitMetrics(1, md)
+	split := SplitMetrics(1, md)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, 6, md.MetricCount())
 	gaugeInt := split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, gaugeInt.Gauge().DataPoints().Len())
 	assert.Equal(t, "test-metric-int-0-0", gaugeInt.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	assert.Equal(t, 2, split.MetricCount())
 	assert.Equal(t, 5, md.MetricCount())
 	gaugeInt = split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
@@ -194,7 +197,7 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	assert.Equal(t, 1, gaugeDouble.Gauge().DataPoints().Len())
 	assert.Equal(t, "test-metric-int-0-1", gaugeDouble.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	assert.Equal(t, 2, split.MetricCount())
 	assert.Equal(t, 4, md.MetricCount())
 	gaugeDouble = split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
@@ -206,7 +209,7 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	assert.Equal(t, true, sumInt.Sum().IsMonotonic())
 	assert.Equal(t, "test-metric-int-0-2", sumInt.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	assert.Equal(t, 2, split.MetricCount())
 	assert.Equal(t, 3, md.MetricCount())
 	sumInt = split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
@@ -220,7 +223,7 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	assert.Equal(t, true, sumDouble.Sum().IsMonotonic())
 	assert.Equal(t, "test-metric-int-0-3", sumDouble.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	assert.Equal(t, 2, split.MetricCount())
 	assert.Equal(t, 2, md.MetricCount())
 	sumDouble = split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
@@ -233,7 +236,7 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	assert.Equal(t, pdata.MetricAggregationTemporalityCumulative, doubleHistogram.Histogram().AggregationTemporality())
 	assert.Equal(t, "test-metric-int-0-4", doubleHistogram.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	assert.Equal(t, 2, split.MetricCount())
 	assert.Equal(t, 1, md.MetricCount())
 	doubleHistogram = split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
@@ -244,7 +247,7 @@ func TestSplitMetricsAllTypes(t *testing.T) {
 	assert.Equal(t, 1, doubleSummary.Summary().DataPoints().Len())
 	assert.Equal(t, "test-metric-int-0-5", doubleSummary.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	doubleSummary = split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, doubleSummary.Summary().DataPoints().Len())
 	assert.Equal(t, "test-metric-int-0-5", doubleSummary.Name())
@@ -260,7 +263,7 @@ func TestSplitMetricsBatchSizeSmallerThanDataPointCount(t *testing.T) {
 	}
 
 	splitSize := 1
-	split := splitMetrics(splitSize, md)
+	split := SplitMetrics(splitSize, md)
 	splitMetric := split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, 2, md.MetricCount())
@@ -268,7 +271,7 @@ func TestSplitMetricsBatchSizeSmallerThanDataPointCount(t *testing.T) {
 	assert.Equal(t, true, splitMetric.Sum().IsMonotonic())
 	assert.Equal(t, "test-metric-int-0-0", splitMetric.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	splitMetric = split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, 1, md.MetricCount())
@@ -276,7 +279,7 @@ func TestSplitMetricsBatchSizeSmallerThanDataPointCount(t *testing.T) {
 	assert.Equal(t, true, splitMetric.Sum().IsMonotonic())
 	assert.Equal(t, "test-metric-int-0-0", splitMetric.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	splitMetric = split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, 1, md.MetricCount())
@@ -284,7 +287,7 @@ func TestSplitMetricsBatchSizeSmallerThanDataPointCount(t *testing.T) {
 	assert.Equal(t, true, splitMetric.Sum().IsMonotonic())
 	assert.Equal(t, "test-metric-int-0-1", splitMetric.Name())
 
-	split = splitMetrics(splitSize, md)
+	split = SplitMetrics(splitSize, md)
 	splitMetric = split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0)
 	assert.Equal(t, 1, split.MetricCount())
 	assert.Equal(t, 1, md.MetricCount())
@@ -315,7 +318,7 @@ func TestSplitMetricsMultipleILM(t *testing.T) {
 
 	splitMetricCount := 40
 	splitSize := splitMetricCount * dataPointCount
-	split := splitMetrics(splitSize, md)
+	split := SplitMetrics(splitSize, md)
 	assert.Equal(t, splitMetricCount, split.MetricCount())
 	assert.Equal(t, 20, md.MetricCount())
 	assert.Equal(t, "test-metric-int-0-0", split.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Name())
@@ -346,9 +349,71 @@ func BenchmarkSplitMetrics(b *testing.B) {
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
 		cloneReq := clones[n]
-		split := splitMetrics(128*dataPointCount, cloneReq)
+		split := SplitMetrics(128*dataPointCount, cloneReq)
 		if split.MetricCount() != 128 || cloneReq.MetricCount() != 400-128 {
 			b.Fail()
 		}
 	}
 }
+
+func getTestMetricName(requestNum, index int) string {
+	return fmt.Sprintf("test-metric-int-%d-%d", requestNum, index)
+}
+
+func TestSplitMetricsBySize(t *testing.T) {
+	sizer := otlp.NewProtobufMetricsMarshaler().(pdata.MetricsSizer)
+	src := func() pdata.Metrics {
+		md := testdata.GenerateMetricsManyMetricsSameResource(30)
+		// A metric with many data points, to be split.
+		dps := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Sum().DataPoints()
+		for i := 0; i < 30; i++ {
+			dps.At(0).CopyTo(dps.AppendEmpty())
+		}
+		testdata.GenerateMetricsAllTypesEmptyDataPoint().ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
+		return md
+	}()
+	total := src.DataPointCount()
+	maxBytes := sizer.MetricsSize(src) / 5
+
+	count := 0
+	estimatedSize := sizer.MetricsSize(src)
+	for {
+		remaining := src.DataPointCount()
+		split := SplitMetricsBySize(0, maxBytes, sizer, src)
+		require.Greater(t, split.DataPointCount(), 0)
+		assert.LessOrEqual(t, sizer.MetricsSize(split), maxBytes)
+		count += split.DataPointCount()
+		if split.DataPointCount() == remaining {
+			break
+		}
+		// The size left can be tracked without measuring it again.
+		estimatedSize += SplitMetricsOverhead(sizer, src) - sizer.MetricsSize(split)
+		assert.LessOrEqual(t, sizer.MetricsSize(src), estimatedSize)
+		// Splits are not much smaller than the budget.
+		assert.Greater(t, sizer.MetricsSize(split), maxBytes*3/4)
+	}
+	assert.Equal(t, total, count)
+}
+
+func TestSplitMetricsBySize_Limits(t *testing.T) {
+	sizer := otlp.NewProtobufMetricsMarshaler().(pdata.MetricsSizer)
+	src := func() pdata.Metrics {
+		md := testdata.GenerateMetricsManyMetricsSameResource(30)
+		// A metric with many data points, to be split.
+		dps := md.ResourceMetrics().At(0).InstrumentationLibraryMetrics().At(0).Metrics().At(0).Sum().DataPoints()
+		for i := 0; i < 30; i++ {
+			dps.At(0).CopyTo(dps.AppendEmpty())
+		}
+		testdata.GenerateMetricsAllTypesEmptyDataPoint().ResourceMetrics().MoveAndAppendTo(md.ResourceMetrics())
+		return md
+	}()
+	total := src.DataPointCount()
+
+	split := SplitMetricsBySize(3, 1<<20, sizer, src)
+	assert.Equal(t, 3, split.DataPointCount())
+	assert.Equal(t, total-3, src.DataPointCount())
+
+	// An item larger than the budget is sent alone.
+	split = SplitMetricsBySize(0, 1, sizer, src)
+	assert.Equal(t, 1, split.DataPointCount())
+}
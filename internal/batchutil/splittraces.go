@@ -0,0 +1,211 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batchutil // import "go.opentelemetry.io/collector/internal/batchutil"
+
+import (
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// lengthSlack is the maximum growth of the encoded length of a message whose size is measured before it is
+// filled, a varint of up to 5 bytes.
+const lengthSlack = 4
+
+// SplitTraces removes spans from the input trace and returns a new trace of the specified size.
+func SplitTraces(size int, src pdata.Traces) pdata.Traces {
+	if src.SpanCount() <= size {
+		return src
+	}
+	totalCopiedSpans := 0
+	dest := pdata.NewTraces()
+
+	src.ResourceSpans().RemoveIf(func(srcRs pdata.ResourceSpans) bool {
+		// If we are done skip everything else.
+		if totalCopiedSpans == size {
+			return false
+		}
+
+		// If it fully fits
+		srcRsSC := resourceSC(srcRs)
+		if (totalCopiedSpans + srcRsSC) <= size {
+			totalCopiedSpans += srcRsSC
+			srcRs.MoveTo(dest.ResourceSpans().AppendEmpty())
+			return true
+		}
+
+		destRs := dest.ResourceSpans().AppendEmpty()
+		srcRs.Resource().CopyTo(destRs.Resource())
+		srcRs.InstrumentationLibrarySpans().RemoveIf(func(srcIls pdata.InstrumentationLibrarySpans) bool {
+			// If we are done skip everything else.
+			if totalCopiedSpans == size {
+				return false
+			}
+
+			// If possible to move all metrics do that.
+			srcIlsSC := srcIls.Spans().Len()
+			if size-totalCopiedSpans >= srcIlsSC {
+				totalCopiedSpans += srcIlsSC
+				srcIls.MoveTo(destRs.InstrumentationLibrarySpans().AppendEmpty())
+				return true
+			}
+
+			destIls := destRs.InstrumentationLibrarySpans().AppendEmpty()
+			srcIls.InstrumentationLibrary().CopyTo(destIls.InstrumentationLibrary())
+			srcIls.Spans().RemoveIf(func(srcSpan pdata.Span) bool {
+				// If we are done skip everything else.
+				if totalCopiedSpans == size {
+					return false
+				}
+				srcSpan.MoveTo(destIls.Spans().AppendEmpty())
+				totalCopiedSpans++
+				return true
+			})
+			return false
+		})
+		return srcRs.InstrumentationLibrarySpans().Len() == 0
+	})
+
+	return dest
+}
+
+// SplitTracesBySize removes spans from the input traces and returns new traces of at most maxSpans spans and of
+// at most maxBytes bytes once encoded, as measured by sizer. A limit of 0 means no limit. At least one span is
+// returned, even if it is larger than maxBytes.
+func SplitTracesBySize(maxSpans, maxBytes int, sizer pdata.TracesSizer, src pdata.Traces) pdata.Traces {
+	size := src.SpanCount()
+	if maxSpans > 0 && maxSpans < size {
+		size = maxSpans
+	}
+	if maxBytes > 0 {
+		size = spansInBudget(size, maxBytes, sizer, src)
+	}
+	return SplitTraces(size, src)
+}
+
+// SplitTracesOverhead returns an upper bound of the encoded size duplicated by SplitTracesBySize, given the traces
+// it left in src: when the split falls within the first resource or instrumentation library of src, their
+// descriptions are kept in both parts. The size of src is at most its size before the split, minus the size of
+// the returned traces, plus the overhead.
+func SplitTracesOverhead(sizer pdata.TracesSizer, src pdata.Traces) int {
+	rss := src.ResourceSpans()
+	if rss.Len() == 0 {
+		return 0
+	}
+	overhead := resourceSpansHeaderSize(sizer, rss.At(0)) + lengthSlack
+	if ilss := rss.At(0).InstrumentationLibrarySpans(); ilss.Len() > 0 {
+		overhead += ilsHeaderSize(sizer, ilss.At(0)) + lengthSlack
+	}
+	return overhead
+}
+
+// spansInBudget returns the number of leading spans of src, in the order SplitTraces moves them, that fit into
+// maxBytes, at most limit and at least 1.
+func spansInBudget(limit, maxBytes int, sizer pdata.TracesSizer, src pdata.Traces) int {
+	count, bytes := 0, 0
+	rss := src.ResourceSpans()
+	for i := 0; i < rss.Len() && count < limit; i++ {
+		srcRs := rss.At(i)
+		if rsSize := resourceSpansSize(sizer, srcRs); bytes+rsSize <= maxBytes {
+			bytes += rsSize
+			count += resourceSC(srcRs)
+			continue
+		}
+
+		// The budget ends in this resource, only the spans that fit are counted.
+		bytes += resourceSpansHeaderSize(sizer, srcRs) + lengthSlack
+		ilss := srcRs.InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			srcIls := ilss.At(j)
+			if ilsSize := ilsSize(sizer, srcIls); bytes+ilsSize <= maxBytes {
+				bytes += ilsSize
+				count += srcIls.Spans().Len()
+				continue
+			}
+
+			bytes += ilsHeaderSize(sizer, srcIls) + lengthSlack
+			scratch := newILSScratch()
+			scratchSpan := scratch.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().AppendEmpty()
+			emptySize := sizer.TracesSize(newILSScratch())
+			spans := srcIls.Spans()
+			for k := 0; k < spans.Len(); k++ {
+				spans.At(k).MoveTo(scratchSpan)
+				spanSize := sizer.TracesSize(scratch) - emptySize
+				scratchSpan.MoveTo(spans.At(k))
+				if bytes+spanSize > maxBytes {
+					break
+				}
+				bytes += spanSize
+				count++
+			}
+			break
+		}
+		break
+	}
+	if count > limit {
+		return limit
+	}
+	if count < 1 {
+		return 1
+	}
+	return count
+}
+
+// resourceSpansSize returns the encoded size of rs within traces.
+func resourceSpansSize(sizer pdata.TracesSizer, rs pdata.ResourceSpans) int {
+	scratch := pdata.NewTraces()
+	scratchRs := scratch.ResourceSpans().AppendEmpty()
+	rs.MoveTo(scratchRs)
+	size := sizer.TracesSize(scratch)
+	scratchRs.MoveTo(rs)
+	return size
+}
+
+// resourceSpansHeaderSize returns the encoded size of rs within traces, without its spans.
+func resourceSpansHeaderSize(sizer pdata.TracesSizer, rs pdata.ResourceSpans) int {
+	scratch := pdata.NewTraces()
+	rs.Resource().CopyTo(scratch.ResourceSpans().AppendEmpty().Resource())
+	return sizer.TracesSize(scratch)
+}
+
+// ilsSize returns the encoded size of ils within its resource.
+func ilsSize(sizer pdata.TracesSizer, ils pdata.InstrumentationLibrarySpans) int {
+	scratch := newILSScratch()
+	scratchIls := scratch.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0)
+	ils.MoveTo(scratchIls)
+	size := sizer.TracesSize(scratch)
+	scratchIls.MoveTo(ils)
+	return size - resourceSpansHeaderSize(sizer, pdata.NewResourceSpans())
+}
+
+// ilsHeaderSize returns the encoded size of ils within its resource, without its spans.
+func ilsHeaderSize(sizer pdata.TracesSizer, ils pdata.InstrumentationLibrarySpans) int {
+	scratch := newILSScratch()
+	ils.InstrumentationLibrary().CopyTo(scratch.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).InstrumentationLibrary())
+	return sizer.TracesSize(scratch) - resourceSpansHeaderSize(sizer, pdata.NewResourceSpans())
+}
+
+// newILSScratch returns traces with an empty resource and an empty instrumentation library.
+func newILSScratch() pdata.Traces {
+	scratch := pdata.NewTraces()
+	scratch.ResourceSpans().AppendEmpty().InstrumentationLibrarySpans().AppendEmpty()
+	return scratch
+}
+
+// resourceSC calculates the total number of spans in the pdata.ResourceSpans.
+func resourceSC(rs pdata.ResourceSpans) (count int) {
+	for k := 0; k < rs.InstrumentationLibrarySpans().Len(); k++ {
+		count += rs.InstrumentationLibrarySpans().At(k).Spans().Len()
+	}
+	return
+}
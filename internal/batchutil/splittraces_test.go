@@ -12,21 +12,24 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package batchprocessor
+package batchutil
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
 
 func TestSplitTraces_noop(t *testing.T) {
 	td := testdata.GenerateTracesManySpansSameResource(20)
 	splitSize := 40
-	split := splitTraces(splitSize, td)
+	split := SplitTraces(splitSize, td)
 	assert.Equal(t, td, split)
 
 	i := 0
@@ -57,24 +60,24 @@ func TestSplitTraces(t *testing.T) {
 	spans.At(4).CopyTo(cpSpans.AppendEmpty())
 
 	splitSize := 5
-	split := splitTraces(splitSize, td)
+	split := SplitTraces(splitSize, td)
 	assert.Equal(t, splitSize, split.SpanCount())
 	assert.Equal(t, cp, split)
 	assert.Equal(t, 15, td.SpanCount())
 	assert.Equal(t, "test-span-0-0", split.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
 	assert.Equal(t, "test-span-0-4", split.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(4).Name())
 
-	split = splitTraces(splitSize, td)
+	split = SplitTraces(splitSize, td)
 	assert.Equal(t, 10, td.SpanCount())
 	assert.Equal(t, "test-span-0-5", split.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
 	assert.Equal(t, "test-span-0-9", split.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(4).Name())
 
-	split = splitTraces(splitSize, td)
+	split = SplitTraces(splitSize, td)
 	assert.Equal(t, 5, td.SpanCount())
 	assert.Equal(t, "test-span-0-10", split.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
 	assert.Equal(t, "test-span-0-14", split.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(4).Name())
 
-	split = splitTraces(splitSize, td)
+	split = SplitTraces(splitSize, td)
 	assert.Equal(t, 5, td.SpanCount())
 	assert.Equal(t, "test-span-0-15", split.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
 	assert.Equal(t, "test-span-0-19", split.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(4).Name())
@@ -95,7 +98,7 @@ func TestSplitTracesMultipleResourceSpans(t *testing.T) {
 	}
 
 	splitSize := 5
-	split := splitTraces(splitSize, td)
+	split := SplitTraces(splitSize, td)
 	assert.Equal(t, splitSize, split.SpanCount())
 	assert.Equal(t, 35, td.SpanCount())
 	assert.Equal(t, "test-span-0-0", split.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
@@ -117,7 +120,7 @@ func TestSplitTracesMultipleResourceSpans_SplitSizeGreaterThanSpanSize(t *testin
 	}
 
 	splitSize := 25
-	split := splitTraces(splitSize, td)
+	split := SplitTraces(splitSize, td)
 	assert.Equal(t, splitSize, split.SpanCount())
 	assert.Equal(t, 40-splitSize, td.SpanCount())
 	assert.Equal(t, 1, td.ResourceSpans().Len())
@@ -171,7 +174,7 @@ func TestSplitTracesMultipleILS(t *testing.T) {
 	}
 
 	splitSize := 40
-	split := splitTraces(splitSize, td)
+	split := SplitTraces(splitSize, td)
 	assert.Equal(t, splitSize, split.SpanCount())
 	assert.Equal(t, 20, td.SpanCount())
 	assert.Equal(t, "test-span-0-0", split.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0).Name())
@@ -201,9 +204,63 @@ func BenchmarkSplitTraces(b *testing.B) {
 	b.ResetTimer()
 	for n := 0; n < b.N; n++ {
 		cloneReq := clones[n]
-		split := splitTraces(128, cloneReq)
+		split := SplitTraces(128, cloneReq)
 		if split.SpanCount() != 128 || cloneReq.SpanCount() != 400-128 {
 			b.Fail()
 		}
 	}
 }
+
+func getTestSpanName(requestNum, index int) string {
+	return fmt.Sprintf("test-span-%d-%d", requestNum, index)
+}
+
+func TestSplitTracesBySize(t *testing.T) {
+	sizer := otlp.NewProtobufTracesMarshaler().(pdata.TracesSizer)
+	src := func() pdata.Traces {
+		td := testdata.GenerateTracesManySpansSameResource(30)
+		testdata.GenerateTracesTwoSpansSameResourceOneDifferent().ResourceSpans().MoveAndAppendTo(td.ResourceSpans())
+		testdata.GenerateTracesManySpansSameResource(40).ResourceSpans().MoveAndAppendTo(td.ResourceSpans())
+		return td
+	}()
+	total := src.SpanCount()
+	maxBytes := sizer.TracesSize(src) / 5
+
+	count := 0
+	estimatedSize := sizer.TracesSize(src)
+	for {
+		remaining := src.SpanCount()
+		split := SplitTracesBySize(0, maxBytes, sizer, src)
+		require.Greater(t, split.SpanCount(), 0)
+		assert.LessOrEqual(t, sizer.TracesSize(split), maxBytes)
+		count += split.SpanCount()
+		if split.SpanCount() == remaining {
+			break
+		}
+		// The size left can be tracked without measuring it again.
+		estimatedSize += SplitTracesOverhead(sizer, src) - sizer.TracesSize(split)
+		assert.LessOrEqual(t, sizer.TracesSize(src), estimatedSize)
+		// Splits are not much smaller than the budget.
+		assert.Greater(t, sizer.TracesSize(split), maxBytes*3/4)
+	}
+	assert.Equal(t, total, count)
+}
+
+func TestSplitTracesBySize_Limits(t *testing.T) {
+	sizer := otlp.NewProtobufTracesMarshaler().(pdata.TracesSizer)
+	src := func() pdata.Traces {
+		td := testdata.GenerateTracesManySpansSameResource(30)
+		testdata.GenerateTracesTwoSpansSameResourceOneDifferent().ResourceSpans().MoveAndAppendTo(td.ResourceSpans())
+		testdata.GenerateTracesManySpansSameResource(40).ResourceSpans().MoveAndAppendTo(td.ResourceSpans())
+		return td
+	}()
+	total := src.SpanCount()
+
+	split := SplitTracesBySize(3, 1<<20, sizer, src)
+	assert.Equal(t, 3, split.SpanCount())
+	assert.Equal(t, total-3, src.SpanCount())
+
+	// An item larger than the budget is sent alone.
+	split = SplitTracesBySize(0, 1, sizer, src)
+	assert.Equal(t, 1, split.SpanCount())
+}
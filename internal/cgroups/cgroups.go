@@ -39,6 +39,8 @@
 
 package cgroups // import "go.opentelemetry.io/collector/internal/cgroups"
 
+import "strconv"
+
 const (
 	// _cgroupFSType is the Linux CGroup file system type used in
 	// `/proc/$PID/mountinfo`.
@@ -53,6 +55,16 @@ const (
 	_cgroupSubsysMemory = "memory"
 
 	_cgroupMemoryLimitBytes = "memory.limit_in_bytes"
+
+	// _cgroupv2FSType is the Linux CGroup v2 file system type used in
+	// `/proc/$PID/mountinfo`.
+	_cgroupv2FSType = "cgroup2"
+	// _cgroupv2Unified is the key of the CGroup of the v2 unified hierarchy,
+	// which has no subsystem name in `/proc/$PID/cgroup`.
+	_cgroupv2Unified = ""
+	// _cgroupv2MemoryMax is the CGroup v2 memory limit, "max" when unlimited.
+	_cgroupv2MemoryMax = "memory.max"
+	_cgroupv2Unlimited = "max"
 )
 
 const (
@@ -74,6 +86,19 @@ func NewCGroups(procPathMountInfo, procPathCGroup string) (CGroups, error) {
 
 	cgroups := make(CGroups)
 	newMountPoint := func(mp *MountPoint) error {
+		if mp.FSType == _cgroupv2FSType {
+			// The unified hierarchy is listed with the ID 0 and no subsystem.
+			subsys, exists := cgroupSubsystems[_cgroupv2Unified]
+			if !exists || subsys.ID != 0 {
+				return nil
+			}
+			cgroupPath, err := mp.Translate(subsys.Name)
+			if err != nil {
+				return err
+			}
+			cgroups[_cgroupv2Unified] = NewCGroup(cgroupPath)
+			return nil
+		}
 		if mp.FSType != _cgroupFSType {
 			return nil
 		}
@@ -107,11 +132,15 @@ func NewCGroupsForCurrentProcess() (CGroups, error) {
 }
 
 // MemoryQuota returns the total memory a
-// It is a result of `memory.limit_in_bytes`. If the value of
-// `memory.limit_in_bytes` was not set (-1) or (9223372036854771712), the method returns `(-1, false, nil)`.
+// It is a result of `memory.limit_in_bytes`, or of `memory.max` with CGroup v2 if the memory
+// subsystem is not in a v1 hierarchy. If the value of `memory.limit_in_bytes` was not set (-1)
+// or the value of `memory.max` is "max", the method returns `(-1, false, nil)`.
 func (cg CGroups) MemoryQuota() (int64, bool, error) {
 	memCGroup, exists := cg[_cgroupSubsysMemory]
 	if !exists {
+		if unified, exists := cg[_cgroupv2Unified]; exists {
+			return unified.memoryMax()
+		}
 		return -1, false, nil
 	}
 
@@ -121,3 +150,19 @@ func (cg CGroups) MemoryQuota() (int64, bool, error) {
 	}
 	return int64(memLimitBytes), true, nil
 }
+
+// memoryMax returns the value of the CGroup v2 `memory.max`, `(-1, false, nil)` if it is "max".
+func (cg *CGroup) memoryMax() (int64, bool, error) {
+	text, err := cg.readFirstLine(_cgroupv2MemoryMax)
+	if err != nil {
+		return -1, false, err
+	}
+	if text == _cgroupv2Unlimited {
+		return -1, false, nil
+	}
+	memMaxBytes, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return -1, false, err
+	}
+	return memMaxBytes, true, nil
+}
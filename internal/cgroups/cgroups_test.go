@@ -136,3 +136,78 @@ func TestCGroupsCPUQuota(t *testing.T) {
 		}
 	}
 }
+
+func TestNewCGroupsV2(t *testing.T) {
+	testTable := []struct {
+		name  string
+		paths map[string]string
+	}{
+		{
+			name: "cgroupsv2",
+			paths: map[string]string{
+				_cgroupv2Unified: "/sys/fs/cgroup/large",
+			},
+		},
+		{
+			name: "cgroupshybrid",
+			paths: map[string]string{
+				_cgroupSubsysCPU:     "/sys/fs/cgroup/cpu,cpuacct",
+				_cgroupSubsysCPUAcct: "/sys/fs/cgroup/cpu,cpuacct",
+				_cgroupSubsysCPUSet:  "/sys/fs/cgroup/cpuset",
+				_cgroupSubsysMemory:  "/sys/fs/cgroup/memory/large",
+				_cgroupv2Unified:     "/sys/fs/cgroup/unified/large",
+			},
+		},
+	}
+
+	for _, tt := range testTable {
+		cgroups, err := NewCGroups(filepath.Join(testDataProcPath, tt.name, "mountinfo"), filepath.Join(testDataProcPath, tt.name, "cgroup"))
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, len(tt.paths), len(cgroups), tt.name)
+		for subsys, path := range tt.paths {
+			cgroup, exists := cgroups[subsys]
+			if assert.True(t, exists, "%q expected to present in `cgroups`", subsys) {
+				assert.Equal(t, path, cgroup.path, tt.name)
+			}
+		}
+	}
+}
+
+func TestCGroupsMemoryQuotaV2(t *testing.T) {
+	testTable := []struct {
+		name            string
+		expectedQuota   int64
+		expectedDefined bool
+		shouldHaveError bool
+	}{
+		{
+			name:            "v2",
+			expectedQuota:   268435456,
+			expectedDefined: true,
+		},
+		{
+			name:            "v2-unlimited",
+			expectedQuota:   -1,
+			expectedDefined: false,
+		},
+		{
+			name:            "nonexistent",
+			expectedQuota:   -1,
+			expectedDefined: false,
+			shouldHaveError: true,
+		},
+	}
+
+	for _, tt := range testTable {
+		cgroups := CGroups{_cgroupv2Unified: NewCGroup(filepath.Join(testDataCGroupsPath, tt.name))}
+
+		quota, defined, err := cgroups.MemoryQuota()
+		assert.Equal(t, tt.expectedQuota, quota, tt.name)
+		assert.Equal(t, tt.expectedDefined, defined, tt.name)
+		if tt.shouldHaveError {
+			assert.Error(t, err, tt.name)
+		} else {
+			assert.NoError(t, err, tt.name)
+		}
+	}
+}
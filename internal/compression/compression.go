@@ -0,0 +1,168 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compression contains the compressors of the gzip, zstd and snappy encodings with a configurable
+// compression level, shared by the OTLP gRPC and HTTP clients.
+package compression // import "go.opentelemetry.io/collector/internal/compression"
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Supported encodings.
+const (
+	Gzip   = "gzip"
+	Zstd   = "zstd"
+	Snappy = "snappy"
+)
+
+// Compressor compresses data with an encoding and a compression level. It is safe for concurrent use.
+type Compressor struct {
+	encoding string
+	compress func(dst io.Writer, src []byte) error
+}
+
+// NewCompressor returns the compressor of the encoding, "gzip", "zstd" or "snappy", with the compression level.
+// The level 0 is the default level of the encoding. The gzip levels go from 1 (best speed) to 9 (best
+// compression), the zstd levels from 1 to 22 like the levels of the zstd command line. Snappy has no levels.
+func NewCompressor(encoding string, level int) (*Compressor, error) {
+	if err := Validate(encoding, level); err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(encoding) {
+	case Gzip:
+		return newGzipCompressor(level), nil
+	case Zstd:
+		return newZstdCompressor(level)
+	default:
+		return newSnappyCompressor(), nil
+	}
+}
+
+// Validate checks that the encoding is supported and that the compression level is valid for it, without
+// creating a compressor.
+func Validate(encoding string, level int) error {
+	encoding = strings.ToLower(encoding)
+	switch encoding {
+	case Gzip:
+		if level != 0 && (level < gzip.BestSpeed || level > gzip.BestCompression) {
+			return fmt.Errorf("gzip compression level must be between %d and %d, got %d", gzip.BestSpeed, gzip.BestCompression, level)
+		}
+	case Zstd:
+		if level != 0 && (level < 1 || level > 22) {
+			return fmt.Errorf("zstd compression level must be between 1 and 22, got %d", level)
+		}
+	case Snappy:
+		if level != 0 {
+			return fmt.Errorf("compression level is not supported by %q", Snappy)
+		}
+	default:
+		return fmt.Errorf("unsupported compression type %q", encoding)
+	}
+	return nil
+}
+
+// Encoding returns the encoding of the compressor, e.g. the value of the HTTP Content-Encoding header.
+func (c *Compressor) Encoding() string {
+	return c.encoding
+}
+
+// Compress writes the compressed src to dst.
+func (c *Compressor) Compress(dst io.Writer, src []byte) error {
+	return c.compress(dst, src)
+}
+
+func newGzipCompressor(level int) *Compressor {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	pool := sync.Pool{New: func() interface{} {
+		// The level is valid, so NewWriterLevel does not fail.
+		w, _ := gzip.NewWriterLevel(nil, level)
+		return w
+	}}
+	return &Compressor{
+		encoding: Gzip,
+		compress: func(dst io.Writer, src []byte) error {
+			w := pool.Get().(*gzip.Writer)
+			defer pool.Put(w)
+			w.Reset(dst)
+			if _, err := w.Write(src); err != nil {
+				return err
+			}
+			return w.Close()
+		},
+	}
+}
+
+func newZstdCompressor(level int) (*Compressor, error) {
+	opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+	if level != 0 {
+		opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	}
+	enc, err := zstd.NewWriter(nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Compressor{
+		encoding: Zstd,
+		compress: func(dst io.Writer, src []byte) error {
+			// EncodeAll can be called concurrently.
+			_, err := dst.Write(enc.EncodeAll(src, nil))
+			return err
+		},
+	}, nil
+}
+
+func newSnappyCompressor() *Compressor {
+	pool := sync.Pool{New: func() interface{} {
+		return snappy.NewBufferedWriter(nil)
+	}}
+	return &Compressor{
+		encoding: Snappy,
+		compress: func(dst io.Writer, src []byte) error {
+			w := pool.Get().(*snappy.Writer)
+			defer pool.Put(w)
+			w.Reset(dst)
+			if _, err := w.Write(src); err != nil {
+				return err
+			}
+			return w.Close()
+		},
+	}
+}
+
+// NewReader returns a reader decompressing r with the encoding, "gzip", "zstd" or "snappy".
+func NewReader(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch strings.ToLower(encoding) {
+	case Gzip:
+		return gzip.NewReader(r)
+	case Zstd:
+		dec, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	case Snappy:
+		return io.NopCloser(snappy.NewReader(r)), nil
+	}
+	return nil, fmt.Errorf("unsupported compression type %q", encoding)
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compression
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressor(t *testing.T) {
+	data := []byte(strings.Repeat("the quick brown fox jumps over the lazy dog ", 100))
+	tests := []struct {
+		encoding string
+		level    int
+	}{
+		{encoding: Gzip},
+		{encoding: Gzip, level: 1},
+		{encoding: Gzip, level: 9},
+		{encoding: "GZIP"},
+		{encoding: Zstd},
+		{encoding: Zstd, level: 1},
+		{encoding: Zstd, level: 19},
+		{encoding: Snappy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			c, err := NewCompressor(tt.encoding, tt.level)
+			require.NoError(t, err)
+			assert.Equal(t, strings.ToLower(tt.encoding), c.Encoding())
+
+			// The compressors are reused between calls.
+			for i := 0; i < 2; i++ {
+				var buf bytes.Buffer
+				require.NoError(t, c.Compress(&buf, data))
+				assert.Less(t, buf.Len(), len(data))
+
+				r, err := NewReader(tt.encoding, &buf)
+				require.NoError(t, err)
+				decompressed, err := io.ReadAll(r)
+				require.NoError(t, err)
+				assert.NoError(t, r.Close())
+				assert.Equal(t, data, decompressed)
+			}
+		})
+	}
+}
+
+func TestCompressorErrors(t *testing.T) {
+	tests := []struct {
+		encoding string
+		level    int
+		errMsg   string
+	}{
+		{encoding: "gzip2", errMsg: `unsupported compression type "gzip2"`},
+		{encoding: Gzip, level: 10, errMsg: "gzip compression level must be between 1 and 9, got 10"},
+		{encoding: Gzip, level: -3, errMsg: "gzip compression level must be between 1 and 9, got -3"},
+		{encoding: Zstd, level: 23, errMsg: "zstd compression level must be between 1 and 22, got 23"},
+		{encoding: Snappy, level: 1, errMsg: `compression level is not supported by "snappy"`},
+	}
+	for _, tt := range tests {
+		_, err := NewCompressor(tt.encoding, tt.level)
+		assert.EqualError(t, err, tt.errMsg)
+		assert.EqualError(t, Validate(tt.encoding, tt.level), tt.errMsg)
+	}
+
+	_, err := NewReader("gzip2", &bytes.Buffer{})
+	assert.EqualError(t, err, `unsupported compression type "gzip2"`)
+}
+
+func BenchmarkCompressor(b *testing.B) {
+	data := []byte(strings.Repeat(`{"name":"span","trace_id":"5b8efff798038103d269b633813fc60c","kind":2}`, 1000))
+	benchmarks := []struct {
+		name     string
+		encoding string
+		level    int
+	}{
+		{name: "gzip", encoding: Gzip},
+		{name: "gzip_best_speed", encoding: Gzip, level: 1},
+		{name: "zstd", encoding: Zstd},
+		{name: "zstd_best_speed", encoding: Zstd, level: 1},
+		{name: "snappy", encoding: Snappy},
+	}
+	for _, bm := range benchmarks {
+		c, err := NewCompressor(bm.encoding, bm.level)
+		require.NoError(b, err)
+		b.Run(bm.name, func(b *testing.B) {
+			var buf bytes.Buffer
+			require.NoError(b, c.Compress(&buf, data))
+			b.ReportMetric(float64(len(data))/float64(buf.Len()), "ratio")
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				if err := c.Compress(&buf, data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iruntime // import "go.opentelemetry.io/collector/internal/iruntime"
+
+import "sync"
+
+// setMemoryLimitFn is used by tests to avoid changing the soft memory limit of the test process.
+var setMemoryLimitFn = SetMemoryLimit
+
+// memoryLimits holds the soft memory limits of the Go runtime requested by the components. The limit is
+// process-wide, so the lowest requested one is set, and the limit from before the first request is restored
+// once every request is released.
+var memoryLimits struct {
+	sync.Mutex
+	requests map[*int64]struct{}
+	prev     int64
+}
+
+// RequestMemoryLimit requests the soft memory limit of the Go runtime to be at most limit, until the returned
+// function is called. Several components can hold a request at the same time, the lowest limit applies.
+func RequestMemoryLimit(limit int64) (release func()) {
+	memoryLimits.Lock()
+	defer memoryLimits.Unlock()
+
+	first := len(memoryLimits.requests) == 0
+	if first {
+		memoryLimits.requests = make(map[*int64]struct{})
+	}
+	req := &limit
+	memoryLimits.requests[req] = struct{}{}
+	prev := setMemoryLimitFn(lowestMemoryLimit())
+	if first {
+		memoryLimits.prev = prev
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { releaseMemoryLimit(req) })
+	}
+}
+
+func releaseMemoryLimit(req *int64) {
+	memoryLimits.Lock()
+	defer memoryLimits.Unlock()
+
+	delete(memoryLimits.requests, req)
+	if len(memoryLimits.requests) == 0 {
+		setMemoryLimitFn(memoryLimits.prev)
+		return
+	}
+	setMemoryLimitFn(lowestMemoryLimit())
+}
+
+// lowestMemoryLimit must be called with the memoryLimits lock held.
+func lowestMemoryLimit() int64 {
+	lowest := int64(-1)
+	for req := range memoryLimits.requests {
+		if lowest < 0 || *req < lowest {
+			lowest = *req
+		}
+	}
+	return lowest
+}
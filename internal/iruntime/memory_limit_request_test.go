@@ -0,0 +1,59 @@
+// Copyright  The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iruntime
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestMemoryLimit(t *testing.T) {
+	var goMemoryLimit int64 = math.MaxInt64
+	setMemoryLimitFn = func(limit int64) int64 {
+		prev := goMemoryLimit
+		goMemoryLimit = limit
+		return prev
+	}
+	defer func() { setMemoryLimitFn = SetMemoryLimit }()
+
+	release1 := RequestMemoryLimit(2000)
+	assert.Equal(t, int64(2000), goMemoryLimit)
+
+	release2 := RequestMemoryLimit(1000)
+	assert.Equal(t, int64(1000), goMemoryLimit)
+
+	release3 := RequestMemoryLimit(3000)
+	assert.Equal(t, int64(1000), goMemoryLimit)
+
+	release2()
+	assert.Equal(t, int64(2000), goMemoryLimit)
+
+	// Releasing twice has no effect.
+	release2()
+	assert.Equal(t, int64(2000), goMemoryLimit)
+
+	release1()
+	assert.Equal(t, int64(3000), goMemoryLimit)
+
+	release3()
+	assert.Equal(t, int64(math.MaxInt64), goMemoryLimit)
+
+	release4 := RequestMemoryLimit(500)
+	assert.Equal(t, int64(500), goMemoryLimit)
+	release4()
+	assert.Equal(t, int64(math.MaxInt64), goMemoryLimit)
+}
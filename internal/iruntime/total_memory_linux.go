@@ -31,11 +31,12 @@ func TotalMemory() (uint64, error) {
 		return 0, err
 	}
 	memoryQuota, defined, err := cgroups.MemoryQuota()
-	if err != nil || !defined {
+	if err != nil {
 		return 0, err
 	}
 
-	if memoryQuota == unlimitedMemorySize {
+	// Without a memory limit of the cgroup, v1 or v2, the memory of the host is the limit.
+	if !defined || memoryQuota == unlimitedMemorySize {
 		totalMem, err := readMemInfo()
 		if err != nil {
 			return 0, err
@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancing // import "go.opentelemetry.io/collector/internal/loadbalancing"
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// virtualNodes is the number of points of every endpoint on the ring, spreading the keys evenly.
+const virtualNodes = 100
+
+type ringPoint struct {
+	hash     uint64
+	endpoint string
+}
+
+// HashRing is a consistent hash ring of endpoints: a key is mapped to the same endpoint as long as the endpoint is
+// part of the ring, and adding or removing an endpoint only remaps the keys of that endpoint.
+type HashRing struct {
+	points []ringPoint
+}
+
+// NewHashRing returns a HashRing of the given endpoints.
+func NewHashRing(endpoints []string) *HashRing {
+	r := &HashRing{points: make([]ringPoint, 0, len(endpoints)*virtualNodes)}
+	for _, endpoint := range endpoints {
+		for i := 0; i < virtualNodes; i++ {
+			r.points = append(r.points, ringPoint{hash: hash([]byte(endpoint + "#" + strconv.Itoa(i))), endpoint: endpoint})
+		}
+	}
+	sort.Slice(r.points, func(i, j int) bool {
+		return r.points[i].hash < r.points[j].hash
+	})
+	return r
+}
+
+// Endpoint returns the endpoint of the key, or "" if the ring is empty. The endpoints for which skip returns true,
+// e.g. the unhealthy ones, are skipped in favor of the next endpoint of the ring, unless all the endpoints are skipped.
+func (r *HashRing) Endpoint(key []byte, skip func(endpoint string) bool) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hash(key)
+	start := sort.Search(len(r.points), func(i int) bool {
+		return r.points[i].hash >= h
+	})
+	for i := 0; i < len(r.points); i++ {
+		endpoint := r.points[(start+i)%len(r.points)].endpoint
+		if skip == nil || !skip(endpoint) {
+			return endpoint
+		}
+	}
+	return r.points[start%len(r.points)].endpoint
+}
+
+// hash returns the FNV-1a hash of the key, mixed with the splitmix64 finalizer since FNV-1a alone spreads similar
+// keys, e.g. the virtual nodes of an endpoint, poorly.
+func hash(key []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(key)
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancing
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func keys(n int) [][]byte {
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = make([]byte, 16)
+		binary.BigEndian.PutUint64(keys[i], uint64(i)*0x9E3779B97F4A7C15)
+		binary.BigEndian.PutUint64(keys[i][8:], uint64(i))
+	}
+	return keys
+}
+
+func TestHashRingEmpty(t *testing.T) {
+	assert.Equal(t, "", NewHashRing(nil).Endpoint([]byte("key"), nil))
+}
+
+func TestHashRingDistribution(t *testing.T) {
+	ring := NewHashRing([]string{"a", "b", "c"})
+	counts := map[string]int{}
+	for _, key := range keys(30000) {
+		counts[ring.Endpoint(key, nil)]++
+	}
+	assert.Len(t, counts, 3)
+	for endpoint, count := range counts {
+		assert.InDelta(t, 10000, count, 3000, endpoint)
+	}
+}
+
+func TestHashRingMinimalRebalancing(t *testing.T) {
+	before := NewHashRing([]string{"a", "b", "c"})
+	after := NewHashRing([]string{"a", "b", "c", "d"})
+	for _, key := range keys(10000) {
+		// The keys only move to the new endpoint.
+		if endpoint := after.Endpoint(key, nil); endpoint != "d" {
+			assert.Equal(t, before.Endpoint(key, nil), endpoint)
+		}
+	}
+}
+
+func TestHashRingSkip(t *testing.T) {
+	ring := NewHashRing([]string{"a", "b", "c"})
+	skipB := func(endpoint string) bool { return endpoint == "b" }
+	for _, key := range keys(1000) {
+		endpoint := ring.Endpoint(key, skipB)
+		assert.NotEqual(t, "b", endpoint)
+		if ring.Endpoint(key, nil) != "b" {
+			assert.Equal(t, ring.Endpoint(key, nil), endpoint)
+		}
+	}
+	// All the endpoints are skipped, the key is still mapped.
+	assert.NotEmpty(t, ring.Endpoint([]byte("key"), func(string) bool { return true }))
+}
@@ -0,0 +1,193 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancing // import "go.opentelemetry.io/collector/internal/loadbalancing"
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultFileResolverInterval is the interval the file resolver reads the file at when none is set.
+const DefaultFileResolverInterval = 5 * time.Second
+
+// ErrNoEndpoints is returned when the data is sent before any endpoint is resolved.
+var ErrNoEndpoints = errors.New("no endpoints to send the data to")
+
+// ResolverSettings defines the Resolver created by NewResolver.
+type ResolverSettings struct {
+	// StaticEndpoints is the fixed list of endpoints of the static resolver, used when FilePath is empty.
+	StaticEndpoints []string
+
+	// FilePath is the path of the file listing the endpoints, enabling the file resolver.
+	FilePath string
+
+	// FileInterval is the interval the file is read again at, DefaultFileResolverInterval if not set.
+	FileInterval time.Duration
+
+	// Logger logs the errors of the file resolver.
+	Logger *zap.Logger
+}
+
+// NewResolver returns the file resolver if a file path is set, else the static resolver.
+func NewResolver(set ResolverSettings) Resolver {
+	if set.FilePath == "" {
+		return NewStaticResolver(set.StaticEndpoints)
+	}
+	interval := set.FileInterval
+	if interval == 0 {
+		interval = DefaultFileResolverInterval
+	}
+	return NewFileResolver(set.FilePath, interval, set.Logger)
+}
+
+// Resolver resolves the endpoints of the backends and notifies their changes.
+type Resolver interface {
+	// Start starts resolving the endpoints. onChange is called with the sorted endpoints before Start returns,
+	// then every time they change.
+	Start(ctx context.Context, onChange func(endpoints []string)) error
+
+	// Shutdown stops resolving the endpoints, onChange is not called anymore once it returns.
+	Shutdown(ctx context.Context) error
+}
+
+type staticResolver struct {
+	endpoints []string
+}
+
+// NewStaticResolver returns a Resolver of a fixed list of endpoints.
+func NewStaticResolver(endpoints []string) Resolver {
+	return &staticResolver{endpoints: normalize(endpoints)}
+}
+
+func (r *staticResolver) Start(_ context.Context, onChange func(endpoints []string)) error {
+	onChange(r.endpoints)
+	return nil
+}
+
+func (r *staticResolver) Shutdown(context.Context) error {
+	return nil
+}
+
+type fileResolver struct {
+	path     string
+	interval time.Duration
+	logger   *zap.Logger
+
+	onChange func(endpoints []string)
+	content  []byte
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewFileResolver returns a Resolver reading the endpoints from a file, one per line, ignoring the empty lines and
+// the lines starting with "#". The file is read again every interval, its changes being notified.
+func NewFileResolver(path string, interval time.Duration, logger *zap.Logger) Resolver {
+	return &fileResolver{
+		path:     path,
+		interval: interval,
+		logger:   logger,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+func (r *fileResolver) Start(_ context.Context, onChange func(endpoints []string)) error {
+	r.onChange = onChange
+	if err := r.read(); err != nil {
+		return err
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := r.read(); err != nil {
+					// Keep the last known endpoints, the file may be being rewritten.
+					r.logger.Warn("Failed to read the endpoints file", zap.String("path", r.path), zap.Error(err))
+				}
+			case <-r.stopCh:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// read reads the file and notifies the endpoints if its content changed.
+func (r *fileResolver) read() error {
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		return err
+	}
+	if r.content != nil && bytes.Equal(content, r.content) {
+		return nil
+	}
+
+	var endpoints []string
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		endpoints = append(endpoints, line)
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return fmt.Errorf("no endpoints in %q", r.path)
+	}
+
+	r.content = content
+	r.onChange(normalize(endpoints))
+	return nil
+}
+
+func (r *fileResolver) Shutdown(context.Context) error {
+	if r.onChange != nil {
+		close(r.stopCh)
+		r.wg.Wait()
+	}
+	return nil
+}
+
+// normalize returns the sorted endpoints without duplicates.
+func normalize(endpoints []string) []string {
+	seen := make(map[string]struct{}, len(endpoints))
+	normalized := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if _, ok := seen[endpoint]; ok {
+			continue
+		}
+		seen[endpoint] = struct{}{}
+		normalized = append(normalized, endpoint)
+	}
+	sort.Strings(normalized)
+	return normalized
+}
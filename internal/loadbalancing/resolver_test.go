@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadbalancing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestStaticResolver(t *testing.T) {
+	var got []string
+	r := NewStaticResolver([]string{"b:4317", "a:4317", "b:4317"})
+	require.NoError(t, r.Start(context.Background(), func(endpoints []string) {
+		got = endpoints
+	}))
+	assert.Equal(t, []string{"a:4317", "b:4317"}, got)
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestNewResolver(t *testing.T) {
+	r := NewResolver(ResolverSettings{StaticEndpoints: []string{"a:4317"}})
+	assert.IsType(t, &staticResolver{}, r)
+
+	r = NewResolver(ResolverSettings{FilePath: "endpoints", Logger: zap.NewNop()})
+	require.IsType(t, &fileResolver{}, r)
+	assert.Equal(t, DefaultFileResolverInterval, r.(*fileResolver).interval)
+
+	r = NewResolver(ResolverSettings{FilePath: "endpoints", FileInterval: time.Second, Logger: zap.NewNop()})
+	require.IsType(t, &fileResolver{}, r)
+	assert.Equal(t, time.Second, r.(*fileResolver).interval)
+}
+
+type endpointsRecorder struct {
+	mu      sync.Mutex
+	changes [][]string
+}
+
+func (r *endpointsRecorder) onChange(endpoints []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, endpoints)
+}
+
+func (r *endpointsRecorder) get() [][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.changes
+}
+
+func TestFileResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints")
+	require.NoError(t, os.WriteFile(path, []byte("# backends\nb:4317\n\n  a:4317  \n"), 0600))
+
+	rec := &endpointsRecorder{}
+	r := NewFileResolver(path, 10*time.Millisecond, zap.NewNop())
+	require.NoError(t, r.Start(context.Background(), rec.onChange))
+	assert.Equal(t, [][]string{{"a:4317", "b:4317"}}, rec.get())
+
+	// An unreadable or empty file keeps the last endpoints.
+	require.NoError(t, os.WriteFile(path, []byte("# no backends\n"), 0600))
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, rec.get(), 1)
+
+	require.NoError(t, os.WriteFile(path, []byte("a:4317\nc:4317\n"), 0600))
+	assert.Eventually(t, func() bool {
+		return len(rec.get()) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"a:4317", "c:4317"}, rec.get()[1])
+
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestFileResolverMissingFile(t *testing.T) {
+	r := NewFileResolver(filepath.Join(t.TempDir(), "missing"), time.Second, zap.NewNop())
+	assert.Error(t, r.Start(context.Background(), func([]string) {}))
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
@@ -0,0 +1,378 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorylimiter // import "go.opentelemetry.io/collector/internal/memorylimiter"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/ballastextension"
+	"go.opentelemetry.io/collector/internal/iruntime"
+)
+
+const mibBytes = 1024 * 1024
+
+// Name of BallastSizeMiB config option.
+const ballastSizeMibKey = "ballast_size_mib"
+
+// goMemoryLimitEnv is the environment variable setting the soft memory limit of the Go runtime,
+// which takes precedence over the limit set by the memory limiter.
+const goMemoryLimitEnv = "GOMEMLIMIT"
+
+// Minimum interval between forced GC when in soft limited mode. We don't want to
+// do GCs too frequently since it is a CPU-heavy operation.
+const minGCIntervalWhenSoftLimited = 10 * time.Second
+
+// Construction errors
+var (
+	ErrCheckIntervalOutOfRange = errors.New(
+		"checkInterval must be greater than zero")
+
+	ErrLimitOutOfRange = errors.New(
+		"memAllocLimit or memoryLimitPercentage must be greater than zero")
+
+	ErrMemSpikeLimitOutOfRange = errors.New(
+		"memSpikeLimit must be smaller than memAllocLimit")
+
+	ErrPercentageLimitOutOfRange = errors.New(
+		"memoryLimitPercentage and memorySpikePercentage must be greater than zero and less than or equal to hundred",
+	)
+)
+
+// make it overridable by tests
+var (
+	getMemoryFn      = iruntime.TotalMemory
+	randFloat64      = rand.Float64
+	lookupEnvFn      = os.LookupEnv
+	requestGoLimitFn = iruntime.RequestMemoryLimit
+)
+
+// Settings configures a MemoryLimiter.
+type Settings struct {
+	// CheckInterval is the time between measurements of memory usage.
+	CheckInterval time.Duration
+
+	// MemoryLimitMiB is the maximum amount of memory, in MiB, targeted to be
+	// allocated by the process.
+	MemoryLimitMiB uint32
+
+	// MemorySpikeLimitMiB is the maximum, in MiB, spike expected between the
+	// measurements of memory usage.
+	MemorySpikeLimitMiB uint32
+
+	// MemoryLimitPercentage is the maximum amount of memory, in %, targeted to be
+	// allocated by the process. The fixed memory settings MemoryLimitMiB has a higher precedence.
+	MemoryLimitPercentage uint32
+
+	// MemorySpikePercentage is the maximum, in percents against the total memory,
+	// spike expected between the measurements of memory usage.
+	MemorySpikePercentage uint32
+
+	Logger *zap.Logger
+
+	// ReadMemStatsFn reads the memory usage, runtime.ReadMemStats if nil.
+	ReadMemStatsFn func(m *runtime.MemStats)
+}
+
+// MemoryLimiter checks the memory usage of the collector from a single goroutine and tells the
+// components consulting it when incoming data must be refused.
+type MemoryLimiter struct {
+	usageChecker memUsageChecker
+
+	checkInterval time.Duration
+	ballastSize   uint64
+
+	// dropProbability is the probability, stored atomically as float64 bits, that incoming data is
+	// refused. It grows from 0 at the soft limit to 1 at the hard limit.
+	dropProbability uint64
+
+	// releaseGoMemoryLimit releases the soft memory limit of the Go runtime requested at start.
+	releaseGoMemoryLimit func()
+
+	lastGCDone time.Time
+
+	// The function to read the mem values is set as a reference to help with
+	// testing different values.
+	readMemStatsFn func(m *runtime.MemStats)
+
+	// Fields used for logging.
+	logger                 *zap.Logger
+	configMismatchedLogged bool
+
+	stopCh chan struct{}
+	stopWG sync.WaitGroup
+}
+
+// New returns a new MemoryLimiter, which checks the memory usage once started.
+func New(set Settings) (*MemoryLimiter, error) {
+	if set.CheckInterval <= 0 {
+		return nil, ErrCheckIntervalOutOfRange
+	}
+	if set.MemoryLimitMiB == 0 && set.MemoryLimitPercentage == 0 {
+		return nil, ErrLimitOutOfRange
+	}
+
+	logger := set.Logger
+	usageChecker, err := getMemUsageChecker(set, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Memory limiter configured",
+		zap.Uint64("limit_mib", usageChecker.memAllocLimit/mibBytes),
+		zap.Uint64("spike_limit_mib", usageChecker.memSpikeLimit/mibBytes),
+		zap.Duration("check_interval", set.CheckInterval))
+
+	ml := &MemoryLimiter{
+		usageChecker:   *usageChecker,
+		checkInterval:  set.CheckInterval,
+		readMemStatsFn: set.ReadMemStatsFn,
+		logger:         logger,
+	}
+	if ml.readMemStatsFn == nil {
+		ml.readMemStatsFn = runtime.ReadMemStats
+	}
+	return ml, nil
+}
+
+func getMemUsageChecker(set Settings, logger *zap.Logger) (*memUsageChecker, error) {
+	memAllocLimit := uint64(set.MemoryLimitMiB) * mibBytes
+	memSpikeLimit := uint64(set.MemorySpikeLimitMiB) * mibBytes
+	if set.MemoryLimitMiB != 0 {
+		return newFixedMemUsageChecker(memAllocLimit, memSpikeLimit)
+	}
+	totalMemory, err := getMemoryFn()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get total memory, use fixed memory settings (limit_mib): %w", err)
+	}
+	logger.Info("Using percentage memory limiter",
+		zap.Uint64("total_memory_mib", totalMemory/mibBytes),
+		zap.Uint32("limit_percentage", set.MemoryLimitPercentage),
+		zap.Uint32("spike_limit_percentage", set.MemorySpikePercentage))
+	return newPercentageMemUsageChecker(totalMemory, uint64(set.MemoryLimitPercentage), uint64(set.MemorySpikePercentage))
+}
+
+// Start sets the soft memory limit of the Go runtime and starts the goroutine checking the memory usage
+// every check interval.
+func (ml *MemoryLimiter) Start(_ context.Context, host component.Host) error {
+	for _, extension := range host.GetExtensions() {
+		if ext, ok := extension.(*ballastextension.MemoryBallast); ok {
+			ml.ballastSize = ext.GetBallastSize()
+			break
+		}
+	}
+
+	ml.setGoMemoryLimit()
+
+	ml.stopCh = make(chan struct{})
+	ml.stopWG.Add(1)
+	go func() {
+		defer ml.stopWG.Done()
+		ticker := time.NewTicker(ml.checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ml.CheckMemLimits()
+			case <-ml.stopCh:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Shutdown stops checking the memory usage, releases the soft memory limit of the Go runtime and stops
+// refusing data. It can be called even if the MemoryLimiter was not started.
+func (ml *MemoryLimiter) Shutdown(context.Context) error {
+	if ml.stopCh != nil {
+		close(ml.stopCh)
+		ml.stopWG.Wait()
+		ml.stopCh = nil
+	}
+	if ml.releaseGoMemoryLimit != nil {
+		ml.releaseGoMemoryLimit()
+		ml.releaseGoMemoryLimit = nil
+	}
+	ml.setDropProbability(0)
+	return nil
+}
+
+// MustRefuse indicates when incoming data must be refused so memory resources are released. Between the soft
+// and the hard limits only a part of the data, growing with the memory usage, is refused.
+func (ml *MemoryLimiter) MustRefuse() bool {
+	p := ml.getDropProbability()
+	if p <= 0 {
+		return false
+	}
+	return p >= 1 || randFloat64() < p
+}
+
+// RetryDelay returns the delay refused clients are asked to wait before retrying, the memory usage
+// being checked again by then.
+func (ml *MemoryLimiter) RetryDelay() time.Duration {
+	return ml.checkInterval
+}
+
+// setGoMemoryLimit sets the soft memory limit of the Go runtime to the hard limit, plus the ballast which is
+// allocated but not accounted by the memory limiter, so the garbage collector works harder before the hard
+// limit is reached. The limit set with the GOMEMLIMIT environment variable is kept.
+func (ml *MemoryLimiter) setGoMemoryLimit() {
+	if _, ok := lookupEnvFn(goMemoryLimitEnv); ok {
+		ml.logger.Info("Go runtime memory limit set by the environment, not setting it", zap.String("env", goMemoryLimitEnv))
+		return
+	}
+	limit := ml.usageChecker.memAllocLimit + ml.ballastSize
+	if limit > math.MaxInt64 {
+		return
+	}
+	ml.releaseGoMemoryLimit = requestGoLimitFn(int64(limit))
+	ml.logger.Info("Go runtime memory limit set", zap.Uint64("go_memory_limit_mib", limit/mibBytes))
+}
+
+func (ml *MemoryLimiter) getDropProbability() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&ml.dropProbability))
+}
+
+func (ml *MemoryLimiter) setDropProbability(p float64) {
+	atomic.StoreUint64(&ml.dropProbability, math.Float64bits(p))
+}
+
+func (ml *MemoryLimiter) readMemStats() *runtime.MemStats {
+	ms := &runtime.MemStats{}
+	ml.readMemStatsFn(ms)
+	// If proper configured ms.Alloc should be at least ml.ballastSize but since
+	// a misconfiguration is possible check for that here.
+	if ms.Alloc >= ml.ballastSize {
+		ms.Alloc -= ml.ballastSize
+	} else if !ml.configMismatchedLogged {
+		// This indicates misconfiguration. Log it once.
+		ml.configMismatchedLogged = true
+		ml.logger.Warn(ballastSizeMibKey + " in ballast extension is likely incorrectly configured.")
+	}
+
+	return ms
+}
+
+func memstatToZapField(ms *runtime.MemStats) zap.Field {
+	return zap.Uint64("cur_mem_mib", ms.Alloc/mibBytes)
+}
+
+func (ml *MemoryLimiter) doGCandReadMemStats() *runtime.MemStats {
+	runtime.GC()
+	ml.lastGCDone = time.Now()
+	ms := ml.readMemStats()
+	ml.logger.Info("Memory usage after GC.", memstatToZapField(ms))
+	return ms
+}
+
+// CheckMemLimits checks the memory usage and updates the part of the incoming data to refuse. It is called
+// every check interval once started.
+func (ml *MemoryLimiter) CheckMemLimits() {
+	ms := ml.readMemStats()
+
+	ml.logger.Debug("Currently used memory.", memstatToZapField(ms))
+
+	if ml.usageChecker.aboveHardLimit(ms) {
+		ml.logger.Warn("Memory usage is above hard limit. Forcing a GC.", memstatToZapField(ms))
+		ms = ml.doGCandReadMemStats()
+	}
+
+	// Remember current dropping state.
+	wasDropping := ml.getDropProbability() > 0
+
+	// Check how far the memory usage is above the soft limit.
+	dropProbability := ml.usageChecker.dropProbability(ms)
+
+	if wasDropping && dropProbability == 0 {
+		// Was previously dropping but enough memory is available now, no need to limit.
+		ml.logger.Info("Memory usage back within limits. Resuming normal operation.", memstatToZapField(ms))
+	}
+
+	if !wasDropping && dropProbability > 0 {
+		// We are above soft limit, do a GC if it wasn't done recently and see if
+		// it brings memory usage below the soft limit.
+		if time.Since(ml.lastGCDone) > minGCIntervalWhenSoftLimited {
+			ml.logger.Info("Memory usage is above soft limit. Forcing a GC.", memstatToZapField(ms))
+			ms = ml.doGCandReadMemStats()
+			// Check the limit again to see if GC helped.
+			dropProbability = ml.usageChecker.dropProbability(ms)
+		}
+
+		if dropProbability > 0 {
+			ml.logger.Warn("Memory usage is above soft limit. Dropping data.", memstatToZapField(ms),
+				zap.Float64("drop_probability", dropProbability))
+		}
+	}
+
+	ml.setDropProbability(dropProbability)
+}
+
+type memUsageChecker struct {
+	memAllocLimit uint64
+	memSpikeLimit uint64
+}
+
+func (d memUsageChecker) aboveSoftLimit(ms *runtime.MemStats) bool {
+	return ms.Alloc >= d.memAllocLimit-d.memSpikeLimit
+}
+
+func (d memUsageChecker) aboveHardLimit(ms *runtime.MemStats) bool {
+	return ms.Alloc >= d.memAllocLimit
+}
+
+// dropProbability returns the probability that incoming data is refused: 0 below the soft limit, growing
+// linearly between the soft and the hard limits, and 1 above the hard limit.
+func (d memUsageChecker) dropProbability(ms *runtime.MemStats) float64 {
+	if !d.aboveSoftLimit(ms) {
+		return 0
+	}
+	if d.aboveHardLimit(ms) || d.memSpikeLimit == 0 {
+		return 1
+	}
+	return float64(ms.Alloc-(d.memAllocLimit-d.memSpikeLimit)) / float64(d.memSpikeLimit)
+}
+
+func newFixedMemUsageChecker(memAllocLimit, memSpikeLimit uint64) (*memUsageChecker, error) {
+	if memSpikeLimit >= memAllocLimit {
+		return nil, ErrMemSpikeLimitOutOfRange
+	}
+	if memSpikeLimit == 0 {
+		// If spike limit is unspecified use 20% of mem limit.
+		memSpikeLimit = memAllocLimit / 5
+	}
+	return &memUsageChecker{
+		memAllocLimit: memAllocLimit,
+		memSpikeLimit: memSpikeLimit,
+	}, nil
+}
+
+func newPercentageMemUsageChecker(totalMemory uint64, percentageLimit, percentageSpike uint64) (*memUsageChecker, error) {
+	if percentageLimit > 100 || percentageLimit <= 0 || percentageSpike > 100 || percentageSpike <= 0 {
+		return nil, ErrPercentageLimitOutOfRange
+	}
+	return newFixedMemUsageChecker(percentageLimit*totalMemory/100, percentageSpike*totalMemory/100)
+}
@@ -0,0 +1,349 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memorylimiter
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/internal/iruntime"
+)
+
+func TestNew(t *testing.T) {
+	getMemoryFn = func() (uint64, error) { return 1000 * mibBytes, nil }
+	defer func() {
+		getMemoryFn = iruntime.TotalMemory
+	}()
+
+	tests := []struct {
+		name         string
+		set          Settings
+		wantErr      error
+		wantAllocMiB uint64
+		wantSpikeMiB uint64
+	}{
+		{
+			name:    "zero_checkInterval",
+			set:     Settings{MemoryLimitMiB: 100},
+			wantErr: ErrCheckIntervalOutOfRange,
+		},
+		{
+			name:    "zero_memAllocLimit",
+			set:     Settings{CheckInterval: time.Second},
+			wantErr: ErrLimitOutOfRange,
+		},
+		{
+			name:    "memSpikeLimit_gt_memAllocLimit",
+			set:     Settings{CheckInterval: time.Second, MemoryLimitMiB: 1, MemorySpikeLimitMiB: 2},
+			wantErr: ErrMemSpikeLimitOutOfRange,
+		},
+		{
+			name:    "percentage_out_of_range",
+			set:     Settings{CheckInterval: time.Second, MemoryLimitPercentage: 101, MemorySpikePercentage: 10},
+			wantErr: ErrPercentageLimitOutOfRange,
+		},
+		{
+			name:         "fixed",
+			set:          Settings{CheckInterval: time.Second, MemoryLimitMiB: 100, MemorySpikeLimitMiB: 10},
+			wantAllocMiB: 100,
+			wantSpikeMiB: 10,
+		},
+		{
+			name:         "fixed default spike",
+			set:          Settings{CheckInterval: time.Second, MemoryLimitMiB: 100},
+			wantAllocMiB: 100,
+			wantSpikeMiB: 20,
+		},
+		{
+			name:         "percentage",
+			set:          Settings{CheckInterval: time.Second, MemoryLimitPercentage: 50, MemorySpikePercentage: 10},
+			wantAllocMiB: 500,
+			wantSpikeMiB: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.set.Logger = zap.NewNop()
+			ml, err := New(tt.set)
+			if tt.wantErr != nil {
+				assert.Equal(t, tt.wantErr, err)
+				assert.Nil(t, ml)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAllocMiB*mibBytes, ml.usageChecker.memAllocLimit)
+			assert.Equal(t, tt.wantSpikeMiB*mibBytes, ml.usageChecker.memSpikeLimit)
+			assert.Equal(t, time.Second, ml.RetryDelay())
+		})
+	}
+
+	getMemoryFn = func() (uint64, error) { return 0, errors.New("failed") }
+	_, err := New(Settings{CheckInterval: time.Second, MemoryLimitPercentage: 50, MemorySpikePercentage: 10, Logger: zap.NewNop()})
+	assert.Error(t, err)
+}
+
+// TestCheckMemLimits manipulates results from querying memory and
+// check expected side effects.
+func TestCheckMemLimits(t *testing.T) {
+	var currentMemAlloc uint64
+	ml := &MemoryLimiter{
+		usageChecker: memUsageChecker{
+			memAllocLimit: 1024,
+		},
+		readMemStatsFn: func(ms *runtime.MemStats) {
+			ms.Alloc = currentMemAlloc
+		},
+		logger: zap.NewNop(),
+	}
+	defer func() {
+		randFloat64 = rand.Float64
+	}()
+
+	// Below memAllocLimit.
+	currentMemAlloc = 800
+	ml.CheckMemLimits()
+	assert.False(t, ml.MustRefuse())
+
+	// Above memAllocLimit.
+	currentMemAlloc = 1800
+	ml.CheckMemLimits()
+	assert.True(t, ml.MustRefuse())
+
+	// Check ballast effect
+	ml.ballastSize = 1000
+
+	// Below memAllocLimit accounting for ballast.
+	currentMemAlloc = 800 + ml.ballastSize
+	ml.CheckMemLimits()
+	assert.False(t, ml.MustRefuse())
+
+	// Above memAllocLimit even accounting for ballast.
+	currentMemAlloc = 1800 + ml.ballastSize
+	ml.CheckMemLimits()
+	assert.True(t, ml.MustRefuse())
+
+	// Restore ballast to default.
+	ml.ballastSize = 0
+
+	// Check spike limit
+	ml.usageChecker.memSpikeLimit = 512
+
+	// Below memSpikeLimit.
+	currentMemAlloc = 500
+	ml.CheckMemLimits()
+	assert.False(t, ml.MustRefuse())
+
+	// Between the soft and the hard limits, half of the data is refused.
+	currentMemAlloc = 768
+	ml.CheckMemLimits()
+	randFloat64 = func() float64 { return 0.4 }
+	assert.True(t, ml.MustRefuse())
+	randFloat64 = func() float64 { return 0.6 }
+	assert.False(t, ml.MustRefuse())
+
+	// Above memAllocLimit, all the data is refused.
+	currentMemAlloc = 1024
+	ml.CheckMemLimits()
+	assert.True(t, ml.MustRefuse())
+}
+
+func TestGetDecision(t *testing.T) {
+	t.Run("fixed_limit", func(t *testing.T) {
+		d, err := getMemUsageChecker(Settings{MemoryLimitMiB: 100, MemorySpikeLimitMiB: 20}, zap.NewNop())
+		require.NoError(t, err)
+		assert.Equal(t, &memUsageChecker{
+			memAllocLimit: 100 * mibBytes,
+			memSpikeLimit: 20 * mibBytes,
+		}, d)
+	})
+	t.Run("fixed_limit_error", func(t *testing.T) {
+		d, err := getMemUsageChecker(Settings{MemoryLimitMiB: 20, MemorySpikeLimitMiB: 100}, zap.NewNop())
+		require.Error(t, err)
+		assert.Nil(t, d)
+	})
+
+	t.Cleanup(func() {
+		getMemoryFn = iruntime.TotalMemory
+	})
+	getMemoryFn = func() (uint64, error) {
+		return 100 * mibBytes, nil
+	}
+	t.Run("percentage_limit", func(t *testing.T) {
+		d, err := getMemUsageChecker(Settings{MemoryLimitPercentage: 50, MemorySpikePercentage: 10}, zap.NewNop())
+		require.NoError(t, err)
+		assert.Equal(t, &memUsageChecker{
+			memAllocLimit: 50 * mibBytes,
+			memSpikeLimit: 10 * mibBytes,
+		}, d)
+	})
+	t.Run("percentage_limit_error", func(t *testing.T) {
+		d, err := getMemUsageChecker(Settings{MemoryLimitPercentage: 101, MemorySpikePercentage: 10}, zap.NewNop())
+		require.Error(t, err)
+		assert.Nil(t, d)
+		d, err = getMemUsageChecker(Settings{MemoryLimitPercentage: 99, MemorySpikePercentage: 101}, zap.NewNop())
+		require.Error(t, err)
+		assert.Nil(t, d)
+	})
+}
+func TestDropDecision(t *testing.T) {
+	decison1000Limit30Spike30, err := newPercentageMemUsageChecker(1000, 60, 30)
+	require.NoError(t, err)
+	decison1000Limit60Spike50, err := newPercentageMemUsageChecker(1000, 60, 50)
+	require.NoError(t, err)
+	decison1000Limit40Spike20, err := newPercentageMemUsageChecker(1000, 40, 20)
+	require.NoError(t, err)
+	decison1000Limit40Spike60, err := newPercentageMemUsageChecker(1000, 40, 60)
+	require.Error(t, err)
+	assert.Nil(t, decison1000Limit40Spike60)
+
+	tests := []struct {
+		name         string
+		usageChecker memUsageChecker
+		ms           *runtime.MemStats
+		shouldDrop   bool
+	}{
+		{
+			name:         "should drop over limit",
+			usageChecker: *decison1000Limit30Spike30,
+			ms:           &runtime.MemStats{Alloc: 600},
+			shouldDrop:   true,
+		},
+		{
+			name:         "should not drop",
+			usageChecker: *decison1000Limit30Spike30,
+			ms:           &runtime.MemStats{Alloc: 100},
+			shouldDrop:   false,
+		},
+		{
+			name: "should not drop spike, fixed usageChecker",
+			usageChecker: memUsageChecker{
+				memAllocLimit: 600,
+				memSpikeLimit: 500,
+			},
+			ms:         &runtime.MemStats{Alloc: 300},
+			shouldDrop: true,
+		},
+		{
+			name:         "should drop, spike, percentage usageChecker",
+			usageChecker: *decison1000Limit60Spike50,
+			ms:           &runtime.MemStats{Alloc: 300},
+			shouldDrop:   true,
+		},
+		{
+			name:         "should drop, spike, percentage usageChecker",
+			usageChecker: *decison1000Limit40Spike20,
+			ms:           &runtime.MemStats{Alloc: 250},
+			shouldDrop:   true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			shouldDrop := test.usageChecker.aboveSoftLimit(test.ms)
+			assert.Equal(t, test.shouldDrop, shouldDrop)
+		})
+	}
+}
+
+func TestDropProbability(t *testing.T) {
+	usageChecker := memUsageChecker{
+		memAllocLimit: 1000,
+		memSpikeLimit: 200,
+	}
+	tests := []struct {
+		alloc       uint64
+		probability float64
+	}{
+		{alloc: 100, probability: 0},
+		{alloc: 800, probability: 0},
+		{alloc: 850, probability: 0.25},
+		{alloc: 900, probability: 0.5},
+		{alloc: 1000, probability: 1},
+		{alloc: 2000, probability: 1},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.probability, usageChecker.dropProbability(&runtime.MemStats{Alloc: test.alloc}), test.alloc)
+	}
+}
+
+func TestGoMemoryLimit(t *testing.T) {
+	var goMemoryLimit int64 = math.MaxInt64
+	requestGoLimitFn = func(limit int64) func() {
+		goMemoryLimit = limit
+		return func() { goMemoryLimit = math.MaxInt64 }
+	}
+	defer func() {
+		requestGoLimitFn = iruntime.RequestMemoryLimit
+		lookupEnvFn = os.LookupEnv
+	}()
+
+	newLimiter := func() *MemoryLimiter {
+		return &MemoryLimiter{
+			usageChecker: memUsageChecker{memAllocLimit: 1000 * mibBytes},
+			logger:       zap.NewNop(),
+		}
+	}
+
+	lookupEnvFn = func(string) (string, bool) { return "", false }
+	ml := newLimiter()
+	ml.ballastSize = 100 * mibBytes
+	ml.setGoMemoryLimit()
+	assert.Equal(t, int64(1100*mibBytes), goMemoryLimit)
+	require.NoError(t, ml.Shutdown(context.Background()))
+	assert.Equal(t, int64(math.MaxInt64), goMemoryLimit)
+
+	// GOMEMLIMIT takes precedence.
+	lookupEnvFn = func(key string) (string, bool) { return "500MiB", key == goMemoryLimitEnv }
+	ml = newLimiter()
+	ml.setGoMemoryLimit()
+	assert.Equal(t, int64(math.MaxInt64), goMemoryLimit)
+	require.NoError(t, ml.Shutdown(context.Background()))
+	assert.Equal(t, int64(math.MaxInt64), goMemoryLimit)
+}
+
+func TestStartShutdown(t *testing.T) {
+	var goMemoryLimit int64 = 42
+	requestGoLimitFn = func(limit int64) func() {
+		goMemoryLimit = limit
+		return func() { goMemoryLimit = 42 }
+	}
+	lookupEnvFn = func(string) (string, bool) { return "", false }
+	defer func() {
+		requestGoLimitFn = iruntime.RequestMemoryLimit
+		lookupEnvFn = os.LookupEnv
+	}()
+
+	ml, err := New(Settings{CheckInterval: time.Millisecond, MemoryLimitMiB: 1, Logger: zap.NewNop()})
+	require.NoError(t, err)
+	require.NoError(t, ml.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Equal(t, int64(mibBytes), goMemoryLimit)
+
+	// The process uses more than 1 MiB, all data is refused once the usage is checked.
+	assert.Eventually(t, ml.MustRefuse, time.Second, time.Millisecond)
+
+	require.NoError(t, ml.Shutdown(context.Background()))
+	assert.Equal(t, int64(42), goMemoryLimit)
+	assert.False(t, ml.MustRefuse())
+}
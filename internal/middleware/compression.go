@@ -16,25 +16,44 @@ package middleware // import "go.opentelemetry.io/collector/internal/middleware"
 
 import (
 	"bytes"
-	"compress/gzip"
 	"compress/zlib"
 	"io"
+	"io/ioutil"
 	"net/http"
+
+	"go.opentelemetry.io/collector/internal/compression"
 )
 
 const (
 	headerContentEncoding = "Content-Encoding"
-	headerValueGZIP       = "gzip"
 )
 
 type CompressRoundTripper struct {
 	http.RoundTripper
+	compressor *compression.Compressor
 }
 
+// NewCompressRoundTripper returns a round tripper compressing the request bodies with gzip.
 func NewCompressRoundTripper(rt http.RoundTripper) *CompressRoundTripper {
+	// The default gzip level is valid, so NewCompressor does not fail.
+	compressor, _ := compression.NewCompressor(compression.Gzip, 0)
 	return &CompressRoundTripper{
 		RoundTripper: rt,
+		compressor:   compressor,
+	}
+}
+
+// NewCompressRoundTripperWithEncoding returns a round tripper compressing the request bodies with the encoding,
+// "gzip", "zstd" or "snappy", and the compression level, 0 being the default level of the encoding.
+func NewCompressRoundTripperWithEncoding(rt http.RoundTripper, encoding string, level int) (*CompressRoundTripper, error) {
+	compressor, err := compression.NewCompressor(encoding, level)
+	if err != nil {
+		return nil, err
 	}
+	return &CompressRoundTripper{
+		RoundTripper: rt,
+		compressor:   compressor,
+	}, nil
 }
 
 func (r *CompressRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
@@ -47,22 +66,19 @@ func (r *CompressRoundTripper) RoundTrip(req *http.Request) (*http.Response, err
 		return r.RoundTripper.RoundTrip(req)
 	}
 
-	// Gzip the body.
-	buf := bytes.NewBuffer([]byte{})
-	gzipWriter := gzip.NewWriter(buf)
-	_, copyErr := io.Copy(gzipWriter, req.Body)
+	// Compress the body.
+	body, readErr := ioutil.ReadAll(req.Body)
 	closeErr := req.Body.Close()
-
-	if err := gzipWriter.Close(); err != nil {
-		return nil, err
-	}
-
-	if copyErr != nil {
-		return nil, copyErr
+	if readErr != nil {
+		return nil, readErr
 	}
 	if closeErr != nil {
 		return nil, closeErr
 	}
+	buf := bytes.NewBuffer([]byte{})
+	if err := r.compressor.Compress(buf, body); err != nil {
+		return nil, err
+	}
 
 	// Create a new request since the docs say that we cannot modify the "req"
 	// (see https://golang.org/pkg/net/http/#RoundTripper).
@@ -71,9 +87,9 @@ func (r *CompressRoundTripper) RoundTrip(req *http.Request) (*http.Response, err
 		return nil, err
 	}
 
-	// Clone the headers and add gzip encoding header.
+	// Clone the headers and add the encoding header.
 	cReq.Header = req.Header.Clone()
-	cReq.Header.Add(headerContentEncoding, headerValueGZIP)
+	cReq.Header.Add(headerContentEncoding, r.compressor.Encoding())
 
 	return r.RoundTripper.RoundTrip(cReq)
 }
@@ -95,7 +111,7 @@ func WithErrorHandler(e ErrorHandler) DecompressorOption {
 // HTTPContentDecompressor is a middleware that offloads the task of handling compressed
 // HTTP requests by identifying the compression format in the "Content-Encoding" header and re-writing
 // request body so that the handlers further in the chain can work on decompressed data.
-// It supports gzip and deflate/zlib compression.
+// It supports gzip, deflate/zlib, zstd and snappy compression.
 func HTTPContentDecompressor(h http.Handler, opts ...DecompressorOption) http.Handler {
 	d := &decompressor{}
 	for _, o := range opts {
@@ -129,13 +145,9 @@ func (d *decompressor) wrap(h http.Handler) http.Handler {
 }
 
 func newBodyReader(r *http.Request) (io.ReadCloser, error) {
-	switch r.Header.Get("Content-Encoding") {
-	case "gzip":
-		gr, err := gzip.NewReader(r.Body)
-		if err != nil {
-			return nil, err
-		}
-		return gr, nil
+	switch encoding := r.Header.Get("Content-Encoding"); encoding {
+	case compression.Gzip, compression.Zstd, compression.Snappy:
+		return compression.NewReader(encoding, r.Body)
 	case "deflate", "zlib":
 		zr, err := zlib.NewReader(r.Body)
 		if err != nil {
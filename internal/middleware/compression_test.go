@@ -28,12 +28,15 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"go.opentelemetry.io/collector/internal/compression"
 	"go.opentelemetry.io/collector/internal/testutil"
 )
 
 func TestHTTPClientCompression(t *testing.T) {
 	testBody := []byte("uncompressed_text")
 	compressedBody, _ := compressGzip(testBody)
+	compressedZstdBody, _ := compress(compression.Zstd, testBody)
+	compressedSnappyBody, _ := compress(compression.Snappy, testBody)
 
 	tests := []struct {
 		name     string
@@ -50,6 +53,16 @@ func TestHTTPClientCompression(t *testing.T) {
 			encoding: "gzip",
 			reqBody:  compressedBody.Bytes(),
 		},
+		{
+			name:     "ValidZstd",
+			encoding: "zstd",
+			reqBody:  compressedZstdBody.Bytes(),
+		},
+		{
+			name:     "ValidSnappy",
+			encoding: "snappy",
+			reqBody:  compressedSnappyBody.Bytes(),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -79,8 +92,13 @@ func TestHTTPClientCompression(t *testing.T) {
 			require.NoError(t, err, "failed to create request to test handler")
 
 			client := http.Client{}
-			if tt.encoding == "gzip" {
+			switch tt.encoding {
+			case "":
+			case "gzip":
 				client.Transport = NewCompressRoundTripper(http.DefaultTransport)
+			default:
+				client.Transport, err = NewCompressRoundTripperWithEncoding(http.DefaultTransport, tt.encoding, 0)
+				require.NoError(t, err)
 			}
 			res, err := client.Do(req)
 			require.NoError(t, err)
@@ -126,6 +144,22 @@ func TestHTTPContentDecompressionHandler(t *testing.T) {
 			},
 			respCode: 200,
 		},
+		{
+			name:     "ValidZstd",
+			encoding: "zstd",
+			reqBodyFunc: func() (*bytes.Buffer, error) {
+				return compress(compression.Zstd, testBody)
+			},
+			respCode: 200,
+		},
+		{
+			name:     "ValidSnappy",
+			encoding: "snappy",
+			reqBodyFunc: func() (*bytes.Buffer, error) {
+				return compress(compression.Snappy, testBody)
+			},
+			respCode: 200,
+		},
 		{
 			name:     "InvalidGzip",
 			encoding: "gzip",
@@ -217,3 +251,20 @@ func compressZlib(body []byte) (*bytes.Buffer, error) {
 
 	return &buf, nil
 }
+
+func TestNewCompressRoundTripperWithEncodingError(t *testing.T) {
+	_, err := NewCompressRoundTripperWithEncoding(http.DefaultTransport, "gzip2", 0)
+	assert.EqualError(t, err, `unsupported compression type "gzip2"`)
+}
+
+func compress(encoding string, body []byte) (*bytes.Buffer, error) {
+	c, err := compression.NewCompressor(encoding, 0)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := c.Compress(&buf, body); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
@@ -0,0 +1,169 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlptext // import "go.opentelemetry.io/collector/internal/otlptext"
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// NewSummaryTracesMarshaler returns a pdata.TracesMarshaler encoding every span as a one-line summary of
+// key=value pairs, followed by the given attributes looked up in the span then in the resource attributes.
+func NewSummaryTracesMarshaler(attributes []string) pdata.TracesMarshaler {
+	return summaryMarshaler{attributes: attributes}
+}
+
+// NewSummaryMetricsMarshaler returns a pdata.MetricsMarshaler encoding every metric as a one-line summary of
+// key=value pairs, followed by the given attributes looked up in the resource attributes.
+func NewSummaryMetricsMarshaler(attributes []string) pdata.MetricsMarshaler {
+	return summaryMarshaler{attributes: attributes}
+}
+
+// NewSummaryLogsMarshaler returns a pdata.LogsMarshaler encoding every log record as a one-line summary of
+// key=value pairs, followed by the given attributes looked up in the log record then in the resource attributes.
+func NewSummaryLogsMarshaler(attributes []string) pdata.LogsMarshaler {
+	return summaryMarshaler{attributes: attributes}
+}
+
+type summaryMarshaler struct {
+	attributes []string
+}
+
+// MarshalTraces pdata.Traces to one line per span.
+func (s summaryMarshaler) MarshalTraces(td pdata.Traces) ([]byte, error) {
+	buf := summaryBuffer{}
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		ilss := rs.InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				buf.field("span", span.Name())
+				buf.field("trace_id", span.TraceID().HexString())
+				buf.field("span_id", span.SpanID().HexString())
+				buf.field("duration", span.EndTimestamp().AsTime().Sub(span.StartTimestamp().AsTime()).String())
+				buf.field("status", span.Status().Code().String())
+				buf.projection(s.attributes, span.Attributes(), rs.Resource().Attributes())
+				buf.endLine()
+			}
+		}
+	}
+	return buf.buf.Bytes(), nil
+}
+
+// MarshalMetrics pdata.Metrics to one line per metric.
+func (s summaryMarshaler) MarshalMetrics(md pdata.Metrics) ([]byte, error) {
+	buf := summaryBuffer{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				metric := metrics.At(k)
+				buf.field("metric", metric.Name())
+				buf.field("type", metric.DataType().String())
+				buf.field("data_points", strconv.Itoa(dataPointCount(metric)))
+				buf.projection(s.attributes, rm.Resource().Attributes())
+				buf.endLine()
+			}
+		}
+	}
+	return buf.buf.Bytes(), nil
+}
+
+// MarshalLogs pdata.Logs to one line per log record.
+func (s summaryMarshaler) MarshalLogs(ld pdata.Logs) ([]byte, error) {
+	buf := summaryBuffer{}
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				lr := logs.At(k)
+				buf.field("time", lr.Timestamp().AsTime().Format(time.RFC3339Nano))
+				buf.field("severity", lr.SeverityNumber().String())
+				buf.field("body", attributeValueToString(lr.Body()))
+				if !lr.TraceID().IsEmpty() {
+					buf.field("trace_id", lr.TraceID().HexString())
+					buf.field("span_id", lr.SpanID().HexString())
+				}
+				buf.projection(s.attributes, lr.Attributes(), rl.Resource().Attributes())
+				buf.endLine()
+			}
+		}
+	}
+	return buf.buf.Bytes(), nil
+}
+
+func dataPointCount(metric pdata.Metric) int {
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		return metric.Gauge().DataPoints().Len()
+	case pdata.MetricDataTypeSum:
+		return metric.Sum().DataPoints().Len()
+	case pdata.MetricDataTypeHistogram:
+		return metric.Histogram().DataPoints().Len()
+	case pdata.MetricDataTypeSummary:
+		return metric.Summary().DataPoints().Len()
+	}
+	return 0
+}
+
+type summaryBuffer struct {
+	buf     bytes.Buffer
+	newLine bool
+}
+
+// field appends a key=value pair to the current line, quoting the value if needed.
+func (b *summaryBuffer) field(key string, value string) {
+	if b.buf.Len() > 0 && !b.newLine {
+		b.buf.WriteByte(' ')
+	}
+	b.newLine = false
+	b.buf.WriteString(key)
+	b.buf.WriteByte('=')
+	if value == "" || strings.ContainsAny(value, " \t\r\n=\"") {
+		value = strconv.Quote(value)
+	}
+	b.buf.WriteString(value)
+}
+
+// projection appends the given attributes, taking every one from the first map containing it.
+// The attributes found in none of the maps are omitted.
+func (b *summaryBuffer) projection(attributes []string, ams ...pdata.AttributeMap) {
+	for _, key := range attributes {
+		for _, am := range ams {
+			if v, ok := am.Get(key); ok {
+				b.field(key, attributeValueToString(v))
+				break
+			}
+		}
+	}
+}
+
+func (b *summaryBuffer) endLine() {
+	b.buf.WriteByte('\n')
+	b.newLine = true
+}
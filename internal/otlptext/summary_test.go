@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlptext
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestTracesSummary(t *testing.T) {
+	buf, err := NewSummaryTracesMarshaler(nil).MarshalTraces(pdata.NewTraces())
+	require.NoError(t, err)
+	assert.Empty(t, buf)
+
+	buf, err = NewSummaryTracesMarshaler([]string{"resource-attr", "missing"}).MarshalTraces(testdata.GenerateTracesTwoSpansSameResource())
+	require.NoError(t, err)
+	assert.Equal(t, `span=operationA trace_id=0102030405060708090a0b0c0d0e0f10 span_id=1112131415161718 duration=1.000000468s status=STATUS_CODE_ERROR resource-attr=resource-attr-val-1
+span=operationB trace_id="" span_id="" duration=1.000000468s status=STATUS_CODE_UNSET resource-attr=resource-attr-val-1
+`, string(buf))
+}
+
+func TestTracesSummaryAttributes(t *testing.T) {
+	td := testdata.GenerateTracesOneSpan()
+	span := td.ResourceSpans().At(0).InstrumentationLibrarySpans().At(0).Spans().At(0)
+	span.SetName("GET /index")
+	span.Attributes().InsertString("resource-attr", "span-attr-val")
+	span.Attributes().InsertInt("http.status_code", 200)
+
+	buf, err := NewSummaryTracesMarshaler([]string{"http.status_code", "resource-attr"}).MarshalTraces(td)
+	require.NoError(t, err)
+	assert.Equal(t, `span="GET /index" trace_id=0102030405060708090a0b0c0d0e0f10 span_id=1112131415161718 duration=1.000000468s status=STATUS_CODE_ERROR http.status_code=200 resource-attr=span-attr-val
+`, string(buf))
+}
+
+func TestMetricsSummary(t *testing.T) {
+	buf, err := NewSummaryMetricsMarshaler([]string{"resource-attr"}).MarshalMetrics(testdata.GeneratMetricsAllTypesWithSampleDatapoints())
+	require.NoError(t, err)
+	assert.Equal(t, `metric=gauge-int type=Gauge data_points=2 resource-attr=resource-attr-val-1
+metric=gauge-double type=Gauge data_points=2 resource-attr=resource-attr-val-1
+metric=counter-int type=Sum data_points=2 resource-attr=resource-attr-val-1
+metric=counter-double type=Sum data_points=2 resource-attr=resource-attr-val-1
+metric=double-histogram type=Histogram data_points=2 resource-attr=resource-attr-val-1
+metric=double-summary type=Summary data_points=2 resource-attr=resource-attr-val-1
+`, string(buf))
+}
+
+func TestLogsSummary(t *testing.T) {
+	buf, err := NewSummaryLogsMarshaler([]string{"app"}).MarshalLogs(testdata.GenerateLogsTwoLogRecordsSameResource())
+	require.NoError(t, err)
+	assert.Equal(t, `time=2020-02-11T20:26:13.000000789Z severity=SEVERITY_NUMBER_INFO body="This is a log message" trace_id=08040201000000000000000000000000 span_id=0102040800000000 app=server
+time=2020-02-11T20:26:13.000000789Z severity=SEVERITY_NUMBER_INFO body="something happened"
+`, string(buf))
+}
@@ -16,6 +16,7 @@ package obsreport // import "go.opentelemetry.io/collector/obsreport"
 
 import (
 	"context"
+	"errors"
 
 	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
@@ -25,6 +26,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/internal/obsreportconfig"
 	"go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
 )
@@ -64,6 +66,10 @@ func (exp *Exporter) StartTracesOp(ctx context.Context) context.Context {
 // EndTracesOp completes the export operation that was started with StartTracesOp.
 func (exp *Exporter) EndTracesOp(ctx context.Context, numSpans int, err error) {
 	numSent, numFailedToSend := toNumItems(numSpans, err)
+	var partialErr consumererror.PartialTraces
+	if errors.As(err, &partialErr) {
+		numSent, numFailedToSend = toNumPartialItems(numSpans, partialErr.GetRetryable().SpanCount()+partialErr.GetPermanent().SpanCount())
+	}
 	exp.recordMetrics(ctx, numSent, numFailedToSend, obsmetrics.ExporterSentSpans, obsmetrics.ExporterFailedToSendSpans)
 	endSpan(ctx, err, numSent, numFailedToSend, obsmetrics.SentSpansKey, obsmetrics.FailedToSendSpansKey)
 }
@@ -79,6 +85,10 @@ func (exp *Exporter) StartMetricsOp(ctx context.Context) context.Context {
 // StartMetricsOp.
 func (exp *Exporter) EndMetricsOp(ctx context.Context, numMetricPoints int, err error) {
 	numSent, numFailedToSend := toNumItems(numMetricPoints, err)
+	var partialErr consumererror.PartialMetrics
+	if errors.As(err, &partialErr) {
+		numSent, numFailedToSend = toNumPartialItems(numMetricPoints, partialErr.GetRetryable().DataPointCount()+partialErr.GetPermanent().DataPointCount())
+	}
 	exp.recordMetrics(ctx, numSent, numFailedToSend, obsmetrics.ExporterSentMetricPoints, obsmetrics.ExporterFailedToSendMetricPoints)
 	endSpan(ctx, err, numSent, numFailedToSend, obsmetrics.SentMetricPointsKey, obsmetrics.FailedToSendMetricPointsKey)
 }
@@ -93,6 +103,10 @@ func (exp *Exporter) StartLogsOp(ctx context.Context) context.Context {
 // EndLogsOp completes the export operation that was started with StartLogsOp.
 func (exp *Exporter) EndLogsOp(ctx context.Context, numLogRecords int, err error) {
 	numSent, numFailedToSend := toNumItems(numLogRecords, err)
+	var partialErr consumererror.PartialLogs
+	if errors.As(err, &partialErr) {
+		numSent, numFailedToSend = toNumPartialItems(numLogRecords, partialErr.GetRetryable().LogRecordCount()+partialErr.GetPermanent().LogRecordCount())
+	}
 	exp.recordMetrics(ctx, numSent, numFailedToSend, obsmetrics.ExporterSentLogRecords, obsmetrics.ExporterFailedToSendLogRecords)
 	endSpan(ctx, err, numSent, numFailedToSend, obsmetrics.SentLogRecordsKey, obsmetrics.FailedToSendLogRecordsKey)
 }
@@ -132,3 +146,12 @@ func toNumItems(numExportedItems int, err error) (int64, int64) {
 	}
 	return int64(numExportedItems), 0
 }
+
+// toNumPartialItems returns the number of sent and failed items when only numFailedItems out of
+// numExportedItems failed to be sent.
+func toNumPartialItems(numExportedItems int, numFailedItems int) (int64, int64) {
+	if numFailedItems > numExportedItems {
+		numFailedItems = numExportedItems
+	}
+	return int64(numExportedItems - numFailedItems), int64(numFailedItems)
+}
@@ -26,7 +26,9 @@ import (
 
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configtelemetry"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/internal/obsreportconfig/obsmetrics"
+	"go.opentelemetry.io/collector/internal/testdata"
 	"go.opentelemetry.io/collector/obsreport/obsreporttest"
 	"go.opentelemetry.io/collector/receiver/scrapererror"
 )
@@ -304,6 +306,30 @@ func TestExportTraceDataOp(t *testing.T) {
 	require.NoError(t, obsreporttest.CheckExporterTraces(tt, exporter, int64(sentSpans), int64(failedToSendSpans)))
 }
 
+func TestExportTraceDataOp_PartialError(t *testing.T) {
+	tt, err := obsreporttest.SetupTelemetry()
+	require.NoError(t, err)
+	defer tt.Shutdown(context.Background())
+
+	obsrep := NewExporter(ExporterSettings{
+		Level:                  configtelemetry.LevelNormal,
+		ExporterID:             exporter,
+		ExporterCreateSettings: tt.ToExporterCreateSettings(),
+	})
+
+	ctx := obsrep.StartTracesOp(context.Background())
+	partialErr := consumererror.NewPartialTraces(errFake, testdata.GenerateTracesOneSpan(), testdata.GenerateTracesTwoSpansSameResource())
+	obsrep.EndTracesOp(ctx, 10, partialErr)
+
+	spans := tt.SpanRecorder.Ended()
+	require.Len(t, spans, 1)
+	require.Contains(t, spans[0].Attributes(), attribute.KeyValue{Key: obsmetrics.SentSpansKey, Value: attribute.Int64Value(7)})
+	require.Contains(t, spans[0].Attributes(), attribute.KeyValue{Key: obsmetrics.FailedToSendSpansKey, Value: attribute.Int64Value(3)})
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+
+	require.NoError(t, obsreporttest.CheckExporterTraces(tt, exporter, 7, 3))
+}
+
 func TestExportMetricsOp(t *testing.T) {
 	tt, err := obsreporttest.SetupTelemetry()
 	require.NoError(t, err)
@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor // import "go.opentelemetry.io/collector/processor/attributesprocessor"
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// attrAction is an action with its value and pattern ready to be applied.
+type attrAction struct {
+	ActionKeyValue
+	// value is set when the action sets a fixed value.
+	value pdata.AttributeValue
+	// hasValue tells if value is set, the zero pdata.AttributeValue must not be used.
+	hasValue bool
+	// regex is the compiled pattern of the extract action.
+	regex *regexp.Regexp
+}
+
+// attrProc applies the actions, in order, to attribute maps.
+type attrProc struct {
+	actions []attrAction
+}
+
+func newAttrProc(actions []ActionKeyValue) (*attrProc, error) {
+	ap := &attrProc{actions: make([]attrAction, 0, len(actions))}
+	for _, a := range actions {
+		action := attrAction{ActionKeyValue: a}
+		if a.Value != nil {
+			v, err := toAttributeValue(a.Value)
+			if err != nil {
+				return nil, err
+			}
+			action.value, action.hasValue = v, true
+		}
+		if a.Action == actionExtract {
+			re, err := compileExtractPattern(a.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			action.regex = re
+		}
+		ap.actions = append(ap.actions, action)
+	}
+	return ap, nil
+}
+
+// toAttributeValue converts a configured value to an attribute value.
+func toAttributeValue(v interface{}) (pdata.AttributeValue, error) {
+	switch val := v.(type) {
+	case string:
+		return pdata.NewAttributeValueString(val), nil
+	case int:
+		return pdata.NewAttributeValueInt(int64(val)), nil
+	case int64:
+		return pdata.NewAttributeValueInt(val), nil
+	case float64:
+		return pdata.NewAttributeValueDouble(val), nil
+	case bool:
+		return pdata.NewAttributeValueBool(val), nil
+	default:
+		return pdata.AttributeValue{}, fmt.Errorf("unsupported value type %T", v)
+	}
+}
+
+func (ap *attrProc) process(attrs pdata.AttributeMap) {
+	for _, action := range ap.actions {
+		switch action.Action {
+		case actionInsert, actionUpdate, actionUpsert:
+			v, ok := action.valueFrom(attrs)
+			if !ok {
+				continue
+			}
+			switch action.Action {
+			case actionInsert:
+				attrs.Insert(action.Key, v)
+			case actionUpdate:
+				attrs.Update(action.Key, v)
+			case actionUpsert:
+				attrs.Upsert(action.Key, v)
+			}
+		case actionDelete:
+			attrs.Delete(action.Key)
+		case actionHash:
+			if v, ok := attrs.Get(action.Key); ok {
+				attrs.UpdateString(action.Key, hashValue(v))
+			}
+		case actionExtract:
+			extract(action.regex, action.Key, attrs)
+		}
+	}
+}
+
+// valueFrom returns the value set by the action, which may come from another attribute.
+func (a *attrAction) valueFrom(attrs pdata.AttributeMap) (pdata.AttributeValue, bool) {
+	if a.hasValue {
+		return a.value, true
+	}
+	return attrs.Get(a.FromAttribute)
+}
+
+// hashValue returns the hexadecimal SHA-256 hash of the raw bytes, or the string representation, of the value.
+func hashValue(v pdata.AttributeValue) string {
+	var b []byte
+	if v.Type() == pdata.AttributeValueTypeBytes {
+		b = v.BytesVal()
+	} else {
+		b = []byte(v.AsString())
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// extract upserts the attributes named after the named subexpressions of the regex, when it matches the
+// string value of the attribute key.
+func extract(re *regexp.Regexp, key string, attrs pdata.AttributeMap) {
+	v, ok := attrs.Get(key)
+	if !ok || v.Type() != pdata.AttributeValueTypeString {
+		return
+	}
+	matches := re.FindStringSubmatch(v.StringVal())
+	if matches == nil {
+		return
+	}
+	for i, name := range re.SubexpNames() {
+		if i == 0 || name == "" {
+			continue
+		}
+		attrs.UpsertString(name, matches[i])
+	}
+}
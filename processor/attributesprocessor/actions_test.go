@@ -0,0 +1,168 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+func TestAttrProc(t *testing.T) {
+	tests := []struct {
+		name     string
+		action   ActionKeyValue
+		input    map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:     "insert missing",
+			action:   ActionKeyValue{Key: "env", Action: actionInsert, Value: "prod"},
+			input:    map[string]interface{}{},
+			expected: map[string]interface{}{"env": "prod"},
+		},
+		{
+			name:     "insert existing",
+			action:   ActionKeyValue{Key: "env", Action: actionInsert, Value: "prod"},
+			input:    map[string]interface{}{"env": "dev"},
+			expected: map[string]interface{}{"env": "dev"},
+		},
+		{
+			name:     "update missing",
+			action:   ActionKeyValue{Key: "retries", Action: actionUpdate, Value: 3},
+			input:    map[string]interface{}{},
+			expected: map[string]interface{}{},
+		},
+		{
+			name:     "update existing",
+			action:   ActionKeyValue{Key: "retries", Action: actionUpdate, Value: 3},
+			input:    map[string]interface{}{"retries": int64(1)},
+			expected: map[string]interface{}{"retries": int64(3)},
+		},
+		{
+			name:     "upsert missing",
+			action:   ActionKeyValue{Key: "ratio", Action: actionUpsert, Value: 0.5},
+			input:    map[string]interface{}{},
+			expected: map[string]interface{}{"ratio": 0.5},
+		},
+		{
+			name:     "upsert existing",
+			action:   ActionKeyValue{Key: "sampled", Action: actionUpsert, Value: true},
+			input:    map[string]interface{}{"sampled": false},
+			expected: map[string]interface{}{"sampled": true},
+		},
+		{
+			name:     "upsert from attribute",
+			action:   ActionKeyValue{Key: "client", Action: actionUpsert, FromAttribute: "peer"},
+			input:    map[string]interface{}{"peer": "10.0.0.1"},
+			expected: map[string]interface{}{"peer": "10.0.0.1", "client": "10.0.0.1"},
+		},
+		{
+			name:     "upsert from missing attribute",
+			action:   ActionKeyValue{Key: "client", Action: actionUpsert, FromAttribute: "peer"},
+			input:    map[string]interface{}{"client": "10.0.0.2"},
+			expected: map[string]interface{}{"client": "10.0.0.2"},
+		},
+		{
+			name:     "delete",
+			action:   ActionKeyValue{Key: "password", Action: actionDelete},
+			input:    map[string]interface{}{"password": "secret", "user": "alice"},
+			expected: map[string]interface{}{"user": "alice"},
+		},
+		{
+			name:     "hash",
+			action:   ActionKeyValue{Key: "email", Action: actionHash},
+			input:    map[string]interface{}{"email": "alice@example.com"},
+			expected: map[string]interface{}{"email": "ff8d9819fc0e12bf0d24892e45987e249a28dce836a85cad60e28eaaa8c6d976"},
+		},
+		{
+			name:     "hash int",
+			action:   ActionKeyValue{Key: "user.id", Action: actionHash},
+			input:    map[string]interface{}{"user.id": int64(123)},
+			expected: map[string]interface{}{"user.id": "a665a45920422f9d417e4867efdc4fb8a04a1f3fff1fa07e998e86f7f7a27ae3"},
+		},
+		{
+			name:     "hash missing",
+			action:   ActionKeyValue{Key: "email", Action: actionHash},
+			input:    map[string]interface{}{},
+			expected: map[string]interface{}{},
+		},
+		{
+			name:   "extract",
+			action: ActionKeyValue{Key: "url", Action: actionExtract, Pattern: `^(?P<scheme>[a-z]+)://(?P<host>[^/]*)`},
+			input:  map[string]interface{}{"url": "https://example.com/path", "host": "old"},
+			expected: map[string]interface{}{
+				"url":    "https://example.com/path",
+				"scheme": "https",
+				"host":   "example.com",
+			},
+		},
+		{
+			name:     "extract no match",
+			action:   ActionKeyValue{Key: "url", Action: actionExtract, Pattern: `^(?P<scheme>[a-z]+)://`},
+			input:    map[string]interface{}{"url": "/path"},
+			expected: map[string]interface{}{"url": "/path"},
+		},
+		{
+			name:     "extract not string",
+			action:   ActionKeyValue{Key: "url", Action: actionExtract, Pattern: `^(?P<number>[0-9]+)`},
+			input:    map[string]interface{}{"url": int64(42)},
+			expected: map[string]interface{}{"url": int64(42)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, (&Config{Actions: []ActionKeyValue{tt.action}}).Validate())
+			ap, err := newAttrProc([]ActionKeyValue{tt.action})
+			require.NoError(t, err)
+			attrs := pdata.NewAttributeMap().InitFromMap(rawToAttributes(tt.input))
+			ap.process(attrs)
+			assert.Equal(t, tt.expected, attrs.AsRaw())
+		})
+	}
+}
+
+func TestAttrProc_Order(t *testing.T) {
+	ap, err := newAttrProc([]ActionKeyValue{
+		{Key: "copy", Action: actionInsert, FromAttribute: "email"},
+		{Key: "email", Action: actionHash},
+		{Key: "copy", Action: actionDelete},
+	})
+	require.NoError(t, err)
+	attrs := pdata.NewAttributeMap().InitFromMap(map[string]pdata.AttributeValue{
+		"email": pdata.NewAttributeValueString("alice@example.com"),
+	})
+	ap.process(attrs)
+	assert.Equal(t, map[string]interface{}{
+		"email": "ff8d9819fc0e12bf0d24892e45987e249a28dce836a85cad60e28eaaa8c6d976",
+	}, attrs.AsRaw())
+}
+
+func rawToAttributes(raw map[string]interface{}) map[string]pdata.AttributeValue {
+	attrs := make(map[string]pdata.AttributeValue, len(raw))
+	for k, v := range raw {
+		switch val := v.(type) {
+		case int64:
+			attrs[k] = pdata.NewAttributeValueInt(val)
+		default:
+			av, _ := toAttributeValue(v)
+			attrs[k] = av
+		}
+	}
+	return attrs
+}
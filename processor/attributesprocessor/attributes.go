@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor // import "go.opentelemetry.io/collector/processor/attributesprocessor"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/model/pdata"
+	conventions "go.opentelemetry.io/collector/model/semconv/v1.5.0"
+)
+
+type attributesProcessor struct {
+	attrProc *attrProc
+	matcher  *matcher
+}
+
+func newAttributesProcessor(cfg *Config) (*attributesProcessor, error) {
+	ap, err := newAttrProc(cfg.Actions)
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMatcher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &attributesProcessor{attrProc: ap, matcher: m}, nil
+}
+
+func serviceName(res pdata.Resource) string {
+	if v, ok := res.Attributes().Get(conventions.AttributeServiceName); ok {
+		return v.StringVal()
+	}
+	return ""
+}
+
+func (a *attributesProcessor) processTraces(_ context.Context, td pdata.Traces) (pdata.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		service := serviceName(rs.Resource())
+		ilss := rs.InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				if a.matcher.matches(service, span.Name()) {
+					a.attrProc.process(span.Attributes())
+				}
+			}
+		}
+	}
+	return td, nil
+}
+
+func (a *attributesProcessor) processMetrics(_ context.Context, md pdata.Metrics) (pdata.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		if !a.matcher.matches(serviceName(rm.Resource()), "") {
+			continue
+		}
+		ilms := rm.InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				a.processMetric(metrics.At(k))
+			}
+		}
+	}
+	return md, nil
+}
+
+func (a *attributesProcessor) processMetric(m pdata.Metric) {
+	switch m.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps := m.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			a.attrProc.process(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeSum:
+		dps := m.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			a.attrProc.process(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeHistogram:
+		dps := m.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			a.attrProc.process(dps.At(i).Attributes())
+		}
+	case pdata.MetricDataTypeSummary:
+		dps := m.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			a.attrProc.process(dps.At(i).Attributes())
+		}
+	}
+}
+
+func (a *attributesProcessor) processLogs(_ context.Context, ld pdata.Logs) (pdata.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		if !a.matcher.matches(serviceName(rl.Resource()), "") {
+			continue
+		}
+		ills := rl.InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				a.attrProc.process(logs.At(k).Attributes())
+			}
+		}
+	}
+	return ld, nil
+}
@@ -0,0 +1,173 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+	conventions "go.opentelemetry.io/collector/model/semconv/v1.5.0"
+)
+
+func newTestConfig(include, exclude *MatchProperties) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = include
+	cfg.Exclude = exclude
+	cfg.Actions = []ActionKeyValue{{Key: "processed", Action: actionInsert, Value: true}}
+	return cfg
+}
+
+func TestProcessTraces(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  *MatchProperties
+		exclude  *MatchProperties
+		expected []string
+	}{
+		{
+			name:     "all",
+			expected: []string{"auth/login", "auth/logout", "cart/login", "/login"},
+		},
+		{
+			name:     "include service",
+			include:  &MatchProperties{MatchType: matchTypeStrict, Services: []string{"auth"}},
+			expected: []string{"auth/login", "auth/logout"},
+		},
+		{
+			name:     "include service and span name",
+			include:  &MatchProperties{MatchType: matchTypeRegexp, Services: []string{"^a"}, SpanNames: []string{"in$"}},
+			expected: []string{"auth/login"},
+		},
+		{
+			name:     "exclude span name",
+			exclude:  &MatchProperties{MatchType: matchTypeStrict, SpanNames: []string{"login"}},
+			expected: []string{"auth/logout"},
+		},
+		{
+			name:     "include service, exclude span name",
+			include:  &MatchProperties{MatchType: matchTypeRegexp, Services: []string{"auth|cart"}},
+			exclude:  &MatchProperties{MatchType: matchTypeStrict, SpanNames: []string{"logout"}},
+			expected: []string{"auth/login", "cart/login"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := newTestConfig(tt.include, tt.exclude)
+			require.NoError(t, cfg.Validate())
+			sink := new(consumertest.TracesSink)
+			tp, err := NewFactory().CreateTracesProcessor(context.Background(), componenttest.NewNopProcessorCreateSettings(), cfg, sink)
+			require.NoError(t, err)
+
+			td := pdata.NewTraces()
+			for _, service := range []string{"auth", "cart", ""} {
+				rs := td.ResourceSpans().AppendEmpty()
+				if service != "" {
+					rs.Resource().Attributes().InsertString(conventions.AttributeServiceName, service)
+				}
+				spans := rs.InstrumentationLibrarySpans().AppendEmpty().Spans()
+				spans.AppendEmpty().SetName("login")
+				if service == "auth" {
+					spans.AppendEmpty().SetName("logout")
+				}
+			}
+			require.NoError(t, tp.ConsumeTraces(context.Background(), td))
+
+			var processed []string
+			require.Len(t, sink.AllTraces(), 1)
+			rss := sink.AllTraces()[0].ResourceSpans()
+			for i := 0; i < rss.Len(); i++ {
+				service := serviceName(rss.At(i).Resource())
+				spans := rss.At(i).InstrumentationLibrarySpans().At(0).Spans()
+				for j := 0; j < spans.Len(); j++ {
+					if _, ok := spans.At(j).Attributes().Get("processed"); ok {
+						processed = append(processed, service+"/"+spans.At(j).Name())
+					}
+				}
+			}
+			assert.Equal(t, tt.expected, processed)
+		})
+	}
+}
+
+func TestProcessMetrics(t *testing.T) {
+	cfg := newTestConfig(&MatchProperties{MatchType: matchTypeStrict, Services: []string{"auth"}}, nil)
+	sink := new(consumertest.MetricsSink)
+	mp, err := NewFactory().CreateMetricsProcessor(context.Background(), componenttest.NewNopProcessorCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	md := pdata.NewMetrics()
+	for _, service := range []string{"auth", "cart"} {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().InsertString(conventions.AttributeServiceName, service)
+		metrics := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics()
+		gauge := metrics.AppendEmpty()
+		gauge.SetDataType(pdata.MetricDataTypeGauge)
+		gauge.Gauge().DataPoints().AppendEmpty()
+		sum := metrics.AppendEmpty()
+		sum.SetDataType(pdata.MetricDataTypeSum)
+		sum.Sum().DataPoints().AppendEmpty()
+		histogram := metrics.AppendEmpty()
+		histogram.SetDataType(pdata.MetricDataTypeHistogram)
+		histogram.Histogram().DataPoints().AppendEmpty()
+		summary := metrics.AppendEmpty()
+		summary.SetDataType(pdata.MetricDataTypeSummary)
+		summary.Summary().DataPoints().AppendEmpty()
+	}
+	require.NoError(t, mp.ConsumeMetrics(context.Background(), md))
+
+	require.Len(t, sink.AllMetrics(), 1)
+	rms := sink.AllMetrics()[0].ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		expected := serviceName(rms.At(i).Resource()) == "auth"
+		metrics := rms.At(i).InstrumentationLibraryMetrics().At(0).Metrics()
+		for _, attrs := range []pdata.AttributeMap{
+			metrics.At(0).Gauge().DataPoints().At(0).Attributes(),
+			metrics.At(1).Sum().DataPoints().At(0).Attributes(),
+			metrics.At(2).Histogram().DataPoints().At(0).Attributes(),
+			metrics.At(3).Summary().DataPoints().At(0).Attributes(),
+		} {
+			_, ok := attrs.Get("processed")
+			assert.Equal(t, expected, ok)
+		}
+	}
+}
+
+func TestProcessLogs(t *testing.T) {
+	cfg := newTestConfig(nil, &MatchProperties{MatchType: matchTypeRegexp, Services: []string{"^c"}})
+	sink := new(consumertest.LogsSink)
+	lp, err := NewFactory().CreateLogsProcessor(context.Background(), componenttest.NewNopProcessorCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+
+	ld := pdata.NewLogs()
+	for _, service := range []string{"auth", "cart"} {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().InsertString(conventions.AttributeServiceName, service)
+		rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty()
+	}
+	require.NoError(t, lp.ConsumeLogs(context.Background(), ld))
+
+	require.Len(t, sink.AllLogs(), 1)
+	rls := sink.AllLogs()[0].ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		_, ok := rls.At(i).InstrumentationLibraryLogs().At(0).Logs().At(0).Attributes().Get("processed")
+		assert.Equal(t, serviceName(rls.At(i).Resource()) == "auth", ok)
+	}
+}
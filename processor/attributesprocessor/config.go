@@ -0,0 +1,182 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor // import "go.opentelemetry.io/collector/processor/attributesprocessor"
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Actions.
+const (
+	actionInsert  = "insert"
+	actionUpdate  = "update"
+	actionUpsert  = "upsert"
+	actionDelete  = "delete"
+	actionHash    = "hash"
+	actionExtract = "extract"
+)
+
+// Match types.
+const (
+	matchTypeStrict = "strict"
+	matchTypeRegexp = "regexp"
+)
+
+// Config defines configuration for the attributes processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Include specifies the data processed, all the data when not set.
+	Include *MatchProperties `mapstructure:"include"`
+
+	// Exclude specifies the data not processed, among the included data.
+	Exclude *MatchProperties `mapstructure:"exclude"`
+
+	// Actions are the actions applied, in order, to the attributes of the spans, metric data points and log records.
+	Actions []ActionKeyValue `mapstructure:"actions"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// MatchProperties specifies the properties the data must match. The data matches when all the properties set
+// match, a property matches when any of its values matches.
+type MatchProperties struct {
+	// MatchType is "strict" to compare the values as is, or "regexp" to match them as regular expressions.
+	MatchType string `mapstructure:"match_type"`
+
+	// Services are the names of the services, from the "service.name" resource attribute.
+	Services []string `mapstructure:"services"`
+
+	// SpanNames are the names of the spans, only supported by traces pipelines.
+	SpanNames []string `mapstructure:"span_names"`
+}
+
+// ActionKeyValue defines an action on an attribute.
+type ActionKeyValue struct {
+	// Key is the key of the attribute the action applies to.
+	Key string `mapstructure:"key"`
+
+	// Action is "insert" to add the attribute if it does not exist, "update" to change it if it exists, "upsert"
+	// to do either, "delete" to remove it, "hash" to replace its value by its SHA-256 hash, or "extract" to
+	// insert or update the attributes named after the named subexpressions of Pattern matching its value.
+	Action string `mapstructure:"action"`
+
+	// Value is the string, integer, float or boolean value set by the "insert", "update" and "upsert" actions.
+	Value interface{} `mapstructure:"value"`
+
+	// FromAttribute is the key of the attribute whose value is set by the "insert", "update" and "upsert"
+	// actions, instead of Value.
+	FromAttribute string `mapstructure:"from_attribute"`
+
+	// Pattern is the regular expression with named subexpressions of the "extract" action.
+	Pattern string `mapstructure:"pattern"`
+}
+
+// Validate checks if the processor configuration is valid.
+func (cfg *Config) Validate() error {
+	if len(cfg.Actions) == 0 {
+		return errors.New("actions must be set")
+	}
+	for _, action := range cfg.Actions {
+		if err := action.validate(); err != nil {
+			return fmt.Errorf("actions: %w", err)
+		}
+	}
+	if err := cfg.Include.validate(); err != nil {
+		return fmt.Errorf("include: %w", err)
+	}
+	if err := cfg.Exclude.validate(); err != nil {
+		return fmt.Errorf("exclude: %w", err)
+	}
+	return nil
+}
+
+func (a *ActionKeyValue) validate() error {
+	if a.Key == "" {
+		return errors.New("key must be set")
+	}
+	switch a.Action {
+	case actionInsert, actionUpdate, actionUpsert:
+		if (a.Value == nil) == (a.FromAttribute == "") {
+			return fmt.Errorf("one of value and from_attribute of the %s of %q must be set", a.Action, a.Key)
+		}
+		if a.Value != nil {
+			if _, err := toAttributeValue(a.Value); err != nil {
+				return fmt.Errorf("invalid value of the %s of %q: %w", a.Action, a.Key, err)
+			}
+		}
+		if a.Pattern != "" {
+			return fmt.Errorf("pattern of the %s of %q is only supported by extract", a.Action, a.Key)
+		}
+	case actionDelete, actionHash:
+		if a.Value != nil || a.FromAttribute != "" || a.Pattern != "" {
+			return fmt.Errorf("value, from_attribute and pattern of the %s of %q must not be set", a.Action, a.Key)
+		}
+	case actionExtract:
+		if a.Value != nil || a.FromAttribute != "" {
+			return fmt.Errorf("value and from_attribute of the extract of %q must not be set", a.Key)
+		}
+		if a.Pattern == "" {
+			return fmt.Errorf("pattern of the extract of %q must be set", a.Key)
+		}
+		if _, err := compileExtractPattern(a.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern of the extract of %q: %w", a.Key, err)
+		}
+	default:
+		return fmt.Errorf("invalid action %q of %q", a.Action, a.Key)
+	}
+	return nil
+}
+
+func (mp *MatchProperties) validate() error {
+	if mp == nil {
+		return nil
+	}
+	if len(mp.Services) == 0 && len(mp.SpanNames) == 0 {
+		return errors.New("services or span_names must be set")
+	}
+	switch mp.MatchType {
+	case matchTypeStrict:
+	case matchTypeRegexp:
+		for _, values := range [][]string{mp.Services, mp.SpanNames} {
+			for _, v := range values {
+				if _, err := regexp.Compile(v); err != nil {
+					return err
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("invalid match_type %q", mp.MatchType)
+	}
+	return nil
+}
+
+// compileExtractPattern compiles the pattern of an extract action, which must have named subexpressions.
+func compileExtractPattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			return re, nil
+		}
+	}
+	return nil, errors.New("no named subexpressions")
+}
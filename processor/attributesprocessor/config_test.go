@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := configtest.LoadConfig(path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.Nil(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	assert.Equal(t, p0, factory.CreateDefaultConfig())
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "pii")]
+	assert.Equal(t, p1,
+		&Config{
+			ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "pii")),
+			Include: &MatchProperties{
+				MatchType: matchTypeRegexp,
+				Services:  []string{"auth-.*"},
+				SpanNames: []string{"login"},
+			},
+			Exclude: &MatchProperties{
+				MatchType: matchTypeStrict,
+				Services:  []string{"auth-test"},
+			},
+			Actions: []ActionKeyValue{
+				{Key: "environment", Action: actionInsert, Value: "production"},
+				{Key: "retries", Action: actionUpsert, Value: 3},
+				{Key: "client.address", Action: actionUpdate, FromAttribute: "net.peer.ip"},
+				{Key: "user.email", Action: actionHash},
+				{Key: "password", Action: actionDelete},
+				{Key: "http.url", Action: actionExtract, Pattern: `^(?P<http_scheme>.*):\/\/(?P<http_host>[^/]*)`},
+			},
+		})
+	assert.NoError(t, p1.Validate())
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *Config)
+		errMsg string
+	}{
+		{
+			name:   "no actions",
+			modify: func(cfg *Config) { cfg.Actions = nil },
+			errMsg: "actions must be set",
+		},
+		{
+			name:   "no key",
+			modify: func(cfg *Config) { cfg.Actions[0].Key = "" },
+			errMsg: "actions: key must be set",
+		},
+		{
+			name:   "invalid action",
+			modify: func(cfg *Config) { cfg.Actions[0].Action = "rename" },
+			errMsg: `actions: invalid action "rename" of "key"`,
+		},
+		{
+			name:   "no value",
+			modify: func(cfg *Config) { cfg.Actions[0].Value = nil },
+			errMsg: `actions: one of value and from_attribute of the insert of "key" must be set`,
+		},
+		{
+			name:   "value and from_attribute",
+			modify: func(cfg *Config) { cfg.Actions[0].FromAttribute = "other" },
+			errMsg: `actions: one of value and from_attribute of the insert of "key" must be set`,
+		},
+		{
+			name:   "unsupported value",
+			modify: func(cfg *Config) { cfg.Actions[0].Value = []interface{}{"a"} },
+			errMsg: `actions: invalid value of the insert of "key": unsupported value type []interface {}`,
+		},
+		{
+			name:   "insert pattern",
+			modify: func(cfg *Config) { cfg.Actions[0].Pattern = "(?P<a>.*)" },
+			errMsg: `actions: pattern of the insert of "key" is only supported by extract`,
+		},
+		{
+			name: "delete value",
+			modify: func(cfg *Config) {
+				cfg.Actions[0].Action = actionDelete
+			},
+			errMsg: `actions: value, from_attribute and pattern of the delete of "key" must not be set`,
+		},
+		{
+			name: "extract value",
+			modify: func(cfg *Config) {
+				cfg.Actions[0].Action = actionExtract
+			},
+			errMsg: `actions: value and from_attribute of the extract of "key" must not be set`,
+		},
+		{
+			name: "extract no pattern",
+			modify: func(cfg *Config) {
+				cfg.Actions[0] = ActionKeyValue{Key: "key", Action: actionExtract}
+			},
+			errMsg: `actions: pattern of the extract of "key" must be set`,
+		},
+		{
+			name: "extract unnamed pattern",
+			modify: func(cfg *Config) {
+				cfg.Actions[0] = ActionKeyValue{Key: "key", Action: actionExtract, Pattern: "(.*)"}
+			},
+			errMsg: `actions: invalid pattern of the extract of "key": no named subexpressions`,
+		},
+		{
+			name: "include nothing",
+			modify: func(cfg *Config) {
+				cfg.Include = &MatchProperties{MatchType: matchTypeStrict}
+			},
+			errMsg: "include: services or span_names must be set",
+		},
+		{
+			name: "exclude invalid match type",
+			modify: func(cfg *Config) {
+				cfg.Exclude = &MatchProperties{MatchType: "glob", Services: []string{"svc"}}
+			},
+			errMsg: `exclude: invalid match_type "glob"`,
+		},
+		{
+			name: "exclude invalid regexp",
+			modify: func(cfg *Config) {
+				cfg.Exclude = &MatchProperties{MatchType: matchTypeRegexp, SpanNames: []string{"("}}
+			},
+			errMsg: "exclude: error parsing regexp: missing closing ): `(`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Actions = []ActionKeyValue{{Key: "key", Action: actionInsert, Value: "value"}}
+			tt.modify(cfg)
+			assert.EqualError(t, cfg.Validate(), tt.errMsg)
+		})
+	}
+}
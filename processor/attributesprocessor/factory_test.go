@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := NewFactory()
+
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configtest.CheckConfigStruct(cfg))
+}
+
+func TestCreateProcessor(t *testing.T) {
+	factory := NewFactory()
+
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Actions = []ActionKeyValue{{Key: "password", Action: actionDelete}}
+	creationSet := componenttest.NewNopProcessorCreateSettings()
+	tp, err := factory.CreateTracesProcessor(context.Background(), creationSet, cfg, consumertest.NewNop())
+	assert.NotNil(t, tp)
+	assert.NoError(t, err, "cannot create trace processor")
+
+	mp, err := factory.CreateMetricsProcessor(context.Background(), creationSet, cfg, consumertest.NewNop())
+	assert.NotNil(t, mp)
+	assert.NoError(t, err, "cannot create metric processor")
+
+	lp, err := factory.CreateLogsProcessor(context.Background(), creationSet, cfg, consumertest.NewNop())
+	assert.NotNil(t, lp)
+	assert.NoError(t, err, "cannot create logs processor")
+}
+
+func TestCreateProcessor_SpanNames(t *testing.T) {
+	factory := NewFactory()
+
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Actions = []ActionKeyValue{{Key: "password", Action: actionDelete}}
+	cfg.Exclude = &MatchProperties{MatchType: matchTypeStrict, SpanNames: []string{"login"}}
+	creationSet := componenttest.NewNopProcessorCreateSettings()
+	tp, err := factory.CreateTracesProcessor(context.Background(), creationSet, cfg, consumertest.NewNop())
+	assert.NotNil(t, tp)
+	assert.NoError(t, err)
+
+	_, err = factory.CreateMetricsProcessor(context.Background(), creationSet, cfg, consumertest.NewNop())
+	assert.Equal(t, errSpanNamesNotSupported, err)
+
+	_, err = factory.CreateLogsProcessor(context.Background(), creationSet, cfg, consumertest.NewNop())
+	assert.Equal(t, errSpanNamesNotSupported, err)
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package attributesprocessor // import "go.opentelemetry.io/collector/processor/attributesprocessor"
+
+import "regexp"
+
+// nameMatcher matches names against a list of values, strictly or as regular expressions. A nil nameMatcher
+// matches any name.
+type nameMatcher struct {
+	strict  map[string]struct{}
+	regexps []*regexp.Regexp
+}
+
+func newNameMatcher(matchType string, values []string) (*nameMatcher, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	m := &nameMatcher{}
+	if matchType == matchTypeStrict {
+		m.strict = make(map[string]struct{}, len(values))
+		for _, v := range values {
+			m.strict[v] = struct{}{}
+		}
+		return m, nil
+	}
+	for _, v := range values {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return nil, err
+		}
+		m.regexps = append(m.regexps, re)
+	}
+	return m, nil
+}
+
+func (m *nameMatcher) matches(name string) bool {
+	if m == nil {
+		return true
+	}
+	if _, ok := m.strict[name]; ok {
+		return true
+	}
+	for _, re := range m.regexps {
+		if re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// propertiesMatcher matches the data against MatchProperties.
+type propertiesMatcher struct {
+	services  *nameMatcher
+	spanNames *nameMatcher
+}
+
+func newPropertiesMatcher(mp *MatchProperties) (*propertiesMatcher, error) {
+	if mp == nil {
+		return nil, nil
+	}
+	services, err := newNameMatcher(mp.MatchType, mp.Services)
+	if err != nil {
+		return nil, err
+	}
+	spanNames, err := newNameMatcher(mp.MatchType, mp.SpanNames)
+	if err != nil {
+		return nil, err
+	}
+	return &propertiesMatcher{services: services, spanNames: spanNames}, nil
+}
+
+// matcher tells which data is processed according to the include and exclude properties.
+type matcher struct {
+	include *propertiesMatcher
+	exclude *propertiesMatcher
+}
+
+func newMatcher(cfg *Config) (*matcher, error) {
+	include, err := newPropertiesMatcher(cfg.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := newPropertiesMatcher(cfg.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &matcher{include: include, exclude: exclude}, nil
+}
+
+// matches tells if the data of the service, and of the span name for spans, is processed. The span name is
+// ignored when no span names are configured.
+func (m *matcher) matches(service, spanName string) bool {
+	if m.include != nil && !(m.include.services.matches(service) && m.include.spanNames.matches(spanName)) {
+		return false
+	}
+	if m.exclude != nil && m.exclude.services.matches(service) && m.exclude.spanNames.matches(spanName) {
+		return false
+	}
+	return true
+}
@@ -16,17 +16,24 @@ package batchprocessor // import "go.opentelemetry.io/collector/processor/batchp
 
 import (
 	"context"
+	"errors"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
 	"go.uber.org/zap"
 
+	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configtelemetry"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/internal/batchutil"
 	"go.opentelemetry.io/collector/model/otlp"
 	"go.opentelemetry.io/collector/model/pdata"
 )
@@ -38,17 +45,29 @@ import (
 //
 // Batches are sent out with any of the following conditions:
 // - batch size reaches cfg.SendBatchSize
+// - batch size in bytes reaches cfg.SendBatchSizeBytes, if set
 // - cfg.Timeout is elapsed since the timestamp when the previous batch was sent out.
+//
+// When cfg.MetadataKeys is set, the data is batched separately for every distinct combination of the values of
+// these keys in the client metadata, each combination having its own shard.
 type batchProcessor struct {
 	logger           *zap.Logger
 	exportCtx        context.Context
-	timer            *time.Timer
 	timeout          time.Duration
 	sendBatchSize    int
 	sendBatchMaxSize int
 
-	newItem chan interface{}
-	batch   batch
+	sendBatchSizeBytes    int
+	sendBatchMaxSizeBytes int
+
+	newBatch      func() batch
+	metadataKeys  []string
+	metadataLimit int
+	idleTimeout   time.Duration
+
+	lock    sync.Mutex
+	started bool
+	shards  map[string]*shard
 
 	shutdownC  chan struct{}
 	goroutines sync.WaitGroup
@@ -56,9 +75,23 @@ type batchProcessor struct {
 	telemetryLevel configtelemetry.Level
 }
 
+// shard is a batch of the data sharing the same values of the metadata keys, with its own processing cycle.
+type shard struct {
+	processor *batchProcessor
+	key       string
+	exportCtx context.Context
+	timer     *time.Timer
+	newItem   chan interface{}
+	batch     batch
+	// pending is the number of items being sent to newItem, the shard is not removed while it is positive.
+	pending int64
+	// lastItem is the time of the last processed item.
+	lastItem time.Time
+}
+
 type batch interface {
-	// export the current batch
-	export(ctx context.Context, sendBatchMaxSize int) error
+	// export the current batch, or the part of it that fits into the maximum sizes
+	export(ctx context.Context, sendBatchMaxSize, sendBatchMaxSizeBytes int) error
 
 	// itemCount returns the size of the current batch
 	itemCount() int
@@ -74,12 +107,20 @@ var _ consumer.Traces = (*batchProcessor)(nil)
 var _ consumer.Metrics = (*batchProcessor)(nil)
 var _ consumer.Logs = (*batchProcessor)(nil)
 
-func newBatchProcessor(set component.ProcessorCreateSettings, cfg *Config, batch batch, telemetryLevel configtelemetry.Level) (*batchProcessor, error) {
+// errTooManyShards is returned when the data has a new combination of metadata values while the
+// cardinality limit is reached.
+var errTooManyShards = consumererror.NewPermanent(errors.New("too many batcher metadata-value combinations"))
+
+func newBatchProcessor(set component.ProcessorCreateSettings, cfg *Config, newBatch func() batch, telemetryLevel configtelemetry.Level) (*batchProcessor, error) {
 	exportCtx, err := tag.New(context.Background(), tag.Insert(processorTagKey, cfg.ID().String()))
 	if err != nil {
 		return nil, err
 	}
-	return &batchProcessor{
+	metadataKeys := make([]string, len(cfg.MetadataKeys))
+	for i, key := range cfg.MetadataKeys {
+		metadataKeys[i] = strings.ToLower(key)
+	}
+	bp := &batchProcessor{
 		logger:         set.Logger,
 		exportCtx:      exportCtx,
 		telemetryLevel: telemetryLevel,
@@ -87,10 +128,32 @@ func newBatchProcessor(set component.ProcessorCreateSettings, cfg *Config, batch
 		sendBatchSize:    int(cfg.SendBatchSize),
 		sendBatchMaxSize: int(cfg.SendBatchMaxSize),
 		timeout:          cfg.Timeout,
-		newItem:          make(chan interface{}, runtime.NumCPU()),
-		batch:            batch,
-		shutdownC:        make(chan struct{}, 1),
-	}, nil
+
+		sendBatchSizeBytes:    int(cfg.SendBatchSizeBytes),
+		sendBatchMaxSizeBytes: int(cfg.SendBatchMaxSizeBytes),
+
+		newBatch:      newBatch,
+		metadataKeys:  metadataKeys,
+		metadataLimit: int(cfg.MetadataCardinalityLimit),
+		idleTimeout:   cfg.MetadataIdleTimeout,
+		shards:        map[string]*shard{},
+		shutdownC:     make(chan struct{}, 1),
+	}
+	if len(metadataKeys) == 0 {
+		bp.shards[""] = bp.newShard("", exportCtx)
+	}
+	return bp, nil
+}
+
+func (bp *batchProcessor) newShard(key string, exportCtx context.Context) *shard {
+	return &shard{
+		processor: bp,
+		key:       key,
+		exportCtx: exportCtx,
+		newItem:   make(chan interface{}, runtime.NumCPU()),
+		batch:     bp.newBatch(),
+		lastItem:  time.Now(),
+	}
 }
 
 func (bp *batchProcessor) Capabilities() consumer.Capabilities {
@@ -99,8 +162,12 @@ func (bp *batchProcessor) Capabilities() consumer.Capabilities {
 
 // Start is invoked during service startup.
 func (bp *batchProcessor) Start(context.Context, component.Host) error {
-	bp.goroutines.Add(1)
-	go bp.startProcessingCycle()
+	bp.lock.Lock()
+	defer bp.lock.Unlock()
+	bp.started = true
+	for _, s := range bp.shards {
+		bp.startShard(s)
+	}
 	return nil
 }
 
@@ -113,117 +180,202 @@ func (bp *batchProcessor) Shutdown(context.Context) error {
 	return nil
 }
 
-func (bp *batchProcessor) startProcessingCycle() {
-	defer bp.goroutines.Done()
-	bp.timer = time.NewTimer(bp.timeout)
+func (bp *batchProcessor) startShard(s *shard) {
+	bp.goroutines.Add(1)
+	go s.startProcessingCycle()
+}
+
+// shardFor returns the shard of the data consumed with the context, creating it if needed. The returned shard
+// is not removed until consume sent the data to it.
+func (bp *batchProcessor) shardFor(ctx context.Context) (*shard, error) {
+	if len(bp.metadataKeys) == 0 {
+		// The single shard is never added or removed, so it can be read without the lock.
+		return bp.shards[""], nil
+	}
+
+	var md map[string][]string
+	if c, ok := client.FromContext(ctx); ok {
+		md = c.Metadata
+	}
+	var key strings.Builder
+	shardMetadata := map[string][]string{}
+	for _, k := range bp.metadataKeys {
+		values := md[k]
+		if len(values) > 0 {
+			shardMetadata[k] = values
+		}
+		// Quoting the values keeps the key of different combinations different.
+		key.WriteString(strconv.Itoa(len(values)))
+		for _, v := range values {
+			key.WriteString(strconv.Quote(v))
+		}
+	}
+
+	bp.lock.Lock()
+	defer bp.lock.Unlock()
+	s, ok := bp.shards[key.String()]
+	if !ok {
+		if len(bp.shards) >= bp.metadataLimit {
+			return nil, errTooManyShards
+		}
+		// The batches of the shard are exported with the metadata values they were batched by, so the next
+		// consumers can still use them.
+		s = bp.newShard(key.String(), client.NewContext(bp.exportCtx, &client.Client{Metadata: shardMetadata}))
+		bp.shards[key.String()] = s
+		if bp.started {
+			bp.startShard(s)
+		}
+	}
+	atomic.AddInt64(&s.pending, 1)
+	return s, nil
+}
+
+// removeIdleShard removes the shard if it received no data for the idle timeout and no data is being sent to
+// it, returning whether it was removed.
+func (bp *batchProcessor) removeIdleShard(s *shard) bool {
+	if len(bp.metadataKeys) == 0 || bp.idleTimeout == 0 || time.Since(s.lastItem) < bp.idleTimeout {
+		return false
+	}
+	bp.lock.Lock()
+	defer bp.lock.Unlock()
+	// The pending count is only increased with the lock held, so no data can be sent to the shard once removed.
+	if atomic.LoadInt64(&s.pending) > 0 || len(s.newItem) > 0 {
+		return false
+	}
+	delete(bp.shards, s.key)
+	return true
+}
+
+func (bp *batchProcessor) consume(ctx context.Context, data interface{}) error {
+	s, err := bp.shardFor(ctx)
+	if err != nil {
+		return err
+	}
+	s.newItem <- data
+	if len(bp.metadataKeys) > 0 {
+		atomic.AddInt64(&s.pending, -1)
+	}
+	return nil
+}
+
+func (s *shard) startProcessingCycle() {
+	defer s.processor.goroutines.Done()
+	s.timer = time.NewTimer(s.processor.timeout)
 	for {
 		select {
-		case <-bp.shutdownC:
+		case <-s.processor.shutdownC:
 		DONE:
 			for {
 				select {
-				case item := <-bp.newItem:
-					bp.processItem(item)
+				case item := <-s.newItem:
+					s.processItem(item)
 				default:
 					break DONE
 				}
 			}
 			// This is the close of the channel
-			if bp.batch.itemCount() > 0 {
+			if s.batch.itemCount() > 0 {
 				// TODO: Set a timeout on sendTraces or
 				// make it cancellable using the context that Shutdown gets as a parameter
-				bp.sendItems(statTimeoutTriggerSend)
+				s.sendItems(statTimeoutTriggerSend)
 			}
 			return
-		case item := <-bp.newItem:
+		case item := <-s.newItem:
 			if item == nil {
 				continue
 			}
-			bp.processItem(item)
-		case <-bp.timer.C:
-			if bp.batch.itemCount() > 0 {
-				bp.sendItems(statTimeoutTriggerSend)
+			s.processItem(item)
+		case <-s.timer.C:
+			if s.batch.itemCount() > 0 {
+				s.sendItems(statTimeoutTriggerSend)
+			} else if s.processor.removeIdleShard(s) {
+				return
 			}
-			bp.resetTimer()
+			s.resetTimer()
 		}
 	}
 }
 
-func (bp *batchProcessor) processItem(item interface{}) {
-	bp.batch.add(item)
+func (s *shard) processItem(item interface{}) {
+	s.lastItem = time.Now()
+	s.batch.add(item)
 	sent := false
-	for bp.batch.itemCount() >= bp.sendBatchSize {
+	for {
+		if s.batch.itemCount() >= s.processor.sendBatchSize {
+			s.sendItems(statBatchSizeTriggerSend)
+		} else if s.processor.sendBatchSizeBytes > 0 && s.batch.size() >= s.processor.sendBatchSizeBytes {
+			s.sendItems(statBatchSizeBytesTriggerSend)
+		} else {
+			break
+		}
 		sent = true
-		bp.sendItems(statBatchSizeTriggerSend)
 	}
 
 	if sent {
-		bp.stopTimer()
-		bp.resetTimer()
+		s.stopTimer()
+		s.resetTimer()
 	}
 }
 
-func (bp *batchProcessor) stopTimer() {
-	if !bp.timer.Stop() {
-		<-bp.timer.C
+func (s *shard) stopTimer() {
+	if !s.timer.Stop() {
+		<-s.timer.C
 	}
 }
 
-func (bp *batchProcessor) resetTimer() {
-	bp.timer.Reset(bp.timeout)
+func (s *shard) resetTimer() {
+	s.timer.Reset(s.processor.timeout)
 }
 
-func (bp *batchProcessor) sendItems(triggerMeasure *stats.Int64Measure) {
+func (s *shard) sendItems(triggerMeasure *stats.Int64Measure) {
+	bp := s.processor
 	// Add that it came form the trace pipeline?
-	stats.Record(bp.exportCtx, triggerMeasure.M(1), statBatchSendSize.M(int64(bp.batch.itemCount())))
+	stats.Record(bp.exportCtx, triggerMeasure.M(1), statBatchSendSize.M(int64(s.batch.itemCount())))
 
-	if bp.telemetryLevel == configtelemetry.LevelDetailed {
-		stats.Record(bp.exportCtx, statBatchSendSizeBytes.M(int64(bp.batch.size())))
+	if bp.telemetryLevel == configtelemetry.LevelDetailed || bp.sendBatchSizeBytes > 0 || bp.sendBatchMaxSizeBytes > 0 {
+		stats.Record(bp.exportCtx, statBatchSendSizeBytes.M(int64(s.batch.size())))
 	}
 
-	if err := bp.batch.export(bp.exportCtx, bp.sendBatchMaxSize); err != nil {
+	if err := s.batch.export(s.exportCtx, bp.sendBatchMaxSize, bp.sendBatchMaxSizeBytes); err != nil {
 		bp.logger.Warn("Sender failed", zap.Error(err))
 	}
 }
 
 // ConsumeTraces implements TracesProcessor
-func (bp *batchProcessor) ConsumeTraces(_ context.Context, td pdata.Traces) error {
-	bp.newItem <- td
-	return nil
+func (bp *batchProcessor) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
+	return bp.consume(ctx, td)
 }
 
 // ConsumeMetrics implements MetricsProcessor
-func (bp *batchProcessor) ConsumeMetrics(_ context.Context, md pdata.Metrics) error {
-	// First thing is convert into a different internal format
-	bp.newItem <- md
-	return nil
+func (bp *batchProcessor) ConsumeMetrics(ctx context.Context, md pdata.Metrics) error {
+	return bp.consume(ctx, md)
 }
 
 // ConsumeLogs implements LogsProcessor
-func (bp *batchProcessor) ConsumeLogs(_ context.Context, ld pdata.Logs) error {
-	bp.newItem <- ld
-	return nil
+func (bp *batchProcessor) ConsumeLogs(ctx context.Context, ld pdata.Logs) error {
+	return bp.consume(ctx, ld)
 }
 
 // newBatchTracesProcessor creates a new batch processor that batches traces by size or with timeout
 func newBatchTracesProcessor(set component.ProcessorCreateSettings, next consumer.Traces, cfg *Config, telemetryLevel configtelemetry.Level) (*batchProcessor, error) {
-	return newBatchProcessor(set, cfg, newBatchTraces(next), telemetryLevel)
+	return newBatchProcessor(set, cfg, func() batch { return newBatchTraces(next) }, telemetryLevel)
 }
 
 // newBatchMetricsProcessor creates a new batch processor that batches metrics by size or with timeout
 func newBatchMetricsProcessor(set component.ProcessorCreateSettings, next consumer.Metrics, cfg *Config, telemetryLevel configtelemetry.Level) (*batchProcessor, error) {
-	return newBatchProcessor(set, cfg, newBatchMetrics(next), telemetryLevel)
+	return newBatchProcessor(set, cfg, func() batch { return newBatchMetrics(next) }, telemetryLevel)
 }
 
 // newBatchLogsProcessor creates a new batch processor that batches logs by size or with timeout
 func newBatchLogsProcessor(set component.ProcessorCreateSettings, next consumer.Logs, cfg *Config, telemetryLevel configtelemetry.Level) (*batchProcessor, error) {
-	return newBatchProcessor(set, cfg, newBatchLogs(next), telemetryLevel)
+	return newBatchProcessor(set, cfg, func() batch { return newBatchLogs(next) }, telemetryLevel)
 }
 
 type batchTraces struct {
 	nextConsumer consumer.Traces
 	traceData    pdata.Traces
 	spanCount    int
+	bytes        int
 	sizer        pdata.TracesSizer
 }
 
@@ -240,19 +392,27 @@ func (bt *batchTraces) add(item interface{}) {
 	}
 
 	bt.spanCount += newSpanCount
+	// The encoded size of the batch is the sum of the sizes of the appended data.
+	bt.bytes += bt.sizer.TracesSize(td)
 	td.ResourceSpans().MoveAndAppendTo(bt.traceData.ResourceSpans())
 }
 
-func (bt *batchTraces) export(ctx context.Context, sendBatchMaxSize int) error {
+func (bt *batchTraces) export(ctx context.Context, sendBatchMaxSize, sendBatchMaxSizeBytes int) error {
 	var req pdata.Traces
-	if sendBatchMaxSize > 0 && bt.itemCount() > sendBatchMaxSize {
-		req = splitTraces(sendBatchMaxSize, bt.traceData)
-		bt.spanCount -= sendBatchMaxSize
+	if (sendBatchMaxSize > 0 && bt.spanCount > sendBatchMaxSize) || (sendBatchMaxSizeBytes > 0 && bt.bytes > sendBatchMaxSizeBytes) {
+		req = batchutil.SplitTracesBySize(sendBatchMaxSize, sendBatchMaxSizeBytes, bt.sizer, bt.traceData)
+		bt.spanCount -= req.SpanCount()
+		// Only the split part is measured, the remaining size is over-estimated by the descriptions possibly
+		// kept on both sides.
+		bt.bytes += batchutil.SplitTracesOverhead(bt.sizer, bt.traceData) - bt.sizer.TracesSize(req)
 	} else {
 		req = bt.traceData
-		bt.traceData = pdata.NewTraces()
 		bt.spanCount = 0
 	}
+	if bt.spanCount == 0 {
+		bt.traceData = pdata.NewTraces()
+		bt.bytes = 0
+	}
 	return bt.nextConsumer.ConsumeTraces(ctx, req)
 }
 
@@ -261,13 +421,14 @@ func (bt *batchTraces) itemCount() int {
 }
 
 func (bt *batchTraces) size() int {
-	return bt.sizer.TracesSize(bt.traceData)
+	return bt.bytes
 }
 
 type batchMetrics struct {
 	nextConsumer   consumer.Metrics
 	metricData     pdata.Metrics
 	dataPointCount int
+	bytes          int
 	sizer          pdata.MetricsSizer
 }
 
@@ -275,16 +436,20 @@ func newBatchMetrics(nextConsumer consumer.Metrics) *batchMetrics {
 	return &batchMetrics{nextConsumer: nextConsumer, metricData: pdata.NewMetrics(), sizer: otlp.NewProtobufMetricsMarshaler().(pdata.MetricsSizer)}
 }
 
-func (bm *batchMetrics) export(ctx context.Context, sendBatchMaxSize int) error {
+func (bm *batchMetrics) export(ctx context.Context, sendBatchMaxSize, sendBatchMaxSizeBytes int) error {
 	var req pdata.Metrics
-	if sendBatchMaxSize > 0 && bm.dataPointCount > sendBatchMaxSize {
-		req = splitMetrics(sendBatchMaxSize, bm.metricData)
-		bm.dataPointCount -= sendBatchMaxSize
+	if (sendBatchMaxSize > 0 && bm.dataPointCount > sendBatchMaxSize) || (sendBatchMaxSizeBytes > 0 && bm.bytes > sendBatchMaxSizeBytes) {
+		req = batchutil.SplitMetricsBySize(sendBatchMaxSize, sendBatchMaxSizeBytes, bm.sizer, bm.metricData)
+		bm.dataPointCount -= req.DataPointCount()
+		bm.bytes += batchutil.SplitMetricsOverhead(bm.sizer, bm.metricData) - bm.sizer.MetricsSize(req)
 	} else {
 		req = bm.metricData
-		bm.metricData = pdata.NewMetrics()
 		bm.dataPointCount = 0
 	}
+	if bm.dataPointCount == 0 {
+		bm.metricData = pdata.NewMetrics()
+		bm.bytes = 0
+	}
 	return bm.nextConsumer.ConsumeMetrics(ctx, req)
 }
 
@@ -293,7 +458,7 @@ func (bm *batchMetrics) itemCount() int {
 }
 
 func (bm *batchMetrics) size() int {
-	return bm.sizer.MetricsSize(bm.metricData)
+	return bm.bytes
 }
 
 func (bm *batchMetrics) add(item interface{}) {
@@ -304,6 +469,8 @@ func (bm *batchMetrics) add(item interface{}) {
 		return
 	}
 	bm.dataPointCount += newDataPointCount
+	// The encoded size of the batch is the sum of the sizes of the appended data.
+	bm.bytes += bm.sizer.MetricsSize(md)
 	md.ResourceMetrics().MoveAndAppendTo(bm.metricData.ResourceMetrics())
 }
 
@@ -311,6 +478,7 @@ type batchLogs struct {
 	nextConsumer consumer.Logs
 	logData      pdata.Logs
 	logCount     int
+	bytes        int
 	sizer        pdata.LogsSizer
 }
 
@@ -318,16 +486,20 @@ func newBatchLogs(nextConsumer consumer.Logs) *batchLogs {
 	return &batchLogs{nextConsumer: nextConsumer, logData: pdata.NewLogs(), sizer: otlp.NewProtobufLogsMarshaler().(pdata.LogsSizer)}
 }
 
-func (bl *batchLogs) export(ctx context.Context, sendBatchMaxSize int) error {
+func (bl *batchLogs) export(ctx context.Context, sendBatchMaxSize, sendBatchMaxSizeBytes int) error {
 	var req pdata.Logs
-	if sendBatchMaxSize > 0 && bl.logCount > sendBatchMaxSize {
-		req = splitLogs(sendBatchMaxSize, bl.logData)
-		bl.logCount -= sendBatchMaxSize
+	if (sendBatchMaxSize > 0 && bl.logCount > sendBatchMaxSize) || (sendBatchMaxSizeBytes > 0 && bl.bytes > sendBatchMaxSizeBytes) {
+		req = batchutil.SplitLogsBySize(sendBatchMaxSize, sendBatchMaxSizeBytes, bl.sizer, bl.logData)
+		bl.logCount -= req.LogRecordCount()
+		bl.bytes += batchutil.SplitLogsOverhead(bl.sizer, bl.logData) - bl.sizer.LogsSize(req)
 	} else {
 		req = bl.logData
-		bl.logData = pdata.NewLogs()
 		bl.logCount = 0
 	}
+	if bl.logCount == 0 {
+		bl.logData = pdata.NewLogs()
+		bl.bytes = 0
+	}
 	return bl.nextConsumer.ConsumeLogs(ctx, req)
 }
 
@@ -336,7 +508,7 @@ func (bl *batchLogs) itemCount() int {
 }
 
 func (bl *batchLogs) size() int {
-	return bl.sizer.LogsSize(bl.logData)
+	return bl.bytes
 }
 
 func (bl *batchLogs) add(item interface{}) {
@@ -347,5 +519,7 @@ func (bl *batchLogs) add(item interface{}) {
 		return
 	}
 	bl.logCount += newLogsCount
+	// The encoded size of the batch is the sum of the sizes of the appended data.
+	bl.bytes += bl.sizer.LogsSize(ld)
 	ld.ResourceLogs().MoveAndAppendTo(bl.logData.ResourceLogs())
 }
@@ -25,10 +25,12 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opencensus.io/stats/view"
 
+	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configtelemetry"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/internal/testdata"
 	"go.opentelemetry.io/collector/model/otlp"
@@ -182,6 +184,92 @@ func TestBatchProcessorSentBySize(t *testing.T) {
 	assert.Equal(t, sizeSum, int(distData.Sum()))
 }
 
+func TestBatchProcessorSentBySizeBytes(t *testing.T) {
+	sizer := otlp.NewProtobufTracesMarshaler().(pdata.TracesSizer)
+	views := MetricViews()
+	require.NoError(t, view.Register(views...))
+	defer view.Unregister(views...)
+
+	requestCount := 100
+	spansPerRequest := 5
+	requestsPerBatch := 4
+	requestSize := sizer.TracesSize(testdata.GenerateTracesManySpansSameResource(spansPerRequest))
+
+	sink := new(consumertest.TracesSink)
+	cfg := createDefaultConfig().(*Config)
+	cfg.SendBatchSizeBytes = uint32(requestsPerBatch * requestSize)
+	cfg.Timeout = time.Hour
+	batcher, err := newBatchTracesProcessor(componenttest.NewNopProcessorCreateSettings(), sink, cfg, configtelemetry.LevelBasic)
+	require.NoError(t, err)
+	require.NoError(t, batcher.Start(context.Background(), componenttest.NewNopHost()))
+
+	for requestNum := 0; requestNum < requestCount; requestNum++ {
+		assert.NoError(t, batcher.ConsumeTraces(context.Background(), testdata.GenerateTracesManySpansSameResource(spansPerRequest)))
+	}
+	require.NoError(t, batcher.Shutdown(context.Background()))
+
+	require.Equal(t, requestCount*spansPerRequest, sink.SpanCount())
+	require.Len(t, sink.AllTraces(), requestCount/requestsPerBatch)
+	for _, td := range sink.AllTraces() {
+		assert.Equal(t, requestsPerBatch*spansPerRequest, td.SpanCount())
+	}
+
+	viewData, err := view.RetrieveData("processor/batch/" + statBatchSizeBytesTriggerSend.Name())
+	require.NoError(t, err)
+	require.Len(t, viewData, 1)
+	assert.Equal(t, float64(requestCount/requestsPerBatch), viewData[0].Data.(*view.SumData).Value)
+
+	viewData, err = view.RetrieveData("processor/batch/" + statBatchSendSizeBytes.Name())
+	require.NoError(t, err)
+	require.Len(t, viewData, 1)
+	assert.Equal(t, float64(requestCount*requestSize), viewData[0].Data.(*view.DistributionData).Sum())
+}
+
+func TestBatchProcessorSpansDeliveredEnforceBatchSizeBytes(t *testing.T) {
+	sizer := otlp.NewProtobufTracesMarshaler().(pdata.TracesSizer)
+	sink := new(consumertest.TracesSink)
+	cfg := createDefaultConfig().(*Config)
+	cfg.SendBatchSizeBytes = 4000
+	cfg.SendBatchMaxSizeBytes = 5000
+	batcher, err := newBatchTracesProcessor(componenttest.NewNopProcessorCreateSettings(), sink, cfg, configtelemetry.LevelBasic)
+	require.NoError(t, err)
+	require.NoError(t, batcher.Start(context.Background(), componenttest.NewNopHost()))
+
+	requestCount := 100
+	spansPerRequest := 150
+	for requestNum := 0; requestNum < requestCount; requestNum++ {
+		assert.NoError(t, batcher.ConsumeTraces(context.Background(), testdata.GenerateTracesManySpansSameResource(spansPerRequest)))
+	}
+	require.NoError(t, batcher.Shutdown(context.Background()))
+
+	require.Equal(t, requestCount*spansPerRequest, sink.SpanCount())
+	for _, td := range sink.AllTraces() {
+		assert.LessOrEqual(t, sizer.TracesSize(td), int(cfg.SendBatchMaxSizeBytes))
+	}
+}
+
+func TestBatchMetrics_BatchMaxSizeBytes(t *testing.T) {
+	sizer := otlp.NewProtobufMetricsMarshaler().(pdata.MetricsSizer)
+	sink := new(consumertest.MetricsSink)
+	batchMetrics := newBatchMetrics(sink)
+	md := testdata.GenerateMetricsManyMetricsSameResource(100)
+	total := md.DataPointCount()
+	maxSizeBytes := sizer.MetricsSize(md) / 3
+
+	batchMetrics.add(md)
+	for batchMetrics.itemCount() > 0 {
+		require.NoError(t, batchMetrics.export(context.Background(), 0, maxSizeBytes))
+		// The remaining size is tracked without measuring it again, and never under-estimated.
+		assert.LessOrEqual(t, sizer.MetricsSize(batchMetrics.metricData), batchMetrics.size())
+	}
+
+	require.Equal(t, total, sink.DataPointCount())
+	require.Len(t, sink.AllMetrics(), 4)
+	for _, md := range sink.AllMetrics() {
+		assert.LessOrEqual(t, sizer.MetricsSize(md), maxSizeBytes)
+	}
+}
+
 func TestBatchProcessorSentByTimeout(t *testing.T) {
 	sink := new(consumertest.TracesSink)
 	cfg := createDefaultConfig().(*Config)
@@ -386,7 +474,7 @@ func TestBatchMetrics_UnevenBatchMaxSize(t *testing.T) {
 
 	batchMetrics.add(md)
 	require.Equal(t, dataPointsPerMetric*metricsCount, batchMetrics.dataPointCount)
-	require.NoError(t, batchMetrics.export(ctx, sendBatchMaxSize))
+	require.NoError(t, batchMetrics.export(ctx, sendBatchMaxSize, 0))
 	remainingDataPointCount := metricsCount*dataPointsPerMetric - sendBatchMaxSize
 	require.Equal(t, remainingDataPointCount, batchMetrics.dataPointCount)
 }
@@ -790,3 +878,105 @@ func TestShutdown(t *testing.T) {
 	factory := NewFactory()
 	componenttest.VerifyProcessorShutdown(t, factory, factory.CreateDefaultConfig())
 }
+
+// metadataTracesSink stores the received traces by the tenant_id client metadata of their context.
+type metadataTracesSink struct {
+	mu      sync.Mutex
+	batches map[string][]pdata.Traces
+}
+
+func (sink *metadataTracesSink) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (sink *metadataTracesSink) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	tenant := "none"
+	if c, ok := client.FromContext(ctx); ok {
+		if v, ok := c.MetadataValue("tenant_id"); ok {
+			tenant = v
+		}
+	}
+	sink.batches[tenant] = append(sink.batches[tenant], td)
+	return nil
+}
+
+func TestBatchProcessor_MetadataKeys(t *testing.T) {
+	sink := &metadataTracesSink{batches: map[string][]pdata.Traces{}}
+	cfg := createDefaultConfig().(*Config)
+	cfg.SendBatchSize = 1000
+	cfg.Timeout = time.Hour
+	cfg.MetadataKeys = []string{"Tenant_ID"}
+	batcher, err := newBatchTracesProcessor(componenttest.NewNopProcessorCreateSettings(), sink, cfg, configtelemetry.LevelDetailed)
+	require.NoError(t, err)
+	require.NoError(t, batcher.Start(context.Background(), componenttest.NewNopHost()))
+
+	tenantCtx := func(tenant string) context.Context {
+		return client.NewContext(context.Background(), &client.Client{
+			IP:       "10.0.0.1",
+			Metadata: map[string][]string{"tenant_id": {tenant}, "other": {tenant}},
+		})
+	}
+	for i := 0; i < 10; i++ {
+		require.NoError(t, batcher.ConsumeTraces(tenantCtx("a"), testdata.GenerateTracesManySpansSameResource(1)))
+		require.NoError(t, batcher.ConsumeTraces(tenantCtx("b"), testdata.GenerateTracesManySpansSameResource(2)))
+		require.NoError(t, batcher.ConsumeTraces(context.Background(), testdata.GenerateTracesManySpansSameResource(3)))
+	}
+	require.NoError(t, batcher.Shutdown(context.Background()))
+
+	require.Len(t, sink.batches, 3)
+	for tenant, spanCount := range map[string]int{"a": 10, "b": 20, "none": 30} {
+		require.Len(t, sink.batches[tenant], 1, tenant)
+		assert.Equal(t, spanCount, sink.batches[tenant][0].SpanCount(), tenant)
+	}
+}
+
+func TestBatchProcessor_MetadataCardinalityLimit(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	cfg := createDefaultConfig().(*Config)
+	cfg.MetadataKeys = []string{"tenant_id"}
+	cfg.MetadataCardinalityLimit = 1
+	batcher, err := newBatchTracesProcessor(componenttest.NewNopProcessorCreateSettings(), sink, cfg, configtelemetry.LevelDetailed)
+	require.NoError(t, err)
+	require.NoError(t, batcher.Start(context.Background(), componenttest.NewNopHost()))
+
+	ctxA := client.NewContext(context.Background(), &client.Client{Metadata: map[string][]string{"tenant_id": {"a"}}})
+	ctxB := client.NewContext(context.Background(), &client.Client{Metadata: map[string][]string{"tenant_id": {"b"}}})
+	require.NoError(t, batcher.ConsumeTraces(ctxA, testdata.GenerateTracesOneSpan()))
+	require.NoError(t, batcher.ConsumeTraces(ctxA, testdata.GenerateTracesOneSpan()))
+	err = batcher.ConsumeTraces(ctxB, testdata.GenerateTracesOneSpan())
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.ErrorIs(t, err, errTooManyShards)
+	require.NoError(t, batcher.Shutdown(context.Background()))
+
+	assert.Equal(t, 2, sink.SpanCount())
+}
+
+func TestBatchProcessor_MetadataIdleTimeout(t *testing.T) {
+	sink := new(consumertest.TracesSink)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Timeout = 10 * time.Millisecond
+	cfg.MetadataKeys = []string{"tenant_id"}
+	cfg.MetadataCardinalityLimit = 1
+	cfg.MetadataIdleTimeout = 50 * time.Millisecond
+	batcher, err := newBatchTracesProcessor(componenttest.NewNopProcessorCreateSettings(), sink, cfg, configtelemetry.LevelDetailed)
+	require.NoError(t, err)
+	require.NoError(t, batcher.Start(context.Background(), componenttest.NewNopHost()))
+
+	ctxA := client.NewContext(context.Background(), &client.Client{Metadata: map[string][]string{"tenant_id": {"a"}}})
+	ctxB := client.NewContext(context.Background(), &client.Client{Metadata: map[string][]string{"tenant_id": {"b"}}})
+	require.NoError(t, batcher.ConsumeTraces(ctxA, testdata.GenerateTracesOneSpan()))
+	assert.ErrorIs(t, batcher.ConsumeTraces(ctxB, testdata.GenerateTracesOneSpan()), errTooManyShards)
+
+	// Once the shard of tenant a is idle, it is removed and tenant b gets one.
+	assert.Eventually(t, func() bool {
+		return batcher.ConsumeTraces(ctxB, testdata.GenerateTracesOneSpan()) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	batcher.lock.Lock()
+	assert.Len(t, batcher.shards, 1)
+	batcher.lock.Unlock()
+	require.NoError(t, batcher.Shutdown(context.Background()))
+
+	assert.Equal(t, 2, sink.SpanCount())
+}
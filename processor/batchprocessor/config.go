@@ -16,6 +16,8 @@ package batchprocessor // import "go.opentelemetry.io/collector/processor/batchp
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/collector/config"
@@ -35,6 +37,28 @@ type Config struct {
 	// Larger batches are split into smaller units.
 	// Default value is 0, that means no maximum size.
 	SendBatchMaxSize uint32 `mapstructure:"send_batch_max_size,omitempty"`
+
+	// SendBatchSizeBytes is the size of a batch in bytes, measured as OTLP protobuf encoded size, which after
+	// hit, will trigger it to be sent. Default value is 0, that means no byte size trigger.
+	SendBatchSizeBytes uint32 `mapstructure:"send_batch_size_bytes,omitempty"`
+
+	// SendBatchMaxSizeBytes is the maximum size of a batch in bytes, measured as OTLP protobuf encoded size.
+	// It must be larger than SendBatchSizeBytes. Larger batches are split into smaller units.
+	// Default value is 0, that means no maximum size.
+	SendBatchMaxSizeBytes uint32 `mapstructure:"send_batch_max_size_bytes,omitempty"`
+
+	// MetadataKeys is a list of client metadata keys, looked up case-insensitively, the data is batched by.
+	// Every distinct combination of their values is batched separately, and the batches are sent with a
+	// context carrying the client metadata of these keys. Empty means a single batch for all the data.
+	MetadataKeys []string `mapstructure:"metadata_keys"`
+
+	// MetadataCardinalityLimit is the maximum number of distinct combinations of the values of MetadataKeys.
+	// The data of additional combinations is refused.
+	MetadataCardinalityLimit uint32 `mapstructure:"metadata_cardinality_limit"`
+
+	// MetadataIdleTimeout is the time after which the batch of a combination of the values of MetadataKeys that
+	// received no data is removed, no longer counting towards MetadataCardinalityLimit. 0 means never.
+	MetadataIdleTimeout time.Duration `mapstructure:"metadata_idle_timeout"`
 }
 
 var _ config.Processor = (*Config)(nil)
@@ -44,5 +68,22 @@ func (cfg *Config) Validate() error {
 	if cfg.SendBatchMaxSize > 0 && cfg.SendBatchMaxSize < cfg.SendBatchSize {
 		return errors.New("send_batch_max_size must be greater or equal to send_batch_size")
 	}
+	if cfg.SendBatchMaxSizeBytes > 0 && cfg.SendBatchMaxSizeBytes < cfg.SendBatchSizeBytes {
+		return errors.New("send_batch_max_size_bytes must be greater or equal to send_batch_size_bytes")
+	}
+	uniq := map[string]bool{}
+	for _, key := range cfg.MetadataKeys {
+		lower := strings.ToLower(key)
+		if uniq[lower] {
+			return fmt.Errorf("duplicate entry in metadata_keys: %q (case-insensitive)", key)
+		}
+		uniq[lower] = true
+	}
+	if len(cfg.MetadataKeys) > 0 && cfg.MetadataCardinalityLimit == 0 {
+		return errors.New("metadata_cardinality_limit must be positive when metadata_keys is set")
+	}
+	if cfg.MetadataIdleTimeout < 0 {
+		return errors.New("metadata_idle_timeout must not be negative")
+	}
 	return nil
 }
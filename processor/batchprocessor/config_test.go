@@ -53,6 +53,10 @@ func TestLoadConfig(t *testing.T) {
 			SendBatchSize:     sendBatchSize,
 			SendBatchMaxSize:  sendBatchMaxSize,
 			Timeout:           timeout,
+
+			MetadataKeys:             []string{"tenant_id"},
+			MetadataCardinalityLimit: 100,
+			MetadataIdleTimeout:      time.Minute,
 		})
 }
 
@@ -83,3 +87,36 @@ func TestValidateConfig_InvalidBatchSize(t *testing.T) {
 	}
 	assert.Error(t, cfg.Validate())
 }
+
+func TestValidateConfig_MetadataKeys(t *testing.T) {
+	cfg := &Config{
+		ProcessorSettings:        config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "2")),
+		SendBatchSize:            100,
+		MetadataKeys:             []string{"tenant_id", "Tenant_ID"},
+		MetadataCardinalityLimit: 10,
+	}
+	assert.EqualError(t, cfg.Validate(), `duplicate entry in metadata_keys: "Tenant_ID" (case-insensitive)`)
+
+	cfg.MetadataKeys = []string{"tenant_id"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.MetadataCardinalityLimit = 0
+	assert.EqualError(t, cfg.Validate(), "metadata_cardinality_limit must be positive when metadata_keys is set")
+
+	cfg.MetadataCardinalityLimit = 10
+	cfg.MetadataIdleTimeout = -time.Second
+	assert.EqualError(t, cfg.Validate(), "metadata_idle_timeout must not be negative")
+}
+
+func TestValidateConfig_InvalidBatchSizeBytes(t *testing.T) {
+	cfg := &Config{
+		ProcessorSettings:     config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "2")),
+		SendBatchSize:         100,
+		SendBatchSizeBytes:    1000,
+		SendBatchMaxSizeBytes: 100,
+	}
+	assert.EqualError(t, cfg.Validate(), "send_batch_max_size_bytes must be greater or equal to send_batch_size_bytes")
+
+	cfg.SendBatchMaxSizeBytes = 0
+	assert.NoError(t, cfg.Validate())
+}
@@ -31,6 +31,9 @@ const (
 
 	defaultSendBatchSize = uint32(8192)
 	defaultTimeout       = 200 * time.Millisecond
+
+	defaultMetadataCardinalityLimit = uint32(1000)
+	defaultMetadataIdleTimeout      = 5 * time.Minute
 )
 
 // NewFactory returns a new factory for the Batch processor.
@@ -48,6 +51,9 @@ func createDefaultConfig() config.Processor {
 		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
 		SendBatchSize:     defaultSendBatchSize,
 		Timeout:           defaultTimeout,
+
+		MetadataCardinalityLimit: defaultMetadataCardinalityLimit,
+		MetadataIdleTimeout:      defaultMetadataIdleTimeout,
 	}
 }
 
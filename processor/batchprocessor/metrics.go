@@ -24,11 +24,12 @@ import (
 )
 
 var (
-	processorTagKey          = tag.MustNewKey(obsmetrics.ProcessorKey)
-	statBatchSizeTriggerSend = stats.Int64("batch_size_trigger_send", "Number of times the batch was sent due to a size trigger", stats.UnitDimensionless)
-	statTimeoutTriggerSend   = stats.Int64("timeout_trigger_send", "Number of times the batch was sent due to a timeout trigger", stats.UnitDimensionless)
-	statBatchSendSize        = stats.Int64("batch_send_size", "Number of units in the batch", stats.UnitDimensionless)
-	statBatchSendSizeBytes   = stats.Int64("batch_send_size_bytes", "Number of bytes in batch that was sent", stats.UnitBytes)
+	processorTagKey               = tag.MustNewKey(obsmetrics.ProcessorKey)
+	statBatchSizeTriggerSend      = stats.Int64("batch_size_trigger_send", "Number of times the batch was sent due to a size trigger", stats.UnitDimensionless)
+	statBatchSizeBytesTriggerSend = stats.Int64("batch_size_bytes_trigger_send", "Number of times the batch was sent due to a size in bytes trigger", stats.UnitDimensionless)
+	statTimeoutTriggerSend        = stats.Int64("timeout_trigger_send", "Number of times the batch was sent due to a timeout trigger", stats.UnitDimensionless)
+	statBatchSendSize             = stats.Int64("batch_send_size", "Number of units in the batch", stats.UnitDimensionless)
+	statBatchSendSizeBytes        = stats.Int64("batch_send_size_bytes", "Number of bytes in batch that was sent", stats.UnitBytes)
 )
 
 // MetricViews returns the metrics views related to batching
@@ -43,6 +44,14 @@ func MetricViews() []*view.View {
 		Aggregation: view.Sum(),
 	}
 
+	countBatchSizeBytesTriggerSendView := &view.View{
+		Name:        obsreport.BuildProcessorCustomMetricName(typeStr, statBatchSizeBytesTriggerSend.Name()),
+		Measure:     statBatchSizeBytesTriggerSend,
+		Description: statBatchSizeBytesTriggerSend.Description(),
+		TagKeys:     processorTagKeys,
+		Aggregation: view.Sum(),
+	}
+
 	countTimeoutTriggerSendView := &view.View{
 		Name:        obsreport.BuildProcessorCustomMetricName(typeStr, statTimeoutTriggerSend.Name()),
 		Measure:     statTimeoutTriggerSend,
@@ -74,5 +83,6 @@ func MetricViews() []*view.View {
 		countTimeoutTriggerSendView,
 		distributionBatchSendSizeView,
 		distributionBatchSendSizeBytesView,
+		countBatchSizeBytesTriggerSendView,
 	}
 }
@@ -26,6 +26,7 @@ func TestBatchProcessorMetrics(t *testing.T) {
 		"timeout_trigger_send",
 		"batch_send_size",
 		"batch_send_size_bytes",
+		"batch_size_bytes_trigger_send",
 	}
 	views := MetricViews()
 	for i, viewName := range viewNames {
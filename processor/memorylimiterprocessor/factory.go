@@ -82,6 +82,7 @@ func createMetricsProcessor(
 		nextConsumer,
 		ml.processMetrics,
 		processorhelper.WithCapabilities(processorCapabilities),
+		processorhelper.WithStart(ml.start),
 		processorhelper.WithShutdown(ml.shutdown))
 }
 
@@ -100,5 +101,6 @@ func createLogsProcessor(
 		nextConsumer,
 		ml.processLogs,
 		processorhelper.WithCapabilities(processorCapabilities),
+		processorhelper.WithStart(ml.start),
 		processorhelper.WithShutdown(ml.shutdown))
 }
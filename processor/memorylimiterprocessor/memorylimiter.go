@@ -17,103 +17,61 @@ package memorylimiterprocessor // import "go.opentelemetry.io/collector/processo
 import (
 	"context"
 	"errors"
-	"fmt"
-	"runtime"
-	"sync/atomic"
-	"time"
-
-	"go.uber.org/zap"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenthelper"
 	"go.opentelemetry.io/collector/config/configtelemetry"
-	"go.opentelemetry.io/collector/extension/ballastextension"
-	"go.opentelemetry.io/collector/internal/iruntime"
+	"go.opentelemetry.io/collector/internal/memorylimiter"
+	"go.opentelemetry.io/collector/internal/sharedcomponent"
 	"go.opentelemetry.io/collector/model/pdata"
 	"go.opentelemetry.io/collector/obsreport"
 )
 
-const (
-	mibBytes = 1024 * 1024
-)
-
-var (
-	// errForcedDrop will be returned to callers of ConsumeTraceData to indicate
-	// that data is being dropped due to high memory usage.
-	errForcedDrop = errors.New("data dropped due to high memory usage")
-
-	// Construction errors
-
-	errCheckIntervalOutOfRange = errors.New(
-		"checkInterval must be greater than zero")
+// errForcedDrop will be returned to callers of ConsumeTraceData to indicate
+// that data is being dropped due to high memory usage.
+var errForcedDrop = errors.New("data dropped due to high memory usage")
 
-	errLimitOutOfRange = errors.New(
-		"memAllocLimit or memoryLimitPercentage must be greater than zero")
-
-	errMemSpikeLimitOutOfRange = errors.New(
-		"memSpikeLimit must be smaller than memAllocLimit")
-
-	errPercentageLimitOutOfRange = errors.New(
-		"memoryLimitPercentage and memorySpikePercentage must be greater than zero and less than or equal to hundred",
-	)
-)
-
-// make it overridable by tests
-var getMemoryFn = iruntime.TotalMemory
+// This is the map of the memory limiters already created for particular configurations. The processors of all
+// the signals of a configuration share the same memory limiter, so the memory usage is checked by a single
+// goroutine. When the memory limiter is shutdown it is removed from this map so the same configuration can be
+// recreated successfully.
+var limiters = sharedcomponent.NewSharedComponents()
 
 type memoryLimiter struct {
-	usageChecker memUsageChecker
-
-	memCheckWait time.Duration
-	ballastSize  uint64
-
-	// forceDrop is used atomically to indicate when data should be dropped.
-	forceDrop int64
-
-	ticker *time.Ticker
-
-	lastGCDone time.Time
-
-	// The function to read the mem values is set as a reference to help with
-	// testing different values.
-	readMemStatsFn func(m *runtime.MemStats)
-
-	// Fields used for logging.
-	logger                 *zap.Logger
-	configMismatchedLogged bool
+	// shared starts and stops the limiter once for all the signals.
+	shared  *sharedcomponent.SharedComponent
+	limiter *memorylimiter.MemoryLimiter
 
 	obsrep *obsreport.Processor
 }
 
-// Minimum interval between forced GC when in soft limited mode. We don't want to
-// do GCs too frequently since it is a CPU-heavy operation.
-const minGCIntervalWhenSoftLimited = 10 * time.Second
-
 // newMemoryLimiter returns a new memorylimiter processor.
 func newMemoryLimiter(set component.ProcessorCreateSettings, cfg *Config) (*memoryLimiter, error) {
-	if cfg.CheckInterval <= 0 {
-		return nil, errCheckIntervalOutOfRange
-	}
-	if cfg.MemoryLimitMiB == 0 && cfg.MemoryLimitPercentage == 0 {
-		return nil, errLimitOutOfRange
-	}
-
-	logger := set.Logger
-	usageChecker, err := getMemUsageChecker(cfg, logger)
+	var err error
+	shared := limiters.GetOrAdd(cfg, func() component.Component {
+		var limiter *memorylimiter.MemoryLimiter
+		limiter, err = memorylimiter.New(memorylimiter.Settings{
+			CheckInterval:         cfg.CheckInterval,
+			MemoryLimitMiB:        cfg.MemoryLimitMiB,
+			MemorySpikeLimitMiB:   cfg.MemorySpikeLimitMiB,
+			MemoryLimitPercentage: cfg.MemoryLimitPercentage,
+			MemorySpikePercentage: cfg.MemorySpikePercentage,
+			Logger:                set.Logger,
+		})
+		if err != nil {
+			return componenthelper.New()
+		}
+		return limiter
+	})
 	if err != nil {
+		// Remove the failed entry, so the configuration can be fixed and created again.
+		_ = shared.Shutdown(context.Background())
 		return nil, err
 	}
 
-	logger.Info("Memory limiter configured",
-		zap.Uint64("limit_mib", usageChecker.memAllocLimit/mibBytes),
-		zap.Uint64("spike_limit_mib", usageChecker.memSpikeLimit/mibBytes),
-		zap.Duration("check_interval", cfg.CheckInterval))
-
 	ml := &memoryLimiter{
-		usageChecker:   *usageChecker,
-		memCheckWait:   cfg.CheckInterval,
-		ticker:         time.NewTicker(cfg.CheckInterval),
-		readMemStatsFn: runtime.ReadMemStats,
-		logger:         logger,
+		shared:  shared,
+		limiter: shared.Unwrap().(*memorylimiter.MemoryLimiter),
 		obsrep: obsreport.NewProcessor(obsreport.ProcessorSettings{
 			Level:                   configtelemetry.GetMetricsLevelFlagValue(),
 			ProcessorID:             cfg.ID(),
@@ -124,44 +82,17 @@ func newMemoryLimiter(set component.ProcessorCreateSettings, cfg *Config) (*memo
 	return ml, nil
 }
 
-func getMemUsageChecker(cfg *Config, logger *zap.Logger) (*memUsageChecker, error) {
-	memAllocLimit := uint64(cfg.MemoryLimitMiB) * mibBytes
-	memSpikeLimit := uint64(cfg.MemorySpikeLimitMiB) * mibBytes
-	if cfg.MemoryLimitMiB != 0 {
-		return newFixedMemUsageChecker(memAllocLimit, memSpikeLimit)
-	}
-	totalMemory, err := getMemoryFn()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get total memory, use fixed memory settings (limit_mib): %w", err)
-	}
-	logger.Info("Using percentage memory limiter",
-		zap.Uint64("total_memory_mib", totalMemory/mibBytes),
-		zap.Uint32("limit_percentage", cfg.MemoryLimitPercentage),
-		zap.Uint32("spike_limit_percentage", cfg.MemorySpikePercentage))
-	return newPercentageMemUsageChecker(totalMemory, uint64(cfg.MemoryLimitPercentage), uint64(cfg.MemorySpikePercentage))
-}
-
-func (ml *memoryLimiter) start(_ context.Context, host component.Host) error {
-	extensions := host.GetExtensions()
-	for _, extension := range extensions {
-		if ext, ok := extension.(*ballastextension.MemoryBallast); ok {
-			ml.ballastSize = ext.GetBallastSize()
-			break
-		}
-	}
-
-	ml.startMonitoring()
-	return nil
+func (ml *memoryLimiter) start(ctx context.Context, host component.Host) error {
+	return ml.shared.Start(ctx, host)
 }
 
-func (ml *memoryLimiter) shutdown(context.Context) error {
-	ml.ticker.Stop()
-	return nil
+func (ml *memoryLimiter) shutdown(ctx context.Context) error {
+	return ml.shared.Shutdown(ctx)
 }
 
 func (ml *memoryLimiter) processTraces(ctx context.Context, td pdata.Traces) (pdata.Traces, error) {
 	numSpans := td.SpanCount()
-	if ml.forcingDrop() {
+	if ml.limiter.MustRefuse() {
 		// TODO: actually to be 100% sure that this is "refused" and not "dropped"
 		// 	it is necessary to check the pipeline to see if this is directly connected
 		// 	to a receiver (ie.: a receiver is on the call stack). For now it
@@ -180,7 +111,7 @@ func (ml *memoryLimiter) processTraces(ctx context.Context, td pdata.Traces) (pd
 
 func (ml *memoryLimiter) processMetrics(ctx context.Context, md pdata.Metrics) (pdata.Metrics, error) {
 	numDataPoints := md.DataPointCount()
-	if ml.forcingDrop() {
+	if ml.limiter.MustRefuse() {
 		// TODO: actually to be 100% sure that this is "refused" and not "dropped"
 		// 	it is necessary to check the pipeline to see if this is directly connected
 		// 	to a receiver (ie.: a receiver is on the call stack). For now it
@@ -198,7 +129,7 @@ func (ml *memoryLimiter) processMetrics(ctx context.Context, md pdata.Metrics) (
 
 func (ml *memoryLimiter) processLogs(ctx context.Context, ld pdata.Logs) (pdata.Logs, error) {
 	numRecords := ld.LogRecordCount()
-	if ml.forcingDrop() {
+	if ml.limiter.MustRefuse() {
 		// TODO: actually to be 100% sure that this is "refused" and not "dropped"
 		// 	it is necessary to check the pipeline to see if this is directly connected
 		// 	to a receiver (ie.: a receiver is on the call stack). For now it
@@ -214,127 +145,3 @@ func (ml *memoryLimiter) processLogs(ctx context.Context, ld pdata.Logs) (pdata.
 	ml.obsrep.LogsAccepted(ctx, numRecords)
 	return ld, nil
 }
-
-func (ml *memoryLimiter) readMemStats() *runtime.MemStats {
-	ms := &runtime.MemStats{}
-	ml.readMemStatsFn(ms)
-	// If proper configured ms.Alloc should be at least ml.ballastSize but since
-	// a misconfiguration is possible check for that here.
-	if ms.Alloc >= ml.ballastSize {
-		ms.Alloc -= ml.ballastSize
-	} else if !ml.configMismatchedLogged {
-		// This indicates misconfiguration. Log it once.
-		ml.configMismatchedLogged = true
-		ml.logger.Warn(ballastSizeMibKey + " in ballast extension is likely incorrectly configured.")
-	}
-
-	return ms
-}
-
-// startMonitoring starts a ticker'd goroutine that will check memory usage
-// every checkInterval period.
-func (ml *memoryLimiter) startMonitoring() {
-	go func() {
-		for range ml.ticker.C {
-			ml.checkMemLimits()
-		}
-	}()
-}
-
-// forcingDrop indicates when memory resources need to be released.
-func (ml *memoryLimiter) forcingDrop() bool {
-	return atomic.LoadInt64(&ml.forceDrop) != 0
-}
-
-func (ml *memoryLimiter) setForcingDrop(b bool) {
-	var i int64
-	if b {
-		i = 1
-	}
-	atomic.StoreInt64(&ml.forceDrop, i)
-}
-
-func memstatToZapField(ms *runtime.MemStats) zap.Field {
-	return zap.Uint64("cur_mem_mib", ms.Alloc/mibBytes)
-}
-
-func (ml *memoryLimiter) doGCandReadMemStats() *runtime.MemStats {
-	runtime.GC()
-	ml.lastGCDone = time.Now()
-	ms := ml.readMemStats()
-	ml.logger.Info("Memory usage after GC.", memstatToZapField(ms))
-	return ms
-}
-
-func (ml *memoryLimiter) checkMemLimits() {
-	ms := ml.readMemStats()
-
-	ml.logger.Debug("Currently used memory.", memstatToZapField(ms))
-
-	if ml.usageChecker.aboveHardLimit(ms) {
-		ml.logger.Warn("Memory usage is above hard limit. Forcing a GC.", memstatToZapField(ms))
-		ms = ml.doGCandReadMemStats()
-	}
-
-	// Remember current dropping state.
-	wasForcingDrop := ml.forcingDrop()
-
-	// Check if the memory usage is above the soft limit.
-	mustForceDrop := ml.usageChecker.aboveSoftLimit(ms)
-
-	if wasForcingDrop && !mustForceDrop {
-		// Was previously dropping but enough memory is available now, no need to limit.
-		ml.logger.Info("Memory usage back within limits. Resuming normal operation.", memstatToZapField(ms))
-	}
-
-	if !wasForcingDrop && mustForceDrop {
-		// We are above soft limit, do a GC if it wasn't done recently and see if
-		// it brings memory usage below the soft limit.
-		if time.Since(ml.lastGCDone) > minGCIntervalWhenSoftLimited {
-			ml.logger.Info("Memory usage is above soft limit. Forcing a GC.", memstatToZapField(ms))
-			ms = ml.doGCandReadMemStats()
-			// Check the limit again to see if GC helped.
-			mustForceDrop = ml.usageChecker.aboveSoftLimit(ms)
-		}
-
-		if mustForceDrop {
-			ml.logger.Warn("Memory usage is above soft limit. Dropping data.", memstatToZapField(ms))
-		}
-	}
-
-	ml.setForcingDrop(mustForceDrop)
-}
-
-type memUsageChecker struct {
-	memAllocLimit uint64
-	memSpikeLimit uint64
-}
-
-func (d memUsageChecker) aboveSoftLimit(ms *runtime.MemStats) bool {
-	return ms.Alloc >= d.memAllocLimit-d.memSpikeLimit
-}
-
-func (d memUsageChecker) aboveHardLimit(ms *runtime.MemStats) bool {
-	return ms.Alloc >= d.memAllocLimit
-}
-
-func newFixedMemUsageChecker(memAllocLimit, memSpikeLimit uint64) (*memUsageChecker, error) {
-	if memSpikeLimit >= memAllocLimit {
-		return nil, errMemSpikeLimitOutOfRange
-	}
-	if memSpikeLimit == 0 {
-		// If spike limit is unspecified use 20% of mem limit.
-		memSpikeLimit = memAllocLimit / 5
-	}
-	return &memUsageChecker{
-		memAllocLimit: memAllocLimit,
-		memSpikeLimit: memSpikeLimit,
-	}, nil
-}
-
-func newPercentageMemUsageChecker(totalMemory uint64, percentageLimit, percentageSpike uint64) (*memUsageChecker, error) {
-	if percentageLimit > 100 || percentageLimit <= 0 || percentageSpike > 100 || percentageSpike <= 0 {
-		return nil, errPercentageLimitOutOfRange
-	}
-	return newFixedMemUsageChecker(percentageLimit*totalMemory/100, percentageSpike*totalMemory/100)
-}
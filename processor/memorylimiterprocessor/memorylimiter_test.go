@@ -30,7 +30,7 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/extension/ballastextension"
-	"go.opentelemetry.io/collector/internal/iruntime"
+	"go.opentelemetry.io/collector/internal/memorylimiter"
 	"go.opentelemetry.io/collector/model/pdata"
 	"go.opentelemetry.io/collector/obsreport"
 	"go.opentelemetry.io/collector/processor/processorhelper"
@@ -54,7 +54,7 @@ func TestNew(t *testing.T) {
 			args: args{
 				nextConsumer: sink,
 			},
-			wantErr: errCheckIntervalOutOfRange,
+			wantErr: memorylimiter.ErrCheckIntervalOutOfRange,
 		},
 		{
 			name: "zero_memAllocLimit",
@@ -62,7 +62,7 @@ func TestNew(t *testing.T) {
 				nextConsumer:  sink,
 				checkInterval: 100 * time.Millisecond,
 			},
-			wantErr: errLimitOutOfRange,
+			wantErr: memorylimiter.ErrLimitOutOfRange,
 		},
 		{
 			name: "memSpikeLimit_gt_memAllocLimit",
@@ -72,7 +72,7 @@ func TestNew(t *testing.T) {
 				memoryLimitMiB:      1,
 				memorySpikeLimitMiB: 2,
 			},
-			wantErr: errMemSpikeLimitOutOfRange,
+			wantErr: memorylimiter.ErrMemSpikeLimitOutOfRange,
 		},
 		{
 			name: "success",
@@ -101,24 +101,58 @@ func TestNew(t *testing.T) {
 	}
 }
 
-// TestMetricsMemoryPressureResponse manipulates results from querying memory and
-// check expected side effects.
-func TestMetricsMemoryPressureResponse(t *testing.T) {
-	var currentMemAlloc uint64
-	ml := &memoryLimiter{
-		usageChecker: memUsageChecker{
-			memAllocLimit: 1024,
-		},
-		readMemStatsFn: func(ms *runtime.MemStats) {
-			ms.Alloc = currentMemAlloc
+func TestSharedLimiter(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.CheckInterval = time.Second
+	cfg.MemoryLimitMiB = 1024
+
+	traces, err := newMemoryLimiter(componenttest.NewNopProcessorCreateSettings(), cfg)
+	require.NoError(t, err)
+	metrics, err := newMemoryLimiter(componenttest.NewNopProcessorCreateSettings(), cfg)
+	require.NoError(t, err)
+	assert.Same(t, traces.limiter, metrics.limiter)
+
+	// The limiter is started and stopped once, whichever signal does it first.
+	require.NoError(t, metrics.start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, traces.start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, traces.shutdown(context.Background()))
+	require.NoError(t, metrics.shutdown(context.Background()))
+
+	// Once shutdown, the same configuration creates a new limiter.
+	logs, err := newMemoryLimiter(componenttest.NewNopProcessorCreateSettings(), cfg)
+	require.NoError(t, err)
+	assert.NotSame(t, traces.limiter, logs.limiter)
+	assert.NoError(t, logs.shutdown(context.Background()))
+}
+
+const mibBytes = 1024 * 1024
+
+// newTestMemoryLimiter returns a memory limiter with a hard limit of 1 MiB, reading currentMemAlloc as the
+// memory usage.
+func newTestMemoryLimiter(t *testing.T, currentMemAlloc *uint64) *memoryLimiter {
+	limiter, err := memorylimiter.New(memorylimiter.Settings{
+		CheckInterval:  time.Second,
+		MemoryLimitMiB: 1,
+		Logger:         zap.NewNop(),
+		ReadMemStatsFn: func(ms *runtime.MemStats) {
+			ms.Alloc = *currentMemAlloc
 		},
+	})
+	require.NoError(t, err)
+	return &memoryLimiter{
+		limiter: limiter,
 		obsrep: obsreport.NewProcessor(obsreport.ProcessorSettings{
 			Level:       configtelemetry.LevelNone,
 			ProcessorID: config.NewComponentID(typeStr),
 		}),
-
-		logger: zap.NewNop(),
 	}
+}
+
+// TestMetricsMemoryPressureResponse manipulates results from querying memory and
+// check expected side effects.
+func TestMetricsMemoryPressureResponse(t *testing.T) {
+	var currentMemAlloc uint64
+	ml := newTestMemoryLimiter(t, &currentMemAlloc)
 	mp, err := processorhelper.NewMetricsProcessor(
 		&Config{
 			ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
@@ -133,63 +167,21 @@ func TestMetricsMemoryPressureResponse(t *testing.T) {
 	md := pdata.NewMetrics()
 
 	// Below memAllocLimit.
-	currentMemAlloc = 800
-	ml.checkMemLimits()
+	currentMemAlloc = mibBytes / 2
+	ml.limiter.CheckMemLimits()
 	assert.NoError(t, mp.ConsumeMetrics(ctx, md))
 
 	// Above memAllocLimit.
-	currentMemAlloc = 1800
-	ml.checkMemLimits()
+	currentMemAlloc = 2 * mibBytes
+	ml.limiter.CheckMemLimits()
 	assert.Equal(t, errForcedDrop, mp.ConsumeMetrics(ctx, md))
-
-	// Check ballast effect
-	ml.ballastSize = 1000
-
-	// Below memAllocLimit accounting for ballast.
-	currentMemAlloc = 800 + ml.ballastSize
-	ml.checkMemLimits()
-	assert.NoError(t, mp.ConsumeMetrics(ctx, md))
-
-	// Above memAllocLimit even accountiing for ballast.
-	currentMemAlloc = 1800 + ml.ballastSize
-	ml.checkMemLimits()
-	assert.Equal(t, errForcedDrop, mp.ConsumeMetrics(ctx, md))
-
-	// Restore ballast to default.
-	ml.ballastSize = 0
-
-	// Check spike limit
-	ml.usageChecker.memSpikeLimit = 512
-
-	// Below memSpikeLimit.
-	currentMemAlloc = 500
-	ml.checkMemLimits()
-	assert.NoError(t, mp.ConsumeMetrics(ctx, md))
-
-	// Above memSpikeLimit.
-	currentMemAlloc = 550
-	ml.checkMemLimits()
-	assert.Equal(t, errForcedDrop, mp.ConsumeMetrics(ctx, md))
-
 }
 
 // TestTraceMemoryPressureResponse manipulates results from querying memory and
 // check expected side effects.
 func TestTraceMemoryPressureResponse(t *testing.T) {
 	var currentMemAlloc uint64
-	ml := &memoryLimiter{
-		usageChecker: memUsageChecker{
-			memAllocLimit: 1024,
-		},
-		readMemStatsFn: func(ms *runtime.MemStats) {
-			ms.Alloc = currentMemAlloc
-		},
-		obsrep: obsreport.NewProcessor(obsreport.ProcessorSettings{
-			Level:       configtelemetry.LevelNone,
-			ProcessorID: config.NewComponentID(typeStr),
-		}),
-		logger: zap.NewNop(),
-	}
+	ml := newTestMemoryLimiter(t, &currentMemAlloc)
 	tp, err := processorhelper.NewTracesProcessor(
 		&Config{
 			ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
@@ -204,63 +196,21 @@ func TestTraceMemoryPressureResponse(t *testing.T) {
 	td := pdata.NewTraces()
 
 	// Below memAllocLimit.
-	currentMemAlloc = 800
-	ml.checkMemLimits()
+	currentMemAlloc = mibBytes / 2
+	ml.limiter.CheckMemLimits()
 	assert.NoError(t, tp.ConsumeTraces(ctx, td))
 
 	// Above memAllocLimit.
-	currentMemAlloc = 1800
-	ml.checkMemLimits()
-	assert.Equal(t, errForcedDrop, tp.ConsumeTraces(ctx, td))
-
-	// Check ballast effect
-	ml.ballastSize = 1000
-
-	// Below memAllocLimit accounting for ballast.
-	currentMemAlloc = 800 + ml.ballastSize
-	ml.checkMemLimits()
-	assert.NoError(t, tp.ConsumeTraces(ctx, td))
-
-	// Above memAllocLimit even accountiing for ballast.
-	currentMemAlloc = 1800 + ml.ballastSize
-	ml.checkMemLimits()
-	assert.Equal(t, errForcedDrop, tp.ConsumeTraces(ctx, td))
-
-	// Restore ballast to default.
-	ml.ballastSize = 0
-
-	// Check spike limit
-	ml.usageChecker.memSpikeLimit = 512
-
-	// Below memSpikeLimit.
-	currentMemAlloc = 500
-	ml.checkMemLimits()
-	assert.NoError(t, tp.ConsumeTraces(ctx, td))
-
-	// Above memSpikeLimit.
-	currentMemAlloc = 550
-	ml.checkMemLimits()
+	currentMemAlloc = 2 * mibBytes
+	ml.limiter.CheckMemLimits()
 	assert.Equal(t, errForcedDrop, tp.ConsumeTraces(ctx, td))
-
 }
 
 // TestLogMemoryPressureResponse manipulates results from querying memory and
 // check expected side effects.
 func TestLogMemoryPressureResponse(t *testing.T) {
 	var currentMemAlloc uint64
-	ml := &memoryLimiter{
-		usageChecker: memUsageChecker{
-			memAllocLimit: 1024,
-		},
-		readMemStatsFn: func(ms *runtime.MemStats) {
-			ms.Alloc = currentMemAlloc
-		},
-		obsrep: obsreport.NewProcessor(obsreport.ProcessorSettings{
-			Level:       configtelemetry.LevelNone,
-			ProcessorID: config.NewComponentID(typeStr),
-		}),
-		logger: zap.NewNop(),
-	}
+	ml := newTestMemoryLimiter(t, &currentMemAlloc)
 	lp, err := processorhelper.NewLogsProcessor(
 		&Config{
 			ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
@@ -275,143 +225,16 @@ func TestLogMemoryPressureResponse(t *testing.T) {
 	ld := pdata.NewLogs()
 
 	// Below memAllocLimit.
-	currentMemAlloc = 800
-	ml.checkMemLimits()
+	currentMemAlloc = mibBytes / 2
+	ml.limiter.CheckMemLimits()
 	assert.NoError(t, lp.ConsumeLogs(ctx, ld))
 
 	// Above memAllocLimit.
-	currentMemAlloc = 1800
-	ml.checkMemLimits()
-	assert.Equal(t, errForcedDrop, lp.ConsumeLogs(ctx, ld))
-
-	// Check ballast effect
-	ml.ballastSize = 1000
-
-	// Below memAllocLimit accounting for ballast.
-	currentMemAlloc = 800 + ml.ballastSize
-	ml.checkMemLimits()
-	assert.NoError(t, lp.ConsumeLogs(ctx, ld))
-
-	// Above memAllocLimit even accountiing for ballast.
-	currentMemAlloc = 1800 + ml.ballastSize
-	ml.checkMemLimits()
-	assert.Equal(t, errForcedDrop, lp.ConsumeLogs(ctx, ld))
-
-	// Restore ballast to default.
-	ml.ballastSize = 0
-
-	// Check spike limit
-	ml.usageChecker.memSpikeLimit = 512
-
-	// Below memSpikeLimit.
-	currentMemAlloc = 500
-	ml.checkMemLimits()
-	assert.NoError(t, lp.ConsumeLogs(ctx, ld))
-
-	// Above memSpikeLimit.
-	currentMemAlloc = 550
-	ml.checkMemLimits()
+	currentMemAlloc = 2 * mibBytes
+	ml.limiter.CheckMemLimits()
 	assert.Equal(t, errForcedDrop, lp.ConsumeLogs(ctx, ld))
 }
 
-func TestGetDecision(t *testing.T) {
-	t.Run("fixed_limit", func(t *testing.T) {
-		d, err := getMemUsageChecker(&Config{MemoryLimitMiB: 100, MemorySpikeLimitMiB: 20}, zap.NewNop())
-		require.NoError(t, err)
-		assert.Equal(t, &memUsageChecker{
-			memAllocLimit: 100 * mibBytes,
-			memSpikeLimit: 20 * mibBytes,
-		}, d)
-	})
-	t.Run("fixed_limit_error", func(t *testing.T) {
-		d, err := getMemUsageChecker(&Config{MemoryLimitMiB: 20, MemorySpikeLimitMiB: 100}, zap.NewNop())
-		require.Error(t, err)
-		assert.Nil(t, d)
-	})
-
-	t.Cleanup(func() {
-		getMemoryFn = iruntime.TotalMemory
-	})
-	getMemoryFn = func() (uint64, error) {
-		return 100 * mibBytes, nil
-	}
-	t.Run("percentage_limit", func(t *testing.T) {
-		d, err := getMemUsageChecker(&Config{MemoryLimitPercentage: 50, MemorySpikePercentage: 10}, zap.NewNop())
-		require.NoError(t, err)
-		assert.Equal(t, &memUsageChecker{
-			memAllocLimit: 50 * mibBytes,
-			memSpikeLimit: 10 * mibBytes,
-		}, d)
-	})
-	t.Run("percentage_limit_error", func(t *testing.T) {
-		d, err := getMemUsageChecker(&Config{MemoryLimitPercentage: 101, MemorySpikePercentage: 10}, zap.NewNop())
-		require.Error(t, err)
-		assert.Nil(t, d)
-		d, err = getMemUsageChecker(&Config{MemoryLimitPercentage: 99, MemorySpikePercentage: 101}, zap.NewNop())
-		require.Error(t, err)
-		assert.Nil(t, d)
-	})
-}
-
-func TestDropDecision(t *testing.T) {
-	decison1000Limit30Spike30, err := newPercentageMemUsageChecker(1000, 60, 30)
-	require.NoError(t, err)
-	decison1000Limit60Spike50, err := newPercentageMemUsageChecker(1000, 60, 50)
-	require.NoError(t, err)
-	decison1000Limit40Spike20, err := newPercentageMemUsageChecker(1000, 40, 20)
-	require.NoError(t, err)
-	decison1000Limit40Spike60, err := newPercentageMemUsageChecker(1000, 40, 60)
-	require.Error(t, err)
-	assert.Nil(t, decison1000Limit40Spike60)
-
-	tests := []struct {
-		name         string
-		usageChecker memUsageChecker
-		ms           *runtime.MemStats
-		shouldDrop   bool
-	}{
-		{
-			name:         "should drop over limit",
-			usageChecker: *decison1000Limit30Spike30,
-			ms:           &runtime.MemStats{Alloc: 600},
-			shouldDrop:   true,
-		},
-		{
-			name:         "should not drop",
-			usageChecker: *decison1000Limit30Spike30,
-			ms:           &runtime.MemStats{Alloc: 100},
-			shouldDrop:   false,
-		},
-		{
-			name: "should not drop spike, fixed usageChecker",
-			usageChecker: memUsageChecker{
-				memAllocLimit: 600,
-				memSpikeLimit: 500,
-			},
-			ms:         &runtime.MemStats{Alloc: 300},
-			shouldDrop: true,
-		},
-		{
-			name:         "should drop, spike, percentage usageChecker",
-			usageChecker: *decison1000Limit60Spike50,
-			ms:           &runtime.MemStats{Alloc: 300},
-			shouldDrop:   true,
-		},
-		{
-			name:         "should drop, spike, percentage usageChecker",
-			usageChecker: *decison1000Limit40Spike20,
-			ms:           &runtime.MemStats{Alloc: 250},
-			shouldDrop:   true,
-		},
-	}
-	for _, test := range tests {
-		t.Run(test.name, func(t *testing.T) {
-			shouldDrop := test.usageChecker.aboveSoftLimit(test.ms)
-			assert.Equal(t, test.shouldDrop, shouldDrop)
-		})
-	}
-}
-
 func TestBallastSizeMiB(t *testing.T) {
 	ctx := context.Background()
 	ballastExtFactory := ballastextension.NewFactory()
@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filereceiver // import "go.opentelemetry.io/collector/receiver/filereceiver"
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	formatJSON  = "json"
+	formatProto = "proto"
+
+	compressionGzip = "gzip"
+	compressionZstd = "zstd"
+)
+
+// Config defines configuration for file receiver.
+type Config struct {
+	config.ReceiverSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Include is the list of glob patterns of the files to read. The matching files are read once, in the
+	// lexical order of their paths.
+	Include []string `mapstructure:"include"`
+
+	// Format is the encoding of the records, "json" (OTLP JSON, one record per line) or "proto"
+	// (OTLP protobuf, every record prefixed by its size as a 4 bytes big-endian integer).
+	Format string `mapstructure:"format"`
+
+	// Compression of the files, "gzip" or "zstd". Empty means no compression.
+	Compression string `mapstructure:"compression"`
+
+	// ReplaySpeed paces the replay using the timestamps of the records: 1 replays the records with their original
+	// pace, 10 replays them 10 times faster. 0 means the records are replayed as fast as possible.
+	ReplaySpeed float64 `mapstructure:"replay_speed"`
+
+	// StorageID is the ID of the storage extension used to checkpoint the offset of the last replayed record of
+	// every file, so a restarted collector resumes where it left off. If not set, the files are read from the start.
+	StorageID *config.ComponentID `mapstructure:"storage"`
+}
+
+var _ config.Receiver = (*Config)(nil)
+
+// Validate checks the receiver configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Include) == 0 {
+		return errors.New("include must contain at least one pattern")
+	}
+	for _, pattern := range cfg.Include {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+		}
+	}
+	switch cfg.Format {
+	case formatJSON, formatProto:
+	default:
+		return fmt.Errorf("format must be %q or %q, got %q", formatJSON, formatProto, cfg.Format)
+	}
+	switch cfg.Compression {
+	case "", compressionGzip, compressionZstd:
+	default:
+		return fmt.Errorf("compression must be empty, %q or %q, got %q", compressionGzip, compressionZstd, cfg.Compression)
+	}
+	if cfg.ReplaySpeed < 0 {
+		return errors.New("replay_speed must not be negative")
+	}
+	return nil
+}
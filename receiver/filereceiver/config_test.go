@@ -0,0 +1,81 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filereceiver
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := configtest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	r0 := cfg.Receivers[config.NewComponentID(typeStr)]
+	assert.Equal(t, r0,
+		&Config{
+			ReceiverSettings: config.NewReceiverSettings(config.NewComponentID(typeStr)),
+			Include:          []string{"/var/lib/otelcol/*.json"},
+			Format:           formatJSON,
+		})
+
+	storageID := config.NewComponentID("file_storage")
+	r1 := cfg.Receivers[config.NewComponentIDWithName(typeStr, "2")]
+	assert.Equal(t, r1,
+		&Config{
+			ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "2")),
+			Include:          []string{"/var/lib/otelcol/traces-*.pb.gz", "/var/lib/otelcol/traces.pb.gz"},
+			Format:           formatProto,
+			Compression:      compressionGzip,
+			ReplaySpeed:      10,
+			StorageID:        &storageID,
+		})
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{name: "missing include", modify: func(cfg *Config) { cfg.Include = nil }},
+		{name: "invalid include", modify: func(cfg *Config) { cfg.Include = []string{"["} }},
+		{name: "invalid format", modify: func(cfg *Config) { cfg.Format = "text" }},
+		{name: "invalid compression", modify: func(cfg *Config) { cfg.Compression = "lz4" }},
+		{name: "negative replay_speed", modify: func(cfg *Config) { cfg.ReplaySpeed = -1 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Include = []string{"*.json"}
+			assert.NoError(t, cfg.Validate())
+			tt.modify(cfg)
+			assert.Error(t, cfg.Validate())
+		})
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filereceiver // import "go.opentelemetry.io/collector/receiver/filereceiver"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/internal/sharedcomponent"
+	"go.opentelemetry.io/collector/receiver/receiverhelper"
+)
+
+const (
+	typeStr = "file"
+)
+
+// NewFactory creates a new file receiver factory.
+func NewFactory() component.ReceiverFactory {
+	return receiverhelper.NewFactory(
+		typeStr,
+		createDefaultConfig,
+		receiverhelper.WithTraces(createTracesReceiver),
+		receiverhelper.WithMetrics(createMetricsReceiver),
+		receiverhelper.WithLogs(createLogsReceiver))
+}
+
+// createDefaultConfig creates the default configuration for receiver.
+func createDefaultConfig() config.Receiver {
+	return &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentID(typeStr)),
+		Format:           formatJSON,
+	}
+}
+
+// createTracesReceiver creates a trace receiver based on provided config.
+func createTracesReceiver(
+	_ context.Context,
+	set component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	nextConsumer consumer.Traces,
+) (component.TracesReceiver, error) {
+	r := receivers.GetOrAdd(cfg, func() component.Component {
+		return newFileReceiver(cfg.(*Config), set)
+	})
+
+	if err := r.Unwrap().(*fileReceiver).registerTracesConsumer(nextConsumer); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// createMetricsReceiver creates a metrics receiver based on provided config.
+func createMetricsReceiver(
+	_ context.Context,
+	set component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	nextConsumer consumer.Metrics,
+) (component.MetricsReceiver, error) {
+	r := receivers.GetOrAdd(cfg, func() component.Component {
+		return newFileReceiver(cfg.(*Config), set)
+	})
+
+	if err := r.Unwrap().(*fileReceiver).registerMetricsConsumer(nextConsumer); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// createLogsReceiver creates a log receiver based on provided config.
+func createLogsReceiver(
+	_ context.Context,
+	set component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	nextConsumer consumer.Logs,
+) (component.LogsReceiver, error) {
+	r := receivers.GetOrAdd(cfg, func() component.Component {
+		return newFileReceiver(cfg.(*Config), set)
+	})
+
+	if err := r.Unwrap().(*fileReceiver).registerLogsConsumer(nextConsumer); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// This is the map of already created file receivers for particular configurations.
+// We maintain this map because the Factory is asked trace, metric and log receivers separately
+// but they must not create separate objects, they must use one fileReceiver object per configuration
+// so every file is read once and its records are dispatched to the pipeline of their data type.
+var receivers = sharedcomponent.NewSharedComponents()
@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filereceiver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.opentelemetry.io/collector/component/componenterror"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configtest.CheckConfigStruct(cfg))
+}
+
+func TestCreateReceivers(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	set := componenttest.NewNopReceiverCreateSettings()
+
+	tr, err := factory.CreateTracesReceiver(context.Background(), set, cfg, consumertest.NewNop())
+	assert.NoError(t, err)
+	mr, err := factory.CreateMetricsReceiver(context.Background(), set, cfg, consumertest.NewNop())
+	assert.NoError(t, err)
+	lr, err := factory.CreateLogsReceiver(context.Background(), set, cfg, consumertest.NewNop())
+	assert.NoError(t, err)
+	// All the data types share the same receiver.
+	assert.Same(t, tr, mr)
+	assert.Same(t, tr, lr)
+	assert.NoError(t, tr.Shutdown(context.Background()))
+
+	_, err = factory.CreateTracesReceiver(context.Background(), set, cfg, nil)
+	assert.ErrorIs(t, err, componenterror.ErrNilNextConsumer)
+}
@@ -0,0 +1,433 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filereceiver // import "go.opentelemetry.io/collector/receiver/filereceiver"
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenterror"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/model/otlp"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/obsreport"
+)
+
+const (
+	transport = "file"
+
+	// retryInitialInterval and retryMaxInterval bound the delay between the attempts to replay a record
+	// the pipeline failed to accept with a retryable error.
+	retryInitialInterval = 500 * time.Millisecond
+	retryMaxInterval     = 30 * time.Second
+)
+
+var errProtoMultipleSignals = errors.New("the proto format does not identify the data type of the records, the receiver can only be used in pipelines of a single data type")
+
+// fileReceiver replays the records of the files matching the configured patterns to the pipelines of their
+// data type.
+type fileReceiver struct {
+	cfg      *Config
+	settings component.ReceiverCreateSettings
+	obsrecv  *obsreport.Receiver
+
+	nextTraces  consumer.Traces
+	nextMetrics consumer.Metrics
+	nextLogs    consumer.Logs
+
+	tracesUnmarshaler  pdata.TracesUnmarshaler
+	metricsUnmarshaler pdata.MetricsUnmarshaler
+	logsUnmarshaler    pdata.LogsUnmarshaler
+
+	client   storage.Client
+	cancel   context.CancelFunc
+	replayWG sync.WaitGroup
+}
+
+func newFileReceiver(cfg *Config, settings component.ReceiverCreateSettings) *fileReceiver {
+	r := &fileReceiver{
+		cfg:      cfg,
+		settings: settings,
+		obsrecv: obsreport.NewReceiver(obsreport.ReceiverSettings{
+			ReceiverID:             cfg.ID(),
+			Transport:              transport,
+			ReceiverCreateSettings: settings,
+		}),
+	}
+	if cfg.Format == formatProto {
+		r.tracesUnmarshaler = otlp.NewProtobufTracesUnmarshaler()
+		r.metricsUnmarshaler = otlp.NewProtobufMetricsUnmarshaler()
+		r.logsUnmarshaler = otlp.NewProtobufLogsUnmarshaler()
+	} else {
+		r.tracesUnmarshaler = otlp.NewJSONTracesUnmarshaler()
+		r.metricsUnmarshaler = otlp.NewJSONMetricsUnmarshaler()
+		r.logsUnmarshaler = otlp.NewJSONLogsUnmarshaler()
+	}
+	return r
+}
+
+func (r *fileReceiver) registerTracesConsumer(tc consumer.Traces) error {
+	if tc == nil {
+		return componenterror.ErrNilNextConsumer
+	}
+	r.nextTraces = tc
+	return nil
+}
+
+func (r *fileReceiver) registerMetricsConsumer(mc consumer.Metrics) error {
+	if mc == nil {
+		return componenterror.ErrNilNextConsumer
+	}
+	r.nextMetrics = mc
+	return nil
+}
+
+func (r *fileReceiver) registerLogsConsumer(lc consumer.Logs) error {
+	if lc == nil {
+		return componenterror.ErrNilNextConsumer
+	}
+	r.nextLogs = lc
+	return nil
+}
+
+// Start starts replaying the files in the background.
+func (r *fileReceiver) Start(ctx context.Context, host component.Host) error {
+	if r.cfg.Format == formatProto && r.numConsumers() > 1 {
+		return errProtoMultipleSignals
+	}
+	if r.cfg.StorageID != nil {
+		ext, ok := host.GetExtensions()[*r.cfg.StorageID]
+		if !ok {
+			return fmt.Errorf("storage extension %q not found", r.cfg.StorageID.String())
+		}
+		storageExt, ok := ext.(storage.Extension)
+		if !ok {
+			return fmt.Errorf("extension %q is not a storage extension", r.cfg.StorageID.String())
+		}
+		client, err := storageExt.GetClient(ctx, component.KindReceiver, r.cfg.ID(), "")
+		if err != nil {
+			return err
+		}
+		r.client = client
+	}
+
+	replayCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.replayWG.Add(1)
+	go func() {
+		defer r.replayWG.Done()
+		r.replay(replayCtx)
+	}()
+	return nil
+}
+
+// Shutdown stops replaying the files.
+func (r *fileReceiver) Shutdown(ctx context.Context) error {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.replayWG.Wait()
+	if r.client != nil {
+		return r.client.Close(ctx)
+	}
+	return nil
+}
+
+func (r *fileReceiver) numConsumers() int {
+	num := 0
+	for _, registered := range []bool{r.nextTraces != nil, r.nextMetrics != nil, r.nextLogs != nil} {
+		if registered {
+			num++
+		}
+	}
+	return num
+}
+
+// matchingFiles returns the paths of the files matching the include patterns, in lexical order.
+func (r *fileReceiver) matchingFiles() []string {
+	seen := map[string]bool{}
+	var paths []string
+	for _, pattern := range r.cfg.Include {
+		// The patterns are validated with the configuration.
+		matches, _ := filepath.Glob(pattern)
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				paths = append(paths, match)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (r *fileReceiver) replay(ctx context.Context) {
+	p := &pacer{speed: r.cfg.ReplaySpeed}
+	for _, path := range r.matchingFiles() {
+		if err := r.replayFile(ctx, path, p); err != nil {
+			r.settings.Logger.Error("Failed to replay file", zap.String("path", path), zap.Error(err))
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// replayFile replays the records of the file, starting after the last checkpointed record.
+func (r *fileReceiver) replayFile(ctx context.Context, path string, p *pacer) error {
+	offset, err := r.loadCheckpoint(ctx, path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	rr, err := newRecordReader(file, r.cfg.Format, r.cfg.Compression)
+	if err != nil {
+		return err
+	}
+	defer rr.close()
+	if err = rr.skip(offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			r.settings.Logger.Warn("File is shorter than its checkpoint, it is not replayed", zap.String("path", path))
+			return nil
+		}
+		return err
+	}
+
+	for ctx.Err() == nil {
+		record, err := rr.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = r.replayRecordWithRetry(ctx, record, p, path, offset); err != nil {
+			if ctx.Err() != nil {
+				// The checkpoint is not advanced, the record is replayed again after a restart.
+				return nil
+			}
+			r.settings.Logger.Error("Failed to replay record, dropping it", zap.String("path", path), zap.Int64("offset", offset), zap.Error(err))
+		}
+		offset = rr.offset
+		if err = r.saveCheckpoint(ctx, path, offset); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayRecordWithRetry replays the record, retrying with an exponential backoff until it succeeds, fails with
+// a permanent error or the context is done.
+func (r *fileReceiver) replayRecordWithRetry(ctx context.Context, record []byte, p *pacer, path string, offset int64) error {
+	expBackoff := backoff.NewExponentialBackOff()
+	expBackoff.InitialInterval = retryInitialInterval
+	expBackoff.MaxInterval = retryMaxInterval
+	// Retry until the receiver is shut down.
+	expBackoff.MaxElapsedTime = 0
+	for {
+		err := r.replayRecord(ctx, record, p)
+		if err == nil || consumererror.IsPermanent(err) || ctx.Err() != nil {
+			return err
+		}
+		delay := expBackoff.NextBackOff()
+		r.settings.Logger.Warn("Failed to replay record, will retry", zap.String("path", path), zap.Int64("offset", offset),
+			zap.Error(err), zap.Duration("interval", delay))
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// replayRecord unmarshals the record and sends it to the pipeline of its data type, once the pacer allows it.
+// Records that cannot be unmarshaled fail with a permanent error.
+func (r *fileReceiver) replayRecord(ctx context.Context, record []byte, p *pacer) error {
+	dataType, err := r.dataTypeOf(record)
+	if err != nil {
+		return consumererror.NewPermanent(err)
+	}
+	switch dataType {
+	case config.TracesDataType:
+		if r.nextTraces == nil {
+			return nil
+		}
+		td, err := r.tracesUnmarshaler.UnmarshalTraces(record)
+		if err != nil {
+			return consumererror.NewPermanent(err)
+		}
+		if err = p.wait(ctx, tracesTimestamp(td)); err != nil {
+			return err
+		}
+		obsCtx := r.obsrecv.StartTracesOp(ctx)
+		err = r.nextTraces.ConsumeTraces(obsCtx, td)
+		r.obsrecv.EndTracesOp(obsCtx, r.cfg.Format, td.SpanCount(), err)
+		return err
+	case config.MetricsDataType:
+		if r.nextMetrics == nil {
+			return nil
+		}
+		md, err := r.metricsUnmarshaler.UnmarshalMetrics(record)
+		if err != nil {
+			return consumererror.NewPermanent(err)
+		}
+		if err = p.wait(ctx, metricsTimestamp(md)); err != nil {
+			return err
+		}
+		obsCtx := r.obsrecv.StartMetricsOp(ctx)
+		err = r.nextMetrics.ConsumeMetrics(obsCtx, md)
+		r.obsrecv.EndMetricsOp(obsCtx, r.cfg.Format, md.DataPointCount(), err)
+		return err
+	default:
+		if r.nextLogs == nil {
+			return nil
+		}
+		ld, err := r.logsUnmarshaler.UnmarshalLogs(record)
+		if err != nil {
+			return consumererror.NewPermanent(err)
+		}
+		if err = p.wait(ctx, logsTimestamp(ld)); err != nil {
+			return err
+		}
+		obsCtx := r.obsrecv.StartLogsOp(ctx)
+		err = r.nextLogs.ConsumeLogs(obsCtx, ld)
+		r.obsrecv.EndLogsOp(obsCtx, r.cfg.Format, ld.LogRecordCount(), err)
+		return err
+	}
+}
+
+// jsonRecord holds the top-level field identifying the data type of an OTLP JSON record, which may use
+// the lowerCamelCase or the original proto field names.
+type jsonRecord struct {
+	ResourceSpans        json.RawMessage `json:"resourceSpans"`
+	ResourceSpansProto   json.RawMessage `json:"resource_spans"`
+	ResourceMetrics      json.RawMessage `json:"resourceMetrics"`
+	ResourceMetricsProto json.RawMessage `json:"resource_metrics"`
+	ResourceLogs         json.RawMessage `json:"resourceLogs"`
+	ResourceLogsProto    json.RawMessage `json:"resource_logs"`
+}
+
+// dataTypeOf returns the data type of the record. Protobuf records do not identify their data type,
+// which is the one of the single pipeline data type the receiver is used in.
+func (r *fileReceiver) dataTypeOf(record []byte) (config.DataType, error) {
+	if r.cfg.Format == formatProto {
+		switch {
+		case r.nextTraces != nil:
+			return config.TracesDataType, nil
+		case r.nextMetrics != nil:
+			return config.MetricsDataType, nil
+		default:
+			return config.LogsDataType, nil
+		}
+	}
+
+	var jr jsonRecord
+	if err := json.Unmarshal(record, &jr); err != nil {
+		return "", err
+	}
+	switch {
+	case jr.ResourceSpans != nil || jr.ResourceSpansProto != nil:
+		return config.TracesDataType, nil
+	case jr.ResourceMetrics != nil || jr.ResourceMetricsProto != nil:
+		return config.MetricsDataType, nil
+	case jr.ResourceLogs != nil || jr.ResourceLogsProto != nil:
+		return config.LogsDataType, nil
+	}
+	return "", errors.New("record is not an OTLP JSON traces, metrics or logs request")
+}
+
+// loadCheckpoint returns the offset of the first record of the file that has not been replayed yet.
+func (r *fileReceiver) loadCheckpoint(ctx context.Context, path string) (int64, error) {
+	if r.client == nil {
+		return 0, nil
+	}
+	buf, err := r.client.Get(ctx, path)
+	if err != nil || len(buf) == 0 {
+		return 0, err
+	}
+	if len(buf) != 8 {
+		return 0, fmt.Errorf("invalid checkpoint of %q", path)
+	}
+	return int64(binary.LittleEndian.Uint64(buf)), nil
+}
+
+// saveCheckpoint stores the offset of the first record of the file that has not been replayed yet.
+func (r *fileReceiver) saveCheckpoint(ctx context.Context, path string, offset int64) error {
+	if r.client == nil {
+		return nil
+	}
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, uint64(offset))
+	return r.client.Set(ctx, path, buf)
+}
+
+// pacer delays the records so they are replayed with the pace of their timestamps, multiplied by the speed.
+type pacer struct {
+	speed   float64
+	started bool
+	start   time.Time
+	first   pdata.Timestamp
+}
+
+// wait waits until the record with the given timestamp must be replayed. Records without timestamp or older than
+// the first paced record are not delayed.
+func (p *pacer) wait(ctx context.Context, ts pdata.Timestamp) error {
+	if p.speed == 0 || ts == 0 {
+		return nil
+	}
+	if !p.started {
+		p.started = true
+		p.start = time.Now()
+		p.first = ts
+		return nil
+	}
+	if ts <= p.first {
+		return nil
+	}
+	delay := time.Duration(float64(ts-p.first)/p.speed) - time.Since(p.start)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,356 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filereceiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenthelper"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/otlp"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+type mockStorageClient struct {
+	mu sync.Mutex
+	st map[string][]byte
+}
+
+func (m *mockStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.st[key], nil
+}
+
+func (m *mockStorageClient) Set(_ context.Context, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.st[key] = value
+	return nil
+}
+
+func (m *mockStorageClient) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.st, key)
+	return nil
+}
+
+func (m *mockStorageClient) Batch(context.Context, ...storage.Operation) error {
+	panic("not implemented")
+}
+
+func (m *mockStorageClient) Close(context.Context) error {
+	return nil
+}
+
+type mockStorageExtension struct {
+	component.Component
+	client *mockStorageClient
+}
+
+func (m *mockStorageExtension) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return m.client, nil
+}
+
+type storageHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *storageHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func encodeJSON(t *testing.T, data interface{}) []byte {
+	var buf []byte
+	var err error
+	switch d := data.(type) {
+	case pdata.Traces:
+		buf, err = otlp.NewJSONTracesMarshaler().MarshalTraces(d)
+	case pdata.Metrics:
+		buf, err = otlp.NewJSONMetricsMarshaler().MarshalMetrics(d)
+	case pdata.Logs:
+		buf, err = otlp.NewJSONLogsMarshaler().MarshalLogs(d)
+	}
+	require.NoError(t, err)
+	return append(buf, '\n')
+}
+
+func encodeProto(t *testing.T, td pdata.Traces) []byte {
+	buf, err := otlp.NewProtobufTracesMarshaler().MarshalTraces(td)
+	require.NoError(t, err)
+	record := make([]byte, 4, 4+len(buf))
+	binary.BigEndian.PutUint32(record, uint32(len(buf)))
+	return append(record, buf...)
+}
+
+type sinks struct {
+	traces  *consumertest.TracesSink
+	metrics *consumertest.MetricsSink
+	logs    *consumertest.LogsSink
+}
+
+// startReceiver starts a receiver of the given configuration, sending the traces, metrics and logs to the returned sinks.
+func startReceiver(t *testing.T, cfg *Config, host component.Host) (component.Component, sinks) {
+	s := sinks{traces: new(consumertest.TracesSink), metrics: new(consumertest.MetricsSink), logs: new(consumertest.LogsSink)}
+	r := newFileReceiver(cfg, componenttest.NewNopReceiverCreateSettings())
+	require.NoError(t, r.registerTracesConsumer(s.traces))
+	require.NoError(t, r.registerMetricsConsumer(s.metrics))
+	require.NoError(t, r.registerLogsConsumer(s.logs))
+	require.NoError(t, r.Start(context.Background(), host))
+	return r, s
+}
+
+func TestFileReceiver_JSON(t *testing.T) {
+	dir := t.TempDir()
+	// The records of all the data types can be written to the same file.
+	data := append(encodeJSON(t, testdata.GenerateTracesOneSpan()), encodeJSON(t, testdata.GenerateMetricsOneMetric())...)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data-1.json"), data, 0600))
+	// The incomplete last record is not replayed.
+	data = append(encodeJSON(t, testdata.GenerateLogsOneLogRecord()), []byte(`{"resourceSpans":`)...)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "data-2.json"), data, 0600))
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = []string{filepath.Join(dir, "*.json")}
+	r, s := startReceiver(t, cfg, componenttest.NewNopHost())
+	assert.Eventually(t, func() bool {
+		return s.logs.LogRecordCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	assert.Equal(t, []pdata.Traces{testdata.GenerateTracesOneSpan()}, s.traces.AllTraces())
+	assert.Equal(t, []pdata.Metrics{testdata.GenerateMetricsOneMetric()}, s.metrics.AllMetrics())
+	assert.Equal(t, []pdata.Logs{testdata.GenerateLogsOneLogRecord()}, s.logs.AllLogs())
+}
+
+func TestFileReceiver_ProtoGzip(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	_, err := gw.Write(append(encodeProto(t, testdata.GenerateTracesOneSpan()), encodeProto(t, testdata.GenerateTracesTwoSpansSameResource())...))
+	require.NoError(t, err)
+	require.NoError(t, gw.Close())
+	path := filepath.Join(t.TempDir(), "traces.pb.gz")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0600))
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = []string{path}
+	cfg.Format = formatProto
+	cfg.Compression = compressionGzip
+	s := new(consumertest.TracesSink)
+	r := newFileReceiver(cfg, componenttest.NewNopReceiverCreateSettings())
+	require.NoError(t, r.registerTracesConsumer(s))
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Eventually(t, func() bool {
+		return s.SpanCount() == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestFileReceiver_ProtoMultipleDataTypes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = []string{"*.pb"}
+	cfg.Format = formatProto
+	r := newFileReceiver(cfg, componenttest.NewNopReceiverCreateSettings())
+	require.NoError(t, r.registerTracesConsumer(consumertest.NewNop()))
+	require.NoError(t, r.registerLogsConsumer(consumertest.NewNop()))
+	assert.ErrorIs(t, r.Start(context.Background(), componenttest.NewNopHost()), errProtoMultipleSignals)
+}
+
+func TestFileReceiver_Checkpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	require.NoError(t, os.WriteFile(path, encodeJSON(t, testdata.GenerateTracesOneSpan()), 0600))
+
+	storageID := config.NewComponentID("mock_storage")
+	client := &mockStorageClient{st: map[string][]byte{}}
+	host := &storageHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			storageID: &mockStorageExtension{Component: componenthelper.New(), client: client},
+		},
+	}
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = []string{path}
+	cfg.StorageID = &storageID
+
+	r, s := startReceiver(t, cfg, host)
+	assert.Eventually(t, func() bool {
+		return s.traces.SpanCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	// After a restart only the records appended since then are replayed.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.Write(encodeJSON(t, testdata.GenerateTracesTwoSpansSameResource()))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	r, s = startReceiver(t, cfg, host)
+	assert.Eventually(t, func() bool {
+		return s.traces.SpanCount() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+	assert.Equal(t, []pdata.Traces{testdata.GenerateTracesTwoSpansSameResource()}, s.traces.AllTraces())
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	offset, err := r.(*fileReceiver).loadCheckpoint(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), offset)
+}
+
+// failingTracesSink fails the first failures calls with the given error, then stores the traces.
+type failingTracesSink struct {
+	consumertest.TracesSink
+	failures int32
+	err      error
+	calls    int32
+}
+
+func (f *failingTracesSink) ConsumeTraces(ctx context.Context, td pdata.Traces) error {
+	if atomic.AddInt32(&f.calls, 1) <= f.failures {
+		return f.err
+	}
+	return f.TracesSink.ConsumeTraces(ctx, td)
+}
+
+func (f *failingTracesSink) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+func startCheckpointedTracesReceiver(t *testing.T, path string, client *mockStorageClient, next consumer.Traces) component.Component {
+	storageID := config.NewComponentID("mock_storage")
+	host := &storageHost{
+		Host: componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{
+			storageID: &mockStorageExtension{Component: componenthelper.New(), client: client},
+		},
+	}
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = []string{path}
+	cfg.StorageID = &storageID
+	r := newFileReceiver(cfg, componenttest.NewNopReceiverCreateSettings())
+	require.NoError(t, r.registerTracesConsumer(next))
+	require.NoError(t, r.Start(context.Background(), host))
+	return r
+}
+
+func TestFileReceiver_RetryableError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	require.NoError(t, os.WriteFile(path, encodeJSON(t, testdata.GenerateTracesOneSpan()), 0600))
+	client := &mockStorageClient{st: map[string][]byte{}}
+
+	// The record is retried until the pipeline accepts it.
+	sink := &failingTracesSink{failures: 1, err: errors.New("temporary failure")}
+	r := startCheckpointedTracesReceiver(t, path, client, sink)
+	assert.Eventually(t, func() bool {
+		return sink.SpanCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&sink.calls))
+}
+
+func TestFileReceiver_RetryableErrorShutdown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	require.NoError(t, os.WriteFile(path, encodeJSON(t, testdata.GenerateTracesOneSpan()), 0600))
+	client := &mockStorageClient{st: map[string][]byte{}}
+
+	// The checkpoint is not advanced past the record the pipeline did not accept before the shutdown.
+	sink := &failingTracesSink{failures: 1 << 30, err: errors.New("temporary failure")}
+	r := startCheckpointedTracesReceiver(t, path, client, sink)
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&sink.calls) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+	offset, err := r.(*fileReceiver).loadCheckpoint(context.Background(), path)
+	require.NoError(t, err)
+	assert.Zero(t, offset)
+
+	// The record is replayed after a restart.
+	s := new(consumertest.TracesSink)
+	r = startCheckpointedTracesReceiver(t, path, client, s)
+	assert.Eventually(t, func() bool {
+		return s.SpanCount() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestFileReceiver_PermanentError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	data := append(encodeJSON(t, testdata.GenerateTracesOneSpan()), encodeJSON(t, testdata.GenerateTracesTwoSpansSameResource())...)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	client := &mockStorageClient{st: map[string][]byte{}}
+
+	// The record rejected with a permanent error is dropped.
+	sink := &failingTracesSink{failures: 1, err: consumererror.NewPermanent(errors.New("bad data"))}
+	r := startCheckpointedTracesReceiver(t, path, client, sink)
+	assert.Eventually(t, func() bool {
+		return sink.SpanCount() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+	assert.Equal(t, []pdata.Traces{testdata.GenerateTracesTwoSpansSameResource()}, sink.AllTraces())
+	assert.EqualValues(t, 2, atomic.LoadInt32(&sink.calls))
+}
+
+func TestFileReceiver_MissingStorage(t *testing.T) {
+	storageID := config.NewComponentID("mock_storage")
+	cfg := createDefaultConfig().(*Config)
+	cfg.Include = []string{"*.json"}
+	cfg.StorageID = &storageID
+	r := newFileReceiver(cfg, componenttest.NewNopReceiverCreateSettings())
+	require.NoError(t, r.registerTracesConsumer(consumertest.NewNop()))
+	assert.Error(t, r.Start(context.Background(), componenttest.NewNopHost()))
+}
+
+func TestPacer(t *testing.T) {
+	ctx := context.Background()
+	// Without speed the records are never delayed.
+	p := &pacer{}
+	assert.NoError(t, p.wait(ctx, pdata.NewTimestampFromTime(time.Now())))
+	assert.NoError(t, p.wait(ctx, pdata.NewTimestampFromTime(time.Now().Add(time.Hour))))
+
+	p = &pacer{speed: 100}
+	start := time.Now()
+	assert.NoError(t, p.wait(ctx, pdata.NewTimestampFromTime(start)))
+	assert.NoError(t, p.wait(ctx, pdata.NewTimestampFromTime(start.Add(-time.Hour))))
+	assert.NoError(t, p.wait(ctx, pdata.NewTimestampFromTime(start.Add(5*time.Second))))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// Waiting is interrupted by the cancellation of the context.
+	cancelCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, p.wait(cancelCtx, pdata.NewTimestampFromTime(start.Add(time.Hour))), context.Canceled)
+}
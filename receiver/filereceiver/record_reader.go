@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filereceiver // import "go.opentelemetry.io/collector/receiver/filereceiver"
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// recordReader reads the records of a file, keeping track of the offset of the next record in the
+// decompressed data.
+type recordReader struct {
+	format string
+	r      *bufio.Reader
+	closer func()
+	// offset is the offset of the next record in the decompressed data.
+	offset int64
+}
+
+func newRecordReader(r io.Reader, format string, compression string) (*recordReader, error) {
+	rr := &recordReader{format: format, closer: func() {}}
+	switch compression {
+	case compressionGzip:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		r = gr
+	case compressionZstd:
+		zr, err := zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+		r = zr
+		rr.closer = zr.Close
+	}
+	rr.r = bufio.NewReader(r)
+	return rr, nil
+}
+
+// skip skips the given number of bytes, e.g. the records already replayed before a restart.
+func (rr *recordReader) skip(offset int64) error {
+	n, err := io.CopyN(io.Discard, rr.r, offset)
+	rr.offset += n
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return io.EOF
+	}
+	return err
+}
+
+// next returns the next record. It returns io.EOF at the end of the data or if the last record is incomplete,
+// e.g. because the file is still being written, in which case the offset is not advanced past the incomplete record.
+func (rr *recordReader) next() ([]byte, error) {
+	if rr.format == formatProto {
+		return rr.nextProto()
+	}
+	return rr.nextJSON()
+}
+
+func (rr *recordReader) nextJSON() ([]byte, error) {
+	for {
+		line, err := rr.r.ReadBytes('\n')
+		if err != nil {
+			return nil, eofIfIncomplete(err)
+		}
+		rr.offset += int64(len(line))
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			return line, nil
+		}
+	}
+}
+
+func (rr *recordReader) nextProto() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(rr.r, header[:]); err != nil {
+		return nil, eofIfIncomplete(err)
+	}
+	record := make([]byte, binary.BigEndian.Uint32(header[:]))
+	if _, err := io.ReadFull(rr.r, record); err != nil {
+		return nil, eofIfIncomplete(err)
+	}
+	rr.offset += int64(len(header) + len(record))
+	return record, nil
+}
+
+// eofIfIncomplete returns io.EOF if err reports data ending in the middle of a record.
+func eofIfIncomplete(err error) error {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return io.EOF
+	}
+	return err
+}
+
+func (rr *recordReader) close() {
+	rr.closer()
+}
@@ -0,0 +1,74 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filereceiver
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReader_JSON(t *testing.T) {
+	rr, err := newRecordReader(bytes.NewReader([]byte("{\"a\":1}\n\n{\"b\":2}\n{\"c\"")), formatJSON, "")
+	require.NoError(t, err)
+	record, err := rr.next()
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1}`, string(record))
+	assert.EqualValues(t, 8, rr.offset)
+	// Empty lines are skipped.
+	record, err = rr.next()
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":2}`, string(record))
+	assert.EqualValues(t, 17, rr.offset)
+	// The incomplete record is not returned.
+	_, err = rr.next()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.EqualValues(t, 17, rr.offset)
+
+	rr, err = newRecordReader(bytes.NewReader([]byte("{\"a\":1}\n\n{\"b\":2}\n{\"c\"")), formatJSON, "")
+	require.NoError(t, err)
+	require.NoError(t, rr.skip(8))
+	record, err = rr.next()
+	require.NoError(t, err)
+	assert.Equal(t, `{"b":2}`, string(record))
+	assert.ErrorIs(t, rr.skip(100), io.EOF)
+}
+
+func TestRecordReader_ProtoZstd(t *testing.T) {
+	var buf bytes.Buffer
+	zw, err := zstd.NewWriter(&buf)
+	require.NoError(t, err)
+	_, err = zw.Write([]byte{0, 0, 0, 2, 'a', 'b', 0, 0, 0, 1, '\n', 0, 0, 0, 5, 'c'})
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	rr, err := newRecordReader(&buf, formatProto, compressionZstd)
+	require.NoError(t, err)
+	defer rr.close()
+	record, err := rr.next()
+	require.NoError(t, err)
+	assert.Equal(t, "ab", string(record))
+	record, err = rr.next()
+	require.NoError(t, err)
+	assert.Equal(t, "\n", string(record))
+	assert.EqualValues(t, 11, rr.offset)
+	_, err = rr.next()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.EqualValues(t, 11, rr.offset)
+}
@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filereceiver // import "go.opentelemetry.io/collector/receiver/filereceiver"
+
+import (
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// minTimestamp returns the earliest of the non zero timestamps, or 0 if both are 0.
+func minTimestamp(a, b pdata.Timestamp) pdata.Timestamp {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// tracesTimestamp returns the earliest start time of the spans.
+func tracesTimestamp(td pdata.Traces) pdata.Timestamp {
+	var ts pdata.Timestamp
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		ilss := rss.At(i).InstrumentationLibrarySpans()
+		for j := 0; j < ilss.Len(); j++ {
+			spans := ilss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				ts = minTimestamp(ts, spans.At(k).StartTimestamp())
+			}
+		}
+	}
+	return ts
+}
+
+// metricsTimestamp returns the earliest time of the data points.
+func metricsTimestamp(md pdata.Metrics) pdata.Timestamp {
+	var ts pdata.Timestamp
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		ilms := rms.At(i).InstrumentationLibraryMetrics()
+		for j := 0; j < ilms.Len(); j++ {
+			metrics := ilms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				ts = minTimestamp(ts, metricTimestamp(metrics.At(k)))
+			}
+		}
+	}
+	return ts
+}
+
+func metricTimestamp(metric pdata.Metric) pdata.Timestamp {
+	var ts pdata.Timestamp
+	switch metric.DataType() {
+	case pdata.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			ts = minTimestamp(ts, dps.At(i).Timestamp())
+		}
+	case pdata.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			ts = minTimestamp(ts, dps.At(i).Timestamp())
+		}
+	case pdata.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			ts = minTimestamp(ts, dps.At(i).Timestamp())
+		}
+	case pdata.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			ts = minTimestamp(ts, dps.At(i).Timestamp())
+		}
+	}
+	return ts
+}
+
+// logsTimestamp returns the earliest time of the log records.
+func logsTimestamp(ld pdata.Logs) pdata.Timestamp {
+	var ts pdata.Timestamp
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		ills := rls.At(i).InstrumentationLibraryLogs()
+		for j := 0; j < ills.Len(); j++ {
+			logs := ills.At(j).Logs()
+			for k := 0; k < logs.Len(); k++ {
+				ts = minTimestamp(ts, logs.At(k).Timestamp())
+			}
+		}
+	}
+	return ts
+}
@@ -40,6 +40,10 @@ type Config struct {
 	config.ReceiverSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
 	// Protocols is the configuration for the supported protocols, currently gRPC and HTTP (Proto and JSON).
 	Protocols `mapstructure:"protocols"`
+
+	// MemoryLimiterID is the ID of the memory limiter extension consulted before reading the requests. No
+	// request is refused if not set.
+	MemoryLimiterID *config.ComponentID `mapstructure:"memory_limiter"`
 }
 
 var _ config.Receiver = (*Config)(nil)
@@ -42,7 +42,7 @@ func TestLoadConfig(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, cfg)
 
-	assert.Equal(t, len(cfg.Receivers), 10)
+	assert.Equal(t, len(cfg.Receivers), 11)
 
 	assert.Equal(t, cfg.Receivers[config.NewComponentID(typeStr)], factory.CreateDefaultConfig())
 
@@ -173,6 +173,22 @@ func TestLoadConfig(t *testing.T) {
 			},
 		})
 
+	memoryLimiterID := config.NewComponentID("memory_limiter")
+	assert.Equal(t, cfg.Receivers[config.NewComponentIDWithName(typeStr, "memory_limiter")],
+		&Config{
+			ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "memory_limiter")),
+			Protocols: Protocols{
+				GRPC: &configgrpc.GRPCServerSettings{
+					NetAddr: confignet.NetAddr{
+						Endpoint:  "0.0.0.0:4317",
+						Transport: "tcp",
+					},
+					ReadBufferSize: 512 * 1024,
+				},
+			},
+			MemoryLimiterID: &memoryLimiterID,
+		})
+
 	assert.Equal(t, cfg.Receivers[config.NewComponentIDWithName(typeStr, "uds")],
 		&Config{
 			ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "uds")),
@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpreceiver // import "go.opentelemetry.io/collector/receiver/otlpreceiver"
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/tap"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/memorylimiter"
+)
+
+const headerRetryAfter = "Retry-After"
+
+// getMemoryLimiter returns the memory limiter extension with the given ID among the host extensions.
+func getMemoryLimiter(host component.Host, id config.ComponentID) (memorylimiter.Extension, error) {
+	ext, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("memory limiter extension %q not found", id.String())
+	}
+	ml, ok := ext.(memorylimiter.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a memory limiter extension", id.String())
+	}
+	return ml, nil
+}
+
+// refusedStatus returns the status of the requests refused due to high memory usage, with the retry delay
+// in the details as expected by the OTLP protocol.
+func refusedStatus(delay time.Duration) *status.Status {
+	s := status.New(codes.ResourceExhausted, "data refused due to high memory usage")
+	if sd, err := s.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(delay)}); err == nil {
+		return sd
+	}
+	return s
+}
+
+// memoryLimiterTapHandle refuses the gRPC streams before any message is read when the memory limiter
+// requires so. The status details are only sent by gRPC versions forwarding the details of the status
+// returned by the tap handle, clients otherwise retry with their own backoff.
+func memoryLimiterTapHandle(ml memorylimiter.Extension) tap.ServerInHandle {
+	return func(ctx context.Context, _ *tap.Info) (context.Context, error) {
+		if ml.MustRefuse() {
+			return ctx, refusedStatus(ml.RetryDelay()).Err()
+		}
+		return ctx, nil
+	}
+}
+
+// memoryLimiterHandler refuses the HTTP requests with 429 Too Many Requests before their body is read
+// when the memory limiter requires so.
+func memoryLimiterHandler(ml memorylimiter.Extension, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !ml.MustRefuse() {
+			next.ServeHTTP(w, req)
+			return
+		}
+		delay := ml.RetryDelay()
+		w.Header().Set(headerRetryAfter, strconv.Itoa(retryAfterSeconds(delay)))
+		var enc encoder = jsEncoder
+		if req.Header.Get("Content-Type") == pbContentType {
+			enc = pbEncoder
+		}
+		writeStatusResponse(w, enc, http.StatusTooManyRequests, refusedStatus(delay).Proto())
+	})
+}
+
+// retryAfterSeconds rounds up the delay to the whole seconds of the Retry-After header, at least one.
+func retryAfterSeconds(delay time.Duration) int {
+	seconds := int((delay + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
@@ -0,0 +1,161 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlpreceiver
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenthelper"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/internal/testutil"
+	"go.opentelemetry.io/collector/model/otlp"
+)
+
+type mockMemoryLimiter struct {
+	component.Component
+	refuse int32
+}
+
+func (ml *mockMemoryLimiter) MustRefuse() bool {
+	return atomic.LoadInt32(&ml.refuse) == 1
+}
+
+func (ml *mockMemoryLimiter) RetryDelay() time.Duration {
+	return 1500 * time.Millisecond
+}
+
+type memoryLimiterHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *memoryLimiterHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	endpointGrpc := testutil.GetAvailableLocalAddress(t)
+	endpointHTTP := testutil.GetAvailableLocalAddress(t)
+	sink := new(consumertest.TracesSink)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.GRPC.NetAddr.Endpoint = endpointGrpc
+	cfg.HTTP.Endpoint = endpointHTTP
+	limiterID := config.NewComponentID("memory_limiter")
+	cfg.MemoryLimiterID = &limiterID
+	r := newReceiver(t, factory, cfg, sink, nil)
+
+	limiter := &mockMemoryLimiter{Component: componenthelper.New(), refuse: 1}
+	host := &memoryLimiterHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{limiterID: limiter},
+	}
+	require.NoError(t, r.Start(context.Background(), host))
+	t.Cleanup(func() { require.NoError(t, r.Shutdown(context.Background())) })
+
+	cc, err := grpc.Dial(endpointGrpc, grpc.WithInsecure(), grpc.WithBlock())
+	require.NoError(t, err)
+	defer cc.Close()
+
+	td := testdata.GenerateTracesOneSpan()
+	err = exportTraces(cc, td)
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	traceBytes, err := otlp.NewProtobufTracesMarshaler().MarshalTraces(td)
+	require.NoError(t, err)
+	url := fmt.Sprintf("http://%s/v1/traces", endpointHTTP)
+	resp, err := http.DefaultClient.Do(createHTTPProtobufRequest(t, url, "", traceBytes))
+	require.NoError(t, err)
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("Retry-After"))
+	st := &spb.Status{}
+	require.NoError(t, proto.Unmarshal(respBytes, st))
+	s := status.FromProto(st)
+	assert.Equal(t, codes.ResourceExhausted, s.Code())
+	require.Len(t, s.Details(), 1)
+
+	assert.Equal(t, 0, sink.SpanCount())
+
+	atomic.StoreInt32(&limiter.refuse, 0)
+	require.NoError(t, exportTraces(cc, td))
+	resp, err = http.DefaultClient.Do(createHTTPProtobufRequest(t, url, "", traceBytes))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, sink.SpanCount())
+}
+
+func TestMemoryLimiterNotFound(t *testing.T) {
+	limiterID := config.NewComponentID("memory_limiter")
+	tests := []struct {
+		name       string
+		extensions map[config.ComponentID]component.Extension
+		wantErr    string
+	}{
+		{
+			name:    "not found",
+			wantErr: `memory limiter extension "memory_limiter" not found`,
+		},
+		{
+			name:       "not a memory limiter",
+			extensions: map[config.ComponentID]component.Extension{limiterID: componenthelper.New()},
+			wantErr:    `extension "memory_limiter" is not a memory limiter extension`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			factory := NewFactory()
+			cfg := factory.CreateDefaultConfig().(*Config)
+			cfg.GRPC.NetAddr.Endpoint = testutil.GetAvailableLocalAddress(t)
+			cfg.HTTP = nil
+			cfg.MemoryLimiterID = &limiterID
+			r := newReceiver(t, factory, cfg, new(consumertest.TracesSink), nil)
+
+			host := &memoryLimiterHost{Host: componenttest.NewNopHost(), extensions: tt.extensions}
+			assert.EqualError(t, r.Start(context.Background(), host), tt.wantErr)
+			require.NoError(t, r.Shutdown(context.Background()))
+		})
+	}
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	assert.Equal(t, 1, retryAfterSeconds(0))
+	assert.Equal(t, 1, retryAfterSeconds(100*time.Millisecond))
+	assert.Equal(t, 1, retryAfterSeconds(time.Second))
+	assert.Equal(t, 2, retryAfterSeconds(1500*time.Millisecond))
+}
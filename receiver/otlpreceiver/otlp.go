@@ -28,6 +28,7 @@ import (
 	"go.opentelemetry.io/collector/config/configgrpc"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/experimental/memorylimiter"
 	"go.opentelemetry.io/collector/model/otlpgrpc"
 	"go.opentelemetry.io/collector/receiver/otlpreceiver/internal/logs"
 	"go.opentelemetry.io/collector/receiver/otlpreceiver/internal/metrics"
@@ -46,6 +47,9 @@ type otlpReceiver struct {
 	logReceiver     *logs.Receiver
 	shutdownWG      sync.WaitGroup
 
+	// limiter is the memory limiter consulted before reading the requests, nil if none is configured.
+	limiter memorylimiter.Extension
+
 	settings component.ReceiverCreateSettings
 }
 
@@ -108,6 +112,9 @@ func (r *otlpReceiver) startProtocolServers(host component.Host) error {
 		if err != nil {
 			return err
 		}
+		if r.limiter != nil {
+			opts = append(opts, grpc.InTapHandle(memoryLimiterTapHandle(r.limiter)))
+		}
 		r.serverGRPC = grpc.NewServer(opts...)
 
 		if r.traceReceiver != nil {
@@ -133,6 +140,10 @@ func (r *otlpReceiver) startProtocolServers(host component.Host) error {
 			r.settings.TelemetrySettings,
 			confighttp.WithErrorHandler(errorHandler),
 		)
+		if r.limiter != nil {
+			// Refuse the requests before they are decompressed.
+			r.serverHTTP.Handler = memoryLimiterHandler(r.limiter, r.serverHTTP.Handler)
+		}
 		err = r.startHTTPServer(r.cfg.HTTP, host)
 		if err != nil {
 			return err
@@ -157,6 +168,13 @@ func (r *otlpReceiver) startProtocolServers(host component.Host) error {
 // Start runs the trace receiver on the gRPC server. Currently
 // it also enables the metrics receiver too.
 func (r *otlpReceiver) Start(_ context.Context, host component.Host) error {
+	if r.cfg.MemoryLimiterID != nil {
+		limiter, err := getMemoryLimiter(host, *r.cfg.MemoryLimiterID)
+		if err != nil {
+			return err
+		}
+		r.limiter = limiter
+	}
 	return r.startProtocolServers(host)
 }
 
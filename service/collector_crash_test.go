@@ -0,0 +1,312 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenthelper"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configmapprovider"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/extension/extensionhelper"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/internal/testutil"
+	"go.opentelemetry.io/collector/model/otlpgrpc"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+)
+
+// crashTestConfigEnv is the environment variable giving the configuration file to the collector
+// process started by TestCollector_PersistentQueueCrashRecovery.
+const crashTestConfigEnv = "OTELCOL_CRASH_TEST_CONFIG"
+
+// fileStorageConfig is the configuration of the file_storage extension of the crash test.
+type fileStorageConfig struct {
+	config.ExtensionSettings `mapstructure:",squash"`
+	Directory                string `mapstructure:"directory"`
+}
+
+// fileStorageExtension is a storage.Extension keeping the data of every client in a JSON file,
+// rewritten on every change.
+type fileStorageExtension struct {
+	component.Component
+	directory string
+}
+
+func (f *fileStorageExtension) GetClient(_ context.Context, _ component.Kind, id config.ComponentID, name string) (storage.Client, error) {
+	client := &fileStorageClient{path: filepath.Join(f.directory, fmt.Sprintf("%s_%s.json", id.String(), name)), st: map[string][]byte{}}
+	data, err := ioutil.ReadFile(client.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err == nil {
+		if err = json.Unmarshal(data, &client.st); err != nil {
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+type fileStorageClient struct {
+	mu   sync.Mutex
+	path string
+	st   map[string][]byte
+}
+
+func (c *fileStorageClient) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	err := c.Batch(ctx, op)
+	return op.Value, err
+}
+
+func (c *fileStorageClient) Set(ctx context.Context, key string, value []byte) error {
+	return c.Batch(ctx, storage.SetOperation(key, value))
+}
+
+func (c *fileStorageClient) Delete(ctx context.Context, key string) error {
+	return c.Batch(ctx, storage.DeleteOperation(key))
+}
+
+func (c *fileStorageClient) Batch(_ context.Context, ops ...storage.Operation) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := false
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = c.st[op.Key]
+		case storage.Set:
+			c.st[op.Key] = op.Value
+			changed = true
+		case storage.Delete:
+			delete(c.st, op.Key)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	data, err := json.Marshal(c.st)
+	if err != nil {
+		return err
+	}
+	// Write the new content aside and rename it, so that a crash never leaves a partially written file.
+	tmp := c.path + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, c.path)
+}
+
+func (c *fileStorageClient) Close(context.Context) error {
+	return nil
+}
+
+// backendConfig is the configuration of the backend exporter of the crash test.
+type backendConfig struct {
+	config.ExporterSettings `mapstructure:",squash"`
+	// Fail makes every export fail with a retryable error.
+	Fail bool `mapstructure:"fail"`
+}
+
+// newCrashTestFactories returns the factories of the crash test collector: the OTLP receiver, the file_storage
+// extension and the backend exporter, persisting its queue in file_storage and sending the data to the sink.
+func newCrashTestFactories(t *testing.T, sink *consumertest.TracesSink) component.Factories {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	otlpFactory := otlpreceiver.NewFactory()
+	factories.Receivers[otlpFactory.Type()] = otlpFactory
+
+	storageFactory := extensionhelper.NewFactory("file_storage",
+		func() config.Extension {
+			return &fileStorageConfig{ExtensionSettings: config.NewExtensionSettings(config.NewComponentID("file_storage"))}
+		},
+		func(_ context.Context, _ component.ExtensionCreateSettings, cfg config.Extension) (component.Extension, error) {
+			return &fileStorageExtension{Component: componenthelper.New(), directory: cfg.(*fileStorageConfig).Directory}, nil
+		})
+	factories.Extensions[storageFactory.Type()] = storageFactory
+
+	backendFactory := exporterhelper.NewFactory("backend",
+		func() config.Exporter {
+			return &backendConfig{ExporterSettings: config.NewExporterSettings(config.NewComponentID("backend"))}
+		},
+		exporterhelper.WithTraces(func(_ context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.TracesExporter, error) {
+			qCfg := exporterhelper.DefaultQueueSettings()
+			storageID := config.NewComponentID("file_storage")
+			qCfg.StorageID = &storageID
+			return exporterhelper.NewTracesExporter(cfg, set, func(ctx context.Context, td pdata.Traces) error {
+				if cfg.(*backendConfig).Fail {
+					return errors.New("backend unavailable")
+				}
+				return sink.ConsumeTraces(ctx, td)
+			}, exporterhelper.WithQueue(qCfg), exporterhelper.WithRetry(exporterhelper.DefaultRetrySettings()))
+		}))
+	factories.Exporters[backendFactory.Type()] = backendFactory
+	return factories
+}
+
+// writeCrashTestConfig writes the configuration of the crash test collector in the directory and returns its path.
+func writeCrashTestConfig(t *testing.T, dir string, port uint16, fail bool) string {
+	cfg := fmt.Sprintf(`
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: localhost:%d
+exporters:
+  backend:
+    fail: %t
+extensions:
+  file_storage:
+    directory: %s
+service:
+  extensions: [file_storage]
+  pipelines:
+    traces:
+      receivers: [otlp]
+      exporters: [backend]
+`, port, fail, dir)
+	path := filepath.Join(dir, "config-"+strconv.FormatBool(fail)+".yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(cfg), 0600))
+	return path
+}
+
+type nopColTelemetry struct{}
+
+func (tel *nopColTelemetry) init(chan<- error, uint64, *zap.Logger) error {
+	return nil
+}
+
+func (tel *nopColTelemetry) shutdown() error {
+	return nil
+}
+
+// runCrashTestCollector runs the collector of the crash test until the process is killed.
+func runCrashTestCollector(t *testing.T, configPath string) {
+	collectorTelemetry = &nopColTelemetry{}
+	featuregate.Apply(map[string]bool{"exporter.persistentQueue": true})
+	col, err := New(CollectorSettings{
+		BuildInfo:         component.NewDefaultBuildInfo(),
+		Factories:         newCrashTestFactories(t, new(consumertest.TracesSink)),
+		ConfigMapProvider: configmapprovider.NewFile(configPath),
+	})
+	require.NoError(t, err)
+	require.NoError(t, col.Run(context.Background()))
+}
+
+// TestCollector_PersistentQueueCrashRecovery kills a collector process while its exporter fails to send the
+// received data, then restarts the collector with the same storage and checks that the data is exported.
+func TestCollector_PersistentQueueCrashRecovery(t *testing.T) {
+	if configPath := os.Getenv(crashTestConfigEnv); configPath != "" {
+		runCrashTestCollector(t, configPath)
+		return
+	}
+
+	dir := t.TempDir()
+	port := testutil.GetAvailablePort(t)
+	var out bytes.Buffer
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCollector_PersistentQueueCrashRecovery$")
+	cmd.Env = append(os.Environ(), crashTestConfigEnv+"="+writeCrashTestConfig(t, dir, port, true))
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	require.NoError(t, cmd.Start())
+	killed := false
+	defer func() {
+		if !killed {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		}
+		if t.Failed() {
+			t.Log(out.String())
+		}
+	}()
+
+	conn, err := grpc.Dial(fmt.Sprintf("localhost:%d", port), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+	req := otlpgrpc.NewTracesRequest()
+	req.SetTraces(testdata.GenerateTracesTwoSpansSameResource())
+	client := otlpgrpc.NewTracesClient(conn)
+	require.Eventually(t, func() bool {
+		_, err = client.Export(context.Background(), req)
+		return err == nil
+	}, 10*time.Second, 50*time.Millisecond)
+
+	// The queued request is stored until it is exported.
+	queuePath := filepath.Join(dir, "backend_traces.json")
+	require.Eventually(t, func() bool {
+		data, err := ioutil.ReadFile(queuePath)
+		if err != nil {
+			return false
+		}
+		st := map[string][]byte{}
+		return json.Unmarshal(data, &st) == nil && st["0"] != nil
+	}, 10*time.Second, 50*time.Millisecond)
+
+	require.NoError(t, cmd.Process.Kill())
+	_ = cmd.Wait()
+	killed = true
+
+	preservedAppTelemetry := collectorTelemetry
+	collectorTelemetry = &nopColTelemetry{}
+	defer func() { collectorTelemetry = preservedAppTelemetry }()
+	featuregate.Apply(map[string]bool{"exporter.persistentQueue": true})
+	defer featuregate.Apply(map[string]bool{"exporter.persistentQueue": false})
+
+	sink := new(consumertest.TracesSink)
+	col, err := New(CollectorSettings{
+		BuildInfo:         component.NewDefaultBuildInfo(),
+		Factories:         newCrashTestFactories(t, sink),
+		ConfigMapProvider: configmapprovider.NewFile(writeCrashTestConfig(t, dir, testutil.GetAvailablePort(t), false)),
+	})
+	require.NoError(t, err)
+	colDone := make(chan struct{})
+	go func() {
+		defer close(colDone)
+		assert.NoError(t, col.Run(context.Background()))
+	}()
+	assert.Equal(t, Starting, <-col.GetStateChannel())
+	assert.Equal(t, Running, <-col.GetStateChannel())
+
+	assert.Eventually(t, func() bool {
+		return sink.SpanCount() == 2
+	}, 10*time.Second, 10*time.Millisecond)
+
+	col.Shutdown()
+	<-colDone
+}
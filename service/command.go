@@ -18,6 +18,8 @@ import (
 	"github.com/spf13/cobra"
 
 	"go.opentelemetry.io/collector/config/configmapprovider"
+	"go.opentelemetry.io/collector/featuregate"
+	featuregateflags "go.opentelemetry.io/collector/service/featuregate"
 )
 
 // NewCommand constructs a new cobra.Command using the given Collector.
@@ -28,6 +30,7 @@ func NewCommand(set CollectorSettings) *cobra.Command {
 		Version:      set.BuildInfo.Version,
 		SilenceUsage: true,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			featuregate.Apply(featuregateflags.GetFlags())
 			if set.ConfigMapProvider == nil {
 				set.ConfigMapProvider = configmapprovider.NewDefault(getConfigFlag(), getSetFlag())
 			}
@@ -40,5 +43,6 @@ func NewCommand(set CollectorSettings) *cobra.Command {
 	}
 
 	rootCmd.Flags().AddGoFlagSet(flags())
+	rootCmd.AddCommand(newQueueCommand(set))
 	return rootCmd
 }
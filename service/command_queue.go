@@ -0,0 +1,333 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service // import "go.opentelemetry.io/collector/service"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configmapprovider"
+	"go.opentelemetry.io/collector/config/configunmarshaler"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/service/internal/telemetrylogs"
+)
+
+// queueFlags holds the command line flags selecting the persistent queue to operate on.
+type queueFlags struct {
+	config   string
+	set      []string
+	exporter string
+	signal   string
+	storage  string
+}
+
+// queueSession holds the resources opened to operate on a persistent queue while the collector is stopped.
+type queueSession struct {
+	set       CollectorSettings
+	cfg       *config.Config
+	telemetry component.TelemetrySettings
+	host      *queueHost
+	exporter  config.ComponentID
+	signal    config.DataType
+	storage   component.Extension
+	inspector *exporterhelper.PersistentQueueInspector
+}
+
+// queueHost is the component.Host of the components used to operate on a persistent queue.
+// It provides no extensions, so the exporters it runs do not use a persistent queue themselves.
+type queueHost struct {
+	factories component.Factories
+	logger    *zap.Logger
+}
+
+var _ component.Host = (*queueHost)(nil)
+
+func (h *queueHost) ReportFatalError(err error) {
+	h.logger.Error("Component reported a fatal error", zap.Error(err))
+}
+
+func (h *queueHost) GetFactory(kind component.Kind, componentType config.Type) component.Factory {
+	switch kind {
+	case component.KindReceiver:
+		return h.factories.Receivers[componentType]
+	case component.KindProcessor:
+		return h.factories.Processors[componentType]
+	case component.KindExporter:
+		return h.factories.Exporters[componentType]
+	case component.KindExtension:
+		return h.factories.Extensions[componentType]
+	}
+	return nil
+}
+
+func (h *queueHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return map[config.ComponentID]component.Extension{}
+}
+
+func (h *queueHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return map[config.DataType]map[config.ComponentID]component.Exporter{}
+}
+
+// newQueueCommand constructs the command to inspect and drain the persistent queue of an exporter
+// while the collector is stopped.
+func newQueueCommand(set CollectorSettings) *cobra.Command {
+	flags := &queueFlags{}
+	queueCmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Inspects, purges or replays the persistent queue of an exporter, the collector must not be running",
+	}
+	queueCmd.PersistentFlags().StringVar(&flags.config, "config", defaultConfig, "Path to the config file")
+	queueCmd.PersistentFlags().StringArrayVar(&flags.set, "set", nil, "Set arbitrary component config property, see the collector --set flag")
+	queueCmd.PersistentFlags().StringVar(&flags.exporter, "exporter", "", "ID of the exporter owning the queue")
+	queueCmd.PersistentFlags().StringVar(&flags.signal, "signal", "", "Data type of the queue: traces, metrics or logs")
+	queueCmd.PersistentFlags().StringVar(&flags.storage, "storage", "", "ID of the storage extension holding the queue")
+	for _, name := range []string{"exporter", "signal", "storage"} {
+		_ = queueCmd.MarkPersistentFlagRequired(name)
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "Lists the items of the queue",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runQueueCommand(cmd, set, flags, func(qs *queueSession) error {
+				items, err := qs.inspector.Items(cmd.Context())
+				if err != nil {
+					return err
+				}
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+				fmt.Fprintln(w, "KEY\tDISPATCHED\tCOUNT")
+				for _, item := range items {
+					fmt.Fprintf(w, "%s\t%t\t%d\n", item.Key, item.Dispatched, item.Count)
+				}
+				return w.Flush()
+			})
+		},
+	}
+
+	inspectCmd := &cobra.Command{
+		Use:   "inspect [key...]",
+		Short: "Prints the items of the queue with the given keys, or all of them, as OTLP JSON, one item per line",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runQueueCommand(cmd, set, flags, func(qs *queueSession) error {
+				items, err := qs.inspector.Items(cmd.Context())
+				if err != nil {
+					return err
+				}
+				keys := make(map[string]bool, len(args))
+				for _, key := range args {
+					keys[key] = true
+				}
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				for _, item := range items {
+					if len(keys) > 0 && !keys[item.Key] {
+						continue
+					}
+					err = enc.Encode(struct {
+						Key        string          `json:"key"`
+						Dispatched bool            `json:"dispatched"`
+						Count      int             `json:"count"`
+						Data       json.RawMessage `json:"data"`
+					}{item.Key, item.Dispatched, item.Count, item.Data})
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		},
+	}
+
+	purgeCmd := &cobra.Command{
+		Use:   "purge",
+		Short: "Removes all the items of the queue",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runQueueCommand(cmd, set, flags, func(qs *queueSession) error {
+				purged, err := qs.inspector.Purge(cmd.Context())
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Purged %d items\n", purged)
+				return nil
+			})
+		},
+	}
+
+	var target string
+	replayCmd := &cobra.Command{
+		Use:   "replay",
+		Short: "Re-exports the items of the queue, removing the ones exported successfully",
+		Long: "Re-exports the items of the queue using the exporter selected by --target, bypassing its batching and sending queue. " +
+			"The exporter runs without extensions, so it must not use a persistent queue or depend on other extensions.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runQueueCommand(cmd, set, flags, func(qs *queueSession) error {
+				targetID, err := config.NewComponentIDFromString(target)
+				if err != nil {
+					return err
+				}
+				exp, err := qs.createExporter(cmd.Context(), targetID)
+				if err != nil {
+					return err
+				}
+				if err = exp.Start(cmd.Context(), qs.host); err != nil {
+					return fmt.Errorf("failed to start exporter %q: %w", targetID, err)
+				}
+				replayed, err := qs.inspector.Replay(cmd.Context(), exp)
+				err = multierr.Append(err, exp.Shutdown(cmd.Context()))
+				fmt.Fprintf(cmd.OutOrStdout(), "Replayed %d items to %s\n", replayed, targetID)
+				return err
+			})
+		},
+	}
+	replayCmd.Flags().StringVar(&target, "target", "", "ID of the exporter used to re-export the items")
+	_ = replayCmd.MarkFlagRequired("target")
+
+	queueCmd.AddCommand(listCmd, inspectCmd, purgeCmd, replayCmd)
+	return queueCmd
+}
+
+// runQueueCommand opens the persistent queue selected by the flags, runs fn and closes the queue.
+func runQueueCommand(cmd *cobra.Command, set CollectorSettings, flags *queueFlags, fn func(qs *queueSession) error) (err error) {
+	qs, err := openQueue(cmd.Context(), set, flags)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = multierr.Append(err, qs.close(cmd.Context()))
+	}()
+	return fn(qs)
+}
+
+// openQueue loads the configuration and opens the persistent queue selected by the flags.
+func openQueue(ctx context.Context, set CollectorSettings, flags *queueFlags) (*queueSession, error) {
+	exporterID, err := config.NewComponentIDFromString(flags.exporter)
+	if err != nil {
+		return nil, err
+	}
+	storageID, err := config.NewComponentIDFromString(flags.storage)
+	if err != nil {
+		return nil, err
+	}
+	signal := config.DataType(flags.signal)
+	switch signal {
+	case config.TracesDataType, config.MetricsDataType, config.LogsDataType:
+	default:
+		return nil, fmt.Errorf("unsupported data type %q", flags.signal)
+	}
+
+	if set.ConfigMapProvider == nil {
+		set.ConfigMapProvider = configmapprovider.NewDefault(flags.config, flags.set)
+	}
+	if set.ConfigUnmarshaler == nil {
+		set.ConfigUnmarshaler = configunmarshaler.NewDefault()
+	}
+	cfgW, err := newConfigWatcher(ctx, set)
+	if err != nil {
+		return nil, err
+	}
+	cfg := cfgW.cfg
+	if err = cfgW.close(ctx); err != nil {
+		return nil, err
+	}
+	if _, ok := cfg.Exporters[exporterID]; !ok {
+		return nil, fmt.Errorf("exporter %q is not configured", exporterID)
+	}
+
+	logger, err := telemetrylogs.NewLogger(cfg.Service.Telemetry.Logs, set.LoggingOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get logger: %w", err)
+	}
+	qs := &queueSession{
+		set: set,
+		cfg: cfg,
+		telemetry: component.TelemetrySettings{
+			Logger:         logger,
+			TracerProvider: trace.NewNoopTracerProvider(),
+			MeterProvider:  metric.NewNoopMeterProvider(),
+		},
+		host:     &queueHost{factories: set.Factories, logger: logger},
+		exporter: exporterID,
+		signal:   signal,
+	}
+
+	if qs.storage, err = qs.startStorage(ctx, storageID); err != nil {
+		return nil, err
+	}
+	qs.inspector, err = exporterhelper.NewPersistentQueueInspector(ctx, qs.storage.(storage.Extension), exporterID, signal,
+		logger.With(zap.String("exporter", exporterID.String())))
+	if err != nil {
+		return nil, multierr.Append(err, qs.storage.Shutdown(ctx))
+	}
+	return qs, nil
+}
+
+func (qs *queueSession) startStorage(ctx context.Context, id config.ComponentID) (component.Extension, error) {
+	extCfg, ok := qs.cfg.Extensions[id]
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not configured", id)
+	}
+	factory, ok := qs.set.Factories.Extensions[id.Type()]
+	if !ok {
+		return nil, fmt.Errorf("extension factory for type %q is not configured", id.Type())
+	}
+	ext, err := factory.CreateExtension(ctx, component.ExtensionCreateSettings{TelemetrySettings: qs.telemetry, BuildInfo: qs.set.BuildInfo}, extCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create extension %q: %w", id, err)
+	}
+	if _, ok = ext.(storage.Extension); !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", id)
+	}
+	if err = ext.Start(ctx, qs.host); err != nil {
+		return nil, fmt.Errorf("failed to start extension %q: %w", id, err)
+	}
+	return ext, nil
+}
+
+func (qs *queueSession) createExporter(ctx context.Context, id config.ComponentID) (component.Exporter, error) {
+	expCfg, ok := qs.cfg.Exporters[id]
+	if !ok {
+		return nil, fmt.Errorf("exporter %q is not configured", id)
+	}
+	factory, ok := qs.set.Factories.Exporters[id.Type()]
+	if !ok {
+		return nil, fmt.Errorf("exporter factory for type %q is not configured", id.Type())
+	}
+	set := component.ExporterCreateSettings{TelemetrySettings: qs.telemetry, BuildInfo: qs.set.BuildInfo}
+	switch qs.signal {
+	case config.TracesDataType:
+		return factory.CreateTracesExporter(ctx, set, expCfg)
+	case config.MetricsDataType:
+		return factory.CreateMetricsExporter(ctx, set, expCfg)
+	default:
+		return factory.CreateLogsExporter(ctx, set, expCfg)
+	}
+}
+
+func (qs *queueSession) close(ctx context.Context) error {
+	return multierr.Append(qs.inspector.Close(ctx), qs.storage.Shutdown(ctx))
+}
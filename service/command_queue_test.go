@@ -0,0 +1,285 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenthelper"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/extension/extensionhelper"
+	"go.opentelemetry.io/collector/internal/testdata"
+	"go.opentelemetry.io/collector/model/otlp"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// memoryStorageClient is a storage.Client keeping the data in memory.
+type memoryStorageClient struct {
+	mu sync.Mutex
+	st map[string][]byte
+}
+
+func (m *memoryStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.st[key], nil
+}
+
+func (m *memoryStorageClient) Set(_ context.Context, key string, value []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.st[key] = value
+	return nil
+}
+
+func (m *memoryStorageClient) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.st, key)
+	return nil
+}
+
+func (m *memoryStorageClient) Batch(_ context.Context, ops ...storage.Operation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = m.st[op.Key]
+		case storage.Set:
+			m.st[op.Key] = op.Value
+		case storage.Delete:
+			delete(m.st, op.Key)
+		}
+	}
+	return nil
+}
+
+func (m *memoryStorageClient) Close(context.Context) error {
+	return nil
+}
+
+type memoryStorageExtension struct {
+	component.Component
+	client *memoryStorageClient
+}
+
+func (m *memoryStorageExtension) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return m.client, nil
+}
+
+// newQueueTestSettings returns the settings of a collector whose "memory_storage" extension holds
+// a persistent traces queue of the "nop" exporter with items of 1 and 2 spans, and whose "sink"
+// exporter sends the data to the returned sink. The "queued_sink" exporter sends the data to the sink
+// too, slowly and using the default sending queue, the "failing_sink" exporter fails exporting the data put
+// in its default sending queue, and the "persistent_sink" exporter has a persistent queue.
+func newQueueTestSettings(t *testing.T) (CollectorSettings, *memoryStorageClient, *consumertest.TracesSink) {
+	client := &memoryStorageClient{st: map[string][]byte{}}
+	marshaler := otlp.NewProtobufTracesMarshaler()
+	for i, td := range []pdata.Traces{testdata.GenerateTracesOneSpan(), testdata.GenerateTracesTwoSpansSameResource()} {
+		buf, err := marshaler.MarshalTraces(td)
+		require.NoError(t, err)
+		client.st[strconv.Itoa(i)] = buf
+	}
+	writeIndex := make([]byte, 8)
+	binary.LittleEndian.PutUint64(writeIndex, 2)
+	client.st["wi"] = writeIndex
+
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	storageFactory := extensionhelper.NewFactory("memory_storage",
+		func() config.Extension {
+			cfg := config.NewExtensionSettings(config.NewComponentID("memory_storage"))
+			return &cfg
+		},
+		func(context.Context, component.ExtensionCreateSettings, config.Extension) (component.Extension, error) {
+			return &memoryStorageExtension{Component: componenthelper.New(), client: client}, nil
+		})
+	factories.Extensions[storageFactory.Type()] = storageFactory
+
+	sink := new(consumertest.TracesSink)
+	sinkFactory := exporterhelper.NewFactory("sink",
+		func() config.Exporter {
+			cfg := config.NewExporterSettings(config.NewComponentID("sink"))
+			return &cfg
+		},
+		exporterhelper.WithTraces(func(_ context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.TracesExporter, error) {
+			return exporterhelper.NewTracesExporter(cfg, set, sink.ConsumeTraces)
+		}))
+	factories.Exporters[sinkFactory.Type()] = sinkFactory
+
+	queuedSinkFactory := exporterhelper.NewFactory("queued_sink",
+		func() config.Exporter {
+			cfg := config.NewExporterSettings(config.NewComponentID("queued_sink"))
+			return &cfg
+		},
+		exporterhelper.WithTraces(func(_ context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.TracesExporter, error) {
+			return exporterhelper.NewTracesExporter(cfg, set, func(ctx context.Context, td pdata.Traces) error {
+				time.Sleep(10 * time.Millisecond)
+				return sink.ConsumeTraces(ctx, td)
+			}, exporterhelper.WithQueue(exporterhelper.DefaultQueueSettings()))
+		}))
+	factories.Exporters[queuedSinkFactory.Type()] = queuedSinkFactory
+
+	failingSinkFactory := exporterhelper.NewFactory("failing_sink",
+		func() config.Exporter {
+			cfg := config.NewExporterSettings(config.NewComponentID("failing_sink"))
+			return &cfg
+		},
+		exporterhelper.WithTraces(func(_ context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.TracesExporter, error) {
+			return exporterhelper.NewTracesExporter(cfg, set, func(context.Context, pdata.Traces) error {
+				return errors.New("backend unavailable")
+			}, exporterhelper.WithQueue(exporterhelper.DefaultQueueSettings()))
+		}))
+	factories.Exporters[failingSinkFactory.Type()] = failingSinkFactory
+
+	persistentSinkFactory := exporterhelper.NewFactory("persistent_sink",
+		func() config.Exporter {
+			cfg := config.NewExporterSettings(config.NewComponentID("persistent_sink"))
+			return &cfg
+		},
+		exporterhelper.WithTraces(func(_ context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.TracesExporter, error) {
+			qCfg := exporterhelper.DefaultQueueSettings()
+			storageID := config.NewComponentID("memory_storage")
+			qCfg.StorageID = &storageID
+			return exporterhelper.NewTracesExporter(cfg, set, sink.ConsumeTraces, exporterhelper.WithQueue(qCfg))
+		}))
+	factories.Exporters[persistentSinkFactory.Type()] = persistentSinkFactory
+
+	return CollectorSettings{Factories: factories}, client, sink
+}
+
+// executeQueueCommand runs the given queue subcommand on the queue of newQueueTestSettings,
+// args override the flags selecting the queue.
+func executeQueueCommand(set CollectorSettings, subcommand string, args ...string) (string, error) {
+	cmd := NewCommand(set)
+	out := new(bytes.Buffer)
+	cmd.SetOut(out)
+	cmd.SetArgs(append([]string{"queue", subcommand,
+		"--config", path.Join("testdata", "otelcol-queue.yaml"),
+		"--exporter", "nop", "--signal", "traces", "--storage", "memory_storage"}, args...))
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestQueueCommand_List(t *testing.T) {
+	set, _, _ := newQueueTestSettings(t)
+	out, err := executeQueueCommand(set, "list")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"KEY", "DISPATCHED", "COUNT"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"0", "false", "1"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"1", "false", "2"}, strings.Fields(lines[2]))
+}
+
+func TestQueueCommand_Inspect(t *testing.T) {
+	set, _, _ := newQueueTestSettings(t)
+	out, err := executeQueueCommand(set, "inspect", "1")
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 1)
+
+	var item struct {
+		Key  string          `json:"key"`
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &item))
+	assert.Equal(t, "1", item.Key)
+	td, err := otlp.NewJSONTracesUnmarshaler().UnmarshalTraces(item.Data)
+	require.NoError(t, err)
+	assert.Equal(t, testdata.GenerateTracesTwoSpansSameResource(), td)
+}
+
+func TestQueueCommand_Purge(t *testing.T) {
+	set, client, _ := newQueueTestSettings(t)
+	out, err := executeQueueCommand(set, "purge")
+	require.NoError(t, err)
+	assert.Equal(t, "Purged 2 items\n", out)
+	assert.NotContains(t, client.st, "0")
+	assert.NotContains(t, client.st, "1")
+}
+
+func TestQueueCommand_Replay(t *testing.T) {
+	set, client, sink := newQueueTestSettings(t)
+	out, err := executeQueueCommand(set, "replay", "--target", "sink")
+	require.NoError(t, err)
+	assert.Equal(t, "Replayed 2 items to sink\n", out)
+	assert.Equal(t, 3, sink.SpanCount())
+	assert.NotContains(t, client.st, "0")
+	assert.NotContains(t, client.st, "1")
+}
+
+func TestQueueCommand_ReplayQueuedExporter(t *testing.T) {
+	set, client, sink := newQueueTestSettings(t)
+	out, err := executeQueueCommand(set, "replay", "--target", "queued_sink")
+	require.NoError(t, err)
+	assert.Equal(t, "Replayed 2 items to queued_sink\n", out)
+	// The items removed from the queue were exported, not only put in the sending queue of the exporter.
+	assert.Equal(t, 3, sink.SpanCount())
+	assert.NotContains(t, client.st, "0")
+	assert.NotContains(t, client.st, "1")
+}
+
+func TestQueueCommand_ReplayQueuedExporterFailure(t *testing.T) {
+	set, client, _ := newQueueTestSettings(t)
+	out, err := executeQueueCommand(set, "replay", "--target", "failing_sink")
+	assert.Error(t, err)
+	assert.Equal(t, "Replayed 0 items to failing_sink\n", out)
+	// The items are kept since they were not exported.
+	assert.Contains(t, client.st, "0")
+	assert.Contains(t, client.st, "1")
+}
+
+func TestQueueCommand_ReplayPersistentExporter(t *testing.T) {
+	set, client, sink := newQueueTestSettings(t)
+	_, err := executeQueueCommand(set, "replay", "--target", "persistent_sink")
+	assert.Error(t, err)
+	assert.Equal(t, 0, sink.SpanCount())
+	assert.Contains(t, client.st, "0")
+	assert.Contains(t, client.st, "1")
+}
+
+func TestQueueCommand_Errors(t *testing.T) {
+	set, _, _ := newQueueTestSettings(t)
+	_, err := executeQueueCommand(set, "list", "--signal", "unknown")
+	assert.Error(t, err)
+	_, err = executeQueueCommand(set, "list", "--exporter", "unknown")
+	assert.Error(t, err)
+	_, err = executeQueueCommand(set, "list", "--storage", "unknown")
+	assert.Error(t, err)
+	_, err = executeQueueCommand(set, "replay", "--target", "unknown")
+	assert.Error(t, err)
+	_, err = executeQueueCommand(set, "replay")
+	assert.Error(t, err)
+}
@@ -17,6 +17,7 @@ package defaultcomponents
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"runtime"
 	"testing"
 
@@ -28,6 +29,8 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/configgrpc"
+	"go.opentelemetry.io/collector/exporter/fileexporter"
+	"go.opentelemetry.io/collector/exporter/loadbalancingexporter"
 	"go.opentelemetry.io/collector/exporter/otlpexporter"
 	"go.opentelemetry.io/collector/exporter/otlphttpexporter"
 	"go.opentelemetry.io/collector/internal/testutil"
@@ -45,6 +48,22 @@ func TestDefaultExporters(t *testing.T) {
 		getConfigFn   getExporterConfigFn
 		skipLifecycle bool
 	}{
+		{
+			exporter: "file",
+			getConfigFn: func() config.Exporter {
+				cfg := expFactories["file"].CreateDefaultConfig().(*fileexporter.Config)
+				cfg.Path = filepath.Join(t.TempDir(), "data.json")
+				return cfg
+			},
+		},
+		{
+			exporter: "loadbalancing",
+			getConfigFn: func() config.Exporter {
+				cfg := expFactories["loadbalancing"].CreateDefaultConfig().(*loadbalancingexporter.Config)
+				cfg.Resolver.Static = &otlpexporter.StaticResolverSettings{Endpoints: []string{endpoint}}
+				return cfg
+			},
+		},
 		{
 			exporter:      "logging",
 			skipLifecycle: runtime.GOOS == "darwin", // TODO: investigate why this fails on darwin.
@@ -25,6 +25,7 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/extension/ballastextension"
+	"go.opentelemetry.io/collector/extension/memorylimiterextension"
 	"go.opentelemetry.io/collector/extension/zpagesextension"
 	"go.opentelemetry.io/collector/internal/testutil"
 )
@@ -55,6 +56,14 @@ func TestDefaultExtensions(t *testing.T) {
 				return cfg
 			},
 		},
+		{
+			extension: "memory_limiter",
+			getConfigFn: func() config.Extension {
+				cfg := extFactories["memory_limiter"].CreateDefaultConfig().(*memorylimiterextension.Config)
+				cfg.MemoryLimitMiB = 1024
+				return cfg
+			},
+		},
 	}
 
 	assert.Equal(t, len(tests), len(extFactories))
@@ -28,6 +28,7 @@ import (
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/processor/attributesprocessor"
 	"go.opentelemetry.io/collector/processor/memorylimiterprocessor"
 )
 
@@ -41,6 +42,14 @@ func TestDefaultProcessors(t *testing.T) {
 		processor   config.Type
 		getConfigFn getProcessorConfigFn
 	}{
+		{
+			processor: "attributes",
+			getConfigFn: func() config.Processor {
+				cfg := procFactories["attributes"].CreateDefaultConfig().(*attributesprocessor.Config)
+				cfg.Actions = []attributesprocessor.ActionKeyValue{{Key: "password", Action: "delete"}}
+				return cfg
+			},
+		},
 		{
 			processor: "batch",
 		},
@@ -17,6 +17,7 @@ package defaultcomponents
 import (
 	"context"
 	"errors"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,6 +29,7 @@ import (
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/internal/testutil"
+	"go.opentelemetry.io/collector/receiver/filereceiver"
 	"go.opentelemetry.io/collector/receiver/otlpreceiver"
 )
 
@@ -41,6 +43,14 @@ func TestDefaultReceivers(t *testing.T) {
 		receiver    config.Type
 		getConfigFn getReceiverConfigFn
 	}{
+		{
+			receiver: "file",
+			getConfigFn: func() config.Receiver {
+				cfg := rcvrFactories["file"].CreateDefaultConfig().(*filereceiver.Config)
+				cfg.Include = []string{filepath.Join(t.TempDir(), "*.json")}
+				return cfg
+			},
+		},
 		{
 			receiver: "otlp",
 			getConfigFn: func() config.Receiver {
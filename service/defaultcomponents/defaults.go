@@ -19,13 +19,18 @@ import (
 	"go.uber.org/multierr"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter/fileexporter"
+	"go.opentelemetry.io/collector/exporter/loadbalancingexporter"
 	"go.opentelemetry.io/collector/exporter/loggingexporter"
 	"go.opentelemetry.io/collector/exporter/otlpexporter"
 	"go.opentelemetry.io/collector/exporter/otlphttpexporter"
 	"go.opentelemetry.io/collector/extension/ballastextension"
+	"go.opentelemetry.io/collector/extension/memorylimiterextension"
 	"go.opentelemetry.io/collector/extension/zpagesextension"
+	"go.opentelemetry.io/collector/processor/attributesprocessor"
 	"go.opentelemetry.io/collector/processor/batchprocessor"
 	"go.opentelemetry.io/collector/processor/memorylimiterprocessor"
+	"go.opentelemetry.io/collector/receiver/filereceiver"
 	"go.opentelemetry.io/collector/receiver/otlpreceiver"
 )
 
@@ -40,15 +45,19 @@ func Components() (
 	extensions, err := component.MakeExtensionFactoryMap(
 		zpagesextension.NewFactory(),
 		ballastextension.NewFactory(),
+		memorylimiterextension.NewFactory(),
 	)
 	errs = multierr.Append(errs, err)
 
 	receivers, err := component.MakeReceiverFactoryMap(
+		filereceiver.NewFactory(),
 		otlpreceiver.NewFactory(),
 	)
 	errs = multierr.Append(errs, err)
 
 	exporters, err := component.MakeExporterFactoryMap(
+		fileexporter.NewFactory(),
+		loadbalancingexporter.NewFactory(),
 		loggingexporter.NewFactory(),
 		otlpexporter.NewFactory(),
 		otlphttpexporter.NewFactory(),
@@ -56,6 +65,7 @@ func Components() (
 	errs = multierr.Append(errs, err)
 
 	processors, err := component.MakeProcessorFactoryMap(
+		attributesprocessor.NewFactory(),
 		batchprocessor.NewFactory(),
 		memorylimiterprocessor.NewFactory(),
 	)
@@ -0,0 +1,80 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuregate // import "go.opentelemetry.io/collector/service/featuregate"
+
+import (
+	"flag"
+	"sort"
+	"strings"
+)
+
+const gatesListCfg = "feature-gates"
+
+var gatesList = FlagValue{}
+
+// Flags adds CLI flags for managing feature gates to the provided FlagSet.
+// Feature gates can be configured with `--feature-gates=foo,-bar`. This would
+// enable the `foo` feature gate and disable the `bar` feature gate.
+func Flags(flags *flag.FlagSet) {
+	flags.Var(
+		gatesList,
+		gatesListCfg,
+		"Comma-delimited list of feature gate identifiers. Prefix with '-' to disable the feature. '+' or no prefix will enable the feature.")
+}
+
+// GetFlags returns the feature gate statuses set via the CLI flags.
+func GetFlags() FlagValue {
+	return gatesList
+}
+
+// FlagValue implements the flag.Value interface and holds the statuses of feature gates,
+// which can be applied to the registry using Apply.
+type FlagValue map[string]bool
+
+// String returns the comma-delimited list of feature gate identifiers, the disabled ones prefixed with '-'.
+func (f FlagValue) String() string {
+	var ids []string
+	for id, enabled := range f {
+		if !enabled {
+			id = "-" + id
+		}
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+// Set parses a comma-delimited list of feature gate identifiers, the ones prefixed with '-' are disabled.
+func (f FlagValue) Set(s string) error {
+	if s == "" {
+		return nil
+	}
+	for _, id := range strings.Split(s, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		enabled := true
+		switch id[0] {
+		case '-':
+			id = id[1:]
+			enabled = false
+		case '+':
+			id = id[1:]
+		}
+		f[id] = enabled
+	}
+	return nil
+}
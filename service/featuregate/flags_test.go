@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featuregate
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagValue(t *testing.T) {
+	f := FlagValue{}
+	require.NoError(t, f.Set("foo,-bar, +baz"))
+	require.NoError(t, f.Set(""))
+	assert.Equal(t, FlagValue{"foo": true, "bar": false, "baz": true}, f)
+	assert.Equal(t, "-bar,baz,foo", f.String())
+}
+
+func TestFlags(t *testing.T) {
+	flagSet := flag.NewFlagSet("test", flag.ContinueOnError)
+	Flags(flagSet)
+	require.NoError(t, flagSet.Parse([]string{"--feature-gates=foo,-bar"}))
+	assert.Equal(t, FlagValue{"foo": true, "bar": false}, GetFlags())
+}
@@ -15,87 +15,36 @@
 package featuregate // import "go.opentelemetry.io/collector/service/featuregate"
 
 import (
-	"fmt"
-	"sync"
+	"go.opentelemetry.io/collector/featuregate"
 )
 
 // Gate represents an individual feature that may be enabled or disabled based
 // on the lifecycle state of the feature and CLI flags specified by the user.
-type Gate struct {
-	ID          string
-	Description string
-	Enabled     bool
-}
-
-var reg = &registry{gates: make(map[string]Gate)}
+// Deprecated: use go.opentelemetry.io/collector/featuregate.Gate instead.
+type Gate = featuregate.Gate
 
 // IsEnabled returns true if a registered feature gate is enabled and false otherwise.
+// Deprecated: use go.opentelemetry.io/collector/featuregate.IsEnabled instead.
 func IsEnabled(id string) bool {
-	return reg.isEnabled(id)
+	return featuregate.IsEnabled(id)
 }
 
 // List returns a slice of copies of all registered Gates.
+// Deprecated: use go.opentelemetry.io/collector/featuregate.List instead.
 func List() []Gate {
-	return reg.list()
+	return featuregate.List()
 }
 
 // Register a Gate. May only be called in an init() function.
 // Will panic() if a Gate with the same ID is already registered.
+// Deprecated: use go.opentelemetry.io/collector/featuregate.Register instead.
 func Register(g Gate) {
-	if err := reg.add(g); err != nil {
-		panic(err)
-	}
+	featuregate.Register(g)
 }
 
 // Apply a configuration in the form of a map of Gate identifiers to boolean values.
 // Sets only those values provided in the map, other gate values are not changed.
+// Deprecated: use go.opentelemetry.io/collector/featuregate.Apply instead.
 func Apply(cfg map[string]bool) {
-	reg.apply(cfg)
-}
-
-type registry struct {
-	sync.RWMutex
-	gates map[string]Gate
-}
-
-func (r *registry) apply(cfg map[string]bool) {
-	r.Lock()
-	defer r.Unlock()
-	for id, val := range cfg {
-		if g, ok := r.gates[id]; ok {
-			g.Enabled = val
-			r.gates[g.ID] = g
-		}
-	}
-}
-
-func (r *registry) add(g Gate) error {
-	r.Lock()
-	defer r.Unlock()
-	if _, ok := r.gates[g.ID]; ok {
-		return fmt.Errorf("attempted to add pre-existing gate %q", g.ID)
-	}
-
-	r.gates[g.ID] = g
-	return nil
-}
-
-func (r *registry) isEnabled(id string) bool {
-	r.RLock()
-	defer r.RUnlock()
-	g, ok := r.gates[id]
-	return ok && g.Enabled
-}
-
-func (r *registry) list() []Gate {
-	r.RLock()
-	defer r.RUnlock()
-	ret := make([]Gate, len(r.gates))
-	i := 0
-	for _, gate := range r.gates {
-		ret[i] = gate
-		i++
-	}
-
-	return ret
+	featuregate.Apply(cfg)
 }
@@ -18,49 +18,22 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-)
-
-func TestRegistry(t *testing.T) {
-	r := registry{gates: map[string]Gate{}}
-
-	gate := Gate{
-		ID:          "foo",
-		Description: "Test Gate",
-		Enabled:     true,
-	}
 
-	assert.Empty(t, r.list())
-	assert.False(t, r.isEnabled(gate.ID))
-
-	assert.NoError(t, r.add(gate))
-	assert.Len(t, r.list(), 1)
-	assert.True(t, r.isEnabled(gate.ID))
-
-	r.apply(map[string]bool{gate.ID: false})
-	assert.False(t, r.isEnabled(gate.ID))
-
-	assert.Error(t, r.add(gate))
-}
+	"go.opentelemetry.io/collector/featuregate"
+)
 
-func TestGlobalRegistry(t *testing.T) {
+func TestGatesAliases(t *testing.T) {
 	gate := Gate{
-		ID:          "feature_gate_test.foo",
+		ID:          "service_feature_gate_test.foo",
 		Description: "Test Gate",
 		Enabled:     true,
 	}
 
-	assert.NotContains(t, List(), gate)
-	assert.False(t, IsEnabled(gate.ID))
-
 	assert.NotPanics(t, func() { Register(gate) })
+	assert.Contains(t, featuregate.List(), gate)
 	assert.Contains(t, List(), gate)
 	assert.True(t, IsEnabled(gate.ID))
 
 	Apply(map[string]bool{gate.ID: false})
-	assert.False(t, IsEnabled(gate.ID))
-
-	assert.Panics(t, func() { Register(gate) })
-	reg.Lock()
-	delete(reg.gates, gate.ID)
-	reg.Unlock()
+	assert.False(t, featuregate.IsEnabled(gate.ID))
 }
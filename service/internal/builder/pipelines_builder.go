@@ -26,6 +26,7 @@ import (
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/service/internal/components"
 	"go.opentelemetry.io/collector/service/internal/fanoutconsumer"
+	"go.opentelemetry.io/collector/service/internal/routingconsumer"
 )
 
 // builtPipeline is a pipeline that is built based on a config.
@@ -119,7 +120,8 @@ func (pb *pipelinesBuilder) buildPipeline(ctx context.Context, pipelineID config
 
 	// BuildProcessors the pipeline backwards.
 
-	// First create a consumer junction point that fans out the data to all exporters.
+	// First create a consumer junction point that fans out the data to all exporters,
+	// or routes it to some of them.
 	var tc consumer.Traces
 	var mc consumer.Metrics
 	var lc consumer.Logs
@@ -128,13 +130,13 @@ func (pb *pipelinesBuilder) buildPipeline(ctx context.Context, pipelineID config
 	mutatesConsumedData := false
 	switch pipelineID.Type() {
 	case config.TracesDataType:
-		tc = pb.buildFanoutExportersTracesConsumer(pipelineCfg.Exporters)
+		tc = pb.buildExportersTracesConsumer(pipelineCfg)
 		mutatesConsumedData = tc.Capabilities().MutatesData
 	case config.MetricsDataType:
-		mc = pb.buildFanoutExportersMetricsConsumer(pipelineCfg.Exporters)
+		mc = pb.buildExportersMetricsConsumer(pipelineCfg)
 		mutatesConsumedData = mc.Capabilities().MutatesData
 	case config.LogsDataType:
-		lc = pb.buildFanoutExportersLogsConsumer(pipelineCfg.Exporters)
+		lc = pb.buildExportersLogsConsumer(pipelineCfg)
 		mutatesConsumedData = lc.Capabilities().MutatesData
 	}
 
@@ -294,6 +296,61 @@ func (pb *pipelinesBuilder) buildFanoutExportersLogsConsumer(exporterIDs []confi
 	return fanoutconsumer.NewLogs(exporters)
 }
 
+func (pb *pipelinesBuilder) buildExportersTracesConsumer(pipelineCfg *config.Pipeline) consumer.Traces {
+	if pipelineCfg.Routing == nil {
+		return pb.buildFanoutExportersTracesConsumer(pipelineCfg.Exporters)
+	}
+
+	routes := make(map[string]consumer.Traces, len(pipelineCfg.Routing.Table))
+	for _, route := range pipelineCfg.Routing.Table {
+		routes[route.Value] = pb.buildFanoutExportersTracesConsumer(route.Exporters)
+	}
+	var defaultConsumer consumer.Traces
+	if len(pipelineCfg.Routing.DefaultExporters) > 0 {
+		defaultConsumer = pb.buildFanoutExportersTracesConsumer(pipelineCfg.Routing.DefaultExporters)
+	}
+	// Create a junction point that routes the data to the exporters of its route.
+	return routingconsumer.NewTraces(router(pipelineCfg.Routing), routes, defaultConsumer)
+}
+
+func (pb *pipelinesBuilder) buildExportersMetricsConsumer(pipelineCfg *config.Pipeline) consumer.Metrics {
+	if pipelineCfg.Routing == nil {
+		return pb.buildFanoutExportersMetricsConsumer(pipelineCfg.Exporters)
+	}
+
+	routes := make(map[string]consumer.Metrics, len(pipelineCfg.Routing.Table))
+	for _, route := range pipelineCfg.Routing.Table {
+		routes[route.Value] = pb.buildFanoutExportersMetricsConsumer(route.Exporters)
+	}
+	var defaultConsumer consumer.Metrics
+	if len(pipelineCfg.Routing.DefaultExporters) > 0 {
+		defaultConsumer = pb.buildFanoutExportersMetricsConsumer(pipelineCfg.Routing.DefaultExporters)
+	}
+	// Create a junction point that routes the data to the exporters of its route.
+	return routingconsumer.NewMetrics(router(pipelineCfg.Routing), routes, defaultConsumer)
+}
+
+func (pb *pipelinesBuilder) buildExportersLogsConsumer(pipelineCfg *config.Pipeline) consumer.Logs {
+	if pipelineCfg.Routing == nil {
+		return pb.buildFanoutExportersLogsConsumer(pipelineCfg.Exporters)
+	}
+
+	routes := make(map[string]consumer.Logs, len(pipelineCfg.Routing.Table))
+	for _, route := range pipelineCfg.Routing.Table {
+		routes[route.Value] = pb.buildFanoutExportersLogsConsumer(route.Exporters)
+	}
+	var defaultConsumer consumer.Logs
+	if len(pipelineCfg.Routing.DefaultExporters) > 0 {
+		defaultConsumer = pb.buildFanoutExportersLogsConsumer(pipelineCfg.Routing.DefaultExporters)
+	}
+	// Create a junction point that routes the data to the exporters of its route.
+	return routingconsumer.NewLogs(router(pipelineCfg.Routing), routes, defaultConsumer)
+}
+
+func router(cfg *config.PipelineRouting) routingconsumer.Router {
+	return routingconsumer.Router{FromAttribute: cfg.FromAttribute, FromContext: cfg.FromContext}
+}
+
 type capabilitiesLogs struct {
 	consumer.Logs
 	capabilities consumer.Capabilities
@@ -231,6 +231,38 @@ func testPipeline(t *testing.T, pipelineID config.ComponentID, exporterIDs []con
 	assert.NoError(t, err)
 }
 
+func TestBuildPipelines_Routing(t *testing.T) {
+	factories, err := testcomponents.ExampleComponents()
+	assert.NoError(t, err)
+	cfg, err := configtest.LoadConfigAndValidate("testdata/pipelines_routing.yaml", factories)
+	require.NoError(t, err)
+
+	allExporters, err := BuildExporters(componenttest.NewNopTelemetrySettings(), component.NewDefaultBuildInfo(), cfg, factories.Exporters)
+	require.NoError(t, err)
+	pipelineProcessors, err := BuildPipelines(componenttest.NewNopTelemetrySettings(), component.NewDefaultBuildInfo(), cfg, allExporters, factories.Processors)
+	require.NoError(t, err)
+	assert.NoError(t, pipelineProcessors.StartProcessors(context.Background(), componenttest.NewNopHost()))
+
+	processor := pipelineProcessors[config.NewComponentID("traces")]
+	require.NotNil(t, processor)
+
+	// The resource of tenant "a" goes to its route, the other one to the default route.
+	td := testdata.GenerateTracesTwoSpansSameResource()
+	td.ResourceSpans().At(0).CopyTo(td.ResourceSpans().AppendEmpty())
+	td.ResourceSpans().At(0).Resource().Attributes().UpsertString("tenant", "a")
+	td.ResourceSpans().At(1).Resource().Attributes().UpsertString("tenant", "b")
+	require.NoError(t, processor.firstTC.ConsumeTraces(context.Background(), td))
+
+	routed := allExporters[config.NewComponentID("exampleexporter")].getTracesExporter().(*testcomponents.ExampleExporterConsumer)
+	require.Len(t, routed.Traces, 1)
+	assert.EqualValues(t, td.ResourceSpans().At(0), routed.Traces[0].ResourceSpans().At(0))
+	notRouted := allExporters[config.NewComponentIDWithName("exampleexporter", "2")].getTracesExporter().(*testcomponents.ExampleExporterConsumer)
+	require.Len(t, notRouted.Traces, 1)
+	assert.EqualValues(t, td.ResourceSpans().At(1), notRouted.Traces[0].ResourceSpans().At(0))
+
+	assert.NoError(t, pipelineProcessors.ShutdownProcessors(context.Background()))
+}
+
 func TestBuildPipelines_NotSupportedDataType(t *testing.T) {
 	factories := createTestFactories()
 
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routingconsumer // import "go.opentelemetry.io/collector/service/internal/routingconsumer"
+
+import (
+	"context"
+
+	"go.uber.org/multierr"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/internal/batchutil"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// NewLogs returns a consumer sending the data to the consumer of the route whose value matches its routing
+// value, or to the default consumer if none matches. The data matching no route is dropped if the default
+// consumer is nil.
+func NewLogs(router Router, routes map[string]consumer.Logs, defaultConsumer consumer.Logs) consumer.Logs {
+	rc := &logsConsumer{defaultConsumer: defaultConsumer}
+	values := make([]string, 0, len(routes))
+	for value, next := range routes {
+		values = append(values, value)
+		rc.consumers = append(rc.consumers, next)
+		rc.mutatesData = rc.mutatesData || next.Capabilities().MutatesData
+	}
+	if defaultConsumer != nil {
+		rc.mutatesData = rc.mutatesData || defaultConsumer.Capabilities().MutatesData
+	}
+	rc.table = newRouteTable(router, values)
+	return rc
+}
+
+type logsConsumer struct {
+	table           routeTable
+	consumers       []consumer.Logs
+	defaultConsumer consumer.Logs
+	mutatesData     bool
+}
+
+func (rc *logsConsumer) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: rc.mutatesData}
+}
+
+func (rc *logsConsumer) consumer(route int) consumer.Logs {
+	if route == defaultRoute {
+		return rc.defaultConsumer
+	}
+	return rc.consumers[route]
+}
+
+// ConsumeLogs sends the pdata.Logs to the consumers of their routes.
+func (rc *logsConsumer) ConsumeLogs(ctx context.Context, ld pdata.Logs) error {
+	if rc.table.router.FromContext != "" {
+		if next := rc.consumer(rc.table.contextRoute(ctx)); next != nil {
+			return next.ConsumeLogs(ctx, ld)
+		}
+		return nil
+	}
+
+	var errs error
+	for key, group := range batchutil.GroupLogsByResource(ld, rc.table.resourceKey) {
+		if next := rc.consumer(keyRoute(key)); next != nil {
+			errs = multierr.Append(errs, next.ConsumeLogs(ctx, group))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routingconsumer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// generateLogs returns logs with one resource, and one log record, for every one of the tenants.
+func generateLogs(tenants ...string) pdata.Logs {
+	ld := pdata.NewLogs()
+	for _, tenant := range tenants {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().InsertString("tenant", tenant)
+		rl.InstrumentationLibraryLogs().AppendEmpty().Logs().AppendEmpty().SetName(tenant)
+	}
+	return ld
+}
+
+func TestLogsFromAttribute(t *testing.T) {
+	sinkA := new(consumertest.LogsSink)
+	sinkB := new(consumertest.LogsSink)
+	rc := NewLogs(Router{FromAttribute: "tenant"}, map[string]consumer.Logs{"a": sinkA, "b": sinkB}, nil)
+
+	require.NoError(t, rc.ConsumeLogs(context.Background(), generateLogs("a", "b", "c")))
+	assert.Equal(t, 1, sinkA.LogRecordCount())
+	assert.Equal(t, 1, sinkB.LogRecordCount())
+}
+
+func TestLogsFromContext(t *testing.T) {
+	sinkA := new(consumertest.LogsSink)
+	sinkDefault := new(consumertest.LogsSink)
+	rc := NewLogs(Router{FromContext: "x-tenant"}, map[string]consumer.Logs{"a": sinkA}, sinkDefault)
+
+	ctx := client.NewContext(context.Background(), &client.Client{Metadata: map[string][]string{"x-tenant": {"c"}}})
+	require.NoError(t, rc.ConsumeLogs(ctx, generateLogs("a")))
+	assert.Equal(t, 0, sinkA.LogRecordCount())
+	assert.Equal(t, 1, sinkDefault.LogRecordCount())
+}
@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routingconsumer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// generateMetrics returns metrics with one resource, and one data point, for every one of the tenants.
+func generateMetrics(tenants ...string) pdata.Metrics {
+	md := pdata.NewMetrics()
+	for _, tenant := range tenants {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().InsertString("tenant", tenant)
+		metric := rm.InstrumentationLibraryMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName(tenant)
+		metric.SetDataType(pdata.MetricDataTypeGauge)
+		metric.Gauge().DataPoints().AppendEmpty().SetIntVal(1)
+	}
+	return md
+}
+
+func TestMetricsFromAttribute(t *testing.T) {
+	sinkA := new(consumertest.MetricsSink)
+	sinkDefault := new(consumertest.MetricsSink)
+	rc := NewMetrics(Router{FromAttribute: "tenant"}, map[string]consumer.Metrics{"a": sinkA}, sinkDefault)
+
+	require.NoError(t, rc.ConsumeMetrics(context.Background(), generateMetrics("a", "b", "a")))
+	assert.Equal(t, 2, sinkA.DataPointCount())
+	assert.Equal(t, 1, sinkDefault.DataPointCount())
+}
+
+func TestMetricsFromContext(t *testing.T) {
+	sinkA := new(consumertest.MetricsSink)
+	sinkB := new(consumertest.MetricsSink)
+	rc := NewMetrics(Router{FromContext: "x-tenant"}, map[string]consumer.Metrics{"a": sinkA, "b": sinkB}, nil)
+
+	ctx := client.NewContext(context.Background(), &client.Client{Metadata: map[string][]string{"x-tenant": {"b"}}})
+	require.NoError(t, rc.ConsumeMetrics(ctx, generateMetrics("a", "a")))
+	assert.Equal(t, 0, sinkA.DataPointCount())
+	assert.Equal(t, 2, sinkB.DataPointCount())
+
+	// The requests matching no route are dropped without default route.
+	require.NoError(t, rc.ConsumeMetrics(context.Background(), generateMetrics("a")))
+	assert.Equal(t, 0, sinkA.DataPointCount())
+}
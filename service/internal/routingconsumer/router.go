@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routingconsumer // import "go.opentelemetry.io/collector/service/internal/routingconsumer"
+
+import (
+	"context"
+	"strconv"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// defaultRoute is the index of the default route.
+const defaultRoute = -1
+
+// Router defines where the routing value of the data is read from, only one of the fields must be set.
+type Router struct {
+	// FromAttribute is the resource attribute whose value routes every resource.
+	FromAttribute string
+	// FromContext is the client metadata key whose value routes all the data of a request.
+	FromContext string
+}
+
+// routeTable maps the routing values to the index of their route.
+type routeTable struct {
+	router  Router
+	indexes map[string]int
+}
+
+func newRouteTable(router Router, values []string) routeTable {
+	indexes := make(map[string]int, len(values))
+	for i, value := range values {
+		indexes[value] = i
+	}
+	return routeTable{router: router, indexes: indexes}
+}
+
+func (rt routeTable) route(value string, ok bool) int {
+	if !ok {
+		return defaultRoute
+	}
+	if i, ok := rt.indexes[value]; ok {
+		return i
+	}
+	return defaultRoute
+}
+
+// contextRoute returns the route of the data of the request of the context.
+func (rt routeTable) contextRoute(ctx context.Context) int {
+	c, ok := client.FromContext(ctx)
+	if !ok {
+		return defaultRoute
+	}
+	return rt.route(c.MetadataValue(rt.router.FromContext))
+}
+
+// resourceKey returns the key grouping the resources of the same route.
+func (rt routeTable) resourceKey(res pdata.Resource) string {
+	v, ok := res.Attributes().Get(rt.router.FromAttribute)
+	if !ok {
+		return strconv.Itoa(defaultRoute)
+	}
+	return strconv.Itoa(rt.route(v.AsString(), true))
+}
+
+// keyRoute returns the route of a key returned by resourceKey.
+func keyRoute(key string) int {
+	i, _ := strconv.Atoi(key)
+	return i
+}
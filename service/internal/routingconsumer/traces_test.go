@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routingconsumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/model/pdata"
+)
+
+// generateTraces returns traces with one resource, and one span, for every one of the tenants. An empty tenant
+// is a resource without the tenant attribute.
+func generateTraces(tenants ...string) pdata.Traces {
+	td := pdata.NewTraces()
+	for _, tenant := range tenants {
+		rs := td.ResourceSpans().AppendEmpty()
+		if tenant != "" {
+			rs.Resource().Attributes().InsertString("tenant", tenant)
+		}
+		rs.InstrumentationLibrarySpans().AppendEmpty().Spans().AppendEmpty().SetName(tenant)
+	}
+	return td
+}
+
+func TestTracesFromAttribute(t *testing.T) {
+	sinkA := new(consumertest.TracesSink)
+	sinkB := new(consumertest.TracesSink)
+	sinkDefault := new(consumertest.TracesSink)
+	rc := NewTraces(Router{FromAttribute: "tenant"},
+		map[string]consumer.Traces{"a": sinkA, "b": sinkB}, sinkDefault)
+	assert.False(t, rc.Capabilities().MutatesData)
+
+	require.NoError(t, rc.ConsumeTraces(context.Background(), generateTraces("a", "b", "a", "c", "")))
+	assert.Equal(t, 2, sinkA.SpanCount())
+	assert.Equal(t, 1, sinkB.SpanCount())
+	assert.Equal(t, 2, sinkDefault.SpanCount())
+	assert.Len(t, sinkA.AllTraces(), 1)
+	assert.Len(t, sinkDefault.AllTraces(), 1)
+}
+
+func TestTracesFromContext(t *testing.T) {
+	sinkA := new(consumertest.TracesSink)
+	sinkDefault := new(consumertest.TracesSink)
+	rc := NewTraces(Router{FromContext: "X-Tenant"}, map[string]consumer.Traces{"a": sinkA}, sinkDefault)
+
+	td := generateTraces("b", "c")
+	ctx := client.NewContext(context.Background(), &client.Client{Metadata: map[string][]string{"x-tenant": {"a"}}})
+	require.NoError(t, rc.ConsumeTraces(ctx, td))
+	require.Len(t, sinkA.AllTraces(), 1)
+	assert.True(t, td == sinkA.AllTraces()[0])
+
+	// The requests without client metadata go to the default route.
+	require.NoError(t, rc.ConsumeTraces(context.Background(), td))
+	assert.Equal(t, 2, sinkDefault.SpanCount())
+}
+
+func TestTracesNoDefault(t *testing.T) {
+	sinkA := new(consumertest.TracesSink)
+	rc := NewTraces(Router{FromAttribute: "tenant"}, map[string]consumer.Traces{"a": sinkA}, nil)
+
+	require.NoError(t, rc.ConsumeTraces(context.Background(), generateTraces("a", "b")))
+	assert.Equal(t, 1, sinkA.SpanCount())
+}
+
+func TestTracesError(t *testing.T) {
+	err := errors.New("my error")
+	rc := NewTraces(Router{FromAttribute: "tenant"},
+		map[string]consumer.Traces{"a": consumertest.NewErr(err)}, new(consumertest.TracesSink))
+
+	assert.ErrorIs(t, rc.ConsumeTraces(context.Background(), generateTraces("a", "b")), err)
+}
+
+func TestTracesMutatingCapabilities(t *testing.T) {
+	rc := NewTraces(Router{FromAttribute: "tenant"},
+		map[string]consumer.Traces{"a": new(consumertest.TracesSink)}, &mutatingTracesSink{})
+	assert.True(t, rc.Capabilities().MutatesData)
+}
+
+type mutatingTracesSink struct {
+	consumertest.TracesSink
+}
+
+func (mts *mutatingTracesSink) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
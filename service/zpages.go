@@ -81,11 +81,43 @@ func (srv *service) handlePipelinezRequest(w http.ResponseWriter, r *http.Reques
 		zpages.WriteHTMLComponentHeader(w, zpages.ComponentHeaderData{
 			Name: componentKind + ": " + fullName,
 		})
+		if componentKind == "exporter" {
+			if props := srv.getExporterStatusProperties(componentName); len(props) > 0 {
+				zpages.WriteHTMLPropertiesTable(w, zpages.PropertiesTableData{Name: "Status", Properties: props})
+			}
+		}
 		// TODO: Add config + status info.
 	}
 	zpages.WriteHTMLPageFooter(w)
 }
 
+// circuitBreakerStateReporter is implemented by the exporters that use a circuit breaker,
+// e.g. the exporters created with the exporterhelper.
+type circuitBreakerStateReporter interface {
+	// CircuitBreakerState returns the current state of the circuit breaker,
+	// or an empty string if the circuit breaker is not enabled.
+	CircuitBreakerState() string
+}
+
+// getExporterStatusProperties returns the status of the exporter with the given name for every data type.
+func (srv *service) getExporterStatusProperties(exporterName string) [][2]string {
+	var props [][2]string
+	for dataType, exps := range srv.builtExporters.ToMapByDataType() {
+		for expID, exp := range exps {
+			if expID.String() != exporterName {
+				continue
+			}
+			if cb, ok := exp.(circuitBreakerStateReporter); ok && cb.CircuitBreakerState() != "" {
+				props = append(props, [2]string{string(dataType) + " circuit breaker", cb.CircuitBreakerState()})
+			}
+		}
+	}
+	sort.Slice(props, func(i, j int) bool {
+		return props[i][0] < props[j][0]
+	})
+	return props
+}
+
 func (srv *service) getPipelinesSummaryTableData() zpages.SummaryPipelinesTableData {
 	data := zpages.SummaryPipelinesTableData{}
 